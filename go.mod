@@ -3,7 +3,11 @@ module github.com/abneribeiro/goapi
 go 1.24.0
 
 require (
+	github.com/SherClockHolmes/webpush-go v1.4.0
 	github.com/google/uuid v1.6.0
+	github.com/gorilla/websocket v1.5.3
 	github.com/lib/pq v1.10.9
 	golang.org/x/crypto v0.45.0
 )
+
+require github.com/golang-jwt/jwt/v5 v5.2.1 // indirect
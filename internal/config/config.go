@@ -2,6 +2,8 @@ package config
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -37,13 +39,42 @@ func loadEnvFile(filename string) {
 	}
 }
 
+// Deployment environments. Anything other than EnvDevelopment is treated as
+// a shared environment, where settings with unsafe defaults must be given.
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// ProviderNone turns a delivery channel off. Outside development a channel
+// has to be turned off this way rather than by leaving its provider empty.
+const ProviderNone = "none"
+
+// devWebhookSecret signs payment webhooks in development. It is public, so
+// Validate refuses it anywhere else.
+const devWebhookSecret = "default-webhook-secret-change-me"
+
 type Config struct {
+	App      AppConfig
 	Server   ServerConfig
 	Database DatabaseConfig
 	JWT      JWTConfig
 	Log      LogConfig
 	Upload   UploadConfig
 	Docs     DocsConfig
+	Pricing  PricingConfig
+	Payment  PaymentConfig
+	Jobs     JobsConfig
+	Queue    QueueConfig
+	Outbox   OutboxConfig
+	Webhook  WebhookConfig
+	Stream   StreamConfig
+	Live     LiveConfig
+	Delivery DeliveryConfig
+}
+
+type AppConfig struct {
+	Env string
 }
 
 type ServerConfig struct {
@@ -78,8 +109,97 @@ type DocsConfig struct {
 	Path string
 }
 
-func Load() *Config {
-	return &Config{
+type PricingConfig struct {
+	ServiceFeePercent float64
+	TaxPercent        float64
+	QuoteSecret       string
+	QuoteTTL          time.Duration
+	ExchangeRatesPath string
+}
+
+type PaymentConfig struct {
+	Provider      string
+	WebhookSecret string
+}
+
+type QueueConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	MaxAttempts  int
+	LockTimeout  time.Duration
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	Broker       string
+	TopicPrefix  string
+	WebhookURLs  []string
+}
+
+type WebhookConfig struct {
+	MaxAttempts  int
+	DisableAfter int
+	Timeout      time.Duration
+}
+
+type StreamConfig struct {
+	Heartbeat time.Duration
+}
+
+type LiveConfig struct {
+	SendBuffer     int
+	MaxTopics      int
+	PingInterval   time.Duration
+	AllowedOrigins []string
+}
+
+// DeliveryConfig selects how notifications are sent besides in the app.
+// A provider set to ProviderNone, or left empty in development, turns its
+// channel off.
+type DeliveryConfig struct {
+	AppURL string
+
+	EmailProvider string
+	SMTPHost      string
+	SMTPPort      int
+	SMTPUsername  string
+	SMTPPassword  string
+	EmailFrom     string
+
+	SMSProvider      string
+	SMSFrom          string
+	TwilioAccountSID string
+	TwilioAuthToken  string
+
+	PushProvider    string
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	VAPIDSubject    string
+}
+
+type JobsConfig struct {
+	Interval           time.Duration
+	LateReturnInterval time.Duration
+	PendingExpiry      time.Duration
+	ReminderLead       time.Duration
+	AutoCompleteAfter  time.Duration
+	// NotificationRetention is how long read notifications are kept; zero
+	// keeps them forever.
+	NotificationRetention     time.Duration
+	NotificationPurgeInterval time.Duration
+}
+
+// Load reads the configuration from the environment and validates it, so a
+// deployment with unsafe settings does not start.
+func Load() (*Config, error) {
+	env := getEnv("APP_ENV", EnvDevelopment)
+
+	cfg := &Config{
+		App: AppConfig{
+			Env: env,
+		},
 		Server: ServerConfig{
 			Host: getEnv("SERVER_HOST", "0.0.0.0"),
 			Port: getEnv("SERVER_PORT", "8080"),
@@ -106,7 +226,126 @@ func Load() *Config {
 		Docs: DocsConfig{
 			Path: getEnv("DOCS_PATH", "./docs"),
 		},
+		Pricing: PricingConfig{
+			ServiceFeePercent: getEnvAsFloat("SERVICE_FEE_PERCENT", 0),
+			TaxPercent:        getEnvAsFloat("TAX_PERCENT", 0),
+			QuoteSecret:       getEnv("QUOTE_SECRET", getEnv("JWT_SECRET", "default-secret-change-me")),
+			QuoteTTL:          time.Duration(getEnvAsInt("QUOTE_TTL_MINUTES", 15)) * time.Minute,
+			ExchangeRatesPath: getEnv("EXCHANGE_RATES_PATH", ""),
+		},
+		Payment: PaymentConfig{
+			Provider:      getEnv("PAYMENT_PROVIDER", devDefault(env, "fake")),
+			WebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", devDefault(env, devWebhookSecret)),
+		},
+		Jobs: JobsConfig{
+			Interval:                  time.Duration(getEnvAsInt("JOBS_INTERVAL_MINUTES", 5)) * time.Minute,
+			LateReturnInterval:        time.Duration(getEnvAsInt("LATE_RETURN_CHECK_MINUTES", 15)) * time.Minute,
+			PendingExpiry:             time.Duration(getEnvAsInt("PENDING_EXPIRY_HOURS", 48)) * time.Hour,
+			ReminderLead:              time.Duration(getEnvAsInt("REMINDER_LEAD_HOURS", 24)) * time.Hour,
+			AutoCompleteAfter:         time.Duration(getEnvAsInt("AUTO_COMPLETE_HOURS", 72)) * time.Hour,
+			NotificationRetention:     time.Duration(getEnvAsInt("NOTIFICATION_RETENTION_DAYS", 90)) * 24 * time.Hour,
+			NotificationPurgeInterval: time.Duration(getEnvAsInt("NOTIFICATION_PURGE_INTERVAL_MINUTES", 60)) * time.Minute,
+		},
+		Queue: QueueConfig{
+			Workers:      getEnvAsInt("QUEUE_WORKERS", 4),
+			PollInterval: time.Duration(getEnvAsInt("QUEUE_POLL_SECONDS", 1)) * time.Second,
+			MaxAttempts:  getEnvAsInt("QUEUE_MAX_ATTEMPTS", 8),
+			LockTimeout:  time.Duration(getEnvAsInt("QUEUE_LOCK_TIMEOUT_MINUTES", 10)) * time.Minute,
+		},
+		Outbox: OutboxConfig{
+			PollInterval: time.Duration(getEnvAsInt("OUTBOX_POLL_SECONDS", 1)) * time.Second,
+			BatchSize:    getEnvAsInt("OUTBOX_BATCH_SIZE", 100),
+			MaxAttempts:  getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10),
+			Broker:       getEnv("OUTBOX_BROKER", ""),
+			TopicPrefix:  getEnv("OUTBOX_TOPIC_PREFIX", "goapi"),
+			WebhookURLs:  getEnvAsList("OUTBOX_WEBHOOK_URLS"),
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts:  getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			DisableAfter: getEnvAsInt("WEBHOOK_DISABLE_AFTER_FAILURES", 20),
+			Timeout:      time.Duration(getEnvAsInt("WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second,
+		},
+		Stream: StreamConfig{
+			Heartbeat: time.Duration(getEnvAsInt("STREAM_HEARTBEAT_SECONDS", 25)) * time.Second,
+		},
+		Live: LiveConfig{
+			SendBuffer:     getEnvAsInt("LIVE_SEND_BUFFER", 64),
+			MaxTopics:      getEnvAsInt("LIVE_MAX_TOPICS", 50),
+			PingInterval:   time.Duration(getEnvAsInt("LIVE_PING_SECONDS", 30)) * time.Second,
+			AllowedOrigins: getEnvAsList("LIVE_ALLOWED_ORIGINS"),
+		},
+		Delivery: DeliveryConfig{
+			AppURL:           getEnv("APP_URL", "http://localhost:3000"),
+			EmailProvider:    getEnv("EMAIL_PROVIDER", devDefault(env, "fake")),
+			SMTPHost:         getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:         getEnvAsInt("SMTP_PORT", 587),
+			SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+			EmailFrom:        getEnv("EMAIL_FROM", "GoAPI <noreply@goapi.local>"),
+			SMSProvider:      getEnv("SMS_PROVIDER", devDefault(env, "fake")),
+			SMSFrom:          getEnv("SMS_FROM", ""),
+			TwilioAccountSID: getEnv("TWILIO_ACCOUNT_SID", ""),
+			TwilioAuthToken:  getEnv("TWILIO_AUTH_TOKEN", ""),
+			PushProvider:     getEnv("PUSH_PROVIDER", devDefault(env, "fake")),
+			VAPIDPublicKey:   getEnv("VAPID_PUBLIC_KEY", ""),
+			VAPIDPrivateKey:  getEnv("VAPID_PRIVATE_KEY", ""),
+			VAPIDSubject:     getEnv("VAPID_SUBJECT", "mailto:admin@goapi.local"),
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate reports settings that are unsafe for c.App.Env. Outside
+// development the payment provider and its webhook secret have to be set
+// explicitly, and production may not use the fake payment provider. Each
+// delivery channel needs a real provider or ProviderNone, since the fake
+// ones drop every message.
+func (c *Config) Validate() error {
+	if c.App.Env == EnvDevelopment {
+		return nil
+	}
+
+	var errs []error
+	switch c.Payment.Provider {
+	case "":
+		errs = append(errs, fmt.Errorf("PAYMENT_PROVIDER must be set when APP_ENV is %s", c.App.Env))
+	case "fake":
+		if c.App.Env == EnvProduction {
+			errs = append(errs, errors.New("PAYMENT_PROVIDER=fake is not allowed in production"))
+		}
+	}
+	if c.Payment.WebhookSecret == "" || c.Payment.WebhookSecret == devWebhookSecret {
+		errs = append(errs, fmt.Errorf("PAYMENT_WEBHOOK_SECRET must be set when APP_ENV is %s", c.App.Env))
+	}
+	for _, channel := range []struct{ key, provider string }{
+		{"EMAIL_PROVIDER", c.Delivery.EmailProvider},
+		{"SMS_PROVIDER", c.Delivery.SMSProvider},
+		{"PUSH_PROVIDER", c.Delivery.PushProvider},
+	} {
+		key := channel.key
+		switch channel.provider {
+		case "":
+			errs = append(errs, fmt.Errorf("%s must be set when APP_ENV is %s, to %s to turn the channel off", key, c.App.Env, ProviderNone))
+		case "fake":
+			errs = append(errs, fmt.Errorf("%s=fake is only allowed in development", key))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// devDefault returns value in development and nothing elsewhere, for
+// settings whose convenient default must never reach a deployment.
+func devDefault(env, value string) string {
+	if env == EnvDevelopment {
+		return value
 	}
+	return ""
 }
 
 func (c *Config) ServerAddress() string {
@@ -137,3 +376,22 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
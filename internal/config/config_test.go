@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		provider string
+		secret   string
+		wantErr  bool
+	}{
+		{"development without settings", EnvDevelopment, "", "", false},
+		{"development with defaults", EnvDevelopment, "fake", devWebhookSecret, false},
+		{"staging without provider", "staging", "", "whsec", true},
+		{"staging with fake", "staging", "fake", "whsec", false},
+		{"staging without secret", "staging", "fake", "", true},
+		{"staging with default secret", "staging", "fake", devWebhookSecret, true},
+		{"production without provider", EnvProduction, "", "whsec", true},
+		{"production with fake", EnvProduction, "fake", "whsec", true},
+		{"production with real provider", EnvProduction, "stripe", "whsec", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				App:      AppConfig{Env: tt.env},
+				Payment:  PaymentConfig{Provider: tt.provider, WebhookSecret: tt.secret},
+				Delivery: DeliveryConfig{EmailProvider: "smtp", SMSProvider: ProviderNone, PushProvider: ProviderNone},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateDelivery(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		email   string
+		sms     string
+		push    string
+		wantErr bool
+	}{
+		{"development with fakes", EnvDevelopment, "fake", "fake", "fake", false},
+		{"development with channels off", EnvDevelopment, "", "", "", false},
+		{"production with real providers", EnvProduction, "smtp", "twilio", "webpush", false},
+		{"production with channels turned off", EnvProduction, "smtp", ProviderNone, ProviderNone, false},
+		{"production with fake email", EnvProduction, "fake", ProviderNone, ProviderNone, true},
+		{"staging with fake push", "staging", "smtp", ProviderNone, "fake", true},
+		{"production without sms provider", EnvProduction, "smtp", "", ProviderNone, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				App:      AppConfig{Env: tt.env},
+				Payment:  PaymentConfig{Provider: "stripe", WebhookSecret: "whsec"},
+				Delivery: DeliveryConfig{EmailProvider: tt.email, SMSProvider: tt.sms, PushProvider: tt.push},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_DefaultsByEnvironment(t *testing.T) {
+	t.Run("development", func(t *testing.T) {
+		t.Setenv("APP_ENV", EnvDevelopment)
+		unsetenv(t, "PAYMENT_PROVIDER", "PAYMENT_WEBHOOK_SECRET", "EMAIL_PROVIDER", "SMS_PROVIDER", "PUSH_PROVIDER")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if cfg.Payment.Provider != "fake" || cfg.Payment.WebhookSecret != devWebhookSecret {
+			t.Errorf("Payment = %+v, want the development defaults", cfg.Payment)
+		}
+		if d := cfg.Delivery; d.EmailProvider != "fake" || d.SMSProvider != "fake" || d.PushProvider != "fake" {
+			t.Errorf("Delivery providers = %q, %q, %q, want fake", d.EmailProvider, d.SMSProvider, d.PushProvider)
+		}
+	})
+
+	t.Run("production without settings", func(t *testing.T) {
+		t.Setenv("APP_ENV", EnvProduction)
+		unsetenv(t, "PAYMENT_PROVIDER", "PAYMENT_WEBHOOK_SECRET")
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should refuse a production config without a payment provider and secret")
+		}
+	})
+}
+
+// unsetenv removes keys for the rest of the test, restoring them after.
+func unsetenv(t *testing.T, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+}
@@ -2,8 +2,11 @@ package database
 
 import (
 	"database/sql"
+	"strings"
+	"unicode"
 
 	"github.com/abneribeiro/goapi/internal/pkg/logger"
+	"github.com/abneribeiro/goapi/internal/pkg/slug"
 )
 
 func RunMigrations(db *sql.DB) error {
@@ -13,6 +16,36 @@ func RunMigrations(db *sql.DB) error {
 		createEquipmentPhotosTable,
 		createReservationsTable,
 		createNotificationsTable,
+		createCategoriesTable,
+		addEquipmentCategoryColumn,
+		addEquipmentSpecificationsColumn,
+		addEquipmentInventoryColumns,
+		createEquipmentUnitsTable,
+		addReservationQuantityColumn,
+		createReservationUnitsTable,
+		createEquipmentBlackoutsTable,
+		addEquipmentTimeZoneColumn,
+		createEquipmentOpeningHoursTable,
+		createPricingRulesTable,
+		addReservationPriceBreakdownColumn,
+		addCurrencyColumns,
+		addDepositColumns,
+		createPaymentsTable,
+		createLedgerEntriesTable,
+		addCancellationPolicyColumns,
+		createReservationEventsTable,
+		createHandoverReportsTable,
+		addLateReturnColumn,
+		createReservationChangesTable,
+		addReminderColumns,
+		createJobsTable,
+		createJobRunsTable,
+		createOutboxEventsTable,
+		createWebhookTables,
+		addNotificationSequence,
+		createNotificationDeliveryTables,
+		createNotificationPreferenceTables,
+		createNotificationTemplateTables,
 		createIndexes,
 	}
 
@@ -22,6 +55,16 @@ func RunMigrations(db *sql.DB) error {
 		}
 	}
 
+	dataMigrations := []func(*sql.DB) error{
+		normalizeEquipmentCategories,
+	}
+
+	for _, migration := range dataMigrations {
+		if err := migration(db); err != nil {
+			return err
+		}
+	}
+
 	logger.Info("database migrations completed successfully")
 	return nil
 }
@@ -99,15 +142,626 @@ CREATE TABLE IF NOT EXISTS notifications (
 );
 `
 
+const createCategoriesTable = `
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    parent_id UUID REFERENCES categories(id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(120) UNIQUE NOT NULL,
+    icon VARCHAR(100),
+    attributes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const addEquipmentCategoryColumn = `
+ALTER TABLE equipment ADD COLUMN IF NOT EXISTS category_id UUID REFERENCES categories(id) ON DELETE RESTRICT;
+`
+
+const addEquipmentSpecificationsColumn = `
+ALTER TABLE equipment ADD COLUMN IF NOT EXISTS specifications JSONB NOT NULL DEFAULT '{}';
+`
+
+const addEquipmentInventoryColumns = `
+ALTER TABLE equipment ADD COLUMN IF NOT EXISTS inventory_mode VARCHAR(20) NOT NULL DEFAULT 'pooled';
+ALTER TABLE equipment ADD COLUMN IF NOT EXISTS quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity >= 0);
+`
+
+const createEquipmentUnitsTable = `
+CREATE TABLE IF NOT EXISTS equipment_units (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    equipment_id UUID NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    serial_number VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'available',
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (equipment_id, serial_number)
+);
+`
+
+const addReservationQuantityColumn = `
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity >= 1);
+`
+
+// createReservationUnitsTable copies each reservation's dates and whether
+// it still holds its units onto reservation_units, kept in sync by a
+// trigger, so an exclusion constraint can refuse two live bookings of one
+// unit that overlap in time.
+const createReservationUnitsTable = `
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE IF NOT EXISTS reservation_units (
+    reservation_id UUID NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    unit_id UUID NOT NULL REFERENCES equipment_units(id) ON DELETE RESTRICT,
+    period TSTZRANGE NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (reservation_id, unit_id),
+    CONSTRAINT reservation_units_no_overlap EXCLUDE USING gist (unit_id WITH =, period WITH &&) WHERE (active)
+);
+
+CREATE OR REPLACE FUNCTION sync_reservation_units() RETURNS trigger AS $$
+BEGIN
+    UPDATE reservation_units
+    SET period = tstzrange(NEW.start_date, NEW.end_date),
+        active = NEW.status IN ('pending', 'approved', 'active')
+    WHERE reservation_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS reservations_sync_units ON reservations;
+CREATE TRIGGER reservations_sync_units
+AFTER UPDATE OF status, start_date, end_date ON reservations
+FOR EACH ROW EXECUTE FUNCTION sync_reservation_units();
+`
+
+const createEquipmentBlackoutsTable = `
+CREATE TABLE IF NOT EXISTS equipment_blackouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    equipment_id UUID NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    start_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    reason VARCHAR(30) NOT NULL,
+    notes TEXT,
+    recur_weekly BOOLEAN NOT NULL DEFAULT false,
+    recur_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date > start_date)
+);
+`
+
+const addEquipmentTimeZoneColumn = `
+ALTER TABLE equipment ADD COLUMN IF NOT EXISTS time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+`
+
+const createEquipmentOpeningHoursTable = `
+CREATE TABLE IF NOT EXISTS equipment_opening_hours (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    equipment_id UUID NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    opens_minute SMALLINT NOT NULL CHECK (opens_minute BETWEEN 0 AND 1440),
+    closes_minute SMALLINT NOT NULL CHECK (closes_minute BETWEEN 0 AND 1440),
+    CHECK (closes_minute > opens_minute)
+);
+`
+
+const createPricingRulesTable = `
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    equipment_id UUID NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    name VARCHAR(255),
+    percent DECIMAL(6, 2),
+    amount DECIMAL(10, 2),
+    min_days INT,
+    starts_on DATE,
+    ends_on DATE,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+const addReservationPriceBreakdownColumn = `
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS price_breakdown JSONB;
+`
+
+// addCurrencyColumns stores a currency next to every amount and widens the
+// amount columns to three decimals for currencies such as KWD. Changing a
+// column's type rewrites its table, so it is only done while a column still
+// has the old scale.
+const addCurrencyColumns = `
+ALTER TABLE equipment ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema()
+        AND (table_name, column_name) IN (
+            ('equipment', 'price_per_hour'),
+            ('equipment', 'price_per_day'),
+            ('equipment', 'price_per_week'),
+            ('reservations', 'total_price'),
+            ('pricing_rules', 'amount')
+        )
+        AND (numeric_precision, numeric_scale) IS DISTINCT FROM (12, 3)
+    ) THEN
+        ALTER TABLE equipment
+            ALTER COLUMN price_per_hour TYPE NUMERIC(12, 3),
+            ALTER COLUMN price_per_day TYPE NUMERIC(12, 3),
+            ALTER COLUMN price_per_week TYPE NUMERIC(12, 3);
+        ALTER TABLE reservations ALTER COLUMN total_price TYPE NUMERIC(12, 3);
+        ALTER TABLE pricing_rules ALTER COLUMN amount TYPE NUMERIC(12, 3);
+    END IF;
+END;
+$$;
+`
+
+const addDepositColumns = `
+ALTER TABLE equipment ADD COLUMN IF NOT EXISTS deposit_amount NUMERIC(12, 3);
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS deposit_status VARCHAR(20);
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS deposit_amount NUMERIC(12, 3);
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS deposit_captured NUMERIC(12, 3);
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS deposit_capture_reason TEXT;
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS deposit_settled_at TIMESTAMP WITH TIME ZONE;
+`
+
+// createReservationEventsTable is the audit trail of status changes. A null
+// actor_id means the change was made by the system.
+const createReservationEventsTable = `
+CREATE TABLE IF NOT EXISTS reservation_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reservation_id UUID NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// createHandoverReportsTable stores the pickup and return check-ins. Each
+// reservation has at most one report of each kind.
+const createHandoverReportsTable = `
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS picked_up_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS returned_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS handover_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reservation_id UUID NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('pickup', 'return')),
+    recorded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    meter_type VARCHAR(20),
+    meter_reading NUMERIC(12, 1),
+    fuel_level INTEGER CHECK (fuel_level BETWEEN 0 AND 100),
+    checklist JSONB NOT NULL DEFAULT '[]',
+    notes TEXT,
+    photos JSONB NOT NULL DEFAULT '[]',
+    renter_signature JSONB NOT NULL,
+    owner_signature JSONB NOT NULL,
+    UNIQUE (reservation_id, kind)
+);
+`
+
+const addLateReturnColumn = `
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS late_return JSONB;
+`
+
+// addReminderColumns records when pickup and return reminders were sent so
+// each is sent once.
+const addReminderColumns = `
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS pickup_reminded_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS return_reminded_at TIMESTAMP WITH TIME ZONE;
+`
+
+// addNotificationSequence orders notifications for the real-time stream,
+// which uses the sequence as the SSE event ID clients resume from.
+const addNotificationSequence = `
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS sequence BIGSERIAL;
+`
+
+// createNotificationDeliveryTables stores browser push subscriptions and
+// records each notification sent by email, SMS or push, so a retried job
+// does not send it again.
+const createNotificationDeliveryTables = `
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    endpoint TEXT UNIQUE NOT NULL,
+    p256dh TEXT NOT NULL,
+    auth TEXT NOT NULL,
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('email', 'sms', 'push')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed', 'skipped')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (notification_id, channel)
+);
+`
+
+// createJobsTable backs the durable job queue. A unique key may only be held
+// by one unfinished job, so enqueueing the same work twice is a no-op.
+const createJobsTable = `
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    unique_key VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'succeeded', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_error TEXT,
+    locked_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_unique_key ON jobs(unique_key) WHERE status IN ('queued', 'running');
+`
+
+// createJobRunsTable records when each scheduled job last ran on any
+// replica, so replicas taking turns on its lock keep to its interval.
+const createJobRunsTable = `
+CREATE TABLE IF NOT EXISTS job_runs (
+    name VARCHAR(100) PRIMARY KEY,
+    last_run_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+`
+
+// createOutboxEventsTable is the transactional outbox. Events are written in
+// the transaction that makes the change and relayed to sinks afterwards;
+// delivered_to lists the sinks that already have an event. dead_at marks
+// events the relay gave up on, which no longer hold back the later events
+// of their aggregate.
+const createOutboxEventsTable = `
+CREATE TABLE IF NOT EXISTS outbox_events (
+    sequence BIGSERIAL PRIMARY KEY,
+    id UUID UNIQUE NOT NULL,
+    type VARCHAR(100) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    data JSONB NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    delivered_to TEXT[] NOT NULL DEFAULT '{}',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE,
+    dead_at TIMESTAMP WITH TIME ZONE
+);
+`
+
+// createWebhookTables stores outbound webhook subscriptions and a log of
+// every delivery. An event is delivered to a subscription at most once,
+// however often the outbox relays it.
+const createWebhookTables = `
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    description TEXT,
+    events TEXT[] NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMP WITH TIME ZONE,
+    disabled_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    response_body TEXT,
+    error TEXT,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_attempt_at TIMESTAMP WITH TIME ZONE,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (subscription_id, event_id)
+);
+`
+
+// createReservationChangesTable stores requests to move or extend a
+// reservation. A reservation has at most one pending request at a time.
+const createReservationChangesTable = `
+CREATE TABLE IF NOT EXISTS reservation_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reservation_id UUID NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    from_start_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    from_end_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    start_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    currency CHAR(3) NOT NULL,
+    total_price NUMERIC(12, 3) NOT NULL,
+    price_delta NUMERIC(12, 3) NOT NULL,
+    price_breakdown JSONB NOT NULL,
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    reason TEXT,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reservation_changes_pending ON reservation_changes(reservation_id) WHERE status = 'pending';
+`
+
+// Reservations keep a copy of the listing's policy from booking time, so a
+// later policy change does not alter the terms a renter agreed to.
+const addCancellationPolicyColumns = `
+ALTER TABLE equipment ADD COLUMN IF NOT EXISTS cancellation_policy JSONB NOT NULL DEFAULT '{"type": "flexible"}';
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS cancellation_policy JSONB;
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS cancellation JSONB;
+`
+
+// createPaymentsTable stores every payment made through the provider. kind
+// lets a reservation carry a separate hold for its deposit next to its
+// rental payments.
+const createPaymentsTable = `
+ALTER TABLE equipment ADD COLUMN IF NOT EXISTS require_prepayment BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reservation_id UUID NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL DEFAULT 'rental',
+    provider VARCHAR(50) NOT NULL,
+    provider_ref VARCHAR(255),
+    status VARCHAR(20) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(12, 3) NOT NULL,
+    captured NUMERIC(12, 3) NOT NULL DEFAULT 0,
+    refunded NUMERIC(12, 3) NOT NULL DEFAULT 0,
+    failure_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// createLedgerEntriesTable holds the double-entry ledger. Rows sharing a
+// transaction_id always balance, and entries are only ever appended.
+const createLedgerEntriesTable = `
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL,
+    reservation_id UUID NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    payment_id UUID REFERENCES payments(id) ON DELETE CASCADE,
+    account VARCHAR(50) NOT NULL,
+    direction VARCHAR(6) NOT NULL CHECK (direction IN ('debit', 'credit')),
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(12, 3) NOT NULL CHECK (amount >= 0),
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// createNotificationPreferenceTables stores what each user wants to be sent
+// and when. Notifications hidden from the in-app inbox are still stored so
+// their other deliveries can be recorded, and deliveries held for a digest
+// or quiet hours remember when they are due.
+const createNotificationPreferenceTables = `
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS in_app BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE notification_deliveries ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS notification_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    quiet_start VARCHAR(5),
+    quiet_end VARCHAR(5),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('in_app', 'email', 'sms', 'push')),
+    enabled BOOLEAN NOT NULL,
+    digest VARCHAR(20) NOT NULL DEFAULT 'immediate' CHECK (digest IN ('immediate', 'hourly', 'daily')),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type, channel)
+);
+`
+
+// createNotificationTemplateTables lets notification copy be rendered in
+// each user's language at delivery time. Notifications keep the data their
+// templates are rendered with, and new versions of a template are added
+// rather than edited so earlier copy is kept.
+const createNotificationTemplateTables = `
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT 'en';
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS data JSONB;
+
+CREATE TABLE IF NOT EXISTS notification_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(50) NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    version INTEGER NOT NULL CHECK (version > 0),
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (type, locale, version)
+);
+`
+
 const createIndexes = `
 CREATE INDEX IF NOT EXISTS idx_equipment_owner ON equipment(owner_id);
 CREATE INDEX IF NOT EXISTS idx_equipment_category ON equipment(category);
+CREATE INDEX IF NOT EXISTS idx_equipment_category_id ON equipment(category_id);
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
+CREATE INDEX IF NOT EXISTS idx_equipment_specifications ON equipment USING GIN (specifications jsonb_path_ops);
 CREATE INDEX IF NOT EXISTS idx_equipment_available ON equipment(available);
 CREATE INDEX IF NOT EXISTS idx_equipment_location ON equipment(location);
 CREATE INDEX IF NOT EXISTS idx_reservations_equipment ON reservations(equipment_id);
 CREATE INDEX IF NOT EXISTS idx_reservations_renter ON reservations(renter_id);
 CREATE INDEX IF NOT EXISTS idx_reservations_status ON reservations(status);
 CREATE INDEX IF NOT EXISTS idx_reservations_dates ON reservations(start_date, end_date);
+CREATE INDEX IF NOT EXISTS idx_reservations_overdue ON reservations(end_date) WHERE status IN ('approved', 'active') AND returned_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_reservations_pending_created ON reservations(created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_equipment_units_equipment ON equipment_units(equipment_id);
+CREATE INDEX IF NOT EXISTS idx_reservation_units_unit ON reservation_units(unit_id);
+CREATE INDEX IF NOT EXISTS idx_equipment_blackouts_equipment ON equipment_blackouts(equipment_id, start_date);
+CREATE INDEX IF NOT EXISTS idx_equipment_opening_hours_equipment ON equipment_opening_hours(equipment_id);
+CREATE INDEX IF NOT EXISTS idx_pricing_rules_equipment ON pricing_rules(equipment_id);
+CREATE INDEX IF NOT EXISTS idx_payments_reservation ON payments(reservation_id);
+CREATE INDEX IF NOT EXISTS idx_payments_reservation_kind ON payments(reservation_id, kind, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_provider_ref ON payments(provider, provider_ref);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_reservation ON ledger_entries(reservation_id);
+CREATE INDEX IF NOT EXISTS idx_reservation_events_reservation ON reservation_events(reservation_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_reservation_changes_reservation ON reservation_changes(reservation_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account ON ledger_entries(account);
+CREATE INDEX IF NOT EXISTS idx_jobs_ready ON jobs(run_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_jobs_running ON jobs(locked_at) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_undelivered ON outbox_events(sequence) WHERE published_at IS NULL AND dead_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate_undelivered ON outbox_events(aggregate_id, sequence) WHERE published_at IS NULL AND dead_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_user ON webhook_subscriptions(user_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at);
 CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id);
 CREATE INDEX IF NOT EXISTS idx_notifications_read ON notifications(read);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_sequence ON notifications(user_id, sequence);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_read_created ON notifications(user_id, read, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_purge ON notifications(created_at) WHERE read OR NOT in_app;
+CREATE INDEX IF NOT EXISTS idx_push_subscriptions_user ON push_subscriptions(user_id);
 `
+
+// normalizeEquipmentCategories folds the legacy free-text equipment.category
+// values into the categories table. Values that only differ by case, spacing
+// or a trailing plural "s" end up pointing at the same category.
+func normalizeEquipmentCategories(db *sql.DB) error {
+	rows, err := db.Query(`SELECT DISTINCT category FROM equipment WHERE category_id IS NULL`)
+	if err != nil {
+		return err
+	}
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			rows.Close()
+			return err
+		}
+		values = append(values, value)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	slugs := make(map[string]bool)
+	existing, err := db.Query(`SELECT slug FROM categories`)
+	if err != nil {
+		return err
+	}
+	for existing.Next() {
+		var s string
+		if err := existing.Scan(&s); err != nil {
+			existing.Close()
+			return err
+		}
+		slugs[s] = true
+	}
+	existing.Close()
+
+	for _, value := range values {
+		slugs[slug.Make(value)] = true
+	}
+
+	canonical := func(value string) string {
+		s := slug.Make(value)
+		if singular := strings.TrimSuffix(s, "s"); singular != s && slugs[singular] {
+			return singular
+		}
+		return s
+	}
+
+	names := make(map[string]string)
+	for _, value := range values {
+		s := canonical(value)
+		if s == "" {
+			continue
+		}
+		name := strings.TrimSpace(value)
+		if current, ok := names[s]; !ok || (slug.Make(name) == s && slug.Make(current) != s) {
+			names[s] = name
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for s, name := range names {
+		if _, err := tx.Exec(
+			`INSERT INTO categories (name, slug) VALUES ($1, $2) ON CONFLICT (slug) DO NOTHING`,
+			capitalize(name), s,
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, value := range values {
+		s := canonical(value)
+		if s == "" {
+			continue
+		}
+		if _, err := tx.Exec(`
+			UPDATE equipment e
+			SET category_id = c.id, category = c.name
+			FROM categories c
+			WHERE c.slug = $1 AND e.category = $2 AND e.category_id IS NULL
+		`, s, value); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Info("normalized equipment categories", logger.WithFields(map[string]interface{}{
+		"values":     len(values),
+		"categories": len(names),
+	}))
+
+	return nil
+}
+
+func capitalize(value string) string {
+	runes := []rune(value)
+	if len(runes) == 0 {
+		return value
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,80 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/abneribeiro/goapi/internal/config"
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+var (
+	// ErrNoAddress means the recipient cannot be reached on the channel,
+	// such as a user without a phone number. It is not worth retrying.
+	ErrNoAddress       = errors.New("recipient has no address for this channel")
+	ErrUnknownProvider = errors.New("unknown delivery provider")
+)
+
+// Recipient is who a notification is sent to, with their address on each
+// channel.
+type Recipient struct {
+	Name              string
+	Email             string
+	Phone             string
+	Locale            string
+	PushSubscriptions []*model.PushSubscription
+}
+
+// Message is a notification rendered for a channel. Email uses Subject,
+// Text and HTML; SMS uses Text; push uses Subject as the title, Text as the
+// body and URL as the page to open.
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+	URL     string
+}
+
+// Channel sends rendered notifications one way, such as by email.
+type Channel interface {
+	Name() model.DeliveryChannel
+	Send(ctx context.Context, to Recipient, msg Message) error
+}
+
+// NewChannels returns the channels turned on in cfg.
+func NewChannels(cfg config.DeliveryConfig) ([]Channel, error) {
+	var channels []Channel
+
+	switch cfg.EmailProvider {
+	case "", config.ProviderNone:
+	case "fake":
+		channels = append(channels, NewFakeChannel(model.ChannelEmail))
+	case "smtp":
+		channels = append(channels, NewSMTPChannel(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.EmailFrom))
+	default:
+		return nil, fmt.Errorf("%w: email provider %q", ErrUnknownProvider, cfg.EmailProvider)
+	}
+
+	switch cfg.SMSProvider {
+	case "", config.ProviderNone:
+	case "fake":
+		channels = append(channels, NewFakeChannel(model.ChannelSMS))
+	case "twilio":
+		channels = append(channels, NewSMSChannel(NewTwilioProvider(cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.SMSFrom)))
+	default:
+		return nil, fmt.Errorf("%w: sms provider %q", ErrUnknownProvider, cfg.SMSProvider)
+	}
+
+	switch cfg.PushProvider {
+	case "", config.ProviderNone:
+	case "fake":
+		channels = append(channels, NewFakeChannel(model.ChannelPush))
+	case "webpush":
+		channels = append(channels, NewWebPushChannel(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, cfg.VAPIDSubject))
+	default:
+		return nil, fmt.Errorf("%w: push provider %q", ErrUnknownProvider, cfg.PushProvider)
+	}
+
+	return channels, nil
+}
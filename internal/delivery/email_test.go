@@ -0,0 +1,47 @@
+package delivery
+
+import (
+	"context"
+	"net/smtp"
+	"strings"
+	"testing"
+)
+
+func TestSMTPChannel_Send(t *testing.T) {
+	channel := NewSMTPChannel("smtp.example.com", 587, "", "", "GoAPI <noreply@example.com>")
+
+	var gotFrom string
+	var gotTo []string
+	var gotMsg string
+	channel.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotFrom, gotTo, gotMsg = from, to, string(msg)
+		return nil
+	}
+
+	err := channel.Send(context.Background(), Recipient{Name: "Ana", Email: "ana@example.com"}, Message{
+		Subject: "Reservation Approved",
+		Text:    "Approved",
+		HTML:    "<p>Approved</p>",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotFrom != "noreply@example.com" {
+		t.Errorf("expected envelope sender noreply@example.com, got %q", gotFrom)
+	}
+	if len(gotTo) != 1 || gotTo[0] != "ana@example.com" {
+		t.Errorf("unexpected recipients %v", gotTo)
+	}
+	for _, want := range []string{
+		"Subject: Reservation Approved\r\n",
+		"Content-Type: multipart/alternative;",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Type: text/html; charset=utf-8",
+		"<p>Approved</p>",
+	} {
+		if !strings.Contains(gotMsg, want) {
+			t.Errorf("expected message to contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package delivery
+
+import (
+	"context"
+	"sync"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+// fakeRetained is how many messages a fake channel keeps.
+const fakeRetained = 100
+
+// Sent is a message a fake channel was asked to send.
+type Sent struct {
+	To      Recipient
+	Message Message
+}
+
+// FakeChannel records messages instead of sending them, for development
+// and tests. It keeps the most recent ones only.
+type FakeChannel struct {
+	name model.DeliveryChannel
+
+	mu   sync.Mutex
+	sent []Sent
+	err  error
+}
+
+func NewFakeChannel(name model.DeliveryChannel) *FakeChannel {
+	return &FakeChannel{name: name}
+}
+
+func (c *FakeChannel) Name() model.DeliveryChannel {
+	return c.name
+}
+
+func (c *FakeChannel) Send(ctx context.Context, to Recipient, msg Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	if !reachable(c.name, to) {
+		return ErrNoAddress
+	}
+
+	c.sent = append(c.sent, Sent{To: to, Message: msg})
+	if len(c.sent) > fakeRetained {
+		c.sent = c.sent[len(c.sent)-fakeRetained:]
+	}
+	return nil
+}
+
+// FailWith makes every later Send return err, or succeed again if err is
+// nil.
+func (c *FakeChannel) FailWith(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.err = err
+}
+
+func (c *FakeChannel) Sent() []Sent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Sent{}, c.sent...)
+}
+
+func reachable(name model.DeliveryChannel, to Recipient) bool {
+	switch name {
+	case model.ChannelEmail:
+		return to.Email != ""
+	case model.ChannelSMS:
+		return to.Phone != ""
+	case model.ChannelPush:
+		return len(to.PushSubscriptions) > 0
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestFakeChannel_Send(t *testing.T) {
+	ctx := context.Background()
+	channel := NewFakeChannel(model.ChannelSMS)
+
+	if err := channel.Send(ctx, Recipient{Email: "ana@example.com"}, Message{Text: "hi"}); !errors.Is(err, ErrNoAddress) {
+		t.Errorf("expected ErrNoAddress without a phone number, got %v", err)
+	}
+
+	if err := channel.Send(ctx, Recipient{Phone: "+15550100"}, Message{Text: "hi"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failure := errors.New("gateway down")
+	channel.FailWith(failure)
+	if err := channel.Send(ctx, Recipient{Phone: "+15550100"}, Message{Text: "again"}); !errors.Is(err, failure) {
+		t.Errorf("expected the configured failure, got %v", err)
+	}
+
+	sent := channel.Sent()
+	if len(sent) != 1 || sent[0].Message.Text != "hi" {
+		t.Errorf("expected one recorded message, got %+v", sent)
+	}
+}
+
+func TestSender_Send(t *testing.T) {
+	templates, err := NewTemplates()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	email := NewFakeChannel(model.ChannelEmail)
+	sender := NewSender([]Channel{email}, templates, "https://app.example.com/")
+
+	if sender.Supports(model.ChannelSMS) {
+		t.Error("expected SMS to be unsupported when not configured")
+	}
+
+	refID := uuid.New()
+	notification := &model.Notification{
+		Type:          model.NotificationReservationCreated,
+		Title:         "New Reservation Request",
+		Message:       "You have a new reservation request",
+		ReferenceID:   &refID,
+		ReferenceType: "reservation",
+	}
+
+	wording := Copy{Greeting: "Hi Ana,", Link: "View details"}
+	err = sender.Send(context.Background(), model.ChannelEmail, Recipient{Name: "Ana", Email: "ana@example.com"}, notification, wording)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sent := email.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("expected one email, got %d", len(sent))
+	}
+	if want := "https://app.example.com/reservations/" + refID.String(); sent[0].Message.URL != want {
+		t.Errorf("expected link %q, got %q", want, sent[0].Message.URL)
+	}
+}
@@ -0,0 +1,104 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+// pushTTL is how long, in seconds, a push service holds a message for an
+// offline browser.
+const pushTTL = 24 * 60 * 60
+
+// GoneError lists push subscriptions the push service no longer knows,
+// which should be deleted.
+type GoneError struct {
+	Endpoints []string
+}
+
+func (e *GoneError) Error() string {
+	return fmt.Sprintf("%d push subscriptions have expired", len(e.Endpoints))
+}
+
+// PushPayload is the JSON a service worker receives.
+type PushPayload struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+}
+
+// WebPushChannel sends notifications to every browser a user subscribed
+// from, using the Web Push protocol with VAPID.
+type WebPushChannel struct {
+	options webpush.Options
+}
+
+func NewWebPushChannel(publicKey, privateKey, subject string) *WebPushChannel {
+	return &WebPushChannel{
+		options: webpush.Options{
+			Subscriber:      subject,
+			VAPIDPublicKey:  publicKey,
+			VAPIDPrivateKey: privateKey,
+			TTL:             pushTTL,
+		},
+	}
+}
+
+func (c *WebPushChannel) Name() model.DeliveryChannel {
+	return model.ChannelPush
+}
+
+// PublicKey is the application server key browsers subscribe with.
+func (c *WebPushChannel) PublicKey() string {
+	return c.options.VAPIDPublicKey
+}
+
+// Send pushes to each subscription. Subscriptions the push service reports
+// gone are returned in a *GoneError, on its own when nothing else failed so
+// callers can tell the rest were delivered.
+func (c *WebPushChannel) Send(ctx context.Context, to Recipient, msg Message) error {
+	if len(to.PushSubscriptions) == 0 {
+		return ErrNoAddress
+	}
+
+	payload, err := json.Marshal(PushPayload{Title: msg.Subject, Body: msg.Text, URL: msg.URL})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	gone := &GoneError{}
+	for _, sub := range to.PushSubscriptions {
+		options := c.options
+		resp, err := webpush.SendNotificationWithContext(ctx, payload, &webpush.Subscription{
+			Endpoint: sub.Endpoint,
+			Keys:     webpush.Keys{P256dh: sub.Keys.P256dh, Auth: sub.Keys.Auth},
+		}, &options)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+			gone.Endpoints = append(gone.Endpoints, sub.Endpoint)
+		case resp.StatusCode < 200 || resp.StatusCode >= 300:
+			errs = append(errs, fmt.Errorf("push service responded with status %d", resp.StatusCode))
+		}
+	}
+
+	if len(gone.Endpoints) == 0 {
+		return errors.Join(errs...)
+	}
+	if len(errs) == 0 {
+		return gone
+	}
+	return errors.Join(append(errs, gone)...)
+}
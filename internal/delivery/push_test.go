@@ -0,0 +1,71 @@
+package delivery
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func testPushSubscription(t *testing.T, endpoint string) *model.PushSubscription {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	auth := make([]byte, 16)
+	rand.Read(auth)
+
+	return &model.PushSubscription{
+		Endpoint: endpoint,
+		Keys: model.PushKeys{
+			P256dh: base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			Auth:   base64.RawURLEncoding.EncodeToString(auth),
+		},
+	}
+}
+
+func TestWebPushChannel_Send(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/expired" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	channel := NewWebPushChannel(publicKey, privateKey, "mailto:admin@example.com")
+	ctx := context.Background()
+	msg := Message{Subject: "Reservation Approved", Text: "Approved"}
+
+	if err := channel.Send(ctx, Recipient{}, msg); !errors.Is(err, ErrNoAddress) {
+		t.Errorf("expected ErrNoAddress without subscriptions, got %v", err)
+	}
+
+	err = channel.Send(ctx, Recipient{PushSubscriptions: []*model.PushSubscription{
+		testPushSubscription(t, server.URL+"/active"),
+		testPushSubscription(t, server.URL+"/expired"),
+	}}, msg)
+
+	gone, ok := err.(*GoneError)
+	if !ok {
+		t.Fatalf("expected only a *GoneError, got %v", err)
+	}
+	if len(gone.Endpoints) != 1 || gone.Endpoints[0] != server.URL+"/expired" {
+		t.Errorf("unexpected gone endpoints %v", gone.Endpoints)
+	}
+}
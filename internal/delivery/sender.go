@@ -0,0 +1,101 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+// Sender renders notifications and sends them on the configured channels.
+type Sender struct {
+	channels  map[model.DeliveryChannel]Channel
+	templates *Templates
+	appURL    string
+}
+
+func NewSender(channels []Channel, templates *Templates, appURL string) *Sender {
+	s := &Sender{
+		channels:  make(map[model.DeliveryChannel]Channel, len(channels)),
+		templates: templates,
+		appURL:    strings.TrimSuffix(appURL, "/"),
+	}
+	for _, channel := range channels {
+		s.channels[channel.Name()] = channel
+	}
+	return s
+}
+
+// Supports reports whether channel is configured.
+func (s *Sender) Supports(channel model.DeliveryChannel) bool {
+	_, ok := s.channels[channel]
+	return ok
+}
+
+// PushPublicKey returns the VAPID key browsers subscribe with, or "" when
+// web push is not configured.
+func (s *Sender) PushPublicKey() string {
+	if push, ok := s.channels[model.ChannelPush].(*WebPushChannel); ok {
+		return push.PublicKey()
+	}
+	return ""
+}
+
+func (s *Sender) Send(ctx context.Context, channel model.DeliveryChannel, to Recipient, notification *model.Notification, wording Copy) error {
+	ch, ok := s.channels[channel]
+	if !ok {
+		return fmt.Errorf("delivery channel %s is not configured", channel)
+	}
+
+	msg, err := s.templates.Render(channel, TemplateData{
+		Notification: notification,
+		URL:          s.link(notification),
+		Copy:         wording,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ch.Send(ctx, to, msg)
+}
+
+// SendDigest sends several notifications as one message.
+func (s *Sender) SendDigest(ctx context.Context, channel model.DeliveryChannel, to Recipient, notifications []*model.Notification, wording Copy) error {
+	ch, ok := s.channels[channel]
+	if !ok {
+		return fmt.Errorf("delivery channel %s is not configured", channel)
+	}
+
+	var link string
+	if s.appURL != "" {
+		link = s.appURL + "/notifications"
+	}
+
+	msg, err := s.templates.RenderDigest(channel, TemplateData{
+		Notifications: notifications,
+		URL:           link,
+		Copy:          wording,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ch.Send(ctx, to, msg)
+}
+
+// link points at the page for what the notification is about, if the app
+// has one.
+func (s *Sender) link(notification *model.Notification) string {
+	if s.appURL == "" || notification.ReferenceID == nil {
+		return ""
+	}
+
+	switch notification.ReferenceType {
+	case "reservation":
+		return s.appURL + "/reservations/" + notification.ReferenceID.String()
+	case "equipment":
+		return s.appURL + "/equipment/" + notification.ReferenceID.String()
+	}
+	return ""
+}
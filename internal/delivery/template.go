@@ -0,0 +1,153 @@
+package delivery
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+const (
+	// defaultTemplate is used for types without a template of their own.
+	defaultTemplate = "default"
+	// digestTemplate renders several notifications sent together.
+	digestTemplate = "digest"
+)
+
+// TemplateData is what notification templates are rendered with. Digests
+// are given Notifications instead of Notification.
+type TemplateData struct {
+	Notification  *model.Notification
+	Notifications []*model.Notification
+	URL           string
+	Copy          Copy
+}
+
+// Copy is the wording around notifications, already rendered in the
+// recipient's locale from the versioned template store. Templates hold only
+// markup, so all text can be changed and translated without a deploy.
+// Subject and Intro are set for digests and reminders.
+type Copy struct {
+	Greeting string
+	Link     string
+	Subject  string
+	Intro    string
+}
+
+// templateSet is one template file parsed twice: as text for subjects and
+// plain bodies, and as HTML so the html block is escaped.
+type templateSet struct {
+	text *template.Template
+	html *htmltemplate.Template
+}
+
+// Templates renders notifications from the files under templates/. Each
+// channel has a directory with a default.tmpl, and may override it for a
+// type with <type>.tmpl. A file defines any of the subject, text and html
+// blocks, and takes its wording from TemplateData.Copy.
+type Templates struct {
+	sets map[string]*templateSet
+}
+
+func NewTemplates() (*Templates, error) {
+	t := &Templates{sets: make(map[string]*templateSet)}
+
+	err := fs.WalkDir(templateFiles, "templates", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(name) != ".tmpl" {
+			return err
+		}
+
+		src, err := templateFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		set := &templateSet{}
+		if set.text, err = template.New(name).Parse(string(src)); err != nil {
+			return err
+		}
+		if set.html, err = htmltemplate.New(name).Parse(string(src)); err != nil {
+			return err
+		}
+
+		key := strings.TrimSuffix(strings.TrimPrefix(name, "templates/"), ".tmpl")
+		t.sets[key] = set
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification templates: %w", err)
+	}
+
+	return t, nil
+}
+
+// Render builds the message for a notification on a channel.
+func (t *Templates) Render(channel model.DeliveryChannel, data TemplateData) (Message, error) {
+	set, ok := t.lookup(channel, string(data.Notification.Type), defaultTemplate)
+	if !ok {
+		return Message{}, fmt.Errorf("no template for %s notifications", channel)
+	}
+
+	return render(set, data)
+}
+
+// RenderDigest builds one message for several notifications on a channel.
+func (t *Templates) RenderDigest(channel model.DeliveryChannel, data TemplateData) (Message, error) {
+	set, ok := t.lookup(channel, digestTemplate)
+	if !ok {
+		return Message{}, fmt.Errorf("no digest template for %s notifications", channel)
+	}
+
+	return render(set, data)
+}
+
+// lookup returns the first of the named templates the channel has.
+func (t *Templates) lookup(channel model.DeliveryChannel, names ...string) (*templateSet, bool) {
+	for _, name := range names {
+		if set, ok := t.sets[string(channel)+"/"+name]; ok {
+			return set, true
+		}
+	}
+	return nil, false
+}
+
+func render(set *templateSet, data TemplateData) (Message, error) {
+	msg := Message{URL: data.URL}
+	var err error
+	if msg.Subject, err = executeText(set.text, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if msg.Text, err = executeText(set.text, "text", data); err != nil {
+		return Message{}, err
+	}
+	if set.html.Lookup("html") != nil {
+		var buf bytes.Buffer
+		if err := set.html.ExecuteTemplate(&buf, "html", data); err != nil {
+			return Message{}, err
+		}
+		msg.HTML = strings.TrimSpace(buf.String())
+	}
+
+	return msg, nil
+}
+
+func executeText(tmpl *template.Template, name string, data TemplateData) (string, error) {
+	if tmpl.Lookup(name) == nil {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,137 @@
+package delivery
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestTemplates_Render(t *testing.T) {
+	templates, err := NewTemplates()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := TemplateData{
+		Notification: &model.Notification{
+			Type:    model.NotificationReservationApproved,
+			Title:   "Reservation Approved",
+			Message: "Your reservation for <Drill> has been approved",
+		},
+		URL:  "https://app.example.com/reservations/1",
+		Copy: Copy{Greeting: "Hi Ana,", Link: "View details"},
+	}
+
+	email, err := templates.Render(model.ChannelEmail, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if email.Subject != "Reservation Approved" {
+		t.Errorf("unexpected subject %q", email.Subject)
+	}
+	if !strings.HasPrefix(email.Text, "Hi Ana,") || !strings.Contains(email.HTML, ">View details</a>") {
+		t.Errorf("expected the copy around the message, got %q / %q", email.Text, email.HTML)
+	}
+	if !strings.Contains(email.Text, "<Drill>") {
+		t.Errorf("expected plain text to be left unescaped, got %q", email.Text)
+	}
+	if !strings.Contains(email.HTML, "&lt;Drill&gt;") {
+		t.Errorf("expected html to be escaped, got %q", email.HTML)
+	}
+
+	sms, err := templates.Render(model.ChannelSMS, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sms.Subject != "" || sms.HTML != "" {
+		t.Error("expected SMS to render text only")
+	}
+	if !strings.HasSuffix(sms.Text, data.URL) {
+		t.Errorf("expected SMS to end with the link, got %q", sms.Text)
+	}
+}
+
+func TestTemplates_RenderTypeOverride(t *testing.T) {
+	templates, err := NewTemplates()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg, err := templates.Render(model.ChannelEmail, TemplateData{
+		Notification: &model.Notification{
+			Type:  model.NotificationReservationReminder,
+			Title: "Pickup Tomorrow",
+		},
+		Copy: Copy{Subject: "Reminder: Pickup Tomorrow"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Subject != "Reminder: Pickup Tomorrow" {
+		t.Errorf("expected the reminder template, got subject %q", msg.Subject)
+	}
+}
+
+func TestTemplates_RenderDigest(t *testing.T) {
+	templates, err := NewTemplates()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := TemplateData{
+		Notifications: []*model.Notification{
+			{Type: model.NotificationReservationCreated, Title: "New Reservation Request", Message: "You have a new reservation request"},
+			{Type: model.NotificationEquipmentReturned, Title: "Equipment Returned", Message: "Your drill was returned"},
+		},
+		Copy: Copy{Greeting: "Hi Ana,", Subject: "You have 2 new notifications", Intro: "Here is what happened since we last wrote:"},
+	}
+
+	for _, channel := range model.OutboundChannels {
+		msg, err := templates.RenderDigest(channel, data)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", channel, err)
+		}
+		if msg.Text == "" {
+			t.Errorf("%s: expected a text body", channel)
+		}
+	}
+
+	email, _ := templates.RenderDigest(model.ChannelEmail, data)
+	if email.Subject != "You have 2 new notifications" {
+		t.Errorf("unexpected subject %q", email.Subject)
+	}
+	if !strings.Contains(email.Text, "Equipment Returned: Your drill was returned") {
+		t.Errorf("expected every notification to be listed, got %q", email.Text)
+	}
+}
+
+// Wording lives in the versioned template store so it can be changed and
+// translated without a deploy; the files may only hold markup.
+func TestTemplates_HoldNoCopy(t *testing.T) {
+	templates, err := NewTemplates()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tags := regexp.MustCompile(`<[^>]*>`)
+	data := TemplateData{
+		Notification:  &model.Notification{},
+		Notifications: []*model.Notification{{}, {}},
+	}
+
+	for name, set := range templates.sets {
+		msg, err := render(set, data)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		for _, out := range []string{msg.Subject, msg.Text, tags.ReplaceAllString(msg.HTML, "")} {
+			if strings.IndexFunc(out, unicode.IsLetter) >= 0 {
+				t.Errorf("%s: expected markup only, got copy in %q", name, out)
+			}
+		}
+	}
+}
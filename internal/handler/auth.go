@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/abneribeiro/goapi/internal/middleware"
 	"github.com/abneribeiro/goapi/internal/model"
 	"github.com/abneribeiro/goapi/internal/pkg/validator"
 	"github.com/abneribeiro/goapi/internal/service"
@@ -70,6 +71,23 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, model.SuccessResponse(resp))
 }
 
+// StreamToken returns a stream token for the live endpoints.
+func (h *AuthHandler) StreamToken(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	resp, err := h.authService.StreamToken(claims)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to issue stream token"))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(resp))
+}
+
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -6,8 +6,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/abneribeiro/goapi/internal/middleware"
 	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/jwt"
+	"github.com/abneribeiro/goapi/internal/service"
 )
 
 func TestRespondJSON(t *testing.T) {
@@ -107,3 +111,43 @@ func TestAuthHandler_Login_InvalidJSON(t *testing.T) {
 		t.Error("expected INVALID_JSON error code")
 	}
 }
+
+func TestAuthHandler_StreamToken_OpensStreams(t *testing.T) {
+	jwtManager := jwt.NewManager("test-secret", time.Hour)
+	handler := NewAuthHandler(service.NewAuthService(nil, jwtManager))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/stream-token", nil).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+	handler.StreamToken(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Data model.StreamTokenResponse `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	auth := middleware.NewAuthMiddleware(jwtManager)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	stream := httptest.NewRequest(http.MethodGet, "/api/v1/live?token="+response.Data.Token, nil)
+	w = httptest.NewRecorder()
+	auth.AuthenticateStream(ok).ServeHTTP(w, stream)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected the stream token to open a stream, got status %d", w.Code)
+	}
+
+	api := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+	api.Header.Set("Authorization", "Bearer "+response.Data.Token)
+	w = httptest.NewRecorder()
+	auth.Authenticate(ok).ServeHTTP(w, api)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected the stream token to be refused by the API, got status %d", w.Code)
+	}
+}
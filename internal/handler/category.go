@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+type CategoryHandler struct {
+	categoryService *service.CategoryService
+}
+
+func NewCategoryHandler(categoryService *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+func (h *CategoryHandler) List(w http.ResponseWriter, r *http.Request) {
+	var categories []*model.Category
+	var err error
+
+	if r.URL.Query().Get("flat") == "true" {
+		categories, err = h.categoryService.List(r.Context())
+	} else {
+		categories, err = h.categoryService.Tree(r.Context())
+	}
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to list categories"))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(categories))
+}
+
+func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCategoryID(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid category ID"))
+		return
+	}
+
+	category, err := h.categoryService.GetByID(r.Context(), id)
+	if err != nil {
+		h.respondError(w, err, "Failed to get category")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(category))
+}
+
+func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	category, err := h.categoryService.Create(r.Context(), &req)
+	if err != nil {
+		h.respondError(w, err, "Failed to create category")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, model.SuccessResponse(category))
+}
+
+func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCategoryID(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid category ID"))
+		return
+	}
+
+	var req model.UpdateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	category, err := h.categoryService.Update(r.Context(), id, &req)
+	if err != nil {
+		h.respondError(w, err, "Failed to update category")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(category))
+}
+
+func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCategoryID(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid category ID"))
+		return
+	}
+
+	if err := h.categoryService.Delete(r.Context(), id); err != nil {
+		h.respondError(w, err, "Failed to delete category")
+		return
+	}
+
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *CategoryHandler) respondError(w http.ResponseWriter, err error, fallback string) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+	case errors.Is(err, service.ErrCategoryNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Category not found"))
+	case errors.Is(err, service.ErrCategorySlugExists):
+		respondJSON(w, http.StatusConflict, model.ErrorResponse("SLUG_EXISTS", "Category slug already exists"))
+	case errors.Is(err, service.ErrCategoryInUse):
+		respondJSON(w, http.StatusConflict, model.ErrorResponse("CATEGORY_IN_USE", "Category is still referenced by equipment or subcategories"))
+	case errors.Is(err, service.ErrCategoryCycle):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_PARENT", "Category cannot be moved under itself or a descendant"))
+	default:
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", fallback))
+	}
+}
+
+func parseCategoryID(r *http.Request) (uuid.UUID, error) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/categories/")
+	idStr = strings.Split(idStr, "/")[0]
+	return uuid.Parse(idStr)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestCategoryHandler_GetByID_InvalidID(t *testing.T) {
+	handler := &CategoryHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/categories/invalid-uuid", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetByID(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_ID" {
+		t.Error("expected INVALID_ID error code")
+	}
+}
+
+func TestCategoryHandler_Create_InvalidJSON(t *testing.T) {
+	handler := &CategoryHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", strings.NewReader("invalid json"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_JSON" {
+		t.Error("expected INVALID_JSON error code")
+	}
+}
+
+func TestCategoryHandler_Update_InvalidID(t *testing.T) {
+	handler := &CategoryHandler{}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/categories/invalid-uuid", strings.NewReader(`{"name": "Tools"}`))
+	w := httptest.NewRecorder()
+
+	handler.Update(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestCategoryHandler_Update_InvalidJSON(t *testing.T) {
+	handler := &CategoryHandler{}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/categories/"+uuid.New().String(), strings.NewReader("invalid json"))
+	w := httptest.NewRecorder()
+
+	handler.Update(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestCategoryHandler_Delete_InvalidID(t *testing.T) {
+	handler := &CategoryHandler{}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/categories/invalid-uuid", nil)
+	w := httptest.NewRecorder()
+
+	handler.Delete(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
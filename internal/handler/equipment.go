@@ -3,7 +3,11 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -73,6 +77,10 @@ func (h *EquipmentHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.displayIn(w, r, equipment) {
+		return
+	}
+
 	respondJSON(w, http.StatusOK, model.SuccessResponse(equipment))
 }
 
@@ -85,17 +93,53 @@ func (h *EquipmentHandler) List(w http.ResponseWriter, r *http.Request) {
 		Location: query.Get("location"),
 	}
 
+	if categoryIDStr := query.Get("category_id"); categoryIDStr != "" {
+		categoryID, err := uuid.Parse(categoryIDStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid category ID"))
+			return
+		}
+		filter.CategoryID = &categoryID
+	}
+
 	if availableStr := query.Get("available"); availableStr != "" {
 		available := availableStr == "true"
 		filter.Available = &available
 	}
 
+	if startStr, endStr := query.Get("start_date"), query.Get("end_date"); startStr != "" || endStr != "" {
+		startDate, startErr := parseDateParam(startStr)
+		endDate, endErr := parseDateParam(endStr)
+		if startErr != nil || endErr != nil || !endDate.After(startDate) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_FILTER", "start_date and end_date must both be set, with end_date after start_date"))
+			return
+		}
+		filter.StartDate = &startDate
+		filter.EndDate = &endDate
+	}
+
+	specs, err := parseSpecFilters(query)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_FILTER", err.Error()))
+		return
+	}
+	filter.Specs = specs
+
 	equipment, total, err := h.equipmentService.List(r.Context(), filter, pag)
 	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_FILTER", validationErrors.Error()))
+			return
+		}
 		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to list equipment"))
 		return
 	}
 
+	if !h.displayIn(w, r, equipment...) {
+		return
+	}
+
 	meta := &model.Meta{
 		Page:       pag.Page,
 		PerPage:    pag.PerPage,
@@ -128,6 +172,11 @@ func (h *EquipmentHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	equipment, err := h.equipmentService.Update(r.Context(), id, claims.UserID, &req)
 	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+			return
+		}
 		if errors.Is(err, service.ErrEquipmentNotFound) {
 			respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Equipment not found"))
 			return
@@ -282,6 +331,10 @@ func (h *EquipmentHandler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.displayIn(w, r, equipment...) {
+		return
+	}
+
 	meta := &model.Meta{
 		Page:       pag.Page,
 		PerPage:    pag.PerPage,
@@ -291,3 +344,70 @@ func (h *EquipmentHandler) Search(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, model.SuccessResponseWithMeta(equipment, meta))
 }
+
+func parseDateParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+var specParamRegex = regexp.MustCompile(`^spec\.([a-z][a-z0-9_]*)(?:\[([a-z]+)\])?$`)
+
+func parseSpecFilters(query url.Values) ([]model.SpecFilter, error) {
+	var filters []model.SpecFilter
+
+	for param, values := range query {
+		if !strings.HasPrefix(param, "spec.") {
+			continue
+		}
+
+		matches := specParamRegex.FindStringSubmatch(param)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid specification filter %q", param)
+		}
+
+		operator := model.SpecEq
+		if matches[2] != "" {
+			operator = model.SpecOperator(matches[2])
+		}
+		if !operator.Valid() {
+			return nil, fmt.Errorf("unsupported operator %q in %q", operator, param)
+		}
+
+		for _, value := range values {
+			if operator.IsRange() {
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return nil, fmt.Errorf("%s requires a numeric value", param)
+				}
+			}
+			filters = append(filters, model.SpecFilter{
+				Key:      matches[1],
+				Operator: operator,
+				Value:    value,
+			})
+		}
+	}
+
+	return filters, nil
+}
+
+// displayIn converts listing prices to the currency requested via the
+// currency query parameter. It reports false once an error response is sent.
+func (h *EquipmentHandler) displayIn(w http.ResponseWriter, r *http.Request, items ...*model.Equipment) bool {
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		return true
+	}
+
+	if err := h.equipmentService.DisplayIn(currency, items...); err != nil {
+		if errors.Is(err, service.ErrUnsupportedCurrency) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_CURRENCY", "Unsupported currency"))
+			return false
+		}
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to convert prices"))
+		return false
+	}
+
+	return true
+}
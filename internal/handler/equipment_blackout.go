@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+func (h *EquipmentHandler) ListBlackouts(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, _, err := parseEquipmentSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment ID"))
+		return
+	}
+
+	blackouts, err := h.equipmentService.ListBlackouts(r.Context(), equipmentID, claims.UserID)
+	if err != nil {
+		h.respondBlackoutError(w, err, "Failed to list blackouts")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(blackouts))
+}
+
+func (h *EquipmentHandler) CreateBlackout(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, _, err := parseEquipmentSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment ID"))
+		return
+	}
+
+	var req model.CreateBlackoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	blackout, err := h.equipmentService.CreateBlackout(r.Context(), equipmentID, claims.UserID, &req)
+	if err != nil {
+		h.respondBlackoutError(w, err, "Failed to create blackout")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, model.SuccessResponse(blackout))
+}
+
+func (h *EquipmentHandler) UpdateBlackout(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, blackoutID, err := parseEquipmentSubPath(r)
+	if err != nil || blackoutID == uuid.Nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment or blackout ID"))
+		return
+	}
+
+	var req model.UpdateBlackoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	blackout, err := h.equipmentService.UpdateBlackout(r.Context(), equipmentID, blackoutID, claims.UserID, &req)
+	if err != nil {
+		h.respondBlackoutError(w, err, "Failed to update blackout")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(blackout))
+}
+
+func (h *EquipmentHandler) DeleteBlackout(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, blackoutID, err := parseEquipmentSubPath(r)
+	if err != nil || blackoutID == uuid.Nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment or blackout ID"))
+		return
+	}
+
+	if err := h.equipmentService.DeleteBlackout(r.Context(), equipmentID, blackoutID, claims.UserID); err != nil {
+		h.respondBlackoutError(w, err, "Failed to delete blackout")
+		return
+	}
+
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *EquipmentHandler) respondBlackoutError(w http.ResponseWriter, err error, fallback string) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+	case errors.Is(err, service.ErrEquipmentNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Equipment not found"))
+	case errors.Is(err, service.ErrBlackoutNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Blackout not found"))
+	case errors.Is(err, service.ErrNotOwner):
+		respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not the owner of this equipment"))
+	default:
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", fallback))
+	}
+}
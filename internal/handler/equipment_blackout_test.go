@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestEquipmentHandler_CreateBlackout_Unauthorized(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/equipment/"+uuid.New().String()+"/blackouts", bytes.NewBufferString("{}"))
+	w := httptest.NewRecorder()
+
+	handler.CreateBlackout(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestEquipmentHandler_CreateBlackout_InvalidJSON(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/equipment/"+uuid.New().String()+"/blackouts", bytes.NewBufferString("invalid")).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.CreateBlackout(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_JSON" {
+		t.Error("expected INVALID_JSON error code")
+	}
+}
+
+func TestEquipmentHandler_DeleteBlackout_InvalidID(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/equipment/"+uuid.New().String()+"/blackouts/invalid-uuid", nil).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.DeleteBlackout(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+func (h *EquipmentHandler) ListPricingRules(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, _, err := parseEquipmentSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment ID"))
+		return
+	}
+
+	rules, err := h.equipmentService.ListPricingRules(r.Context(), equipmentID, claims.UserID)
+	if err != nil {
+		h.respondPricingRuleError(w, err, "Failed to list pricing rules")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(rules))
+}
+
+func (h *EquipmentHandler) CreatePricingRule(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, _, err := parseEquipmentSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment ID"))
+		return
+	}
+
+	var req model.CreatePricingRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	rule, err := h.equipmentService.CreatePricingRule(r.Context(), equipmentID, claims.UserID, &req)
+	if err != nil {
+		h.respondPricingRuleError(w, err, "Failed to create pricing rule")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, model.SuccessResponse(rule))
+}
+
+func (h *EquipmentHandler) UpdatePricingRule(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, ruleID, err := parseEquipmentSubPath(r)
+	if err != nil || ruleID == uuid.Nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment or pricing rule ID"))
+		return
+	}
+
+	var req model.UpdatePricingRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	rule, err := h.equipmentService.UpdatePricingRule(r.Context(), equipmentID, ruleID, claims.UserID, &req)
+	if err != nil {
+		h.respondPricingRuleError(w, err, "Failed to update pricing rule")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(rule))
+}
+
+func (h *EquipmentHandler) DeletePricingRule(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, ruleID, err := parseEquipmentSubPath(r)
+	if err != nil || ruleID == uuid.Nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment or pricing rule ID"))
+		return
+	}
+
+	if err := h.equipmentService.DeletePricingRule(r.Context(), equipmentID, ruleID, claims.UserID); err != nil {
+		h.respondPricingRuleError(w, err, "Failed to delete pricing rule")
+		return
+	}
+
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *EquipmentHandler) respondPricingRuleError(w http.ResponseWriter, err error, fallback string) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+	case errors.Is(err, service.ErrEquipmentNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Equipment not found"))
+	case errors.Is(err, service.ErrPricingRuleNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Pricing rule not found"))
+	case errors.Is(err, service.ErrNotOwner):
+		respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not the owner of this equipment"))
+	default:
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", fallback))
+	}
+}
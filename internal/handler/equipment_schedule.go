@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+func (h *EquipmentHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	equipmentID, _, err := parseEquipmentSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment ID"))
+		return
+	}
+
+	query := r.URL.Query()
+	schedule, err := h.equipmentService.GetSchedule(r.Context(), equipmentID, model.ScheduleQuery{
+		Start: query.Get("start"),
+		End:   query.Get("end"),
+	})
+	if err != nil {
+		h.respondScheduleError(w, err, "Failed to get schedule")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(schedule))
+}
+
+func (h *EquipmentHandler) GetSlots(w http.ResponseWriter, r *http.Request) {
+	equipmentID, _, err := parseEquipmentSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment ID"))
+		return
+	}
+
+	query := r.URL.Query()
+	scheduleQuery := model.ScheduleQuery{
+		Start: query.Get("start"),
+		End:   query.Get("end"),
+	}
+
+	if scheduleQuery.Duration, err = time.ParseDuration(query.Get("duration")); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_FILTER", "duration must be a duration such as 90m or 2h"))
+		return
+	}
+	if stepStr := query.Get("step"); stepStr != "" {
+		if scheduleQuery.Step, err = time.ParseDuration(stepStr); err != nil {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_FILTER", "step must be a duration such as 15m or 1h"))
+			return
+		}
+	}
+
+	slots, err := h.equipmentService.GetSlots(r.Context(), equipmentID, scheduleQuery)
+	if err != nil {
+		h.respondScheduleError(w, err, "Failed to get slots")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(slots))
+}
+
+func (h *EquipmentHandler) GetOpeningHours(w http.ResponseWriter, r *http.Request) {
+	equipmentID, _, err := parseEquipmentSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment ID"))
+		return
+	}
+
+	hours, err := h.equipmentService.GetOpeningHours(r.Context(), equipmentID)
+	if err != nil {
+		h.respondScheduleError(w, err, "Failed to get opening hours")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(hours))
+}
+
+func (h *EquipmentHandler) SetOpeningHours(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, _, err := parseEquipmentSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment ID"))
+		return
+	}
+
+	var req model.SetOpeningHoursRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	hours, err := h.equipmentService.SetOpeningHours(r.Context(), equipmentID, claims.UserID, &req)
+	if err != nil {
+		h.respondScheduleError(w, err, "Failed to set opening hours")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(hours))
+}
+
+func (h *EquipmentHandler) respondScheduleError(w http.ResponseWriter, err error, fallback string) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+	case errors.Is(err, service.ErrEquipmentNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Equipment not found"))
+	case errors.Is(err, service.ErrNotOwner):
+		respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not the owner of this equipment"))
+	default:
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", fallback))
+	}
+}
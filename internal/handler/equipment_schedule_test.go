@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEquipmentHandler_GetSchedule_InvalidID(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/equipment/invalid-uuid/schedule", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetSchedule(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestEquipmentHandler_GetSlots_InvalidDuration(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	for _, q := range []string{"", "?duration=two-hours", "?duration=2h&step=often"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/equipment/"+uuid.New().String()+"/slots"+q, nil)
+		w := httptest.NewRecorder()
+
+		handler.GetSlots(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected status %d, got %d", q, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestEquipmentHandler_SetOpeningHours_Unauthorized(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/equipment/"+uuid.New().String()+"/opening-hours", bytes.NewBufferString(`{"hours":[]}`))
+	w := httptest.NewRecorder()
+
+	handler.SetOpeningHours(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
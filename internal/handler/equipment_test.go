@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -165,3 +166,86 @@ func TestEquipmentHandler_GetAvailability_InvalidID(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
 	}
 }
+
+func TestParseSpecFilters(t *testing.T) {
+	query := url.Values{
+		"spec.weight_kg[lte]": {"5000"},
+		"spec.fuel_type":      {"diesel"},
+		"category":            {"excavators"},
+	}
+
+	filters, err := parseSpecFilters(query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(filters) != 2 {
+		t.Fatalf("expected 2 filters, got %d", len(filters))
+	}
+
+	byKey := make(map[string]model.SpecFilter)
+	for _, f := range filters {
+		byKey[f.Key] = f
+	}
+
+	if f := byKey["weight_kg"]; f.Operator != model.SpecLte || f.Value != "5000" {
+		t.Errorf("unexpected weight_kg filter: %+v", f)
+	}
+
+	if f := byKey["fuel_type"]; f.Operator != model.SpecEq || f.Value != "diesel" {
+		t.Errorf("unexpected fuel_type filter: %+v", f)
+	}
+}
+
+func TestParseSpecFilters_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query url.Values
+	}{
+		{"unknown operator", url.Values{"spec.weight_kg[between]": {"1"}}},
+		{"non numeric range", url.Values{"spec.weight_kg[gte]": {"heavy"}}},
+		{"invalid key", url.Values{"spec.Weight-KG": {"1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseSpecFilters(tt.query); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestEquipmentHandler_List_InvalidSpecFilter(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/equipment?spec.weight_kg[lte]=heavy", nil)
+	w := httptest.NewRecorder()
+
+	handler.List(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestEquipmentHandler_List_InvalidDateFilter(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	queries := []string{
+		"start_date=2024-03-01",
+		"start_date=2024-03-05&end_date=2024-03-01",
+		"start_date=yesterday&end_date=2024-03-01",
+	}
+
+	for _, q := range queries {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/equipment?"+q, nil)
+		w := httptest.NewRecorder()
+
+		handler.List(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", q, http.StatusBadRequest, w.Code)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+func (h *EquipmentHandler) ListUnits(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, _, err := parseEquipmentSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment ID"))
+		return
+	}
+
+	units, err := h.equipmentService.ListUnits(r.Context(), equipmentID, claims.UserID)
+	if err != nil {
+		h.respondUnitError(w, err, "Failed to list units")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(units))
+}
+
+func (h *EquipmentHandler) CreateUnit(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, _, err := parseEquipmentSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment ID"))
+		return
+	}
+
+	var req model.CreateEquipmentUnitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	unit, err := h.equipmentService.AddUnit(r.Context(), equipmentID, claims.UserID, &req)
+	if err != nil {
+		h.respondUnitError(w, err, "Failed to create unit")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, model.SuccessResponse(unit))
+}
+
+func (h *EquipmentHandler) UpdateUnit(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, unitID, err := parseEquipmentSubPath(r)
+	if err != nil || unitID == uuid.Nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment or unit ID"))
+		return
+	}
+
+	var req model.UpdateEquipmentUnitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	unit, err := h.equipmentService.UpdateUnit(r.Context(), equipmentID, unitID, claims.UserID, &req)
+	if err != nil {
+		h.respondUnitError(w, err, "Failed to update unit")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(unit))
+}
+
+func (h *EquipmentHandler) DeleteUnit(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	equipmentID, unitID, err := parseEquipmentSubPath(r)
+	if err != nil || unitID == uuid.Nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment or unit ID"))
+		return
+	}
+
+	if err := h.equipmentService.DeleteUnit(r.Context(), equipmentID, unitID, claims.UserID); err != nil {
+		h.respondUnitError(w, err, "Failed to delete unit")
+		return
+	}
+
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *EquipmentHandler) respondUnitError(w http.ResponseWriter, err error, fallback string) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+	case errors.Is(err, service.ErrEquipmentNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Equipment not found"))
+	case errors.Is(err, service.ErrUnitNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Unit not found"))
+	case errors.Is(err, service.ErrNotOwner):
+		respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not the owner of this equipment"))
+	case errors.Is(err, service.ErrUnitsNotTracked):
+		respondJSON(w, http.StatusConflict, model.ErrorResponse("UNITS_NOT_TRACKED", "Equipment does not track individual units"))
+	case errors.Is(err, service.ErrUnitSerialExists):
+		respondJSON(w, http.StatusConflict, model.ErrorResponse("SERIAL_EXISTS", "Serial number already exists for this equipment"))
+	case errors.Is(err, service.ErrUnitInUse):
+		respondJSON(w, http.StatusConflict, model.ErrorResponse("UNIT_IN_USE", "Unit has reservations; retire it instead"))
+	default:
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", fallback))
+	}
+}
+
+// parseEquipmentSubPath extracts the IDs from /api/v1/equipment/{id}/{collection}[/{itemId}].
+// itemID is uuid.Nil when the path addresses the collection.
+func parseEquipmentSubPath(r *http.Request) (equipmentID, itemID uuid.UUID, err error) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/equipment/"), "/")
+
+	equipmentID, err = uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	if len(parts) >= 3 && parts[2] != "" {
+		itemID, err = uuid.Parse(parts[2])
+		if err != nil {
+			return uuid.Nil, uuid.Nil, err
+		}
+	}
+
+	return equipmentID, itemID, nil
+}
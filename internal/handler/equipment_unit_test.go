@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/jwt"
+)
+
+func ownerContext() context.Context {
+	claims := &jwt.Claims{
+		UserID: uuid.New(),
+		Email:  "owner@example.com",
+		Role:   "owner",
+	}
+	return context.WithValue(context.Background(), middleware.UserContextKey, claims)
+}
+
+func TestEquipmentHandler_CreateUnit_Unauthorized(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	body, _ := json.Marshal(model.CreateEquipmentUnitRequest{SerialNumber: "SN-1"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/equipment/"+uuid.New().String()+"/units", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handler.CreateUnit(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestEquipmentHandler_CreateUnit_InvalidJSON(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/equipment/"+uuid.New().String()+"/units", bytes.NewBufferString("invalid")).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.CreateUnit(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestEquipmentHandler_UpdateUnit_InvalidID(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	paths := []string{
+		"/api/v1/equipment/invalid-uuid/units/" + uuid.New().String(),
+		"/api/v1/equipment/" + uuid.New().String() + "/units/invalid-uuid",
+		"/api/v1/equipment/" + uuid.New().String() + "/units/",
+	}
+
+	for _, path := range paths {
+		req := httptest.NewRequest(http.MethodPut, path, bytes.NewBufferString("{}")).WithContext(ownerContext())
+		w := httptest.NewRecorder()
+
+		handler.UpdateUnit(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", path, http.StatusBadRequest, w.Code)
+		}
+
+		var response model.APIResponse
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.Error == nil || response.Error.Code != "INVALID_ID" {
+			t.Errorf("%s: expected INVALID_ID error code", path)
+		}
+	}
+}
+
+func TestEquipmentHandler_DeleteUnit_Unauthorized(t *testing.T) {
+	handler := &EquipmentHandler{}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/equipment/"+uuid.New().String()+"/units/"+uuid.New().String(), nil)
+	w := httptest.NewRecorder()
+
+	handler.DeleteUnit(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
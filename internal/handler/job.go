@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/jobs"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/pagination"
+	"github.com/abneribeiro/goapi/internal/queue"
+)
+
+type JobHandler struct {
+	scheduler *jobs.Scheduler
+	jobQueue  *queue.Queue
+}
+
+func NewJobHandler(scheduler *jobs.Scheduler, jobQueue *queue.Queue) *JobHandler {
+	return &JobHandler{
+		scheduler: scheduler,
+		jobQueue:  jobQueue,
+	}
+}
+
+func (h *JobHandler) List(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, model.SuccessResponse(h.scheduler.Metrics()))
+}
+
+func (h *JobHandler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	filter := &model.JobFilter{
+		Type: r.URL.Query().Get("type"),
+	}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		s := model.JobStatus(status)
+		switch s {
+		case model.JobQueued, model.JobRunning, model.JobSucceeded, model.JobDead:
+		default:
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_STATUS", "Status must be one of queued, running, succeeded, dead"))
+			return
+		}
+		filter.Status = &s
+	}
+
+	pag := pagination.FromRequest(r)
+
+	queued, total, err := h.jobQueue.List(r.Context(), filter, pag)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to list jobs"))
+		return
+	}
+
+	meta := &model.Meta{
+		Page:       pag.Page,
+		PerPage:    pag.PerPage,
+		Total:      total,
+		TotalPages: pagination.CalculateTotalPages(total, pag.PerPage),
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponseWithMeta(queued, meta))
+}
+
+func (h *JobHandler) GetQueued(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/queue/"))
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid job ID"))
+		return
+	}
+
+	job, err := h.jobQueue.Get(r.Context(), id)
+	if err != nil {
+		respondQueueError(w, err, "Failed to get job")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(job))
+}
+
+func (h *JobHandler) Retry(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/queue/")
+	idStr = strings.TrimSuffix(idStr, "/retry")
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid job ID"))
+		return
+	}
+
+	job, err := h.jobQueue.Retry(r.Context(), id)
+	if err != nil {
+		respondQueueError(w, err, "Failed to retry job")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(job))
+}
+
+func respondQueueError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, queue.ErrJobNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Job not found"))
+	case errors.Is(err, queue.ErrJobNotDead):
+		respondJSON(w, http.StatusConflict, model.ErrorResponse("JOB_NOT_DEAD", err.Error()))
+	case errors.Is(err, queue.ErrJobExists):
+		respondJSON(w, http.StatusConflict, model.ErrorResponse("JOB_EXISTS", err.Error()))
+	default:
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", fallback))
+	}
+}
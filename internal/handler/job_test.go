@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestJobHandler_ListQueue_InvalidStatus(t *testing.T) {
+	handler := &JobHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/queue?status=unknown", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListQueue(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_STATUS" {
+		t.Error("expected INVALID_STATUS error code")
+	}
+}
+
+func TestJobHandler_Retry_InvalidID(t *testing.T) {
+	handler := &JobHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/queue/invalid-uuid/retry", nil)
+	w := httptest.NewRecorder()
+
+	handler.Retry(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
+	"github.com/abneribeiro/goapi/internal/realtime"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+const (
+	liveWriteTimeout   = 10 * time.Second
+	liveMaxMessageSize = 4096
+)
+
+type LiveHandler struct {
+	liveService  *service.LiveService
+	pingInterval time.Duration
+	upgrader     websocket.Upgrader
+}
+
+func NewLiveHandler(liveService *service.LiveService, pingInterval time.Duration, allowedOrigins []string) *LiveHandler {
+	return &LiveHandler{
+		liveService:  liveService,
+		pingInterval: pingInterval,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: checkOrigin(allowedOrigins),
+		},
+	}
+}
+
+// checkOrigin lets browsers connect from the server's own origin or one of
+// allowed. Requests without an Origin header come from other clients, which
+// a browser page cannot impersonate, and are let through.
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	origins := make(map[string]struct{}, len(allowed))
+	for _, origin := range allowed {
+		origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = struct{}{}
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+
+		if _, ok := origins[strings.ToLower(origin)]; ok {
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
+
+// Connect upgrades to a WebSocket on which the client subscribes to topics
+// and is pushed their updates. A client too slow to take its updates is
+// disconnected with close code 1013 and should reconnect and reload.
+func (h *LiveHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	client := h.liveService.NewClient()
+	defer client.Close()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.write(conn, client, stop)
+	}()
+
+	h.read(r, conn, client, claims.UserID)
+	close(stop)
+	<-done
+}
+
+// read handles the client's requests until the connection fails or the
+// client is dropped.
+func (h *LiveHandler) read(r *http.Request, conn *websocket.Conn, client *realtime.Client, userID uuid.UUID) {
+	conn.SetReadLimit(liveMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(2 * h.pingInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * h.pingInterval))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req model.LiveRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			reply(client, model.LiveMessage{Type: model.LiveError, Error: &model.APIError{Code: "INVALID_JSON", Message: "Invalid message"}})
+			continue
+		}
+
+		switch req.Action {
+		case model.LiveSubscribe:
+			err := h.liveService.Subscribe(r.Context(), client, userID, req.Topic)
+			if err != nil {
+				reply(client, model.LiveMessage{Type: model.LiveError, Topic: req.Topic, Error: liveError(err)})
+				continue
+			}
+			reply(client, model.LiveMessage{Type: model.LiveSubscribed, Topic: req.Topic})
+
+		case model.LiveUnsubscribe:
+			h.liveService.Unsubscribe(client, req.Topic)
+			reply(client, model.LiveMessage{Type: model.LiveUnsubscribed, Topic: req.Topic})
+
+		default:
+			reply(client, model.LiveMessage{Type: model.LiveError, Error: &model.APIError{Code: "INVALID_ACTION", Message: "Action must be subscribe or unsubscribe"}})
+		}
+	}
+}
+
+// write sends the client's messages and pings until it is dropped, a write
+// fails or stop is closed.
+func (h *LiveHandler) write(conn *websocket.Conn, client *realtime.Client, stop <-chan struct{}) {
+	ticker := time.NewTicker(h.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-client.Send:
+			conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				conn.Close()
+				return
+			}
+
+		case <-client.Dropped:
+			deadline := time.Now().Add(liveWriteTimeout)
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow"), deadline)
+			conn.Close()
+			return
+
+		case <-stop:
+			return
+
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(liveWriteTimeout)); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func reply(client *realtime.Client, msg model.LiveMessage) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		logger.Error("failed to encode live message", logger.WithFields(map[string]interface{}{
+			"error": err.Error(),
+		}))
+		return
+	}
+	client.Reply(body)
+}
+
+func liveError(err error) *model.APIError {
+	switch {
+	case errors.Is(err, model.ErrInvalidTopic):
+		return &model.APIError{Code: "INVALID_TOPIC", Message: "Topic must be reservation:{id} or equipment:{id}:availability"}
+	case errors.Is(err, service.ErrReservationNotFound):
+		return &model.APIError{Code: "NOT_FOUND", Message: "Reservation not found"}
+	case errors.Is(err, service.ErrEquipmentNotFound):
+		return &model.APIError{Code: "NOT_FOUND", Message: "Equipment not found"}
+	case errors.Is(err, service.ErrNotAuthorized):
+		return &model.APIError{Code: "FORBIDDEN", Message: "Not authorized to follow this topic"}
+	case errors.Is(err, realtime.ErrTooManyTopics):
+		return &model.APIError{Code: "TOO_MANY_TOPICS", Message: "Too many topics on one connection"}
+	default:
+		return &model.APIError{Code: "INTERNAL_ERROR", Message: "Failed to subscribe"}
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/realtime"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+func TestLiveHandler_Connect_Unauthorized(t *testing.T) {
+	handler := &LiveHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/live", nil)
+	w := httptest.NewRecorder()
+
+	handler.Connect(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestLiveHandler_Connect_RejectsInvalidRequests(t *testing.T) {
+	liveService := service.NewLiveService(nil, nil, nil, realtime.NewTopics(8, 10))
+	handler := NewLiveHandler(liveService, time.Minute, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.Connect(w, r.WithContext(ownerContext()))
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	tests := []struct {
+		message string
+		code    string
+	}{
+		{"invalid", "INVALID_JSON"},
+		{`{"action":"subscribe","topic":"users:all"}`, "INVALID_TOPIC"},
+		{`{"action":"publish","topic":"reservation:1"}`, "INVALID_ACTION"},
+	}
+
+	for _, tt := range tests {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(tt.message)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+
+		var msg model.LiveMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if msg.Type != model.LiveError || msg.Error == nil || msg.Error.Code != tt.code {
+			t.Errorf("%s: expected %s error, got %+v", tt.message, tt.code, msg)
+		}
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	check := checkOrigin([]string{"https://app.example.com/", "http://localhost:3000"})
+
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{"no origin", "", true},
+		{"allowed origin", "https://app.example.com", true},
+		{"allowed origin in another case", "HTTPS://App.Example.com", true},
+		{"allowed local origin", "http://localhost:3000", true},
+		{"same origin", "https://api.example.com", true},
+		{"other origin", "https://evil.example.com", false},
+		{"allowed host on another scheme", "http://app.example.com", false},
+		{"malformed origin", "://", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "https://api.example.com/api/v1/live", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			if got := check(req); got != tt.want {
+				t.Errorf("checkOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
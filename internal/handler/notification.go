@@ -1,25 +1,32 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/abneribeiro/goapi/internal/middleware"
 	"github.com/abneribeiro/goapi/internal/model"
 	"github.com/abneribeiro/goapi/internal/pkg/pagination"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
 	"github.com/abneribeiro/goapi/internal/service"
 )
 
 type NotificationHandler struct {
 	notificationService *service.NotificationService
+	heartbeat           time.Duration
 }
 
-func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+func NewNotificationHandler(notificationService *service.NotificationService, heartbeat time.Duration) *NotificationHandler {
 	return &NotificationHandler{
 		notificationService: notificationService,
+		heartbeat:           heartbeat,
 	}
 }
 
@@ -30,9 +37,29 @@ func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter := &model.NotificationFilter{}
+
+	if readStr := r.URL.Query().Get("read"); readStr != "" {
+		read, err := strconv.ParseBool(readStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_FILTER", "read must be true or false"))
+			return
+		}
+		filter.Read = &read
+	}
+
+	if t := r.URL.Query().Get("type"); t != "" {
+		notifType := model.NotificationType(t)
+		if !slices.Contains(model.NotificationTypes(), notifType) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_TYPE", "Unknown notification type"))
+			return
+		}
+		filter.Type = &notifType
+	}
+
 	pag := pagination.FromRequest(r)
 
-	notifications, total, err := h.notificationService.List(r.Context(), claims.UserID, pag)
+	notifications, total, err := h.notificationService.List(r.Context(), claims.UserID, filter, pag)
 	if err != nil {
 		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to list notifications"))
 		return
@@ -113,6 +140,60 @@ func (h *NotificationHandler) MarkAllAsRead(w http.ResponseWriter, r *http.Reque
 	respondJSON(w, http.StatusOK, model.SuccessResponse(map[string]string{"message": "All notifications marked as read"}))
 }
 
+func (h *NotificationHandler) BulkMarkAsRead(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	var req model.BulkNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	result, err := h.notificationService.BulkMarkAsRead(r.Context(), claims.UserID, &req)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to mark notifications as read"))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(result))
+}
+
+func (h *NotificationHandler) BulkDelete(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	var req model.BulkNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	result, err := h.notificationService.BulkDelete(r.Context(), claims.UserID, &req)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to delete notifications"))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(result))
+}
+
 func (h *NotificationHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	claims := middleware.GetUserFromContext(r.Context())
 	if claims == nil {
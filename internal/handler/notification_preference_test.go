@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestNotificationHandler_GetPreferences_Unauthorized(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/notification-preferences", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetPreferences(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestNotificationHandler_UpdatePreferences_InvalidJSON(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/users/me/notification-preferences", bytes.NewBufferString("invalid")).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.UpdatePreferences(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_JSON" {
+		t.Error("expected INVALID_JSON error code")
+	}
+}
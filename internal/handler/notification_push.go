@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+// PushPublicKey returns the VAPID public key browsers pass to
+// pushManager.subscribe as the applicationServerKey.
+func (h *NotificationHandler) PushPublicKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.notificationService.PushPublicKey()
+	if err != nil {
+		respondPushError(w, err, "Failed to get push public key")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(map[string]string{"public_key": key}))
+}
+
+func (h *NotificationHandler) SubscribePush(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	var req model.CreatePushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	sub, err := h.notificationService.SubscribePush(r.Context(), claims.UserID, r.UserAgent(), &req)
+	if err != nil {
+		respondPushError(w, err, "Failed to save push subscription")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, model.SuccessResponse(sub))
+}
+
+func (h *NotificationHandler) UnsubscribePush(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	var req model.DeletePushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	if err := h.notificationService.UnsubscribePush(r.Context(), claims.UserID, req.Endpoint); err != nil {
+		respondPushError(w, err, "Failed to remove push subscription")
+		return
+	}
+
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+func respondPushError(w http.ResponseWriter, err error, fallback string) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+	case errors.Is(err, service.ErrPushNotConfigured):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("PUSH_NOT_CONFIGURED", "Web push is not configured"))
+	default:
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", fallback))
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestNotificationHandler_SubscribePush_Unauthorized(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/push-subscriptions", bytes.NewBufferString("{}"))
+	w := httptest.NewRecorder()
+
+	handler.SubscribePush(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestNotificationHandler_SubscribePush_InvalidJSON(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/push-subscriptions", bytes.NewBufferString("invalid")).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.SubscribePush(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_JSON" {
+		t.Error("expected INVALID_JSON error code")
+	}
+}
+
+func TestNotificationHandler_UnsubscribePush_Unauthorized(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/push-subscriptions", bytes.NewBufferString("{}"))
+	w := httptest.NewRecorder()
+
+	handler.UnsubscribePush(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
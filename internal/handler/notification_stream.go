@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
+)
+
+// streamRescan is how far behind its position, in notification sequences,
+// a stream reads again on every catch-up. Sequences are taken when a
+// notification is written but become visible when it commits, so one can
+// appear after higher ones were already sent; reading back finds it, and
+// the ones already sent are skipped.
+const streamRescan = 1000
+
+// notificationSource is the part of NotificationService a stream reads.
+type notificationSource interface {
+	ListSince(ctx context.Context, userID uuid.UUID, after int64) ([]*model.Notification, error)
+	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+// Stream sends the user's new notifications and unread count as
+// Server-Sent Events. Each notification event's ID is the highest sequence
+// sent so far, so a client reconnecting with Last-Event-ID is sent what it
+// missed; without one the stream starts from now. A reconnecting client
+// may be sent a notification again and should skip IDs it already has. A
+// comment is sent every heartbeat to keep idle connections open through
+// proxies.
+func (h *NotificationHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	var lastID int64
+	resume := r.Header.Get("Last-Event-ID")
+	if resume != "" {
+		var err error
+		lastID, err = strconv.ParseInt(resume, 10, 64)
+		if err != nil || lastID < 0 {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_EVENT_ID", "Last-Event-ID must be a notification event ID"))
+			return
+		}
+	}
+
+	ctx := r.Context()
+
+	// Watch before reading, so a change between the two is not missed.
+	sub := h.notificationService.Watch(claims.UserID)
+	defer sub.Close()
+
+	if resume == "" {
+		var err error
+		lastID, err = h.notificationService.LatestSequence(ctx, claims.UserID)
+		if err != nil {
+			respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to open notification stream"))
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := newNotificationStream(w, rc, h.notificationService, claims.UserID, lastID)
+
+	// A new connection starts from now, so what is already there is only
+	// noted as seen.
+	if resume == "" {
+		if err := stream.skipExisting(ctx); err != nil {
+			stream.fail(ctx, err)
+			return
+		}
+	}
+
+	if err := stream.catchUp(ctx); err != nil {
+		stream.fail(ctx, err)
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.C:
+			if err := stream.catchUp(ctx); err != nil {
+				stream.fail(ctx, err)
+				return
+			}
+		case <-ticker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+type notificationStream struct {
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	source notificationSource
+	userID uuid.UUID
+	lastID int64
+	unread int64
+	// sent holds the sequences sent within streamRescan of lastID.
+	sent map[int64]bool
+}
+
+func newNotificationStream(w http.ResponseWriter, rc *http.ResponseController, source notificationSource, userID uuid.UUID, lastID int64) *notificationStream {
+	return &notificationStream{
+		w:      w,
+		rc:     rc,
+		source: source,
+		userID: userID,
+		lastID: lastID,
+		unread: -1,
+		sent:   make(map[int64]bool),
+	}
+}
+
+// catchUp sends the notifications not sent yet, then the unread count if it
+// changed.
+func (s *notificationStream) catchUp(ctx context.Context) error {
+	err := s.scan(ctx, func(n *model.Notification) error {
+		s.lastID = max(s.lastID, n.Sequence)
+		s.sent[n.Sequence] = true
+		return s.send(strconv.FormatInt(s.lastID, 10), "notification", n)
+	})
+	if err != nil {
+		return err
+	}
+
+	count, err := s.source.GetUnreadCount(ctx, s.userID)
+	if err != nil {
+		return err
+	}
+	if count != s.unread {
+		if err := s.send("", "unread_count", map[string]int64{"unread_count": count}); err != nil {
+			return err
+		}
+		s.unread = count
+	}
+
+	return s.rc.Flush()
+}
+
+// skipExisting marks the notifications up to lastID as sent without sending
+// them. Later ones are left for catchUp.
+func (s *notificationStream) skipExisting(ctx context.Context) error {
+	return s.scan(ctx, func(n *model.Notification) error {
+		if n.Sequence <= s.lastID {
+			s.sent[n.Sequence] = true
+		}
+		return nil
+	})
+}
+
+// scan passes fn each notification from streamRescan before lastID onwards
+// that was not sent yet, oldest first, then forgets sent sequences that have
+// fallen out of the window.
+func (s *notificationStream) scan(ctx context.Context, fn func(*model.Notification) error) error {
+	cursor := max(s.lastID-streamRescan, 0)
+	for {
+		notifications, err := s.source.ListSince(ctx, s.userID, cursor)
+		if err != nil {
+			return err
+		}
+		if len(notifications) == 0 {
+			break
+		}
+
+		for _, n := range notifications {
+			cursor = n.Sequence
+			if s.sent[n.Sequence] {
+				continue
+			}
+			if err := fn(n); err != nil {
+				return err
+			}
+		}
+	}
+
+	for sequence := range s.sent {
+		if sequence <= s.lastID-streamRescan {
+			delete(s.sent, sequence)
+		}
+	}
+
+	return nil
+}
+
+func (s *notificationStream) send(id, event string, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		fmt.Fprintf(s.w, "id: %s\n", id)
+	}
+	_, err = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, body)
+	return err
+}
+
+// fail logs why the stream ended, unless the client went away. The client
+// reconnects and resumes from the last event it received.
+func (s *notificationStream) fail(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	logger.Error("notification stream failed", logger.WithFields(map[string]interface{}{
+		"user_id": s.userID.String(),
+		"error":   err.Error(),
+	}))
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestNotificationHandler_Stream_Unauthorized(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications/stream", nil)
+	w := httptest.NewRecorder()
+
+	handler.Stream(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestNotificationHandler_Stream_InvalidLastEventID(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications/stream", nil).WithContext(ownerContext())
+	req.Header.Set("Last-Event-ID", "not-a-number")
+	w := httptest.NewRecorder()
+
+	handler.Stream(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_EVENT_ID" {
+		t.Error("expected INVALID_EVENT_ID error code")
+	}
+}
+
+// committedNotifications serves the notifications committed so far, in
+// sequence order, as the repository would.
+type committedNotifications struct {
+	committed []*model.Notification
+}
+
+func (c *committedNotifications) commit(sequence int64) {
+	c.committed = append(c.committed, &model.Notification{ID: uuid.New(), Sequence: sequence})
+	slices.SortFunc(c.committed, func(a, b *model.Notification) int { return int(a.Sequence - b.Sequence) })
+}
+
+func (c *committedNotifications) ListSince(ctx context.Context, userID uuid.UUID, after int64) ([]*model.Notification, error) {
+	var since []*model.Notification
+	for _, n := range c.committed {
+		if n.Sequence > after {
+			since = append(since, n)
+		}
+	}
+	return since, nil
+}
+
+func (c *committedNotifications) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return int64(len(c.committed)), nil
+}
+
+// sent returns the sequences of the notification events in body, in the
+// order they were sent.
+func (c *committedNotifications) sent(t *testing.T, body string) []int64 {
+	t.Helper()
+	var sequences []int64
+	for _, event := range strings.Split(body, "\n\n") {
+		if !strings.Contains(event, "event: notification\n") {
+			continue
+		}
+		_, data, _ := strings.Cut(event, "data: ")
+
+		var n model.Notification
+		if err := json.Unmarshal([]byte(data), &n); err != nil {
+			t.Fatalf("decode %q: %v", data, err)
+		}
+		for _, committed := range c.committed {
+			if committed.ID == n.ID {
+				sequences = append(sequences, committed.Sequence)
+			}
+		}
+	}
+	return sequences
+}
+
+func TestNotificationStream_LateCommit(t *testing.T) {
+	source := &committedNotifications{}
+	source.commit(10)
+
+	w := httptest.NewRecorder()
+	stream := newNotificationStream(w, http.NewResponseController(w), source, uuid.New(), 10)
+	if err := stream.skipExisting(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 12 commits while 11, written earlier, is still in its transaction.
+	source.commit(12)
+	if err := stream.catchUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	source.commit(11)
+	if err := stream.catchUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := source.sent(t, w.Body.String()); !slices.Equal(got, []int64{12, 11}) {
+		t.Errorf("expected 12 then the late 11 and nothing twice, got %v", got)
+	}
+	if !strings.Contains(w.Body.String(), "id: 12\nevent: notification") || strings.Contains(w.Body.String(), "id: 11\n") {
+		t.Error("expected the event ID to stay at the highest sequence sent")
+	}
+}
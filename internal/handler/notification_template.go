@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/abneribeiro/goapi/internal/i18n"
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+)
+
+func (h *NotificationHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	filter := &model.NotificationTemplateFilter{}
+
+	if t := r.URL.Query().Get("type"); t != "" {
+		notifType := model.NotificationType(t)
+		if !slices.Contains(model.TemplateTypes(), notifType) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_TYPE", "Unknown notification type"))
+			return
+		}
+		filter.Type = &notifType
+	}
+
+	if locale := r.URL.Query().Get("locale"); locale != "" {
+		if !i18n.Supported(locale) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_LOCALE", "Locale must be one of "+strings.Join(i18n.Locales(), ", ")))
+			return
+		}
+		filter.Locale = &locale
+	}
+
+	templates, err := h.notificationService.ListTemplates(r.Context(), filter)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to list notification templates"))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(templates))
+}
+
+func (h *NotificationHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	var req model.CreateNotificationTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	tmpl, err := h.notificationService.CreateTemplate(r.Context(), claims.UserID, &req)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to create notification template"))
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, model.SuccessResponse(tmpl))
+}
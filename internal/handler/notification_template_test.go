@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestNotificationHandler_ListTemplates_InvalidFilters(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	tests := []struct {
+		query string
+		code  string
+	}{
+		{"?type=unknown", "INVALID_TYPE"},
+		{"?locale=de", "INVALID_LOCALE"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/notification-templates"+tt.query, nil)
+		w := httptest.NewRecorder()
+
+		handler.ListTemplates(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", tt.query, http.StatusBadRequest, w.Code)
+		}
+
+		var response model.APIResponse
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.Error == nil || response.Error.Code != tt.code {
+			t.Errorf("%s: expected %s error code", tt.query, tt.code)
+		}
+	}
+}
+
+func TestNotificationHandler_CreateTemplate_Unauthorized(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/notification-templates", nil)
+	w := httptest.NewRecorder()
+
+	handler.CreateTemplate(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestNotificationHandler_CreateTemplate_InvalidJSON(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/notification-templates", bytes.NewBufferString("invalid")).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.CreateTemplate(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
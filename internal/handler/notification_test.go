@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -33,6 +34,50 @@ func TestNotificationHandler_List_Unauthorized(t *testing.T) {
 	}
 }
 
+func TestNotificationHandler_List_InvalidType(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications?read=false&type=unknown", nil).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.List(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_TYPE" {
+		t.Error("expected INVALID_TYPE error code")
+	}
+}
+
+func TestNotificationHandler_List_InvalidRead(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	for _, value := range []string{"yes", "unread", "2"} {
+		t.Run(value, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications?read="+value, nil).WithContext(ownerContext())
+			w := httptest.NewRecorder()
+
+			handler.List(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+
+			var response model.APIResponse
+			json.NewDecoder(w.Body).Decode(&response)
+
+			if response.Error == nil || response.Error.Code != "INVALID_FILTER" {
+				t.Error("expected INVALID_FILTER error code")
+			}
+		})
+	}
+}
+
 func TestNotificationHandler_GetUnreadCount_Unauthorized(t *testing.T) {
 	handler := &NotificationHandler{}
 
@@ -131,3 +176,49 @@ func TestNotificationHandler_Delete_InvalidID(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
 	}
 }
+
+func TestNotificationHandler_Bulk_Unauthorized(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	tests := []struct {
+		path    string
+		handler http.HandlerFunc
+	}{
+		{"/api/v1/notifications/bulk-read", handler.BulkMarkAsRead},
+		{"/api/v1/notifications/bulk-delete", handler.BulkDelete},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(`{"read":true}`))
+		w := httptest.NewRecorder()
+
+		tt.handler(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected status %d, got %d", tt.path, http.StatusUnauthorized, w.Code)
+		}
+	}
+}
+
+func TestNotificationHandler_Bulk_InvalidJSON(t *testing.T) {
+	handler := &NotificationHandler{}
+
+	tests := []struct {
+		path    string
+		handler http.HandlerFunc
+	}{
+		{"/api/v1/notifications/bulk-read", handler.BulkMarkAsRead},
+		{"/api/v1/notifications/bulk-delete", handler.BulkDelete},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString("invalid")).WithContext(ownerContext())
+		w := httptest.NewRecorder()
+
+		tt.handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", tt.path, http.StatusBadRequest, w.Code)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+const maxWebhookSize = 1 << 20
+
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+}
+
+func NewPaymentHandler(paymentService *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+	}
+}
+
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_BODY", "Failed to read request body"))
+		return
+	}
+
+	if err := h.paymentService.HandleWebhook(r.Context(), payload, r.Header); err != nil {
+		if errors.Is(err, service.ErrInvalidWebhook) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_WEBHOOK", "Webhook signature or payload is invalid"))
+			return
+		}
+		if errors.Is(err, service.ErrPaymentNotFound) {
+			respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Payment not found"))
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to process webhook"))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(nil))
+}
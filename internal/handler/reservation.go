@@ -53,6 +53,14 @@ func (h *ReservationHandler) Create(w http.ResponseWriter, r *http.Request) {
 			respondJSON(w, http.StatusConflict, model.ErrorResponse("UNAVAILABLE", "Equipment not available for selected dates"))
 			return
 		}
+		if errors.Is(err, service.ErrQuoteExpired) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("QUOTE_EXPIRED", "Quote has expired, request a new one"))
+			return
+		}
+		if errors.Is(err, service.ErrInvalidQuote) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_QUOTE", "Quote is invalid or does not match the reservation"))
+			return
+		}
 		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to create reservation"))
 		return
 	}
@@ -60,6 +68,48 @@ func (h *ReservationHandler) Create(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusCreated, model.SuccessResponse(reservation))
 }
 
+func (h *ReservationHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/equipment/")
+	idStr = strings.TrimSuffix(idStr, "/quote")
+
+	equipmentID, err := uuid.Parse(idStr)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid equipment ID"))
+		return
+	}
+
+	var req model.QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	quote, err := h.reservationService.Quote(r.Context(), equipmentID, &req)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+			return
+		}
+		if errors.Is(err, service.ErrUnsupportedCurrency) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_CURRENCY", "Unsupported currency"))
+			return
+		}
+		if errors.Is(err, service.ErrEquipmentNotFound) {
+			respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Equipment not found"))
+			return
+		}
+		if errors.Is(err, service.ErrEquipmentUnavailable) {
+			respondJSON(w, http.StatusConflict, model.ErrorResponse("UNAVAILABLE", "Equipment has no price configured"))
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to calculate quote"))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(quote))
+}
+
 func (h *ReservationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	claims := middleware.GetUserFromContext(r.Context())
 	if claims == nil {
@@ -169,10 +219,14 @@ func (h *ReservationHandler) Approve(w http.ResponseWriter, r *http.Request) {
 			respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not authorized to approve this reservation"))
 			return
 		}
-		if errors.Is(err, service.ErrReservationNotPending) {
+		if errors.Is(err, service.ErrInvalidTransition) {
 			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_STATUS", "Reservation is not pending"))
 			return
 		}
+		if errors.Is(err, service.ErrPaymentRequired) {
+			respondJSON(w, http.StatusPaymentRequired, model.ErrorResponse("PAYMENT_REQUIRED", "The renter has not authorized payment yet"))
+			return
+		}
 		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to approve reservation"))
 		return
 	}
@@ -209,7 +263,7 @@ func (h *ReservationHandler) Reject(w http.ResponseWriter, r *http.Request) {
 			respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not authorized to reject this reservation"))
 			return
 		}
-		if errors.Is(err, service.ErrReservationNotPending) {
+		if errors.Is(err, service.ErrInvalidTransition) {
 			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_STATUS", "Reservation is not pending"))
 			return
 		}
@@ -249,7 +303,7 @@ func (h *ReservationHandler) Cancel(w http.ResponseWriter, r *http.Request) {
 			respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not authorized to cancel this reservation"))
 			return
 		}
-		if errors.Is(err, service.ErrCannotCancel) {
+		if errors.Is(err, service.ErrCannotCancel) || errors.Is(err, service.ErrInvalidTransition) {
 			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("CANNOT_CANCEL", "Cannot cancel this reservation"))
 			return
 		}
@@ -286,6 +340,10 @@ func (h *ReservationHandler) Complete(w http.ResponseWriter, r *http.Request) {
 			respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not authorized to complete this reservation"))
 			return
 		}
+		if errors.Is(err, service.ErrInvalidTransition) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_STATUS", "Only approved or returned reservations can be completed"))
+			return
+		}
 		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to complete reservation"))
 		return
 	}
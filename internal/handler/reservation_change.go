@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+func (h *ReservationHandler) RequestChange(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, _, err := parseReservationSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation ID"))
+		return
+	}
+
+	var req model.CreateReservationChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	change, err := h.reservationService.RequestChange(r.Context(), id, claims.UserID, &req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to request change")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, model.SuccessResponse(change))
+}
+
+func (h *ReservationHandler) ListChanges(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, _, err := parseReservationSubPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation ID"))
+		return
+	}
+
+	changes, err := h.reservationService.ListChanges(r.Context(), id, claims.UserID)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to list change requests")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(changes))
+}
+
+func (h *ReservationHandler) ApproveChange(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, changeID, err := parseReservationSubPath(r)
+	if err != nil || changeID == uuid.Nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation or change request ID"))
+		return
+	}
+
+	change, err := h.reservationService.ApproveChange(r.Context(), id, changeID, claims.UserID)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to approve change request")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(change))
+}
+
+func (h *ReservationHandler) RejectChange(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, changeID, err := parseReservationSubPath(r)
+	if err != nil || changeID == uuid.Nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation or change request ID"))
+		return
+	}
+
+	var req model.CancelReservationRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	change, err := h.reservationService.RejectChange(r.Context(), id, changeID, claims.UserID, req.Reason)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to reject change request")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(change))
+}
+
+func (h *ReservationHandler) respondChangeError(w http.ResponseWriter, err error, fallback string) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+	case errors.Is(err, service.ErrReservationNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Reservation not found"))
+	case errors.Is(err, service.ErrChangeNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Change request not found"))
+	case errors.Is(err, service.ErrNotAuthorized):
+		respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not authorized to change this reservation"))
+	case errors.Is(err, service.ErrEquipmentUnavailable):
+		respondJSON(w, http.StatusConflict, model.ErrorResponse("UNAVAILABLE", "Equipment not available for the new dates"))
+	case errors.Is(err, service.ErrChangePending):
+		respondJSON(w, http.StatusConflict, model.ErrorResponse("CHANGE_PENDING", "Reservation already has a pending change request"))
+	case errors.Is(err, service.ErrChangeNotPending):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_STATUS", "Change request is no longer pending"))
+	case errors.Is(err, service.ErrChangeNeedsPayment):
+		respondJSON(w, http.StatusConflict, model.ErrorResponse("PAYMENT_REQUIRED", "The higher price needs a payment method to be charged to"))
+	case errors.Is(err, service.ErrPaymentDeclined):
+		respondJSON(w, http.StatusPaymentRequired, model.ErrorResponse("PAYMENT_DECLINED", "Payment was declined"))
+	case errors.Is(err, service.ErrCannotChange):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("CANNOT_CHANGE", "Reservation dates can no longer be changed"))
+	default:
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", fallback))
+	}
+}
+
+func parseReservationSubPath(r *http.Request) (reservationID, itemID uuid.UUID, err error) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/reservations/"), "/")
+
+	reservationID, err = uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	if len(parts) >= 3 && parts[2] != "" {
+		itemID, err = uuid.Parse(parts[2])
+		if err != nil {
+			return uuid.Nil, uuid.Nil, err
+		}
+	}
+
+	return reservationID, itemID, nil
+}
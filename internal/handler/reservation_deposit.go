@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+func (h *ReservationHandler) CaptureDeposit(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, err := parseDepositPath(r, "/deposit/capture")
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation ID"))
+		return
+	}
+
+	var req model.CaptureDepositRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	reservation, err := h.reservationService.CaptureDeposit(r.Context(), id, claims.UserID, &req)
+	if err != nil {
+		respondDepositError(w, err, "Failed to capture deposit")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(reservation))
+}
+
+func (h *ReservationHandler) ReleaseDeposit(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, err := parseDepositPath(r, "/deposit/release")
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation ID"))
+		return
+	}
+
+	reservation, err := h.reservationService.ReleaseDeposit(r.Context(), id, claims.UserID)
+	if err != nil {
+		respondDepositError(w, err, "Failed to release deposit")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(reservation))
+}
+
+func parseDepositPath(r *http.Request, suffix string) (uuid.UUID, error) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/reservations/")
+	return uuid.Parse(strings.TrimSuffix(idStr, suffix))
+}
+
+func respondDepositError(w http.ResponseWriter, err error, fallback string) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+	case errors.Is(err, service.ErrReservationNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Reservation not found"))
+	case errors.Is(err, service.ErrNotAuthorized):
+		respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Only the equipment owner can settle the deposit"))
+	case errors.Is(err, service.ErrNoDeposit):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("NO_DEPOSIT", "Reservation has no deposit"))
+	case errors.Is(err, service.ErrDepositNotHeld):
+		respondJSON(w, http.StatusConflict, model.ErrorResponse("DEPOSIT_NOT_HELD", "Deposit is not currently held"))
+	default:
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", fallback))
+	}
+}
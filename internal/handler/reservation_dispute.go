@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+func (h *ReservationHandler) Dispute(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, err := parseDisputePath(r, "/dispute")
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation ID"))
+		return
+	}
+
+	var req model.DisputeReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	reservation, err := h.reservationService.Dispute(r.Context(), id, claims.UserID, &req)
+	if err != nil {
+		respondDisputeError(w, err, "Only returned reservations can be disputed", "Failed to dispute reservation")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(reservation))
+}
+
+func (h *ReservationHandler) ResolveDispute(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, err := parseDisputePath(r, "/dispute/resolve")
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation ID"))
+		return
+	}
+
+	var req model.ResolveDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	admin := claims.Role == string(model.RoleAdmin)
+	reservation, err := h.reservationService.ResolveDispute(r.Context(), id, claims.UserID, admin, &req)
+	if err != nil {
+		respondDisputeError(w, err, "Only disputed reservations can be resolved", "Failed to resolve dispute")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(reservation))
+}
+
+func parseDisputePath(r *http.Request, suffix string) (uuid.UUID, error) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/reservations/")
+	return uuid.Parse(strings.TrimSuffix(idStr, suffix))
+}
+
+func respondDisputeError(w http.ResponseWriter, err error, invalidStatus, fallback string) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+	case errors.Is(err, service.ErrReservationNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Reservation not found"))
+	case errors.Is(err, service.ErrNotAuthorized):
+		respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not authorized to act on this dispute"))
+	case errors.Is(err, service.ErrInvalidTransition):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_STATUS", invalidStatus))
+	default:
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", fallback))
+	}
+}
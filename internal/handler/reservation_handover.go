@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+const maxHandoverUploadSize = 32 << 20
+
+func (h *ReservationHandler) Pickup(w http.ResponseWriter, r *http.Request) {
+	h.handover(w, r, model.HandoverPickup)
+}
+
+func (h *ReservationHandler) Return(w http.ResponseWriter, r *http.Request) {
+	h.handover(w, r, model.HandoverReturn)
+}
+
+func (h *ReservationHandler) ListHandovers(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/reservations/")
+	id, err := uuid.Parse(strings.TrimSuffix(idStr, "/handovers"))
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation ID"))
+		return
+	}
+
+	reports, err := h.reservationService.ListHandovers(r.Context(), id, claims.UserID)
+	if err != nil {
+		if errors.Is(err, service.ErrReservationNotFound) {
+			respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Reservation not found"))
+			return
+		}
+		if errors.Is(err, service.ErrNotAuthorized) {
+			respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not authorized to view this reservation"))
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to list handover reports"))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(reports))
+}
+
+func (h *ReservationHandler) handover(w http.ResponseWriter, r *http.Request, kind model.HandoverKind) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/reservations/")
+	id, err := uuid.Parse(strings.TrimSuffix(idStr, "/"+string(kind)))
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation ID"))
+		return
+	}
+
+	req, files, err := parseHandoverRequest(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	photos := make([]service.Upload, 0, len(files))
+	for _, fh := range files {
+		file, err := fh.Open()
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_FORM", "Invalid form data"))
+			return
+		}
+		defer file.Close()
+		photos = append(photos, service.Upload{Filename: fh.Filename, Content: file})
+	}
+
+	record := h.reservationService.Pickup
+	if kind == model.HandoverReturn {
+		record = h.reservationService.Return
+	}
+
+	report, err := record(r.Context(), id, claims.UserID, req, photos)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+		case errors.Is(err, service.ErrReservationNotFound):
+			respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Reservation not found"))
+		case errors.Is(err, service.ErrNotAuthorized):
+			respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not authorized to check in this reservation"))
+		case errors.Is(err, service.ErrInvalidTransition):
+			message := "Only approved reservations can be picked up"
+			if kind == model.HandoverReturn {
+				message = "Only active reservations can be returned"
+			}
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_STATUS", message))
+		default:
+			respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to record handover"))
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, model.SuccessResponse(report))
+}
+
+// parseHandoverRequest accepts either a JSON body or a multipart form with
+// the report as JSON in the "report" field and images in "photos".
+func parseHandoverRequest(r *http.Request) (*model.HandoverRequest, []*multipart.FileHeader, error) {
+	var req model.HandoverRequest
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, nil, err
+		}
+		return &req, nil, nil
+	}
+
+	if err := r.ParseMultipartForm(maxHandoverUploadSize); err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal([]byte(r.FormValue("report")), &req); err != nil {
+		return nil, nil, err
+	}
+	return &req, r.MultipartForm.File["photos"], nil
+}
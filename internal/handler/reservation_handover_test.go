@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestReservationHandler_Pickup_Unauthorized(t *testing.T) {
+	handler := &ReservationHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/reservations/"+uuid.New().String()+"/pickup", bytes.NewBufferString("{}"))
+	w := httptest.NewRecorder()
+
+	handler.Pickup(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestReservationHandler_Return_InvalidID(t *testing.T) {
+	handler := &ReservationHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/reservations/invalid-uuid/return", bytes.NewBufferString("{}")).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.Return(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestReservationHandler_Pickup_InvalidMultipartReport(t *testing.T) {
+	handler := &ReservationHandler{}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("report", "not json")
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/reservations/"+uuid.New().String()+"/pickup", &body).WithContext(ownerContext())
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+
+	handler.Pickup(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_JSON" {
+		t.Error("expected INVALID_JSON error code")
+	}
+}
+
+func TestReservationHandler_ListHandovers_InvalidID(t *testing.T) {
+	handler := &ReservationHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reservations/invalid-uuid/handovers", nil).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.ListHandovers(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
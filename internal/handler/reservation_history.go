@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+func (h *ReservationHandler) History(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/reservations/")
+	id, err := uuid.Parse(strings.TrimSuffix(idStr, "/history"))
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation ID"))
+		return
+	}
+
+	events, err := h.reservationService.History(r.Context(), id, claims.UserID)
+	if err != nil {
+		if errors.Is(err, service.ErrReservationNotFound) {
+			respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Reservation not found"))
+			return
+		}
+		if errors.Is(err, service.ErrNotAuthorized) {
+			respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not authorized to view this reservation"))
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to get reservation history"))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(events))
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestReservationHandler_History_Unauthorized(t *testing.T) {
+	handler := &ReservationHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reservations/"+uuid.New().String()+"/history", nil)
+	w := httptest.NewRecorder()
+
+	handler.History(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestReservationHandler_History_InvalidID(t *testing.T) {
+	handler := &ReservationHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reservations/invalid-uuid/history", nil).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.History(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
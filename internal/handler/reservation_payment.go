@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+func (h *ReservationHandler) Pay(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, err := parsePaymentsPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation ID"))
+		return
+	}
+
+	var req model.PayReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	reservation, err := h.reservationService.Pay(r.Context(), id, claims.UserID, &req)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+		case errors.Is(err, service.ErrReservationNotFound):
+			respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Reservation not found"))
+		case errors.Is(err, service.ErrNotAuthorized):
+			respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Only the renter can pay for this reservation"))
+		case errors.Is(err, service.ErrPaymentNotRequired):
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("PAYMENT_NOT_REQUIRED", "This equipment does not require prepayment"))
+		case errors.Is(err, service.ErrReservationNotPending):
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_STATUS", "Reservation is not pending"))
+		case errors.Is(err, service.ErrAlreadyPaid):
+			respondJSON(w, http.StatusConflict, model.ErrorResponse("ALREADY_PAID", "Reservation already has an active payment"))
+		case errors.Is(err, service.ErrPaymentDeclined):
+			respondJSON(w, http.StatusPaymentRequired, model.ErrorResponse("PAYMENT_DECLINED", "Payment was declined"))
+		default:
+			respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to process payment"))
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(reservation))
+}
+
+func (h *ReservationHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, err := parsePaymentsPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid reservation ID"))
+		return
+	}
+
+	payments, err := h.reservationService.ListPayments(r.Context(), id, claims.UserID)
+	if err != nil {
+		if errors.Is(err, service.ErrReservationNotFound) {
+			respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Reservation not found"))
+			return
+		}
+		if errors.Is(err, service.ErrNotAuthorized) {
+			respondJSON(w, http.StatusForbidden, model.ErrorResponse("FORBIDDEN", "Not authorized to view this reservation"))
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to list payments"))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(payments))
+}
+
+func parsePaymentsPath(r *http.Request) (uuid.UUID, error) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/v1/reservations/")
+	return uuid.Parse(strings.TrimSuffix(idStr, "/payments"))
+}
@@ -182,3 +182,43 @@ func TestReservationHandler_Complete_Unauthorized(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
 	}
 }
+
+func TestReservationHandler_Quote_InvalidID(t *testing.T) {
+	handler := &ReservationHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/equipment/invalid-uuid/quote", strings.NewReader("{}"))
+	w := httptest.NewRecorder()
+
+	handler.Quote(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_ID" {
+		t.Error("expected INVALID_ID error code")
+	}
+}
+
+func TestReservationHandler_Quote_InvalidJSON(t *testing.T) {
+	handler := &ReservationHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/equipment/"+uuid.New().String()+"/quote", strings.NewReader("invalid json"))
+	w := httptest.NewRecorder()
+
+	handler.Quote(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_JSON" {
+		t.Error("expected INVALID_JSON error code")
+	}
+}
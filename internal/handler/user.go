@@ -7,6 +7,7 @@ import (
 
 	"github.com/abneribeiro/goapi/internal/middleware"
 	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
 	"github.com/abneribeiro/goapi/internal/service"
 )
 
@@ -55,6 +56,11 @@ func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userService.Update(r.Context(), claims.UserID, &req)
 	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+			return
+		}
 		if errors.Is(err, service.ErrUserNotFound) {
 			respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "User not found"))
 			return
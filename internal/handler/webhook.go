@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/pagination"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	subs, err := h.webhookService.List(r.Context(), claims.UserID)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", "Failed to list webhooks"))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(subs))
+}
+
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	var req model.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	sub, err := h.webhookService.Create(r.Context(), claims.UserID, &req)
+	if err != nil {
+		respondWebhookError(w, err, "Failed to create webhook")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, model.SuccessResponse(sub))
+}
+
+func (h *WebhookHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, err := parseWebhookPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid webhook ID"))
+		return
+	}
+
+	sub, err := h.webhookService.Get(r.Context(), id, claims.UserID)
+	if err != nil {
+		respondWebhookError(w, err, "Failed to get webhook")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(sub))
+}
+
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, err := parseWebhookPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid webhook ID"))
+		return
+	}
+
+	var req model.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_JSON", "Invalid request body"))
+		return
+	}
+
+	sub, err := h.webhookService.Update(r.Context(), id, claims.UserID, &req)
+	if err != nil {
+		respondWebhookError(w, err, "Failed to update webhook")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(sub))
+}
+
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, err := parseWebhookPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid webhook ID"))
+		return
+	}
+
+	if err := h.webhookService.Delete(r.Context(), id, claims.UserID); err != nil {
+		respondWebhookError(w, err, "Failed to delete webhook")
+		return
+	}
+
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, err := parseWebhookPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid webhook ID"))
+		return
+	}
+
+	pag := pagination.FromRequest(r)
+
+	deliveries, total, err := h.webhookService.ListDeliveries(r.Context(), id, claims.UserID, pag)
+	if err != nil {
+		respondWebhookError(w, err, "Failed to list deliveries")
+		return
+	}
+
+	meta := &model.Meta{
+		Page:       pag.Page,
+		PerPage:    pag.PerPage,
+		Total:      total,
+		TotalPages: pagination.CalculateTotalPages(total, pag.PerPage),
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponseWithMeta(deliveries, meta))
+}
+
+func (h *WebhookHandler) SendTest(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		respondJSON(w, http.StatusUnauthorized, model.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+
+	id, err := parseWebhookPath(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("INVALID_ID", "Invalid webhook ID"))
+		return
+	}
+
+	delivery, err := h.webhookService.SendTest(r.Context(), id, claims.UserID)
+	if err != nil {
+		respondWebhookError(w, err, "Failed to send test event")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, model.SuccessResponse(delivery))
+}
+
+// parseWebhookPath reads the subscription ID from /api/v1/webhooks/{id}
+// and its sub-paths.
+func parseWebhookPath(r *http.Request) (uuid.UUID, error) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/webhooks/"), "/")
+	return uuid.Parse(parts[0])
+}
+
+func respondWebhookError(w http.ResponseWriter, err error, fallback string) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		respondJSON(w, http.StatusBadRequest, model.ErrorResponse("VALIDATION_ERROR", validationErrors.Error()))
+	case errors.Is(err, service.ErrWebhookNotFound):
+		respondJSON(w, http.StatusNotFound, model.ErrorResponse("NOT_FOUND", "Webhook not found"))
+	default:
+		respondJSON(w, http.StatusInternalServerError, model.ErrorResponse("INTERNAL_ERROR", fallback))
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestWebhookHandler_Create_Unauthorized(t *testing.T) {
+	handler := &WebhookHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewBufferString("{}"))
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestWebhookHandler_Create_InvalidJSON(t *testing.T) {
+	handler := &WebhookHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewBufferString("invalid")).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response model.APIResponse
+	json.NewDecoder(w.Body).Decode(&response)
+
+	if response.Error == nil || response.Error.Code != "INVALID_JSON" {
+		t.Error("expected INVALID_JSON error code")
+	}
+}
+
+func TestWebhookHandler_SendTest_InvalidID(t *testing.T) {
+	handler := &WebhookHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/invalid-uuid/test", nil).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.SendTest(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestWebhookHandler_ListDeliveries_InvalidID(t *testing.T) {
+	handler := &WebhookHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/invalid-uuid/deliveries", nil).WithContext(ownerContext())
+	w := httptest.NewRecorder()
+
+	handler.ListDeliveries(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,163 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+//go:embed templates/*.json
+var builtinFiles embed.FS
+
+// builtinCopy is the source of a type's title and message in a locale.
+type builtinCopy struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+// Catalog holds the copy shipped with the app for every type and locale.
+// Newer versions stored in the database take precedence over it.
+type Catalog struct {
+	builtin map[string]*Template
+}
+
+// NewCatalog loads the built-in copy from templates/<locale>.json.
+func NewCatalog() (*Catalog, error) {
+	c := &Catalog{builtin: make(map[string]*Template)}
+
+	files, err := builtinFiles.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		locale := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+		src, err := builtinFiles.ReadFile("templates/" + file.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		var copies map[model.NotificationType]builtinCopy
+		if err := json.Unmarshal(src, &copies); err != nil {
+			return nil, fmt.Errorf("failed to read %s notification templates: %w", locale, err)
+		}
+
+		for notifType, cp := range copies {
+			tmpl, err := Parse(notifType, locale, cp.Title, cp.Message)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s template for %s: %w", locale, notifType, err)
+			}
+			c.builtin[key(notifType, locale)] = tmpl
+		}
+	}
+
+	return c, nil
+}
+
+// Builtin returns the shipped template for a type in a locale.
+func (c *Catalog) Builtin(notifType model.NotificationType, locale string) (*Template, bool) {
+	tmpl, ok := c.builtin[key(notifType, locale)]
+	return tmpl, ok
+}
+
+func key(notifType model.NotificationType, locale string) string {
+	return string(notifType) + "/" + locale
+}
+
+var (
+	sampleStart = time.Date(2024, 3, 5, 9, 30, 0, 0, time.UTC)
+	sampleEnd   = time.Date(2024, 3, 8, 18, 0, 0, 0, time.UTC)
+	sampleMoney = money.New(123450, money.DefaultCurrency)
+)
+
+// samples are the shapes of data each type is created with. Variables that
+// are only sometimes set appear in some samples and not others, so
+// templates must check for them.
+var samples = map[model.NotificationType][]map[string]interface{}{
+	model.NotificationReservationCreated: {
+		{"equipment": "Canon EOS R5", "quantity": 1, "start_date": sampleStart, "end_date": sampleEnd},
+		{"equipment": "Canon EOS R5", "quantity": 2, "start_date": sampleStart, "end_date": sampleEnd},
+	},
+	model.NotificationReservationApproved: {
+		{"equipment": "Canon EOS R5", "start_date": sampleStart, "automatic": false},
+		{"equipment": "Canon EOS R5", "start_date": sampleStart, "automatic": true},
+	},
+	model.NotificationReservationRejected: {
+		{"equipment": "Canon EOS R5"},
+	},
+	model.NotificationReservationCancelled: {
+		{"equipment": "Canon EOS R5"},
+	},
+	model.NotificationReservationCompleted: {
+		{"equipment": "Canon EOS R5"},
+	},
+	model.NotificationReservationDisputed: {
+		{"equipment": "Canon EOS R5", "reason": "Returned with a cracked screen"},
+	},
+	model.NotificationReservationExpired: {
+		{"equipment": "Canon EOS R5", "recipient": "renter"},
+		{"equipment": "Canon EOS R5", "recipient": "owner"},
+	},
+	model.NotificationReservationReminder: {
+		{"equipment": "Canon EOS R5", "kind": "pickup", "due_at": sampleStart},
+		{"equipment": "Canon EOS R5", "kind": "return", "due_at": sampleEnd},
+	},
+	model.NotificationChangeRequested: {
+		{"equipment": "Canon EOS R5", "renter": "Jane Doe", "start_date": sampleStart, "end_date": sampleEnd},
+	},
+	model.NotificationChangeApproved: {
+		{"equipment": "Canon EOS R5", "start_date": sampleStart, "end_date": sampleEnd},
+		{"equipment": "Canon EOS R5", "start_date": sampleStart, "end_date": sampleEnd, "amount_due": sampleMoney},
+		{"equipment": "Canon EOS R5", "start_date": sampleStart, "end_date": sampleEnd, "amount_saved": sampleMoney},
+	},
+	model.NotificationChangeRejected: {
+		{"equipment": "Canon EOS R5"},
+	},
+	model.NotificationEquipmentPickedUp: {
+		{"equipment": "Canon EOS R5"},
+	},
+	model.NotificationEquipmentReturned: {
+		{"equipment": "Canon EOS R5"},
+		{"equipment": "Canon EOS R5", "late_fee": sampleMoney},
+	},
+	model.NotificationReturnOverdue: {
+		{"equipment": "Canon EOS R5", "renter": "Jane Doe", "recipient": "renter", "due_at": sampleEnd},
+		{"equipment": "Canon EOS R5", "renter": "Jane Doe", "recipient": "owner", "due_at": sampleEnd},
+	},
+	model.NotificationReturnConflict: {
+		{"equipment": "Canon EOS R5", "recipient": "owner", "start_date": sampleStart},
+		{"equipment": "Canon EOS R5", "recipient": "renter", "start_date": sampleStart},
+	},
+	model.NotificationPaymentReceived: {
+		{"equipment": "Canon EOS R5", "amount": sampleMoney},
+	},
+	model.NotificationDepositHeld: {
+		{"equipment": "Canon EOS R5", "amount": sampleMoney},
+	},
+	model.NotificationDepositCaptured: {
+		{"equipment": "Canon EOS R5", "amount": sampleMoney, "reason": "Scratched lens"},
+		{"equipment": "Canon EOS R5", "amount": sampleMoney, "reason": "Scratched lens", "released": sampleMoney},
+	},
+	model.NotificationDepositReleased: {
+		{"equipment": "Canon EOS R5", "amount": sampleMoney},
+	},
+	model.NotificationWebhookDisabled: {
+		{"url": "https://example.com/hooks", "failures": 1},
+		{"url": "https://example.com/hooks", "failures": 5},
+	},
+	model.CopyDeliveryEmail: {
+		{"name": "Jane Doe"},
+	},
+	model.CopyDeliveryDigest: {
+		{"name": "Jane Doe", "count": 2},
+	},
+	model.CopyDeliveryReminder: {
+		{"name": "Jane Doe", "title": "Pickup Reminder"},
+	},
+}
@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+func TestCatalog_HasEveryTypeInEveryLocale(t *testing.T) {
+	catalog, err := NewCatalog()
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+
+	for _, locale := range Locales() {
+		for _, notifType := range model.TemplateTypes() {
+			tmpl, ok := catalog.Builtin(notifType, locale)
+			if !ok {
+				t.Errorf("no %s template for %s", locale, notifType)
+				continue
+			}
+
+			for _, data := range samples[notifType] {
+				title, message, err := tmpl.Render(data, time.UTC)
+				if err != nil {
+					t.Errorf("%s %s: %v", locale, notifType, err)
+				}
+				if title == "" || message == "" {
+					t.Errorf("%s %s rendered empty copy", locale, notifType)
+				}
+			}
+		}
+	}
+}
+
+func TestTemplate_RenderStoredData(t *testing.T) {
+	tmpl, err := Parse(model.NotificationDepositCaptured, "pt",
+		"Caução cobrada",
+		`{{money .amount}} retidos{{if .released}}, {{money .released}} liberados{{end}}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// Data is read back from JSON, so money and numbers are decoded into
+	// maps and floats.
+	raw, _ := json.Marshal(map[string]interface{}{
+		"amount":   money.New(5000, "EUR"),
+		"released": money.New(250000, "EUR"),
+	})
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatal(err)
+	}
+
+	_, message, err := tmpl.Render(data, time.UTC)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if want := "50,00 € retidos, 2.500,00 € liberados"; message != want {
+		t.Errorf("got %q, want %q", message, want)
+	}
+}
+
+func TestParse_RejectsUnknownVariables(t *testing.T) {
+	_, err := Parse(model.NotificationDepositHeld, "en", "Deposit Held", "Held for {{.renter}}")
+	if err == nil || !strings.Contains(err.Error(), "variable") {
+		t.Errorf("expected an unknown variable to be rejected, got %v", err)
+	}
+
+	if _, err := Parse(model.NotificationDepositHeld, "en", "Deposit Held", "{{money .equipment}}"); err == nil {
+		t.Error("expected a template that fails to render to be rejected")
+	}
+
+	if _, err := Parse(model.NotificationDepositHeld, "de", "Kaution", "Kaution"); err == nil {
+		t.Error("expected an unsupported locale to be rejected")
+	}
+}
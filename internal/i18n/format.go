@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+// FormatMoney writes an amount the way the locale does, e.g. "$1,234.50"
+// in English and "1.234,50 €" in Portuguese. Currencies without a symbol
+// are written with their code.
+func FormatMoney(code string, m money.Money) string {
+	l := get(code)
+
+	plain := m.String()
+	sign := ""
+	if strings.HasPrefix(plain, "-") {
+		sign = "-"
+		plain = plain[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(plain, ".")
+	amount := groupThousands(whole, l.thousands)
+	if fraction != "" {
+		amount += l.decimal + fraction
+	}
+
+	symbol, ok := l.symbols[m.Currency]
+	if !ok {
+		symbol, ok = sharedSymbols[m.Currency]
+	}
+	if !ok {
+		symbol = m.Currency
+	}
+
+	if l.symbolFirst {
+		if !ok {
+			symbol += " "
+		}
+		return sign + symbol + amount
+	}
+	return sign + amount + " " + symbol
+}
+
+func groupThousands(digits, sep string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// FormatDate writes the date of t in loc, e.g. "March 5, 2024" or
+// "5 de março de 2024".
+func FormatDate(code string, t time.Time, loc *time.Location) string {
+	l := get(code)
+	t = t.In(loc)
+	return l.date(t.Day(), l.months[t.Month()-1], t.Year())
+}
+
+// FormatDateTime writes the date and time of t in loc.
+func FormatDateTime(code string, t time.Time, loc *time.Location) string {
+	l := get(code)
+	return FormatDate(code, t, loc) + l.at + t.In(loc).Format(l.clock)
+}
+
+// Plural picks the form for n, replacing "#" with n. Forms are given as
+// one and other, the categories the supported locales use.
+func Plural(code string, n int64, one, other string) string {
+	form := other
+	if get(code).one(n) {
+		form = one
+	}
+	return strings.ReplaceAll(form, "#", strconv.FormatInt(n, 10))
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,96 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		locale string
+		m      money.Money
+		want   string
+	}{
+		{"en", money.New(123450, "USD"), "$1,234.50"},
+		{"en", money.New(-500, "USD"), "-$5.00"},
+		{"en", money.New(99, "EUR"), "€0.99"},
+		{"en", money.New(1000, "CHF"), "CHF 10.00"},
+		{"pt", money.New(123450, "BRL"), "1.234,50 R$"},
+		{"pt", money.New(123450, "USD"), "1.234,50 US$"},
+		{"es", money.New(1234567, "EUR"), "12.345,67 €"},
+		{"es", money.New(1500, "JPY"), "1.500 ¥"},
+		{"fr", money.New(100, "USD"), "$1.00"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatMoney(tt.locale, tt.m); got != tt.want {
+			t.Errorf("FormatMoney(%s, %s) = %q, want %q", tt.locale, tt.m.Format(), got, tt.want)
+		}
+	}
+}
+
+func TestFormatDateTime(t *testing.T) {
+	at := time.Date(2024, 3, 5, 23, 30, 0, 0, time.UTC)
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+
+	tests := []struct {
+		locale string
+		loc    *time.Location
+		want   string
+	}{
+		{"en", time.UTC, "March 5, 2024 at 11:30 PM"},
+		{"pt", saoPaulo, "5 de março de 2024 às 20:30"},
+		{"es", time.UTC, "5 de marzo de 2024 a las 23:30"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDateTime(tt.locale, at, tt.loc); got != tt.want {
+			t.Errorf("FormatDateTime(%s) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+
+	if got := FormatDate("en", at, saoPaulo); got != "March 5, 2024" {
+		t.Errorf("FormatDate = %q", got)
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      int64
+		want   string
+	}{
+		{"en", 0, "0 days"},
+		{"en", 1, "1 day"},
+		{"en", 2, "2 days"},
+		{"pt", 0, "0 day"},
+		{"es", 0, "0 days"},
+	}
+
+	for _, tt := range tests {
+		if got := Plural(tt.locale, tt.n, "# day", "# days"); got != tt.want {
+			t.Errorf("Plural(%s, %d) = %q, want %q", tt.locale, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := map[string]string{
+		"pt-BR": "pt",
+		"es_MX": "es",
+		"EN":    "en",
+		"de":    DefaultLocale,
+		"":      DefaultLocale,
+	}
+
+	for tag, want := range tests {
+		if got := Match(tag); got != want {
+			t.Errorf("Match(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package i18n renders notification copy in the user's language, with
+// locale-aware plurals, dates and money.
+package i18n
+
+import (
+	"slices"
+	"strings"
+)
+
+// DefaultLocale is used for users who have not chosen one and for copy
+// missing in their locale.
+const DefaultLocale = "en"
+
+// locale holds how a language writes numbers and dates.
+type locale struct {
+	decimal     string
+	thousands   string
+	symbolFirst bool
+	symbols     map[string]string
+	months      [12]string
+	date        func(day int, month string, year int) string
+	at          string
+	clock       string
+	one         func(n int64) bool
+}
+
+var sharedSymbols = map[string]string{
+	"BRL": "R$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"INR": "₹",
+	"KRW": "₩",
+	"ILS": "₪",
+}
+
+var locales = map[string]*locale{
+	"en": {
+		decimal:     ".",
+		thousands:   ",",
+		symbolFirst: true,
+		symbols:     map[string]string{"USD": "$"},
+		months: [12]string{"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"},
+		date:  func(day int, month string, year int) string { return month + " " + itoa(day) + ", " + itoa(year) },
+		at:    " at ",
+		clock: "3:04 PM",
+		one:   func(n int64) bool { return n == 1 },
+	},
+	"pt": {
+		decimal:   ",",
+		thousands: ".",
+		symbols:   map[string]string{"USD": "US$"},
+		months: [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+			"julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		date:  func(day int, month string, year int) string { return itoa(day) + " de " + month + " de " + itoa(year) },
+		at:    " às ",
+		clock: "15:04",
+		one:   func(n int64) bool { return n == 0 || n == 1 },
+	},
+	"es": {
+		decimal:   ",",
+		thousands: ".",
+		symbols:   map[string]string{"USD": "US$"},
+		months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		date:  func(day int, month string, year int) string { return itoa(day) + " de " + month + " de " + itoa(year) },
+		at:    " a las ",
+		clock: "15:04",
+		one:   func(n int64) bool { return n == 1 },
+	},
+}
+
+// Locales returns the supported locales.
+func Locales() []string {
+	codes := make([]string, 0, len(locales))
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+func Supported(code string) bool {
+	_, ok := locales[code]
+	return ok
+}
+
+// Match returns the supported locale for a language tag such as "pt-BR",
+// or DefaultLocale.
+func Match(tag string) string {
+	code := strings.ToLower(tag)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	if Supported(code) {
+		return code
+	}
+	return DefaultLocale
+}
+
+func get(code string) *locale {
+	if l, ok := locales[code]; ok {
+		return l
+	}
+	return locales[DefaultLocale]
+}
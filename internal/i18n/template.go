@@ -0,0 +1,164 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+// noValue is what text/template writes for a variable the data lacks.
+const noValue = "<no value>"
+
+// Template is the title and message of a notification type in one locale.
+// Both are text/template sources rendered with the notification's data, and
+// can call:
+//
+//	money .amount                  the amount in the locale's format
+//	date .start_date               the date in the user's time zone
+//	datetime .due_at               the date and time in the user's time zone
+//	plural .count "# day" "# days" the form for the count, with # replaced by it
+type Template struct {
+	Locale  string
+	title   *template.Template
+	message *template.Template
+}
+
+// Parse compiles a title and message for a type in a locale, and renders
+// them with the type's sample data so templates using variables the type
+// does not have, or calling functions wrongly, are rejected before they are
+// sent to anyone.
+func Parse(notifType model.NotificationType, locale, title, message string) (*Template, error) {
+	if !Supported(locale) {
+		return nil, fmt.Errorf("unsupported locale %q", locale)
+	}
+
+	t := &Template{Locale: locale}
+	var err error
+	if t.title, err = template.New("title").Funcs(funcs(locale, time.UTC)).Parse(title); err != nil {
+		return nil, err
+	}
+	if t.message, err = template.New("message").Funcs(funcs(locale, time.UTC)).Parse(message); err != nil {
+		return nil, err
+	}
+
+	for _, data := range samples[notifType] {
+		renderedTitle, renderedMessage, err := t.Render(data, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(renderedTitle, noValue) || strings.Contains(renderedMessage, noValue) {
+			return nil, fmt.Errorf("template uses a variable %s notifications do not have", notifType)
+		}
+	}
+
+	return t, nil
+}
+
+// Render builds the title and message from a notification's data, writing
+// dates in loc.
+func (t *Template) Render(data map[string]interface{}, loc *time.Location) (string, string, error) {
+	title, err := execute(t.title, t.Locale, data, loc)
+	if err != nil {
+		return "", "", err
+	}
+	message, err := execute(t.message, t.Locale, data, loc)
+	if err != nil {
+		return "", "", err
+	}
+	return title, message, nil
+}
+
+func execute(tmpl *template.Template, locale string, data map[string]interface{}, loc *time.Location) (string, error) {
+	// Funcs are bound to the time zone, so each render gets its own copy.
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := clone.Funcs(funcs(locale, loc)).Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func funcs(locale string, loc *time.Location) template.FuncMap {
+	return template.FuncMap{
+		"money": func(v interface{}) (string, error) {
+			m, err := toMoney(v)
+			if err != nil {
+				return "", err
+			}
+			return FormatMoney(locale, m), nil
+		},
+		"date": func(v interface{}) (string, error) {
+			t, err := toTime(v)
+			if err != nil {
+				return "", err
+			}
+			return FormatDate(locale, t, loc), nil
+		},
+		"datetime": func(v interface{}) (string, error) {
+			t, err := toTime(v)
+			if err != nil {
+				return "", err
+			}
+			return FormatDateTime(locale, t, loc), nil
+		},
+		"plural": func(v interface{}, one, other string) (string, error) {
+			n, err := toInt(v)
+			if err != nil {
+				return "", err
+			}
+			return Plural(locale, n, one, other), nil
+		},
+	}
+}
+
+// Data is stored as JSON, so the converters accept both the values services
+// put in it and what they decode to.
+
+func toMoney(v interface{}) (money.Money, error) {
+	switch m := v.(type) {
+	case money.Money:
+		return m, nil
+	case map[string]interface{}:
+		amount := fmt.Sprint(m["amount"])
+		currency, _ := m["currency"].(string)
+		return money.Parse(amount, currency)
+	}
+	return money.Money{}, fmt.Errorf("money: unexpected %T", v)
+}
+
+func toTime(v interface{}) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		return time.Parse(time.RFC3339, t)
+	}
+	return time.Time{}, fmt.Errorf("date: unexpected %T", v)
+}
+
+func toInt(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case float64:
+		return int64(n), nil
+	case json.Number:
+		return n.Int64()
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	}
+	return 0, fmt.Errorf("plural: unexpected %T", v)
+}
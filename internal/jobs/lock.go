@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Locker hands out a named lock shared by every replica. TryLock does not
+// wait: acquired is false when another replica holds the lock. The lock is
+// a mutex around one run, not a leadership lease, so replicas take turns.
+type Locker interface {
+	TryLock(ctx context.Context, name string) (unlock func(), acquired bool, err error)
+}
+
+// lockNamespace is the first key of the two-key advisory locks taken for
+// jobs, keeping them apart from any other advisory locks in the database.
+const lockNamespace = 0x6a6f6273
+
+// PostgresLocker uses session-level advisory locks. A lock is held on a
+// dedicated connection, so it is released if the replica dies mid-run.
+type PostgresLocker struct {
+	db *sql.DB
+}
+
+func NewPostgresLocker(db *sql.DB) *PostgresLocker {
+	return &PostgresLocker{db: db}
+}
+
+func (l *PostgresLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var acquired bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1, hashtext($2))`, lockNamespace, name).Scan(&acquired)
+	if err != nil || !acquired {
+		conn.Close()
+		return nil, false, err
+	}
+
+	unlock := func() {
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1, hashtext($2))`, lockNamespace, name)
+		conn.Close()
+	}
+	return unlock, true, nil
+}
+
+// RunLog remembers when each job last ran on any replica, so a replica
+// whose ticker fires just after another one's run does not repeat it.
+type RunLog interface {
+	LastRun(ctx context.Context, name string) (time.Time, error)
+	RecordRun(ctx context.Context, name string, at time.Time) error
+}
+
+// PostgresRunLog keeps the last run of each job in the job_runs table.
+type PostgresRunLog struct {
+	db *sql.DB
+}
+
+func NewPostgresRunLog(db *sql.DB) *PostgresRunLog {
+	return &PostgresRunLog{db: db}
+}
+
+// LastRun returns the zero time for a job that has never run.
+func (l *PostgresRunLog) LastRun(ctx context.Context, name string) (time.Time, error) {
+	var at time.Time
+	err := l.db.QueryRowContext(ctx, `SELECT last_run_at FROM job_runs WHERE name = $1`, name).Scan(&at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return at, err
+}
+
+func (l *PostgresRunLog) RecordRun(ctx context.Context, name string, at time.Time) error {
+	_, err := l.db.ExecContext(ctx, `
+		INSERT INTO job_runs (name, last_run_at) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET last_run_at = EXCLUDED.last_run_at
+	`, name, at)
+	return err
+}
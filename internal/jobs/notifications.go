@@ -0,0 +1,20 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/config"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+// RegisterNotificationJobs adds the purge of old read notifications, unless
+// retention is turned off.
+func RegisterNotificationJobs(s *Scheduler, notificationService *service.NotificationService, cfg config.JobsConfig) {
+	if cfg.NotificationRetention <= 0 {
+		return
+	}
+	s.Register("purge_notifications", cfg.NotificationPurgeInterval, func(ctx context.Context, now time.Time) (int, error) {
+		return notificationService.PurgeRead(ctx, now, cfg.NotificationRetention)
+	})
+}
@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/config"
+	"github.com/abneribeiro/goapi/internal/service"
+)
+
+// RegisterReservationJobs adds the time-driven reservation transitions.
+func RegisterReservationJobs(s *Scheduler, reservationService *service.ReservationService, cfg config.JobsConfig) {
+	s.Register("expire_pending", cfg.Interval, func(ctx context.Context, now time.Time) (int, error) {
+		return reservationService.ExpireStalePending(ctx, now, cfg.PendingExpiry)
+	})
+	s.Register("send_reminders", cfg.Interval, func(ctx context.Context, now time.Time) (int, error) {
+		return reservationService.SendReminders(ctx, now, cfg.ReminderLead)
+	})
+	s.Register("auto_complete", cfg.Interval, func(ctx context.Context, now time.Time) (int, error) {
+		return reservationService.AutoComplete(ctx, now, cfg.AutoCompleteAfter)
+	})
+	s.Register("late_returns", cfg.LateReturnInterval, reservationService.ProcessLateReturns)
+}
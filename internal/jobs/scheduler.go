@@ -0,0 +1,170 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
+)
+
+// Func runs one pass of a job and returns how many items it handled.
+type Func func(ctx context.Context, now time.Time) (int, error)
+
+// Metrics describes a job's runs on this replica. Skipped counts the runs
+// left to another replica, because it held the job's lock or had run the
+// job within its interval.
+type Metrics struct {
+	Job            string     `json:"job"`
+	Interval       string     `json:"interval"`
+	Runs           int64      `json:"runs"`
+	Failures       int64      `json:"failures"`
+	Skipped        int64      `json:"skipped"`
+	Processed      int64      `json:"processed"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      Func
+
+	mu      sync.Mutex
+	metrics Metrics
+}
+
+// intervalSlack is the share of a job's interval a run may come early, so
+// replicas whose tickers drift apart slightly still run it on schedule.
+const intervalSlack = 10
+
+// Scheduler runs registered jobs on their intervals. It does not elect a
+// leader: every replica ticks, and each run takes the job's lock and checks
+// the job's last run under it, so when several replicas share a database a
+// job runs on one of them at a time and at most once per interval.
+type Scheduler struct {
+	locker Locker
+	runs   RunLog
+	jobs   []*job
+	now    func() time.Time
+}
+
+func NewScheduler(locker Locker, runs RunLog) *Scheduler {
+	return &Scheduler{
+		locker: locker,
+		runs:   runs,
+		now:    time.Now,
+	}
+}
+
+func (s *Scheduler) Register(name string, interval time.Duration, run Func) {
+	s.jobs = append(s.jobs, &job{
+		name:     name,
+		interval: interval,
+		run:      run,
+		metrics:  Metrics{Job: name, Interval: interval.String()},
+	})
+}
+
+// Run starts every job, running each once immediately and then on its
+// interval, and blocks until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, j := range s.jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			s.loop(ctx, j)
+		}(j)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) Metrics() []Metrics {
+	metrics := make([]Metrics, len(s.jobs))
+	for i, j := range s.jobs {
+		j.mu.Lock()
+		metrics[i] = j.metrics
+		j.mu.Unlock()
+	}
+	return metrics
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, j)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, j *job) {
+	unlock, acquired, err := s.locker.TryLock(ctx, j.name)
+	if err != nil {
+		s.record(j, s.now(), 0, 0, err)
+		return
+	}
+	if !acquired {
+		j.mu.Lock()
+		j.metrics.Skipped++
+		j.mu.Unlock()
+		return
+	}
+	defer unlock()
+
+	started := s.now()
+	last, err := s.runs.LastRun(ctx, j.name)
+	if err != nil {
+		s.record(j, started, 0, 0, err)
+		return
+	}
+	if started.Sub(last) < j.interval-j.interval/intervalSlack {
+		j.mu.Lock()
+		j.metrics.Skipped++
+		j.mu.Unlock()
+		return
+	}
+
+	processed, err := j.run(ctx, started)
+	if recordErr := s.runs.RecordRun(ctx, j.name, started); recordErr != nil {
+		err = errors.Join(err, recordErr)
+	}
+	s.record(j, started, s.now().Sub(started), processed, err)
+}
+
+func (s *Scheduler) record(j *job, started time.Time, duration time.Duration, processed int, err error) {
+	j.mu.Lock()
+	j.metrics.Runs++
+	j.metrics.Processed += int64(processed)
+	j.metrics.LastRunAt = &started
+	j.metrics.LastDurationMs = duration.Milliseconds()
+	j.metrics.LastError = ""
+	if err != nil {
+		j.metrics.Failures++
+		j.metrics.LastError = err.Error()
+	}
+	j.mu.Unlock()
+
+	fields := map[string]interface{}{
+		"job":         j.name,
+		"processed":   processed,
+		"duration_ms": duration.Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		logger.Error("job failed", logger.WithFields(fields))
+		return
+	}
+	if processed > 0 {
+		logger.Info("job completed", logger.WithFields(fields))
+	}
+}
@@ -0,0 +1,155 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeLocker struct {
+	held     map[string]bool
+	unlocked []string
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	if l.held[name] {
+		return nil, false, nil
+	}
+	return func() { l.unlocked = append(l.unlocked, name) }, true, nil
+}
+
+// fakeRunLog keeps last runs in memory, as the job_runs table does for
+// every replica.
+type fakeRunLog map[string]time.Time
+
+func (l fakeRunLog) LastRun(ctx context.Context, name string) (time.Time, error) {
+	return l[name], nil
+}
+
+func (l fakeRunLog) RecordRun(ctx context.Context, name string, at time.Time) error {
+	l[name] = at
+	return nil
+}
+
+func TestScheduler_RecordsRuns(t *testing.T) {
+	locker := &fakeLocker{}
+	s := NewScheduler(locker, fakeRunLog{})
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.Register("ok", time.Minute, func(ctx context.Context, now time.Time) (int, error) {
+		return 3, nil
+	})
+	s.Register("failing", time.Minute, func(ctx context.Context, now time.Time) (int, error) {
+		return 1, errors.New("boom")
+	})
+
+	for range 2 {
+		for _, j := range s.jobs {
+			s.runOnce(context.Background(), j)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	metrics := s.Metrics()
+	if len(metrics) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(metrics))
+	}
+
+	ok := metrics[0]
+	if ok.Runs != 2 || ok.Processed != 6 || ok.Failures != 0 || ok.LastRunAt == nil {
+		t.Errorf("unexpected metrics for ok: %+v", ok)
+	}
+
+	failing := metrics[1]
+	if failing.Runs != 2 || failing.Failures != 2 || failing.LastError != "boom" {
+		t.Errorf("unexpected metrics for failing: %+v", failing)
+	}
+
+	if len(locker.unlocked) != 4 {
+		t.Errorf("expected every run to release its lock, got %d releases", len(locker.unlocked))
+	}
+}
+
+func TestScheduler_SkipsWhenLockHeld(t *testing.T) {
+	locker := &fakeLocker{held: map[string]bool{"job": true}}
+	s := NewScheduler(locker, fakeRunLog{})
+
+	ran := false
+	s.Register("job", time.Minute, func(ctx context.Context, now time.Time) (int, error) {
+		ran = true
+		return 0, nil
+	})
+
+	s.runOnce(context.Background(), s.jobs[0])
+
+	if ran {
+		t.Error("expected job not to run while another replica holds the lock")
+	}
+
+	m := s.Metrics()[0]
+	if m.Skipped != 1 || m.Runs != 0 {
+		t.Errorf("expected 1 skipped and 0 runs, got %+v", m)
+	}
+}
+
+func TestScheduler_SkipsWithinInterval(t *testing.T) {
+	runs := fakeRunLog{}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Two replicas share the run log and take turns on the lock.
+	var ran []string
+	replica := func(name string) *Scheduler {
+		s := NewScheduler(&fakeLocker{}, runs)
+		s.now = func() time.Time { return now }
+		s.Register("job", 10*time.Minute, func(ctx context.Context, now time.Time) (int, error) {
+			ran = append(ran, name)
+			return 0, nil
+		})
+		return s
+	}
+	a, b := replica("a"), replica("b")
+
+	a.runOnce(context.Background(), a.jobs[0])
+	now = now.Add(time.Minute)
+	b.runOnce(context.Background(), b.jobs[0])
+	now = now.Add(8 * time.Minute)
+	b.runOnce(context.Background(), b.jobs[0])
+
+	if len(ran) != 2 || ran[0] != "a" || ran[1] != "b" {
+		t.Errorf("expected a to run and b only once the interval was nearly up, got %v", ran)
+	}
+	if m := b.Metrics()[0]; m.Skipped != 1 || m.Runs != 1 {
+		t.Errorf("expected 1 skipped and 1 run on b, got %+v", m)
+	}
+}
+
+func TestScheduler_RunStopsOnCancel(t *testing.T) {
+	s := NewScheduler(&fakeLocker{}, fakeRunLog{})
+
+	runs := make(chan struct{}, 1)
+	s.Register("job", time.Hour, func(ctx context.Context, now time.Time) (int, error) {
+		runs <- struct{}{}
+		return 0, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("expected job to run on start")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return after cancel")
+	}
+}
@@ -34,27 +34,87 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			m.respondUnauthorized(w, "invalid authorization header format")
+		m.authenticateHeader(w, r, authHeader, next)
+	})
+}
+
+// AuthenticateStream guards the live connection endpoints. Besides the
+// Authorization header, which browsers cannot set on WebSocket and
+// EventSource requests, it accepts a stream token in the token query
+// parameter. Only stream tokens are accepted there, so a long-lived token
+// never ends up in a URL.
+func (m *AuthMiddleware) AuthenticateStream(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+			m.authenticateHeader(w, r, authHeader, next)
 			return
 		}
 
-		claims, err := m.jwtManager.Validate(parts[1])
-		if err != nil {
-			if err == jwt.ErrExpiredToken {
-				m.respondUnauthorized(w, "token has expired")
-				return
-			}
-			m.respondUnauthorized(w, "invalid token")
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			m.respondUnauthorized(w, "missing authorization header or token")
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), UserContextKey, claims)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		m.authenticate(w, r, token, jwt.PurposeStream, next)
 	})
 }
 
+func (m *AuthMiddleware) authenticateHeader(w http.ResponseWriter, r *http.Request, authHeader string, next http.Handler) {
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		m.respondUnauthorized(w, "invalid authorization header format")
+		return
+	}
+
+	m.authenticate(w, r, parts[1], "", next)
+}
+
+// authenticate serves next with the claims of token, which must have been
+// issued for purpose.
+func (m *AuthMiddleware) authenticate(w http.ResponseWriter, r *http.Request, token, purpose string, next http.Handler) {
+	claims, err := m.jwtManager.Validate(token)
+	if err != nil {
+		if err == jwt.ErrExpiredToken {
+			m.respondUnauthorized(w, "token has expired")
+			return
+		}
+		m.respondUnauthorized(w, "invalid token")
+		return
+	}
+
+	if claims.Purpose != purpose {
+		m.respondUnauthorized(w, "invalid token")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), UserContextKey, claims)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+func (m *AuthMiddleware) RequireRole(roles ...model.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := GetUserFromContext(r.Context())
+			if claims == nil {
+				m.respondUnauthorized(w, "missing authorization header")
+				return
+			}
+
+			for _, role := range roles {
+				if claims.Role == string(role) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(model.ErrorResponse("FORBIDDEN", "insufficient permissions"))
+		})
+	}
+}
+
 func (m *AuthMiddleware) respondUnauthorized(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnauthorized)
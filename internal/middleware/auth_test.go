@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/abneribeiro/goapi/internal/model"
 	"github.com/abneribeiro/goapi/internal/pkg/jwt"
 )
 
@@ -146,3 +147,106 @@ func TestGetUserFromContext_WithUser(t *testing.T) {
 		t.Errorf("expected user ID %s, got %s", expectedClaims.UserID, claims.UserID)
 	}
 }
+
+func TestAuthMiddleware_RequireRole(t *testing.T) {
+	middleware := NewAuthMiddleware(jwt.NewManager("test-secret", time.Hour))
+
+	tests := []struct {
+		name       string
+		claims     *jwt.Claims
+		wantStatus int
+	}{
+		{"no claims", nil, http.StatusUnauthorized},
+		{"wrong role", &jwt.Claims{UserID: uuid.New(), Role: "renter"}, http.StatusForbidden},
+		{"allowed role", &jwt.Claims{UserID: uuid.New(), Role: "admin"}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			ctx := context.Background()
+			if tt.claims != nil {
+				ctx = context.WithValue(ctx, UserContextKey, tt.claims)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			middleware.RequireRole(model.RoleAdmin)(nextHandler).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_Authenticate_RejectsStreamToken(t *testing.T) {
+	jwtManager := jwt.NewManager("test-secret", time.Hour)
+	middleware := NewAuthMiddleware(jwtManager)
+
+	streamToken, _, _ := jwtManager.GenerateStreamToken(&jwt.Claims{UserID: uuid.New(), Role: "renter"})
+
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be called")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+streamToken)
+
+	w := httptest.NewRecorder()
+	middleware.Authenticate(nextHandler).ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestAuthMiddleware_AuthenticateStream(t *testing.T) {
+	jwtManager := jwt.NewManager("test-secret", time.Hour)
+	middleware := NewAuthMiddleware(jwtManager)
+
+	userID := uuid.New()
+	token, _ := jwtManager.Generate(userID, "test@example.com", "renter")
+	streamToken, _, _ := jwtManager.GenerateStreamToken(&jwt.Claims{UserID: userID, Email: "test@example.com", Role: "renter"})
+
+	tests := []struct {
+		name       string
+		header     string
+		query      string
+		wantStatus int
+	}{
+		{"bearer header", "Bearer " + token, "", http.StatusOK},
+		{"stream token in query", "", "?token=" + streamToken, http.StatusOK},
+		{"login token in query", "", "?token=" + token, http.StatusUnauthorized},
+		{"invalid token in query", "", "?token=invalid", http.StatusUnauthorized},
+		{"no credentials", "", "", http.StatusUnauthorized},
+		{"invalid header wins over query", "Token " + token, "?token=" + streamToken, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				claims := GetUserFromContext(r.Context())
+				if claims == nil || claims.UserID != userID {
+					t.Errorf("expected claims for user %s, got %+v", userID, claims)
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/live"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			middleware.AuthenticateStream(nextHandler).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
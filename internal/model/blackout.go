@@ -0,0 +1,139 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/pkg/timeslot"
+)
+
+const week = 7 * 24 * time.Hour
+
+type BlackoutReason string
+
+const (
+	BlackoutMaintenance BlackoutReason = "maintenance"
+	BlackoutPersonalUse BlackoutReason = "personal_use"
+	BlackoutInspection  BlackoutReason = "inspection"
+	BlackoutOther       BlackoutReason = "other"
+)
+
+func (r BlackoutReason) Valid() bool {
+	switch r {
+	case BlackoutMaintenance, BlackoutPersonalUse, BlackoutInspection, BlackoutOther:
+		return true
+	}
+	return false
+}
+
+type Blackout struct {
+	ID          uuid.UUID      `json:"id"`
+	EquipmentID uuid.UUID      `json:"equipment_id"`
+	StartDate   time.Time      `json:"start_date"`
+	EndDate     time.Time      `json:"end_date"`
+	Reason      BlackoutReason `json:"reason"`
+	Notes       string         `json:"notes,omitempty"`
+	RecurWeekly bool           `json:"recur_weekly"`
+	RecurUntil  *time.Time     `json:"recur_until,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	// Location is the equipment's time zone, in which weekly occurrences
+	// keep the wall-clock times of the first. Nil means UTC.
+	Location *time.Location `json:"-"`
+}
+
+type CreateBlackoutRequest struct {
+	StartDate   time.Time      `json:"start_date"`
+	EndDate     time.Time      `json:"end_date"`
+	Reason      BlackoutReason `json:"reason"`
+	Notes       string         `json:"notes,omitempty"`
+	RecurWeekly bool           `json:"recur_weekly"`
+	RecurUntil  *time.Time     `json:"recur_until,omitempty"`
+}
+
+type UpdateBlackoutRequest struct {
+	StartDate   *time.Time      `json:"start_date,omitempty"`
+	EndDate     *time.Time      `json:"end_date,omitempty"`
+	Reason      *BlackoutReason `json:"reason,omitempty"`
+	Notes       *string         `json:"notes,omitempty"`
+	RecurWeekly *bool           `json:"recur_weekly,omitempty"`
+	RecurUntil  *time.Time      `json:"recur_until,omitempty"`
+}
+
+// Overlaps reports whether any occurrence of the blackout intersects
+// [start, end). A weekly blackout repeats every seven days from StartDate
+// until RecurUntil, or indefinitely when RecurUntil is nil.
+func (b *Blackout) Overlaps(start, end time.Time) bool {
+	if !b.RecurWeekly {
+		return b.StartDate.Before(end) && b.EndDate.After(start)
+	}
+
+	occurrence := b.occurrence(b.firstEndingAfter(start))
+	if b.RecurUntil != nil && !occurrence.Start.Before(*b.RecurUntil) {
+		return false
+	}
+	return occurrence.Start.Before(end)
+}
+
+// Occurrences lists every occurrence of the blackout intersecting
+// [start, end), clipped to that window.
+func (b *Blackout) Occurrences(start, end time.Time) []timeslot.Interval {
+	window := timeslot.Interval{Start: start, End: end}
+
+	if !b.RecurWeekly {
+		if clipped, ok := b.occurrence(0).Clip(window); ok {
+			return []timeslot.Interval{clipped}
+		}
+		return nil
+	}
+
+	var occurrences []timeslot.Interval
+	for k := b.firstEndingAfter(start); ; k++ {
+		occurrence := b.occurrence(k)
+		if !occurrence.Start.Before(end) || (b.RecurUntil != nil && !occurrence.Start.Before(*b.RecurUntil)) {
+			break
+		}
+		if clipped, ok := occurrence.Clip(window); ok {
+			occurrences = append(occurrences, clipped)
+		}
+	}
+
+	return occurrences
+}
+
+// occurrence returns the k-th occurrence of the blackout, k weeks after the
+// first in wall-clock time, so a blackout every Monday from 09:00 to 12:00
+// stays there when the clocks change.
+func (b *Blackout) occurrence(k int) timeslot.Interval {
+	if k == 0 {
+		return timeslot.Interval{Start: b.StartDate, End: b.EndDate}
+	}
+
+	loc := b.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return timeslot.Interval{
+		Start: b.StartDate.In(loc).AddDate(0, 0, 7*k),
+		End:   b.EndDate.In(loc).AddDate(0, 0, 7*k),
+	}
+}
+
+// firstEndingAfter returns the index of the first occurrence ending after
+// t. Weeks are an hour shorter or longer across a clock change, so the
+// estimate from a fixed week is corrected by a step either way.
+func (b *Blackout) firstEndingAfter(t time.Time) int {
+	if t.Before(b.EndDate) {
+		return 0
+	}
+
+	k := int(t.Sub(b.EndDate)/week) + 1
+	for k > 0 && b.occurrence(k-1).End.After(t) {
+		k--
+	}
+	for !b.occurrence(k).End.After(t) {
+		k++
+	}
+	return k
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlackout_Overlaps(t *testing.T) {
+	// 2024-03-03 is a Sunday.
+	sunday := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return sunday.AddDate(0, 0, n) }
+	until := day(21)
+
+	oneOff := &Blackout{StartDate: day(1), EndDate: day(3)}
+	everySunday := &Blackout{StartDate: sunday, EndDate: day(1), RecurWeekly: true}
+	limited := &Blackout{StartDate: sunday, EndDate: day(1), RecurWeekly: true, RecurUntil: &until}
+
+	tests := []struct {
+		name     string
+		blackout *Blackout
+		start    time.Time
+		end      time.Time
+		want     bool
+	}{
+		{"one-off overlapping", oneOff, day(2), day(5), true},
+		{"one-off touching end", oneOff, day(3), day(5), false},
+		{"one-off before start", oneOff, day(0), day(1), false},
+		{"weekly first occurrence", everySunday, day(0), day(1), true},
+		{"weekly weekdays only", everySunday, day(1), day(7), false},
+		{"weekly later sunday", everySunday, day(15), day(22), true},
+		{"weekly partial sunday", everySunday, day(13).Add(20 * time.Hour), day(14).Add(2 * time.Hour), true},
+		{"weekly before first occurrence", everySunday, day(-7), day(-1), false},
+		{"weekly within recur_until", limited, day(14), day(15), true},
+		{"weekly after recur_until", limited, day(21), day(22), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.blackout.Overlaps(tt.start, tt.end); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBlackoutReason_Valid(t *testing.T) {
+	if !BlackoutMaintenance.Valid() {
+		t.Error("expected maintenance to be valid")
+	}
+	if BlackoutReason("holiday").Valid() {
+		t.Error("expected unknown reason to be invalid")
+	}
+}
+
+func TestBlackout_Occurrences(t *testing.T) {
+	sunday := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return sunday.AddDate(0, 0, n) }
+	until := day(14)
+
+	everySunday := &Blackout{StartDate: sunday, EndDate: day(1), RecurWeekly: true, RecurUntil: &until}
+
+	got := everySunday.Occurrences(day(3), day(30))
+	if len(got) != 1 {
+		t.Fatalf("expected 1 occurrence before recur_until, got %d", len(got))
+	}
+	if !got[0].Start.Equal(day(7)) || !got[0].End.Equal(day(8)) {
+		t.Errorf("unexpected occurrence %v-%v", got[0].Start, got[0].End)
+	}
+
+	oneOff := &Blackout{StartDate: day(1), EndDate: day(3)}
+	got = oneOff.Occurrences(day(2), day(10))
+	if len(got) != 1 || !got[0].Start.Equal(day(2)) {
+		t.Errorf("expected one-off occurrence clipped to window start, got %v", got)
+	}
+}
+
+func TestBlackout_RecursInLocalTimeAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sundays 09:00-12:00 in Lisbon. Summer time starts on 2024-03-31 and
+	// ends on 2024-10-27.
+	everySunday := &Blackout{
+		StartDate:   time.Date(2024, 3, 24, 9, 0, 0, 0, loc),
+		EndDate:     time.Date(2024, 3, 24, 12, 0, 0, 0, loc),
+		RecurWeekly: true,
+		Location:    loc,
+	}
+	local := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2024, month, day, hour, min, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  bool
+	}{
+		{"first summer sunday morning", local(3, 31, 9, 0), local(3, 31, 9, 30), true},
+		{"first summer sunday after noon", local(3, 31, 12, 0), local(3, 31, 13, 0), false},
+		{"first summer sunday before nine", local(3, 31, 8, 0), local(3, 31, 9, 0), false},
+		{"summer sunday", local(7, 14, 11, 30), local(7, 14, 12, 30), true},
+		{"summer sunday after noon", local(7, 14, 12, 0), local(7, 14, 13, 0), false},
+		{"winter again", local(11, 3, 8, 0), local(11, 3, 9, 0), false},
+		{"winter sunday morning", local(11, 3, 11, 0), local(11, 3, 11, 30), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := everySunday.Overlaps(tt.start, tt.end); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	got := everySunday.Occurrences(local(3, 30, 0, 0), local(4, 1, 0, 0))
+	if len(got) != 1 || !got[0].Start.Equal(local(3, 31, 9, 0)) || !got[0].End.Equal(local(3, 31, 12, 0)) {
+		t.Errorf("expected the occurrence at 09:00-12:00 local time, got %v", got)
+	}
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+type CancellationPolicyType string
+
+const (
+	CancellationFlexible CancellationPolicyType = "flexible"
+	CancellationModerate CancellationPolicyType = "moderate"
+	CancellationStrict   CancellationPolicyType = "strict"
+	CancellationCustom   CancellationPolicyType = "custom"
+)
+
+func (t CancellationPolicyType) Valid() bool {
+	switch t {
+	case CancellationFlexible, CancellationModerate, CancellationStrict, CancellationCustom:
+		return true
+	}
+	return false
+}
+
+// RefundTier refunds RefundPercent of the total when a reservation is
+// cancelled at least HoursBefore hours before it starts.
+type RefundTier struct {
+	HoursBefore   int `json:"hours_before"`
+	RefundPercent int `json:"refund_percent"`
+}
+
+type CancellationPolicy struct {
+	Type        CancellationPolicyType `json:"type"`
+	Tiers       []RefundTier           `json:"tiers"`
+	Description string                 `json:"description"`
+}
+
+type CancellationPolicyRequest struct {
+	Type  CancellationPolicyType `json:"type"`
+	Tiers []RefundTier           `json:"tiers,omitempty"`
+}
+
+var cancellationPresets = map[CancellationPolicyType][]RefundTier{
+	CancellationFlexible: {{HoursBefore: 24, RefundPercent: 100}},
+	CancellationModerate: {{HoursBefore: 120, RefundPercent: 100}, {HoursBefore: 24, RefundPercent: 50}},
+	CancellationStrict:   {{HoursBefore: 168, RefundPercent: 50}},
+}
+
+var DefaultCancellationPolicy = NewCancellationPolicy(CancellationFlexible, nil)
+
+// NewCancellationPolicy builds a policy of the given type. Preset types
+// ignore tiers; custom tiers are ordered from the longest notice down.
+func NewCancellationPolicy(t CancellationPolicyType, tiers []RefundTier) CancellationPolicy {
+	if preset, ok := cancellationPresets[t]; ok {
+		tiers = preset
+	} else {
+		tiers = append([]RefundTier(nil), tiers...)
+		sort.Slice(tiers, func(i, j int) bool { return tiers[i].HoursBefore > tiers[j].HoursBefore })
+	}
+
+	return CancellationPolicy{
+		Type:        t,
+		Tiers:       tiers,
+		Description: describeTiers(tiers),
+	}
+}
+
+// RefundPercent is the share of the total refunded when cancelling with the
+// given notice before the start.
+func (p CancellationPolicy) RefundPercent(notice time.Duration) int {
+	for _, tier := range p.Tiers {
+		if notice >= time.Duration(tier.HoursBefore)*time.Hour {
+			return tier.RefundPercent
+		}
+	}
+	return 0
+}
+
+func describeTiers(tiers []RefundTier) string {
+	var parts []string
+	for _, tier := range tiers {
+		refund := fmt.Sprintf("%d%% refund", tier.RefundPercent)
+		if tier.RefundPercent == 100 {
+			refund = "Full refund"
+		}
+		if tier.HoursBefore == 0 {
+			parts = append(parts, refund+" if cancelled before the start.")
+		} else {
+			parts = append(parts, fmt.Sprintf("%s if cancelled at least %d hours before the start.", refund, tier.HoursBefore))
+		}
+	}
+
+	if len(tiers) == 0 || tiers[len(tiers)-1].HoursBefore > 0 {
+		parts = append(parts, "No refund after that.")
+	}
+	return "Pending reservations are refunded in full. " + strings.Join(parts, " ")
+}
+
+type CancellationParty string
+
+const (
+	PartyRenter CancellationParty = "renter"
+	PartyOwner  CancellationParty = "owner"
+)
+
+// Cancellation records how a cancelled reservation was settled. ChargedTo
+// is the party who bears ChargedAmount: the renter for the part the policy
+// does not refund, or the owner, who forgoes the whole rental when they
+// cancel an approved reservation.
+type Cancellation struct {
+	CancelledBy   CancellationParty `json:"cancelled_by"`
+	RefundPercent int               `json:"refund_percent"`
+	RefundAmount  money.Money       `json:"refund_amount"`
+	ChargedTo     CancellationParty `json:"charged_to,omitempty"`
+	ChargedAmount money.Money       `json:"charged_amount"`
+	CancelledAt   time.Time         `json:"cancelled_at"`
+}
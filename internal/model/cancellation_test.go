@@ -0,0 +1,72 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCancellationPolicy_RefundPercent(t *testing.T) {
+	moderate := NewCancellationPolicy(CancellationModerate, nil)
+	custom := NewCancellationPolicy(CancellationCustom, []RefundTier{
+		{HoursBefore: 0, RefundPercent: 25},
+		{HoursBefore: 48, RefundPercent: 90},
+	})
+
+	tests := []struct {
+		name   string
+		policy CancellationPolicy
+		notice time.Duration
+		want   int
+	}{
+		{"flexible well ahead", DefaultCancellationPolicy, 72 * time.Hour, 100},
+		{"flexible at cutoff", DefaultCancellationPolicy, 24 * time.Hour, 100},
+		{"flexible late", DefaultCancellationPolicy, 23 * time.Hour, 0},
+		{"moderate early", moderate, 6 * 24 * time.Hour, 100},
+		{"moderate middle", moderate, 48 * time.Hour, 50},
+		{"moderate late", moderate, time.Hour, 0},
+		{"custom early", custom, 50 * time.Hour, 90},
+		{"custom late", custom, time.Minute, 25},
+		{"custom after start", custom, -time.Hour, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.RefundPercent(tt.notice); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewCancellationPolicy(t *testing.T) {
+	strict := NewCancellationPolicy(CancellationStrict, []RefundTier{{HoursBefore: 1, RefundPercent: 100}})
+	if len(strict.Tiers) != 1 || strict.Tiers[0].RefundPercent != 50 {
+		t.Errorf("expected preset tiers to replace the given ones, got %+v", strict.Tiers)
+	}
+
+	custom := NewCancellationPolicy(CancellationCustom, []RefundTier{
+		{HoursBefore: 0, RefundPercent: 10},
+		{HoursBefore: 72, RefundPercent: 100},
+	})
+	if custom.Tiers[0].HoursBefore != 72 {
+		t.Errorf("expected tiers ordered by notice, got %+v", custom.Tiers)
+	}
+	if strings.Contains(custom.Description, "after that") {
+		t.Errorf("expected no trailing no-refund sentence when a tier reaches the start, got %q", custom.Description)
+	}
+	if !strings.Contains(DefaultCancellationPolicy.Description, "No refund after that.") {
+		t.Errorf("unexpected description %q", DefaultCancellationPolicy.Description)
+	}
+}
+
+func TestCancellationPolicyType_Valid(t *testing.T) {
+	for _, pt := range []CancellationPolicyType{CancellationFlexible, CancellationModerate, CancellationStrict, CancellationCustom} {
+		if !pt.Valid() {
+			t.Errorf("expected %q to be valid", pt)
+		}
+	}
+	if CancellationPolicyType("lenient").Valid() {
+		t.Error("expected unknown type to be invalid")
+	}
+}
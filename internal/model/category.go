@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AttributeType string
+
+const (
+	AttributeNumber  AttributeType = "number"
+	AttributeEnum    AttributeType = "enum"
+	AttributeBoolean AttributeType = "boolean"
+	AttributeText    AttributeType = "text"
+)
+
+type CategoryAttribute struct {
+	Key      string        `json:"key"`
+	Label    string        `json:"label"`
+	Type     AttributeType `json:"type"`
+	Unit     string        `json:"unit,omitempty"`
+	Options  []string      `json:"options,omitempty"`
+	Required bool          `json:"required"`
+}
+
+type Category struct {
+	ID         uuid.UUID           `json:"id"`
+	ParentID   *uuid.UUID          `json:"parent_id,omitempty"`
+	Name       string              `json:"name"`
+	Slug       string              `json:"slug"`
+	Icon       string              `json:"icon,omitempty"`
+	Attributes []CategoryAttribute `json:"attributes"`
+	Children   []*Category         `json:"children,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+type CreateCategoryRequest struct {
+	Name       string              `json:"name"`
+	Slug       string              `json:"slug,omitempty"`
+	ParentID   *uuid.UUID          `json:"parent_id,omitempty"`
+	Icon       string              `json:"icon,omitempty"`
+	Attributes []CategoryAttribute `json:"attributes,omitempty"`
+}
+
+type UpdateCategoryRequest struct {
+	Name       string               `json:"name,omitempty"`
+	Slug       string               `json:"slug,omitempty"`
+	ParentID   *uuid.UUID           `json:"parent_id,omitempty"`
+	MakeRoot   bool                 `json:"make_root,omitempty"`
+	Icon       *string              `json:"icon,omitempty"`
+	Attributes *[]CategoryAttribute `json:"attributes,omitempty"`
+}
+
+func BuildCategoryTree(categories []*Category) []*Category {
+	byID := make(map[uuid.UUID]*Category, len(categories))
+	for _, c := range categories {
+		c.Children = nil
+		byID[c.ID] = c
+	}
+
+	var roots []*Category
+	for _, c := range categories {
+		if c.ParentID != nil {
+			if parent, ok := byID[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, c)
+				continue
+			}
+		}
+		roots = append(roots, c)
+	}
+
+	return roots
+}
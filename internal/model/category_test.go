@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildCategoryTree(t *testing.T) {
+	construction := &Category{ID: uuid.New(), Name: "Construction"}
+	excavators := &Category{ID: uuid.New(), ParentID: &construction.ID, Name: "Excavators"}
+	mini := &Category{ID: uuid.New(), ParentID: &excavators.ID, Name: "Mini Excavators"}
+	photography := &Category{ID: uuid.New(), Name: "Photography"}
+
+	roots := BuildCategoryTree([]*Category{construction, excavators, mini, photography})
+
+	if len(roots) != 2 {
+		t.Fatalf("expected 2 roots, got %d", len(roots))
+	}
+
+	if len(construction.Children) != 1 || construction.Children[0] != excavators {
+		t.Error("expected excavators to be nested under construction")
+	}
+
+	if len(excavators.Children) != 1 || excavators.Children[0] != mini {
+		t.Error("expected mini excavators to be nested under excavators")
+	}
+
+	if len(photography.Children) != 0 {
+		t.Errorf("expected photography to have no children, got %d", len(photography.Children))
+	}
+}
+
+func TestBuildCategoryTree_OrphanBecomesRoot(t *testing.T) {
+	missing := uuid.New()
+	orphan := &Category{ID: uuid.New(), ParentID: &missing, Name: "Orphan"}
+
+	roots := BuildCategoryTree([]*Category{orphan})
+
+	if len(roots) != 1 || roots[0] != orphan {
+		t.Error("expected orphan category to be returned as a root")
+	}
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryChannel is a way of delivering a notification. The in-app inbox
+// is a channel for preferences only; the others are sent through the job
+// queue.
+type DeliveryChannel string
+
+const (
+	ChannelInApp DeliveryChannel = "in_app"
+	ChannelEmail DeliveryChannel = "email"
+	ChannelSMS   DeliveryChannel = "sms"
+	ChannelPush  DeliveryChannel = "push"
+)
+
+// OutboundChannels are the channels sent outside the app.
+var OutboundChannels = []DeliveryChannel{ChannelEmail, ChannelSMS, ChannelPush}
+
+// Interrupts reports whether a message on the channel alerts the user
+// straight away, so it waits out their quiet hours.
+func (c DeliveryChannel) Interrupts() bool {
+	return c == ChannelSMS || c == ChannelPush
+}
+
+var (
+	urgentChannels  = []DeliveryChannel{ChannelEmail, ChannelPush, ChannelSMS}
+	defaultChannels = []DeliveryChannel{ChannelEmail, ChannelPush}
+)
+
+// notificationChannels lists the types worth a text message; every other
+// type goes out by email and push only.
+var notificationChannels = map[NotificationType][]DeliveryChannel{
+	NotificationReservationApproved: urgentChannels,
+	NotificationReservationReminder: urgentChannels,
+	NotificationReturnOverdue:       urgentChannels,
+	NotificationReturnConflict:      urgentChannels,
+}
+
+// Channels returns the channels outside the app a notification of this
+// type is delivered on unless the user chose otherwise.
+func (t NotificationType) Channels() []DeliveryChannel {
+	if channels, ok := notificationChannels[t]; ok {
+		return channels
+	}
+	return defaultChannels
+}
+
+// NotificationDelivery records sending a notification on one channel, so a
+// retried job does not send it twice.
+type NotificationDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	NotificationID uuid.UUID       `json:"notification_id"`
+	Channel        DeliveryChannel `json:"channel"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	Error          string          `json:"error,omitempty"`
+	SentAt         *time.Time      `json:"sent_at,omitempty"`
+	ScheduledFor   *time.Time      `json:"scheduled_for,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// PushSubscription is a browser's Push API subscription. Keys are as
+// returned by PushSubscription.toJSON().
+type PushSubscription struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Endpoint  string    `json:"endpoint"`
+	Keys      PushKeys  `json:"keys"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PushKeys struct {
+	P256dh string `json:"p256dh"`
+	Auth   string `json:"auth"`
+}
+
+type CreatePushSubscriptionRequest struct {
+	Endpoint string   `json:"endpoint"`
+	Keys     PushKeys `json:"keys"`
+}
+
+type DeletePushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+// DomainEventType names a change other systems can react to. The part
+// before the dot is the aggregate the event belongs to.
+type DomainEventType string
+
+const (
+	EventReservationCreated     DomainEventType = "reservation.created"
+	EventReservationApproved    DomainEventType = "reservation.approved"
+	EventReservationRejected    DomainEventType = "reservation.rejected"
+	EventReservationCancelled   DomainEventType = "reservation.cancelled"
+	EventReservationPickedUp    DomainEventType = "reservation.picked_up"
+	EventReservationReturned    DomainEventType = "reservation.returned"
+	EventReservationCompleted   DomainEventType = "reservation.completed"
+	EventReservationExpired     DomainEventType = "reservation.expired"
+	EventReservationDisputed    DomainEventType = "reservation.disputed"
+	EventReservationRescheduled DomainEventType = "reservation.rescheduled"
+	EventEquipmentCreated       DomainEventType = "equipment.created"
+	EventEquipmentUpdated       DomainEventType = "equipment.updated"
+	EventEquipmentDeleted       DomainEventType = "equipment.deleted"
+	EventBlackoutCreated        DomainEventType = "equipment.blackout_created"
+	EventBlackoutUpdated        DomainEventType = "equipment.blackout_updated"
+	EventBlackoutDeleted        DomainEventType = "equipment.blackout_deleted"
+)
+
+var domainEventTypes = []DomainEventType{
+	EventReservationCreated, EventReservationApproved, EventReservationRejected,
+	EventReservationCancelled, EventReservationPickedUp, EventReservationReturned,
+	EventReservationCompleted, EventReservationExpired, EventReservationDisputed,
+	EventReservationRescheduled, EventEquipmentCreated, EventEquipmentUpdated,
+	EventEquipmentDeleted, EventBlackoutCreated, EventBlackoutUpdated,
+	EventBlackoutDeleted,
+}
+
+// DomainEventTypes returns every event type emitted to the outbox.
+func DomainEventTypes() []DomainEventType {
+	return append([]DomainEventType{}, domainEventTypes...)
+}
+
+var statusEvents = map[ReservationStatus]DomainEventType{
+	StatusPending:   EventReservationCreated,
+	StatusApproved:  EventReservationApproved,
+	StatusRejected:  EventReservationRejected,
+	StatusCancelled: EventReservationCancelled,
+	StatusActive:    EventReservationPickedUp,
+	StatusReturned:  EventReservationReturned,
+	StatusCompleted: EventReservationCompleted,
+	StatusExpired:   EventReservationExpired,
+	StatusDisputed:  EventReservationDisputed,
+}
+
+// StatusEvent returns the event announcing that a reservation moved to
+// status.
+func StatusEvent(status ReservationStatus) DomainEventType {
+	return statusEvents[status]
+}
+
+func (t DomainEventType) Aggregate() string {
+	aggregate, _, _ := strings.Cut(string(t), ".")
+	return aggregate
+}
+
+// DomainEvent is a change recorded in the outbox in the same transaction as
+// the change itself. Sequence orders events; events of one aggregate are
+// always delivered in sequence order.
+type DomainEvent struct {
+	ID            uuid.UUID       `json:"id"`
+	Sequence      int64           `json:"sequence"`
+	Type          DomainEventType `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	Data          json.RawMessage `json:"data"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// OutboxEntry is a domain event waiting to be delivered, with the sinks
+// that already have it.
+type OutboxEntry struct {
+	Event         DomainEvent
+	DeliveredTo   []string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+}
+
+type ReservationEventData struct {
+	ID          uuid.UUID         `json:"id"`
+	EquipmentID uuid.UUID         `json:"equipment_id"`
+	RenterID    uuid.UUID         `json:"renter_id"`
+	Status      ReservationStatus `json:"status"`
+	FromStatus  ReservationStatus `json:"from_status,omitempty"`
+	StartDate   time.Time         `json:"start_date"`
+	EndDate     time.Time         `json:"end_date"`
+	Quantity    int               `json:"quantity"`
+	TotalPrice  money.Money       `json:"total_price"`
+	ActorID     *uuid.UUID        `json:"actor_id,omitempty"`
+	Reason      string            `json:"reason,omitempty"`
+}
+
+// BlackoutEventData describes a blackout as it is after the change, or as
+// it was before being deleted.
+type BlackoutEventData struct {
+	ID          uuid.UUID      `json:"id"`
+	EquipmentID uuid.UUID      `json:"equipment_id"`
+	OwnerID     uuid.UUID      `json:"owner_id"`
+	StartDate   time.Time      `json:"start_date"`
+	EndDate     time.Time      `json:"end_date"`
+	Reason      BlackoutReason `json:"reason"`
+	RecurWeekly bool           `json:"recur_weekly"`
+	RecurUntil  *time.Time     `json:"recur_until,omitempty"`
+}
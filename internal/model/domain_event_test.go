@@ -0,0 +1,20 @@
+package model
+
+import "testing"
+
+func TestStatusEvent(t *testing.T) {
+	statuses := []ReservationStatus{
+		StatusPending, StatusApproved, StatusActive, StatusReturned, StatusCancelled,
+		StatusCompleted, StatusRejected, StatusExpired, StatusDisputed,
+	}
+
+	for _, status := range statuses {
+		event := StatusEvent(status)
+		if event == "" {
+			t.Errorf("expected an event for status %s", status)
+		}
+		if event.Aggregate() != "reservation" {
+			t.Errorf("expected reservation aggregate for %s, got %s", event, event.Aggregate())
+		}
+	}
+}
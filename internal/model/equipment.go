@@ -1,29 +1,52 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
 )
 
 type Equipment struct {
-	ID           uuid.UUID         `json:"id"`
-	OwnerID      uuid.UUID         `json:"owner_id"`
-	Owner        *User             `json:"owner,omitempty"`
-	Name         string            `json:"name"`
-	Description  string            `json:"description,omitempty"`
-	Category     string            `json:"category"`
-	PricePerHour *float64          `json:"price_per_hour,omitempty"`
-	PricePerDay  *float64          `json:"price_per_day,omitempty"`
-	PricePerWeek *float64          `json:"price_per_week,omitempty"`
-	Location     string            `json:"location,omitempty"`
-	Latitude     *float64          `json:"latitude,omitempty"`
-	Longitude    *float64          `json:"longitude,omitempty"`
-	Available    bool              `json:"available"`
-	AutoApprove  bool              `json:"auto_approve"`
-	Photos       []EquipmentPhoto  `json:"photos,omitempty"`
-	CreatedAt    time.Time         `json:"created_at"`
-	UpdatedAt    time.Time         `json:"updated_at"`
+	ID                 uuid.UUID          `json:"id"`
+	OwnerID            uuid.UUID          `json:"owner_id"`
+	Owner              *User              `json:"owner,omitempty"`
+	Name               string             `json:"name"`
+	Description        string             `json:"description,omitempty"`
+	CategoryID         *uuid.UUID         `json:"category_id,omitempty"`
+	Category           string             `json:"category"`
+	Currency           string             `json:"currency"`
+	PricePerHour       *money.Money       `json:"price_per_hour,omitempty"`
+	PricePerDay        *money.Money       `json:"price_per_day,omitempty"`
+	PricePerWeek       *money.Money       `json:"price_per_week,omitempty"`
+	DisplayPrices      *DisplayPrices     `json:"display_prices,omitempty"`
+	DepositAmount      *money.Money       `json:"deposit_amount,omitempty"`
+	Location           string             `json:"location,omitempty"`
+	Latitude           *float64           `json:"latitude,omitempty"`
+	Longitude          *float64           `json:"longitude,omitempty"`
+	Available          bool               `json:"available"`
+	AutoApprove        bool               `json:"auto_approve"`
+	RequirePrepayment  bool               `json:"require_prepayment"`
+	CancellationPolicy CancellationPolicy `json:"cancellation_policy"`
+	InventoryMode      InventoryMode      `json:"inventory_mode"`
+	Quantity           int                `json:"quantity"`
+	TimeZone           string             `json:"time_zone"`
+	Specifications     Specifications     `json:"specifications,omitempty"`
+	Photos             []EquipmentPhoto   `json:"photos,omitempty"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+// DisplayPrices are the listing prices converted to a currency requested by
+// the client. They are informational; reservations are always charged in
+// the listing currency.
+type DisplayPrices struct {
+	Currency     string       `json:"currency"`
+	PricePerHour *money.Money `json:"price_per_hour,omitempty"`
+	PricePerDay  *money.Money `json:"price_per_day,omitempty"`
+	PricePerWeek *money.Money `json:"price_per_week,omitempty"`
 }
 
 type EquipmentPhoto struct {
@@ -35,44 +58,65 @@ type EquipmentPhoto struct {
 }
 
 type CreateEquipmentRequest struct {
-	Name         string   `json:"name"`
-	Description  string   `json:"description,omitempty"`
-	Category     string   `json:"category"`
-	PricePerHour *float64 `json:"price_per_hour,omitempty"`
-	PricePerDay  *float64 `json:"price_per_day,omitempty"`
-	PricePerWeek *float64 `json:"price_per_week,omitempty"`
-	Location     string   `json:"location,omitempty"`
-	Latitude     *float64 `json:"latitude,omitempty"`
-	Longitude    *float64 `json:"longitude,omitempty"`
-	AutoApprove  bool     `json:"auto_approve"`
+	Name               string                     `json:"name"`
+	Description        string                     `json:"description,omitempty"`
+	CategoryID         *uuid.UUID                 `json:"category_id,omitempty"`
+	Category           string                     `json:"category,omitempty"`
+	Currency           string                     `json:"currency,omitempty"`
+	PricePerHour       *json.Number               `json:"price_per_hour,omitempty"`
+	PricePerDay        *json.Number               `json:"price_per_day,omitempty"`
+	PricePerWeek       *json.Number               `json:"price_per_week,omitempty"`
+	DepositAmount      *json.Number               `json:"deposit_amount,omitempty"`
+	Location           string                     `json:"location,omitempty"`
+	Latitude           *float64                   `json:"latitude,omitempty"`
+	Longitude          *float64                   `json:"longitude,omitempty"`
+	AutoApprove        bool                       `json:"auto_approve"`
+	RequirePrepayment  bool                       `json:"require_prepayment"`
+	CancellationPolicy *CancellationPolicyRequest `json:"cancellation_policy,omitempty"`
+	InventoryMode      InventoryMode              `json:"inventory_mode,omitempty"`
+	Quantity           int                        `json:"quantity,omitempty"`
+	TimeZone           string                     `json:"time_zone,omitempty"`
+	Specifications     Specifications             `json:"specifications,omitempty"`
 }
 
 type UpdateEquipmentRequest struct {
-	Name         string   `json:"name,omitempty"`
-	Description  string   `json:"description,omitempty"`
-	Category     string   `json:"category,omitempty"`
-	PricePerHour *float64 `json:"price_per_hour,omitempty"`
-	PricePerDay  *float64 `json:"price_per_day,omitempty"`
-	PricePerWeek *float64 `json:"price_per_week,omitempty"`
-	Location     string   `json:"location,omitempty"`
-	Latitude     *float64 `json:"latitude,omitempty"`
-	Longitude    *float64 `json:"longitude,omitempty"`
-	Available    *bool    `json:"available,omitempty"`
-	AutoApprove  *bool    `json:"auto_approve,omitempty"`
+	Name               string                     `json:"name,omitempty"`
+	Description        string                     `json:"description,omitempty"`
+	CategoryID         *uuid.UUID                 `json:"category_id,omitempty"`
+	Category           string                     `json:"category,omitempty"`
+	Currency           string                     `json:"currency,omitempty"`
+	PricePerHour       *json.Number               `json:"price_per_hour,omitempty"`
+	PricePerDay        *json.Number               `json:"price_per_day,omitempty"`
+	PricePerWeek       *json.Number               `json:"price_per_week,omitempty"`
+	DepositAmount      *json.Number               `json:"deposit_amount,omitempty"`
+	Location           string                     `json:"location,omitempty"`
+	Latitude           *float64                   `json:"latitude,omitempty"`
+	Longitude          *float64                   `json:"longitude,omitempty"`
+	Available          *bool                      `json:"available,omitempty"`
+	AutoApprove        *bool                      `json:"auto_approve,omitempty"`
+	RequirePrepayment  *bool                      `json:"require_prepayment,omitempty"`
+	CancellationPolicy *CancellationPolicyRequest `json:"cancellation_policy,omitempty"`
+	InventoryMode      InventoryMode              `json:"inventory_mode,omitempty"`
+	Quantity           *int                       `json:"quantity,omitempty"`
+	TimeZone           string                     `json:"time_zone,omitempty"`
+	Specifications     Specifications             `json:"specifications,omitempty"`
 }
 
 type EquipmentFilter struct {
-	Category  string
-	Location  string
-	Available *bool
-	MinPrice  *float64
-	MaxPrice  *float64
-	StartDate *time.Time
-	EndDate   *time.Time
-	OwnerID   *uuid.UUID
+	Category   string
+	CategoryID *uuid.UUID
+	Location   string
+	Available  *bool
+	MinPrice   *float64
+	MaxPrice   *float64
+	StartDate  *time.Time
+	EndDate    *time.Time
+	OwnerID    *uuid.UUID
+	Specs      []SpecFilter
 }
 
 type EquipmentAvailability struct {
-	Date      time.Time `json:"date"`
-	Available bool      `json:"available"`
+	Date              time.Time `json:"date"`
+	Available         bool      `json:"available"`
+	AvailableQuantity int       `json:"available_quantity"`
 }
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HandoverKind string
+
+const (
+	HandoverPickup HandoverKind = "pickup"
+	HandoverReturn HandoverKind = "return"
+)
+
+type MeterType string
+
+const (
+	MeterHours    MeterType = "hours"
+	MeterOdometer MeterType = "odometer"
+)
+
+func (m MeterType) Valid() bool {
+	return m == MeterHours || m == MeterOdometer
+}
+
+type ChecklistItem struct {
+	Item  string `json:"item"`
+	OK    bool   `json:"ok"`
+	Notes string `json:"notes,omitempty"`
+}
+
+// Signature is a party's acknowledgement of a handover. Image holds the
+// drawn signature as a data URL.
+type Signature struct {
+	Name     string    `json:"name"`
+	Image    string    `json:"image"`
+	SignedAt time.Time `json:"signed_at"`
+}
+
+// HandoverReport is the condition of the equipment when it left the owner
+// (pickup) or came back (return), signed by both parties.
+type HandoverReport struct {
+	ID              uuid.UUID       `json:"id"`
+	ReservationID   uuid.UUID       `json:"reservation_id"`
+	Kind            HandoverKind    `json:"kind"`
+	RecordedBy      uuid.UUID       `json:"recorded_by"`
+	RecordedAt      time.Time       `json:"recorded_at"`
+	MeterType       MeterType       `json:"meter_type,omitempty"`
+	MeterReading    *float64        `json:"meter_reading,omitempty"`
+	FuelLevel       *int            `json:"fuel_level,omitempty"`
+	Checklist       []ChecklistItem `json:"checklist"`
+	Notes           string          `json:"notes,omitempty"`
+	Photos          []string        `json:"photos"`
+	RenterSignature Signature       `json:"renter_signature"`
+	OwnerSignature  Signature       `json:"owner_signature"`
+}
+
+type HandoverRequest struct {
+	MeterType       MeterType       `json:"meter_type,omitempty"`
+	MeterReading    *float64        `json:"meter_reading,omitempty"`
+	FuelLevel       *int            `json:"fuel_level,omitempty"`
+	Checklist       []ChecklistItem `json:"checklist,omitempty"`
+	Notes           string          `json:"notes,omitempty"`
+	RenterSignature *Signature      `json:"renter_signature"`
+	OwnerSignature  *Signature      `json:"owner_signature"`
+}
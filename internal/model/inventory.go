@@ -0,0 +1,105 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InventoryMode string
+
+const (
+	InventoryPooled     InventoryMode = "pooled"
+	InventorySerialized InventoryMode = "serialized"
+)
+
+func (m InventoryMode) Valid() bool {
+	return m == InventoryPooled || m == InventorySerialized
+}
+
+type UnitStatus string
+
+const (
+	UnitAvailable   UnitStatus = "available"
+	UnitMaintenance UnitStatus = "maintenance"
+	UnitRetired     UnitStatus = "retired"
+)
+
+func (s UnitStatus) Valid() bool {
+	switch s {
+	case UnitAvailable, UnitMaintenance, UnitRetired:
+		return true
+	}
+	return false
+}
+
+type EquipmentUnit struct {
+	ID           uuid.UUID  `json:"id"`
+	EquipmentID  uuid.UUID  `json:"equipment_id"`
+	SerialNumber string     `json:"serial_number"`
+	Status       UnitStatus `json:"status"`
+	Notes        string     `json:"notes,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type CreateEquipmentUnitRequest struct {
+	SerialNumber string     `json:"serial_number"`
+	Status       UnitStatus `json:"status,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+}
+
+type UpdateEquipmentUnitRequest struct {
+	SerialNumber string      `json:"serial_number,omitempty"`
+	Status       *UnitStatus `json:"status,omitempty"`
+	Notes        *string     `json:"notes,omitempty"`
+}
+
+type ReservedQuantity struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Quantity  int
+}
+
+// PeakQuantity returns the highest number of units held at the same time
+// within [start, end). Reservations are treated as half-open intervals, so a
+// booking ending exactly when another starts does not overlap it.
+func PeakQuantity(reserved []ReservedQuantity, start, end time.Time) int {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+
+	events := make([]event, 0, len(reserved)*2)
+	for _, r := range reserved {
+		from, to := r.StartDate, r.EndDate
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		if !from.Before(to) {
+			continue
+		}
+		events = append(events, event{from, r.Quantity}, event{to, -r.Quantity})
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at.Equal(events[j].at) {
+			return events[i].delta < events[j].delta
+		}
+		return events[i].at.Before(events[j].at)
+	})
+
+	peak, current := 0, 0
+	for _, e := range events {
+		current += e.delta
+		if current > peak {
+			peak = current
+		}
+	}
+
+	return peak
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeakQuantity(t *testing.T) {
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return base.AddDate(0, 0, n) }
+
+	tests := []struct {
+		name     string
+		reserved []ReservedQuantity
+		start    time.Time
+		end      time.Time
+		want     int
+	}{
+		{
+			name:  "no reservations",
+			start: day(0),
+			end:   day(5),
+			want:  0,
+		},
+		{
+			name: "overlapping reservations add up",
+			reserved: []ReservedQuantity{
+				{day(0), day(3), 2},
+				{day(2), day(5), 3},
+			},
+			start: day(0),
+			end:   day(5),
+			want:  5,
+		},
+		{
+			name: "disjoint reservations do not add up",
+			reserved: []ReservedQuantity{
+				{day(0), day(2), 4},
+				{day(3), day(5), 3},
+			},
+			start: day(0),
+			end:   day(5),
+			want:  4,
+		},
+		{
+			name: "back to back reservations do not overlap",
+			reserved: []ReservedQuantity{
+				{day(0), day(2), 2},
+				{day(2), day(4), 2},
+			},
+			start: day(0),
+			end:   day(4),
+			want:  2,
+		},
+		{
+			name: "reservations outside the window are ignored",
+			reserved: []ReservedQuantity{
+				{day(0), day(2), 2},
+				{day(4), day(6), 1},
+			},
+			start: day(2),
+			end:   day(4),
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PeakQuantity(tt.reserved, tt.start, tt.end); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnitStatus_Valid(t *testing.T) {
+	for _, s := range []UnitStatus{UnitAvailable, UnitMaintenance, UnitRetired} {
+		if !s.Valid() {
+			t.Errorf("expected %q to be valid", s)
+		}
+	}
+	if UnitStatus("lost").Valid() {
+		t.Error("expected unknown status to be invalid")
+	}
+}
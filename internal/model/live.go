@@ -0,0 +1,113 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidTopic = errors.New("invalid topic")
+
+type TopicKind string
+
+const (
+	TopicReservation  TopicKind = "reservation"
+	TopicAvailability TopicKind = "availability"
+)
+
+// ReservationTopic carries the status changes of one reservation.
+func ReservationTopic(id uuid.UUID) string {
+	return "reservation:" + id.String()
+}
+
+// AvailabilityTopic carries changes to the stock held on an equipment and
+// to its blackouts.
+func AvailabilityTopic(equipmentID uuid.UUID) string {
+	return "equipment:" + equipmentID.String() + ":availability"
+}
+
+// ParseTopic returns what a topic is about.
+func ParseTopic(topic string) (TopicKind, uuid.UUID, error) {
+	parts := strings.Split(topic, ":")
+
+	var kind TopicKind
+	switch {
+	case len(parts) == 2 && parts[0] == "reservation":
+		kind = TopicReservation
+	case len(parts) == 3 && parts[0] == "equipment" && parts[2] == "availability":
+		kind = TopicAvailability
+	default:
+		return "", uuid.Nil, ErrInvalidTopic
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return "", uuid.Nil, ErrInvalidTopic
+	}
+
+	return kind, id, nil
+}
+
+type LiveAction string
+
+const (
+	LiveSubscribe   LiveAction = "subscribe"
+	LiveUnsubscribe LiveAction = "unsubscribe"
+)
+
+// LiveRequest is a message from a WebSocket client.
+type LiveRequest struct {
+	Action LiveAction `json:"action"`
+	Topic  string     `json:"topic"`
+}
+
+type LiveMessageType string
+
+const (
+	LiveSubscribed   LiveMessageType = "subscribed"
+	LiveUnsubscribed LiveMessageType = "unsubscribed"
+	LiveUpdate       LiveMessageType = "update"
+	LiveResync       LiveMessageType = "resync"
+	LiveError        LiveMessageType = "error"
+)
+
+// LiveMessage is a message to a WebSocket client. Updates carry the domain
+// event that caused them; resync asks the client to reload its topics
+// because updates may have been missed.
+type LiveMessage struct {
+	Type       LiveMessageType `json:"type"`
+	Topic      string          `json:"topic,omitempty"`
+	Event      DomainEventType `json:"event,omitempty"`
+	Sequence   int64           `json:"sequence,omitempty"`
+	Data       interface{}     `json:"data,omitempty"`
+	OccurredAt *time.Time      `json:"occurred_at,omitempty"`
+	Error      *APIError       `json:"error,omitempty"`
+}
+
+// AvailabilityChange is the diff sent to an equipment's availability topic
+// when one of its reservations is created, moves or changes status.
+// Clients keep the reservation's dates while HoldsStock is true and drop
+// them once it is false.
+type AvailabilityChange struct {
+	ReservationID uuid.UUID         `json:"reservation_id"`
+	Status        ReservationStatus `json:"status"`
+	StartDate     time.Time         `json:"start_date"`
+	EndDate       time.Time         `json:"end_date"`
+	Quantity      int               `json:"quantity"`
+	HoldsStock    bool              `json:"holds_stock"`
+}
+
+// BlackoutChange is the diff sent to an equipment's availability topic when
+// one of its blackouts is added, edited or removed. Clients keep the
+// blackout while Blocks is true and drop it once it is false; weekly ones
+// repeat in the equipment's time zone until RecurUntil.
+type BlackoutChange struct {
+	BlackoutID  uuid.UUID  `json:"blackout_id"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     time.Time  `json:"end_date"`
+	RecurWeekly bool       `json:"recur_weekly"`
+	RecurUntil  *time.Time `json:"recur_until,omitempty"`
+	Blocks      bool       `json:"blocks"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseTopic(t *testing.T) {
+	id := uuid.New()
+
+	kind, got, err := ParseTopic(ReservationTopic(id))
+	if err != nil || kind != TopicReservation || got != id {
+		t.Errorf("reservation topic: got %s %s %v", kind, got, err)
+	}
+
+	kind, got, err = ParseTopic(AvailabilityTopic(id))
+	if err != nil || kind != TopicAvailability || got != id {
+		t.Errorf("availability topic: got %s %s %v", kind, got, err)
+	}
+
+	for _, topic := range []string{
+		"",
+		"reservation",
+		"reservation:not-a-uuid",
+		"equipment:" + id.String(),
+		"equipment:" + id.String() + ":schedule",
+		"user:" + id.String(),
+	} {
+		if _, _, err := ParseTopic(topic); !errors.Is(err, ErrInvalidTopic) {
+			t.Errorf("%q: expected ErrInvalidTopic, got %v", topic, err)
+		}
+	}
+}
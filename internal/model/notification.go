@@ -14,27 +14,80 @@ const (
 	NotificationReservationRejected  NotificationType = "reservation_rejected"
 	NotificationReservationCancelled NotificationType = "reservation_cancelled"
 	NotificationReservationCompleted NotificationType = "reservation_completed"
+	NotificationReservationDisputed  NotificationType = "reservation_disputed"
+	NotificationReservationExpired   NotificationType = "reservation_expired"
 	NotificationReservationReminder  NotificationType = "reservation_reminder"
+	NotificationChangeRequested      NotificationType = "change_requested"
+	NotificationChangeApproved       NotificationType = "change_approved"
+	NotificationChangeRejected       NotificationType = "change_rejected"
+	NotificationEquipmentPickedUp    NotificationType = "equipment_picked_up"
 	NotificationEquipmentReturned    NotificationType = "equipment_returned"
+	NotificationReturnOverdue        NotificationType = "return_overdue"
+	NotificationReturnConflict       NotificationType = "return_conflict"
 	NotificationPaymentReceived      NotificationType = "payment_received"
+	NotificationDepositHeld          NotificationType = "deposit_held"
+	NotificationDepositCaptured      NotificationType = "deposit_captured"
+	NotificationDepositReleased      NotificationType = "deposit_released"
+	NotificationWebhookDisabled      NotificationType = "webhook_disabled"
 )
 
+// Notification is rendered from its type's template in the reader's
+// locale, using Data. Title and Message hold the copy as it was first
+// rendered, and are shown as they are for notifications without data.
 type Notification struct {
-	ID            uuid.UUID        `json:"id"`
-	UserID        uuid.UUID        `json:"user_id"`
-	Type          NotificationType `json:"type"`
-	Title         string           `json:"title"`
-	Message       string           `json:"message"`
-	Read          bool             `json:"read"`
-	ReferenceID   *uuid.UUID       `json:"reference_id,omitempty"`
-	ReferenceType string           `json:"reference_type,omitempty"`
-	CreatedAt     time.Time        `json:"created_at"`
+	ID            uuid.UUID              `json:"id"`
+	UserID        uuid.UUID              `json:"user_id"`
+	Type          NotificationType       `json:"type"`
+	Title         string                 `json:"title"`
+	Message       string                 `json:"message"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+	Read          bool                   `json:"read"`
+	ReferenceID   *uuid.UUID             `json:"reference_id,omitempty"`
+	ReferenceType string                 `json:"reference_type,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	Sequence      int64                  `json:"-"`
+	InApp         bool                   `json:"-"`
+}
+
+type NotificationChangeKind string
+
+const (
+	NotificationChangeCreated NotificationChangeKind = "created"
+	NotificationChangeRead    NotificationChangeKind = "read"
+	NotificationChangeDeleted NotificationChangeKind = "deleted"
+)
+
+// NotificationChange is broadcast to every replica when a user's
+// notifications change, so their open streams can catch up.
+type NotificationChange struct {
+	UserID uuid.UUID              `json:"user_id"`
+	Kind   NotificationChangeKind `json:"kind"`
 }
 
 type NotificationFilter struct {
 	UserID *uuid.UUID
+	IDs    []uuid.UUID
 	Read   *bool
 	Type   *NotificationType
+	Before *time.Time
+}
+
+// MaxBulkNotificationIDs caps how many notifications one bulk request can
+// name by ID.
+const MaxBulkNotificationIDs = 500
+
+// BulkNotificationRequest selects the user's notifications a bulk operation
+// applies to: those with the given IDs, if any, that also match the other
+// fields that are set. At least one field must be set.
+type BulkNotificationRequest struct {
+	IDs    []uuid.UUID       `json:"ids,omitempty"`
+	Read   *bool             `json:"read,omitempty"`
+	Type   *NotificationType `json:"type,omitempty"`
+	Before *time.Time        `json:"before,omitempty"`
+}
+
+type BulkNotificationResult struct {
+	Affected int64 `json:"affected"`
 }
 
 type CreateNotificationRequest struct {
@@ -0,0 +1,235 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DigestFrequency is how often notifications on a channel are sent.
+// Anything but immediate gathers them into one message per period.
+type DigestFrequency string
+
+const (
+	DigestImmediate DigestFrequency = "immediate"
+	DigestHourly    DigestFrequency = "hourly"
+	DigestDaily     DigestFrequency = "daily"
+)
+
+func (d DigestFrequency) IsValid() bool {
+	switch d {
+	case DigestImmediate, DigestHourly, DigestDaily:
+		return true
+	}
+	return false
+}
+
+// dailyDigestHour is the hour of the user's day daily digests are sent at.
+const dailyDigestHour = 8
+
+var notificationTypes = []NotificationType{
+	NotificationReservationCreated,
+	NotificationReservationApproved,
+	NotificationReservationRejected,
+	NotificationReservationCancelled,
+	NotificationReservationCompleted,
+	NotificationReservationDisputed,
+	NotificationReservationExpired,
+	NotificationReservationReminder,
+	NotificationChangeRequested,
+	NotificationChangeApproved,
+	NotificationChangeRejected,
+	NotificationEquipmentPickedUp,
+	NotificationEquipmentReturned,
+	NotificationReturnOverdue,
+	NotificationReturnConflict,
+	NotificationPaymentReceived,
+	NotificationDepositHeld,
+	NotificationDepositCaptured,
+	NotificationDepositReleased,
+	NotificationWebhookDisabled,
+}
+
+// NotificationTypes returns every notification type.
+func NotificationTypes() []NotificationType {
+	return append([]NotificationType{}, notificationTypes...)
+}
+
+// mandatoryTypes are transactional notifications users cannot turn off in
+// the app or by email.
+var mandatoryTypes = map[NotificationType]bool{
+	NotificationReservationApproved:  true,
+	NotificationReservationRejected:  true,
+	NotificationReservationCancelled: true,
+	NotificationReservationDisputed:  true,
+	NotificationReturnOverdue:        true,
+	NotificationPaymentReceived:      true,
+	NotificationDepositCaptured:      true,
+	NotificationWebhookDisabled:      true,
+}
+
+// PreferenceChannels are the channels users can set preferences for.
+var PreferenceChannels = []DeliveryChannel{ChannelInApp, ChannelEmail, ChannelSMS, ChannelPush}
+
+// Required reports whether a notification of this type is always delivered
+// on channel, immediately, whatever the user's preferences.
+func (t NotificationType) Required(channel DeliveryChannel) bool {
+	return mandatoryTypes[t] && (channel == ChannelInApp || channel == ChannelEmail)
+}
+
+// QuietHours is a daily window, in the user's time zone, during which
+// channels that interrupt are held. Times are "HH:MM"; a window whose end
+// is before its start runs past midnight.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Valid reports whether both times parse and the window is not empty.
+func (q *QuietHours) Valid() bool {
+	start, okStart := parseClock(q.Start)
+	end, okEnd := parseClock(q.End)
+	return okStart && okEnd && start != end
+}
+
+// After returns t, or the end of the quiet window if t falls inside it.
+func (q *QuietHours) After(t time.Time, loc *time.Location) time.Time {
+	start, okStart := parseClock(q.Start)
+	end, okEnd := parseClock(q.End)
+	if !okStart || !okEnd || start == end {
+		return t
+	}
+
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+	endsAt := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+
+	switch {
+	case start < end && now >= start && now < end:
+		return endsAt
+	case start > end && now >= start:
+		return endsAt.AddDate(0, 0, 1)
+	case start > end && now < end:
+		return endsAt
+	}
+	return t
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// NotificationPreference is what a user wants for one type on one channel.
+type NotificationPreference struct {
+	Type      NotificationType `json:"type"`
+	Channel   DeliveryChannel  `json:"channel"`
+	Enabled   bool             `json:"enabled"`
+	Digest    DigestFrequency  `json:"digest"`
+	Mandatory bool             `json:"mandatory"`
+}
+
+// NotificationPreferences are a user's notification settings. Preferences
+// holds only what the user changed; For fills in the defaults.
+type NotificationPreferences struct {
+	UserID      uuid.UUID                `json:"-"`
+	TimeZone    string                   `json:"time_zone"`
+	QuietHours  *QuietHours              `json:"quiet_hours"`
+	Preferences []NotificationPreference `json:"preferences"`
+}
+
+// For returns the preference for a type on a channel. Unless the user
+// changed it, a type is enabled in the app and on its default channels,
+// and sent immediately.
+func (p *NotificationPreferences) For(t NotificationType, channel DeliveryChannel) NotificationPreference {
+	pref := NotificationPreference{
+		Type:      t,
+		Channel:   channel,
+		Enabled:   channel == ChannelInApp || slices.Contains(t.Channels(), channel),
+		Digest:    DigestImmediate,
+		Mandatory: t.Required(channel),
+	}
+
+	for _, saved := range p.Preferences {
+		if saved.Type == t && saved.Channel == channel {
+			pref.Enabled = saved.Enabled
+			if saved.Digest.IsValid() {
+				pref.Digest = saved.Digest
+			}
+			break
+		}
+	}
+
+	if pref.Mandatory {
+		pref.Enabled = true
+		pref.Digest = DigestImmediate
+	}
+
+	return pref
+}
+
+// Resolved returns the preferences with every type and channel filled in.
+func (p *NotificationPreferences) Resolved() *NotificationPreferences {
+	resolved := &NotificationPreferences{
+		UserID:     p.UserID,
+		TimeZone:   p.TimeZone,
+		QuietHours: p.QuietHours,
+	}
+	for _, t := range notificationTypes {
+		for _, channel := range PreferenceChannels {
+			resolved.Preferences = append(resolved.Preferences, p.For(t, channel))
+		}
+	}
+	return resolved
+}
+
+// Location returns the user's time zone, or UTC if it is not set or not
+// known.
+func (p *NotificationPreferences) Location() *time.Location {
+	if p.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// DeliverAt returns when a notification of type t that is ready at now
+// should be sent on channel: straight away, at the end of the digest
+// period, and no earlier than the end of quiet hours for channels that
+// interrupt.
+func (p *NotificationPreferences) DeliverAt(t NotificationType, channel DeliveryChannel, now time.Time) time.Time {
+	pref := p.For(t, channel)
+	loc := p.Location()
+	local := now.In(loc)
+
+	at := now
+	switch pref.Digest {
+	case DigestHourly:
+		at = time.Date(local.Year(), local.Month(), local.Day(), local.Hour()+1, 0, 0, 0, loc)
+	case DigestDaily:
+		at = time.Date(local.Year(), local.Month(), local.Day(), dailyDigestHour, 0, 0, 0, loc)
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+	}
+
+	if p.QuietHours != nil && channel.Interrupts() {
+		at = p.QuietHours.After(at, loc)
+	}
+
+	return at
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	TimeZone    string                   `json:"time_zone"`
+	QuietHours  *QuietHours              `json:"quiet_hours"`
+	Preferences []NotificationPreference `json:"preferences"`
+}
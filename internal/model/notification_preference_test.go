@@ -0,0 +1,119 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotificationPreferences_For(t *testing.T) {
+	p := &NotificationPreferences{
+		Preferences: []NotificationPreference{
+			{Type: NotificationReservationCreated, Channel: ChannelEmail, Enabled: false},
+			{Type: NotificationReservationCreated, Channel: ChannelSMS, Enabled: true, Digest: DigestHourly},
+			{Type: NotificationReservationApproved, Channel: ChannelEmail, Enabled: false, Digest: DigestDaily},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		t       NotificationType
+		channel DeliveryChannel
+		enabled bool
+		digest  DigestFrequency
+	}{
+		{"in app by default", NotificationDepositHeld, ChannelInApp, true, DigestImmediate},
+		{"default channel", NotificationDepositHeld, ChannelPush, true, DigestImmediate},
+		{"not a default channel", NotificationDepositHeld, ChannelSMS, false, DigestImmediate},
+		{"turned off", NotificationReservationCreated, ChannelEmail, false, DigestImmediate},
+		{"opted in with a digest", NotificationReservationCreated, ChannelSMS, true, DigestHourly},
+		{"mandatory ignores preference", NotificationReservationApproved, ChannelEmail, true, DigestImmediate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.For(tt.t, tt.channel)
+			if got.Enabled != tt.enabled || got.Digest != tt.digest {
+				t.Errorf("got enabled=%v digest=%s, want enabled=%v digest=%s", got.Enabled, got.Digest, tt.enabled, tt.digest)
+			}
+		})
+	}
+
+	if len(p.Resolved().Preferences) != len(NotificationTypes())*len(PreferenceChannels) {
+		t.Error("expected a preference for every type and channel")
+	}
+}
+
+func TestQuietHours_After(t *testing.T) {
+	loc := time.UTC
+	day := func(d, hour, minute int) time.Time {
+		return time.Date(2024, 1, d, hour, minute, 0, 0, loc)
+	}
+
+	overnight := &QuietHours{Start: "22:00", End: "07:00"}
+	daytime := &QuietHours{Start: "13:00", End: "14:30"}
+
+	tests := []struct {
+		name  string
+		quiet *QuietHours
+		at    time.Time
+		want  time.Time
+	}{
+		{"before overnight window", overnight, day(1, 21, 59), day(1, 21, 59)},
+		{"late evening", overnight, day(1, 23, 0), day(2, 7, 0)},
+		{"early morning", overnight, day(2, 6, 30), day(2, 7, 0)},
+		{"end is not quiet", overnight, day(2, 7, 0), day(2, 7, 0)},
+		{"inside daytime window", daytime, day(1, 13, 15), day(1, 14, 30)},
+		{"after daytime window", daytime, day(1, 15, 0), day(1, 15, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quiet.After(tt.at, loc); !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if (&QuietHours{Start: "22:00", End: "22:00"}).Valid() {
+		t.Error("expected an empty window to be invalid")
+	}
+	if (&QuietHours{Start: "25:00", End: "07:00"}).Valid() {
+		t.Error("expected an out of range time to be invalid")
+	}
+}
+
+func TestNotificationPreferences_DeliverAt(t *testing.T) {
+	p := &NotificationPreferences{
+		TimeZone:   "America/New_York",
+		QuietHours: &QuietHours{Start: "22:00", End: "07:00"},
+		Preferences: []NotificationPreference{
+			{Type: NotificationReservationCreated, Channel: ChannelEmail, Enabled: true, Digest: DigestDaily},
+			{Type: NotificationReservationCreated, Channel: ChannelPush, Enabled: true, Digest: DigestHourly},
+		},
+	}
+	loc := p.Location()
+	now := time.Date(2024, 1, 1, 21, 20, 0, 0, loc)
+
+	tests := []struct {
+		name    string
+		t       NotificationType
+		channel DeliveryChannel
+		now     time.Time
+		want    time.Time
+	}{
+		{"immediate", NotificationDepositHeld, ChannelPush, now, now},
+		{"email ignores quiet hours", NotificationDepositHeld, ChannelEmail, now.Add(2 * time.Hour), now.Add(2 * time.Hour)},
+		{"push waits out quiet hours", NotificationDepositHeld, ChannelPush, now.Add(2 * time.Hour), time.Date(2024, 1, 2, 7, 0, 0, 0, loc)},
+		{"hourly digest", NotificationReservationCreated, ChannelPush, now.Add(-11 * time.Hour), time.Date(2024, 1, 1, 11, 0, 0, 0, loc)},
+		{"hourly digest ending in quiet hours", NotificationReservationCreated, ChannelPush, now, time.Date(2024, 1, 2, 7, 0, 0, 0, loc)},
+		{"daily digest", NotificationReservationCreated, ChannelEmail, now, time.Date(2024, 1, 2, 8, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.DeliverAt(tt.t, tt.channel, tt.now); !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delivery copy is the wording around notifications sent by email, SMS and
+// push. It is stored and versioned under these types like the copy of the
+// notifications themselves, with the title and message holding:
+//
+//	delivery_email     the greeting and the label of the link into the app
+//	delivery_digest    the subject and introduction of a digest
+//	delivery_reminder  the subject and advice of a reservation reminder
+const (
+	CopyDeliveryEmail    NotificationType = "delivery_email"
+	CopyDeliveryDigest   NotificationType = "delivery_digest"
+	CopyDeliveryReminder NotificationType = "delivery_reminder"
+)
+
+var deliveryCopyTypes = []NotificationType{
+	CopyDeliveryEmail,
+	CopyDeliveryDigest,
+	CopyDeliveryReminder,
+}
+
+// TemplateTypes returns every type with templates: the notification types
+// and the delivery copy.
+func TemplateTypes() []NotificationType {
+	return append(NotificationTypes(), deliveryCopyTypes...)
+}
+
+// NotificationTemplate is one version of a notification type's copy in a
+// locale. The newest version is used; the copy shipped with the app counts
+// as version zero.
+type NotificationTemplate struct {
+	ID        uuid.UUID        `json:"id"`
+	Type      NotificationType `json:"type"`
+	Locale    string           `json:"locale"`
+	Version   int              `json:"version"`
+	Title     string           `json:"title"`
+	Message   string           `json:"message"`
+	CreatedBy *uuid.UUID       `json:"created_by,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+type NotificationTemplateFilter struct {
+	Type   *NotificationType
+	Locale *string
+}
+
+type CreateNotificationTemplateRequest struct {
+	Type    NotificationType `json:"type"`
+	Locale  string           `json:"locale"`
+	Title   string           `json:"title"`
+	Message string           `json:"message"`
+}
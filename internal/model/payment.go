@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/payment"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentAuthorized        PaymentStatus = "authorized"
+	PaymentCaptured          PaymentStatus = "captured"
+	PaymentPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentRefunded          PaymentStatus = "refunded"
+	PaymentVoided            PaymentStatus = "voided"
+	PaymentFailed            PaymentStatus = "failed"
+)
+
+// PaymentKind tells a reservation's rental payment apart from the hold
+// placed for its deposit.
+type PaymentKind string
+
+const (
+	PaymentRental  PaymentKind = "rental"
+	PaymentDeposit PaymentKind = "deposit"
+)
+
+type Payment struct {
+	ID            uuid.UUID     `json:"id"`
+	ReservationID uuid.UUID     `json:"reservation_id"`
+	Kind          PaymentKind   `json:"kind"`
+	Provider      string        `json:"provider"`
+	ProviderRef   string        `json:"provider_ref,omitempty"`
+	Status        PaymentStatus `json:"status"`
+	Amount        money.Money   `json:"amount"`
+	Captured      money.Money   `json:"captured"`
+	Refunded      money.Money   `json:"refunded"`
+	FailureReason string        `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+type LedgerEntry struct {
+	ID            uuid.UUID         `json:"id"`
+	TransactionID uuid.UUID         `json:"transaction_id"`
+	ReservationID uuid.UUID         `json:"reservation_id"`
+	PaymentID     *uuid.UUID        `json:"payment_id,omitempty"`
+	Account       payment.Account   `json:"account"`
+	Direction     payment.Direction `json:"direction"`
+	Amount        money.Money       `json:"amount"`
+	Description   string            `json:"description,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+type ReservationPayments struct {
+	Payments []Payment     `json:"payments"`
+	Ledger   []LedgerEntry `json:"ledger"`
+}
+
+type PayReservationRequest struct {
+	PaymentMethod string `json:"payment_method"`
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/pricing"
+)
+
+type PricingRule struct {
+	ID          uuid.UUID        `json:"id"`
+	EquipmentID uuid.UUID        `json:"equipment_id"`
+	Type        pricing.RuleType `json:"type"`
+	Name        string           `json:"name,omitempty"`
+	Percent     *float64         `json:"percent,omitempty"`
+	Amount      *money.Money     `json:"amount,omitempty"`
+	MinDays     *int             `json:"min_days,omitempty"`
+	StartsOn    *time.Time       `json:"starts_on,omitempty"`
+	EndsOn      *time.Time       `json:"ends_on,omitempty"`
+	Active      bool             `json:"active"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+func (r *PricingRule) ToRule() pricing.Rule {
+	rule := pricing.Rule{Type: r.Type, Name: r.Name}
+	if r.Percent != nil {
+		rule.Percent = *r.Percent
+	}
+	if r.Amount != nil {
+		rule.Amount = r.Amount.Amount
+	}
+	if r.MinDays != nil {
+		rule.MinDays = *r.MinDays
+	}
+	if r.StartsOn != nil {
+		rule.StartsOn = *r.StartsOn
+	}
+	if r.EndsOn != nil {
+		rule.EndsOn = *r.EndsOn
+	}
+	return rule
+}
+
+type CreatePricingRuleRequest struct {
+	Type     pricing.RuleType `json:"type"`
+	Name     string           `json:"name,omitempty"`
+	Percent  *float64         `json:"percent,omitempty"`
+	Amount   *json.Number     `json:"amount,omitempty"`
+	MinDays  *int             `json:"min_days,omitempty"`
+	StartsOn *time.Time       `json:"starts_on,omitempty"`
+	EndsOn   *time.Time       `json:"ends_on,omitempty"`
+	Active   *bool            `json:"active,omitempty"`
+}
+
+type UpdatePricingRuleRequest struct {
+	Name     *string      `json:"name,omitempty"`
+	Percent  *float64     `json:"percent,omitempty"`
+	Amount   *json.Number `json:"amount,omitempty"`
+	MinDays  *int         `json:"min_days,omitempty"`
+	StartsOn *time.Time   `json:"starts_on,omitempty"`
+	EndsOn   *time.Time   `json:"ends_on,omitempty"`
+	Active   *bool        `json:"active,omitempty"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/pricing"
+)
+
+type QuoteRequest struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Quantity  int       `json:"quantity,omitempty"`
+	Currency  string    `json:"currency,omitempty"`
+}
+
+type Quote struct {
+	EquipmentID        uuid.UUID          `json:"equipment_id"`
+	StartDate          time.Time          `json:"start_date"`
+	EndDate            time.Time          `json:"end_date"`
+	Quantity           int                `json:"quantity"`
+	Available          bool               `json:"available"`
+	Price              *pricing.Breakdown `json:"price"`
+	DisplayTotal       *money.Money       `json:"display_total,omitempty"`
+	Deposit            money.Money        `json:"deposit"`
+	CancellationPolicy CancellationPolicy `json:"cancellation_policy"`
+	Token              string             `json:"token,omitempty"`
+	ExpiresAt          *time.Time         `json:"expires_at,omitempty"`
+}
@@ -1,9 +1,13 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/pricing"
 )
 
 type ReservationStatus string
@@ -11,30 +15,112 @@ type ReservationStatus string
 const (
 	StatusPending   ReservationStatus = "pending"
 	StatusApproved  ReservationStatus = "approved"
+	StatusActive    ReservationStatus = "active"
+	StatusReturned  ReservationStatus = "returned"
 	StatusCancelled ReservationStatus = "cancelled"
 	StatusCompleted ReservationStatus = "completed"
 	StatusRejected  ReservationStatus = "rejected"
+	StatusExpired   ReservationStatus = "expired"
+	StatusDisputed  ReservationStatus = "disputed"
+)
+
+// HoldsStock reports whether a reservation in this status keeps its
+// equipment from being booked by others.
+func (s ReservationStatus) HoldsStock() bool {
+	return s == StatusPending || s == StatusApproved || s == StatusActive
+}
+
+type DepositStatus string
+
+const (
+	DepositRequired          DepositStatus = "required"
+	DepositHeld              DepositStatus = "held"
+	DepositPartiallyCaptured DepositStatus = "partially_captured"
+	DepositCaptured          DepositStatus = "captured"
+	DepositReleased          DepositStatus = "released"
 )
 
+// Deposit is the refundable security deposit for a reservation. It is
+// required when the reservation is made, held once it is approved and
+// settled by the owner, who may capture part or all of it.
+type Deposit struct {
+	Status        DepositStatus `json:"status"`
+	Amount        money.Money   `json:"amount"`
+	Captured      money.Money   `json:"captured"`
+	CaptureReason string        `json:"capture_reason,omitempty"`
+	SettledAt     *time.Time    `json:"settled_at,omitempty"`
+}
+
+func (d *Deposit) Settled() bool {
+	return d.Status == DepositPartiallyCaptured || d.Status == DepositCaptured || d.Status == DepositReleased
+}
+
+// Capture keeps amount of a held deposit for the owner and releases the
+// rest. The amount is checked by the caller.
+func (d *Deposit) Capture(amount money.Money, reason string, now time.Time) {
+	d.Status = DepositPartiallyCaptured
+	if amount.Amount == d.Amount.Amount {
+		d.Status = DepositCaptured
+	}
+	d.Captured = amount
+	d.CaptureReason = reason
+	d.SettledAt = &now
+}
+
+// Release returns an unsettled deposit in full. It reports whether money
+// was held, as a deposit still required was never taken.
+func (d *Deposit) Release(now time.Time) bool {
+	wasHeld := d.Status == DepositHeld
+	d.Status = DepositReleased
+	d.SettledAt = &now
+	return wasHeld
+}
+
 type Reservation struct {
-	ID                 uuid.UUID         `json:"id"`
-	EquipmentID        uuid.UUID         `json:"equipment_id"`
-	Equipment          *Equipment        `json:"equipment,omitempty"`
-	RenterID           uuid.UUID         `json:"renter_id"`
-	Renter             *User             `json:"renter,omitempty"`
-	StartDate          time.Time         `json:"start_date"`
-	EndDate            time.Time         `json:"end_date"`
-	Status             ReservationStatus `json:"status"`
-	TotalPrice         float64           `json:"total_price"`
-	CancellationReason string            `json:"cancellation_reason,omitempty"`
-	CreatedAt          time.Time         `json:"created_at"`
-	UpdatedAt          time.Time         `json:"updated_at"`
+	ID                 uuid.UUID           `json:"id"`
+	EquipmentID        uuid.UUID           `json:"equipment_id"`
+	Equipment          *Equipment          `json:"equipment,omitempty"`
+	RenterID           uuid.UUID           `json:"renter_id"`
+	Renter             *User               `json:"renter,omitempty"`
+	StartDate          time.Time           `json:"start_date"`
+	EndDate            time.Time           `json:"end_date"`
+	Quantity           int                 `json:"quantity"`
+	UnitIDs            []uuid.UUID         `json:"unit_ids,omitempty"`
+	Status             ReservationStatus   `json:"status"`
+	TotalPrice         money.Money         `json:"total_price"`
+	PriceBreakdown     *pricing.Breakdown  `json:"price_breakdown,omitempty"`
+	Deposit            *Deposit            `json:"deposit,omitempty"`
+	Payment            *Payment            `json:"payment,omitempty"`
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy,omitempty"`
+	Cancellation       *Cancellation       `json:"cancellation,omitempty"`
+	PickedUpAt         *time.Time          `json:"picked_up_at,omitempty"`
+	ReturnedAt         *time.Time          `json:"returned_at,omitempty"`
+	LateReturn         *LateReturn         `json:"late_return,omitempty"`
+	CancellationReason string              `json:"cancellation_reason,omitempty"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+}
+
+// LateReturn tracks equipment kept past the reservation's end date. The fee
+// accrues while the equipment is out and is final once the return is
+// recorded. WarnedReservationIDs are the upcoming bookings whose renters
+// have been told the equipment may not be back in time.
+type LateReturn struct {
+	DetectedAt           time.Time   `json:"detected_at"`
+	OverdueHours         int         `json:"overdue_hours"`
+	Fee                  money.Money `json:"fee"`
+	AccruedAt            time.Time   `json:"accrued_at"`
+	Final                bool        `json:"final"`
+	WarnedReservationIDs []uuid.UUID `json:"warned_reservation_ids,omitempty"`
 }
 
 type CreateReservationRequest struct {
-	EquipmentID uuid.UUID `json:"equipment_id"`
-	StartDate   time.Time `json:"start_date"`
-	EndDate     time.Time `json:"end_date"`
+	EquipmentID   uuid.UUID `json:"equipment_id"`
+	StartDate     time.Time `json:"start_date"`
+	EndDate       time.Time `json:"end_date"`
+	Quantity      int       `json:"quantity,omitempty"`
+	QuoteToken    string    `json:"quote_token,omitempty"`
+	PaymentMethod string    `json:"payment_method,omitempty"`
 }
 
 type ReservationFilter struct {
@@ -49,3 +135,16 @@ type ReservationFilter struct {
 type CancelReservationRequest struct {
 	Reason string `json:"reason,omitempty"`
 }
+
+type DisputeReservationRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ResolveDisputeRequest struct {
+	Resolution string `json:"resolution"`
+}
+
+type CaptureDepositRequest struct {
+	Amount *json.Number `json:"amount"`
+	Reason string       `json:"reason"`
+}
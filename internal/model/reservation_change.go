@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/pricing"
+)
+
+type ChangeStatus string
+
+const (
+	ChangePending  ChangeStatus = "pending"
+	ChangeApproved ChangeStatus = "approved"
+	ChangeRejected ChangeStatus = "rejected"
+)
+
+// ReservationChange is a renter's request to move or extend a reservation.
+// Price is the reservation repriced for the new dates and PriceDelta the
+// difference from the total at the time of the request; a positive delta is
+// owed by the renter. PaymentID is the hold placed for a price increase on
+// a prepaid reservation.
+type ReservationChange struct {
+	ID            uuid.UUID          `json:"id"`
+	ReservationID uuid.UUID          `json:"reservation_id"`
+	RequestedBy   uuid.UUID          `json:"requested_by"`
+	Status        ChangeStatus       `json:"status"`
+	FromStartDate time.Time          `json:"from_start_date"`
+	FromEndDate   time.Time          `json:"from_end_date"`
+	StartDate     time.Time          `json:"start_date"`
+	EndDate       time.Time          `json:"end_date"`
+	Price         *pricing.Breakdown `json:"price"`
+	PriceDelta    money.Money        `json:"price_delta"`
+	PaymentID     *uuid.UUID         `json:"payment_id,omitempty"`
+	Reason        string             `json:"reason,omitempty"`
+	DecidedBy     *uuid.UUID         `json:"decided_by,omitempty"`
+	DecidedAt     *time.Time         `json:"decided_at,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+// CreateReservationChangeRequest carries a payment method for changes that
+// raise the price of a prepaid reservation.
+type CreateReservationChangeRequest struct {
+	StartDate     time.Time `json:"start_date"`
+	EndDate       time.Time `json:"end_date"`
+	PaymentMethod string    `json:"payment_method,omitempty"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// reservationTransitions is the reservation lifecycle: each status maps to
+// the statuses it may move to. Statuses without an entry are final.
+var reservationTransitions = map[ReservationStatus][]ReservationStatus{
+	StatusPending: {StatusApproved, StatusRejected, StatusCancelled, StatusExpired},
+	// Listings that skip pickup check-in are completed straight from
+	// approved.
+	StatusApproved: {StatusActive, StatusCompleted, StatusCancelled},
+	StatusActive:   {StatusReturned},
+	StatusReturned: {StatusCompleted, StatusDisputed},
+	StatusDisputed: {StatusCompleted},
+}
+
+func (s ReservationStatus) CanTransitionTo(next ReservationStatus) bool {
+	for _, allowed := range reservationTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+func (s ReservationStatus) Final() bool {
+	return len(reservationTransitions[s]) == 0
+}
+
+// ReservationEvent records one status change. FromStatus is empty for the
+// event that creates the reservation, and ActorID is nil for changes made
+// by the system. Date changes are recorded with the status unchanged.
+type ReservationEvent struct {
+	ID            uuid.UUID         `json:"id"`
+	ReservationID uuid.UUID         `json:"reservation_id"`
+	FromStatus    ReservationStatus `json:"from_status,omitempty"`
+	ToStatus      ReservationStatus `json:"to_status"`
+	ActorID       *uuid.UUID        `json:"actor_id,omitempty"`
+	Reason        string            `json:"reason,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
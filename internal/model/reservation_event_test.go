@@ -0,0 +1,45 @@
+package model
+
+import "testing"
+
+func TestReservationStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from ReservationStatus
+		to   ReservationStatus
+		want bool
+	}{
+		{StatusPending, StatusApproved, true},
+		{StatusPending, StatusRejected, true},
+		{StatusPending, StatusExpired, true},
+		{StatusPending, StatusActive, false},
+		{StatusApproved, StatusActive, true},
+		{StatusApproved, StatusCancelled, true},
+		{StatusApproved, StatusRejected, false},
+		{StatusActive, StatusReturned, true},
+		{StatusActive, StatusCancelled, false},
+		{StatusReturned, StatusCompleted, true},
+		{StatusReturned, StatusDisputed, true},
+		{StatusDisputed, StatusCompleted, true},
+		{StatusCompleted, StatusDisputed, false},
+		{StatusCancelled, StatusApproved, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s -> %s: expected %v, got %v", tt.from, tt.to, tt.want, got)
+		}
+	}
+}
+
+func TestReservationStatus_Final(t *testing.T) {
+	for _, s := range []ReservationStatus{StatusCompleted, StatusCancelled, StatusRejected, StatusExpired} {
+		if !s.Final() {
+			t.Errorf("expected %q to be final", s)
+		}
+	}
+	for _, s := range []ReservationStatus{StatusPending, StatusApproved, StatusActive, StatusReturned, StatusDisputed} {
+		if s.Final() {
+			t.Errorf("expected %q not to be final", s)
+		}
+	}
+}
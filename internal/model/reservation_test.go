@@ -0,0 +1,101 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+func heldDeposit() *Deposit {
+	return &Deposit{
+		Status:   DepositHeld,
+		Amount:   money.MustParse("200.00", "EUR"),
+		Captured: money.New(0, "EUR"),
+	}
+}
+
+func TestDeposit_Capture(t *testing.T) {
+	now := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		amount string
+		want   DepositStatus
+	}{
+		{"part of the deposit", "75.50", DepositPartiallyCaptured},
+		{"the whole deposit", "200.00", DepositCaptured},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := heldDeposit()
+			d.Capture(money.MustParse(tt.amount, "EUR"), "scratched lens", now)
+
+			if d.Status != tt.want {
+				t.Errorf("expected status %s, got %s", tt.want, d.Status)
+			}
+			if d.Captured.String() != money.MustParse(tt.amount, "EUR").String() {
+				t.Errorf("expected %s captured, got %s", tt.amount, d.Captured)
+			}
+			if d.CaptureReason != "scratched lens" {
+				t.Errorf("expected the reason to be kept, got %q", d.CaptureReason)
+			}
+			if !d.Settled() || d.SettledAt == nil || !d.SettledAt.Equal(now) {
+				t.Error("expected the deposit to be settled now")
+			}
+		})
+	}
+}
+
+func TestDeposit_Release(t *testing.T) {
+	now := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		status  DepositStatus
+		wasHeld bool
+	}{
+		{"held deposit", DepositHeld, true},
+		{"deposit never taken", DepositRequired, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := heldDeposit()
+			d.Status = tt.status
+
+			if got := d.Release(now); got != tt.wasHeld {
+				t.Errorf("expected wasHeld %v, got %v", tt.wasHeld, got)
+			}
+			if d.Status != DepositReleased || !d.Settled() {
+				t.Errorf("expected a settled release, got %s", d.Status)
+			}
+			if !d.Captured.IsZero() {
+				t.Errorf("expected nothing captured, got %s", d.Captured)
+			}
+		})
+	}
+}
+
+func TestDeposit_Settled(t *testing.T) {
+	tests := []struct {
+		status DepositStatus
+		want   bool
+	}{
+		{DepositRequired, false},
+		{DepositHeld, false},
+		{DepositPartiallyCaptured, true},
+		{DepositCaptured, true},
+		{DepositReleased, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			d := &Deposit{Status: tt.status}
+			if got := d.Settled(); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/pkg/timeslot"
+)
+
+type OpeningHours struct {
+	Weekday time.Weekday `json:"weekday"`
+	Opens   string       `json:"opens"`
+	Closes  string       `json:"closes"`
+}
+
+type SetOpeningHoursRequest struct {
+	Hours []OpeningHours `json:"hours"`
+}
+
+// ParseClock converts an "HH:MM" wall-clock time into an offset from
+// midnight. "24:00" is accepted so a day can close at midnight.
+func ParseClock(value string) (time.Duration, error) {
+	var hours, minutes int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hours, &minutes); err != nil || len(value) != 5 {
+		return 0, fmt.Errorf("must be in HH:MM format")
+	}
+	if hours < 0 || hours > 24 || minutes < 0 || minutes > 59 || (hours == 24 && minutes != 0) {
+		return 0, fmt.Errorf("must be between 00:00 and 24:00")
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+func FormatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset/time.Hour), int((offset%time.Hour)/time.Minute))
+}
+
+func (h OpeningHours) DailyHours() (timeslot.DailyHours, error) {
+	if h.Weekday < time.Sunday || h.Weekday > time.Saturday {
+		return timeslot.DailyHours{}, fmt.Errorf("weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+
+	opens, err := ParseClock(h.Opens)
+	if err != nil {
+		return timeslot.DailyHours{}, fmt.Errorf("opens %w", err)
+	}
+	closes, err := ParseClock(h.Closes)
+	if err != nil {
+		return timeslot.DailyHours{}, fmt.Errorf("closes %w", err)
+	}
+	if closes <= opens {
+		return timeslot.DailyHours{}, fmt.Errorf("closes must be after opens")
+	}
+
+	return timeslot.DailyHours{Weekday: h.Weekday, Opens: opens, Closes: closes}, nil
+}
+
+type BusyReason string
+
+const (
+	BusyReserved BusyReason = "reserved"
+	BusyBlackout BusyReason = "blackout"
+	BusyClosed   BusyReason = "closed"
+)
+
+type BusyInterval struct {
+	timeslot.Interval
+	Reason BusyReason `json:"reason"`
+}
+
+type EquipmentSchedule struct {
+	EquipmentID uuid.UUID           `json:"equipment_id"`
+	TimeZone    string              `json:"time_zone"`
+	Start       time.Time           `json:"start"`
+	End         time.Time           `json:"end"`
+	Free        []timeslot.Interval `json:"free"`
+	Busy        []BusyInterval      `json:"busy"`
+}
+
+type EquipmentSlots struct {
+	EquipmentID     uuid.UUID           `json:"equipment_id"`
+	TimeZone        string              `json:"time_zone"`
+	DurationMinutes int                 `json:"duration_minutes"`
+	Slots           []timeslot.Interval `json:"slots"`
+}
+
+type ScheduleQuery struct {
+	Start    string
+	End      string
+	Duration time.Duration
+	Step     time.Duration
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"09:00", 9 * time.Hour, false},
+		{"17:30", 17*time.Hour + 30*time.Minute, false},
+		{"24:00", 24 * time.Hour, false},
+		{"24:30", 0, true},
+		{"9:00", 0, true},
+		{"25:00", 0, true},
+		{"noon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseClock(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	if got := FormatClock(9*time.Hour + 5*time.Minute); got != "09:05" {
+		t.Errorf("expected 09:05, got %s", got)
+	}
+}
+
+func TestOpeningHours_DailyHours(t *testing.T) {
+	if _, err := (OpeningHours{Weekday: time.Monday, Opens: "09:00", Closes: "17:00"}).DailyHours(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := (OpeningHours{Weekday: time.Monday, Opens: "17:00", Closes: "09:00"}).DailyHours(); err == nil {
+		t.Error("expected error when closing before opening")
+	}
+	if _, err := (OpeningHours{Weekday: 7, Opens: "09:00", Closes: "17:00"}).DailyHours(); err == nil {
+		t.Error("expected error for invalid weekday")
+	}
+}
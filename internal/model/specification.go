@@ -0,0 +1,99 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type Specifications map[string]interface{}
+
+type SpecOperator string
+
+const (
+	SpecEq  SpecOperator = "eq"
+	SpecNe  SpecOperator = "ne"
+	SpecLt  SpecOperator = "lt"
+	SpecLte SpecOperator = "lte"
+	SpecGt  SpecOperator = "gt"
+	SpecGte SpecOperator = "gte"
+)
+
+func (o SpecOperator) IsRange() bool {
+	return o == SpecLt || o == SpecLte || o == SpecGt || o == SpecGte
+}
+
+func (o SpecOperator) Valid() bool {
+	return o == SpecEq || o == SpecNe || o.IsRange()
+}
+
+type SpecFilter struct {
+	Key      string
+	Operator SpecOperator
+	Value    string
+	// Type is the attribute's type in the category schema, which decides
+	// how Value is read.
+	Type AttributeType
+}
+
+// TypedValue reads the raw query string as the attribute's type, the same
+// way specifications of that type are stored, so equality filters can use
+// JSONB containment.
+func (f SpecFilter) TypedValue() (interface{}, error) {
+	switch f.Type {
+	case AttributeNumber:
+		n, err := strconv.ParseFloat(f.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return n, nil
+	case AttributeBoolean:
+		b, err := strconv.ParseBool(f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return b, nil
+	case AttributeEnum, AttributeText:
+		return f.Value, nil
+	default:
+		return nil, fmt.Errorf("has an unsupported attribute type")
+	}
+}
+
+func (a CategoryAttribute) Normalize(value interface{}) (interface{}, error) {
+	switch a.Type {
+	case AttributeNumber:
+		n, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return n, nil
+	case AttributeBoolean:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return b, nil
+	case AttributeEnum:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string")
+		}
+		for _, option := range a.Options {
+			if s == option {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("must be one of the category options")
+	case AttributeText:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string")
+		}
+		if len(s) > 500 {
+			return nil, fmt.Errorf("must be at most 500 characters")
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("has an unsupported attribute type")
+	}
+}
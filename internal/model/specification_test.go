@@ -0,0 +1,71 @@
+package model
+
+import "testing"
+
+func TestCategoryAttribute_Normalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		attr      CategoryAttribute
+		value     interface{}
+		wantError bool
+	}{
+		{"number accepted", CategoryAttribute{Type: AttributeNumber}, 4500.0, false},
+		{"number rejects string", CategoryAttribute{Type: AttributeNumber}, "4500", true},
+		{"boolean accepted", CategoryAttribute{Type: AttributeBoolean}, true, false},
+		{"boolean rejects number", CategoryAttribute{Type: AttributeBoolean}, 1.0, true},
+		{"enum option accepted", CategoryAttribute{Type: AttributeEnum, Options: []string{"full-frame", "aps-c"}}, "aps-c", false},
+		{"enum unknown option rejected", CategoryAttribute{Type: AttributeEnum, Options: []string{"full-frame"}}, "micro-4/3", true},
+		{"text accepted", CategoryAttribute{Type: AttributeText}, "Includes charger", false},
+		{"unknown type rejected", CategoryAttribute{Type: "date"}, "2024-01-01", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.attr.Normalize(tt.value)
+			if tt.wantError && err == nil {
+				t.Error("expected error")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSpecFilter_TypedValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		attrType  AttributeType
+		value     string
+		want      interface{}
+		wantError bool
+	}{
+		{"number", AttributeNumber, "5000", 5000.0, false},
+		{"decimal", AttributeNumber, "2.5", 2.5, false},
+		{"number rejects text", AttributeNumber, "heavy", nil, true},
+		{"boolean", AttributeBoolean, "true", true, false},
+		{"boolean rejects text", AttributeBoolean, "yes please", nil, true},
+		{"enum", AttributeEnum, "diesel", "diesel", false},
+		{"numeric-looking text stays text", AttributeText, "5000", "5000", false},
+		{"boolean-looking enum stays text", AttributeEnum, "true", "true", false},
+		{"unknown type", "date", "2024-01-01", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SpecFilter{Key: "k", Operator: SpecEq, Value: tt.value, Type: tt.attrType}.TypedValue()
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v (%T), got %v (%T)", tt.want, tt.want, got, got)
+			}
+		})
+	}
+}
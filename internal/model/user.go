@@ -11,6 +11,7 @@ type UserRole string
 const (
 	RoleOwner  UserRole = "owner"
 	RoleRenter UserRole = "renter"
+	RoleAdmin  UserRole = "admin"
 )
 
 type User struct {
@@ -19,6 +20,7 @@ type User struct {
 	PasswordHash string    `json:"-"`
 	Name         string    `json:"name"`
 	Phone        string    `json:"phone,omitempty"`
+	Locale       string    `json:"locale"`
 	Role         UserRole  `json:"role"`
 	Verified     bool      `json:"verified"`
 	CreatedAt    time.Time `json:"created_at"`
@@ -30,12 +32,14 @@ type CreateUserRequest struct {
 	Password string   `json:"password"`
 	Name     string   `json:"name"`
 	Phone    string   `json:"phone,omitempty"`
+	Locale   string   `json:"locale,omitempty"`
 	Role     UserRole `json:"role,omitempty"`
 }
 
 type UpdateUserRequest struct {
-	Name  string `json:"name,omitempty"`
-	Phone string `json:"phone,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Phone  string `json:"phone,omitempty"`
+	Locale string `json:"locale,omitempty"`
 }
 
 type LoginRequest struct {
@@ -47,3 +51,10 @@ type AuthResponse struct {
 	Token string `json:"token"`
 	User  User   `json:"user"`
 }
+
+// StreamTokenResponse carries a short-lived token for opening a live
+// connection from a browser.
+type StreamTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventWebhookTest is sent by the "send test event" endpoint only.
+const EventWebhookTest DomainEventType = "webhook.test"
+
+// WebhookAllEvents subscribes to every event the user can see.
+const WebhookAllEvents DomainEventType = "*"
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+	DeliverySkipped   DeliveryStatus = "skipped"
+)
+
+// WebhookSubscription sends the domain events a user is involved in to
+// their URL: reservations they rent or own the equipment of, and their own
+// listings. The secret is only returned when the subscription is created.
+type WebhookSubscription struct {
+	ID                  uuid.UUID         `json:"id"`
+	UserID              uuid.UUID         `json:"user_id"`
+	URL                 string            `json:"url"`
+	Description         string            `json:"description,omitempty"`
+	Events              []DomainEventType `json:"events"`
+	Secret              string            `json:"secret,omitempty"`
+	Active              bool              `json:"active"`
+	ConsecutiveFailures int               `json:"consecutive_failures"`
+	DisabledAt          *time.Time        `json:"disabled_at,omitempty"`
+	DisabledReason      string            `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+}
+
+// Wants reports whether the subscription's filter includes eventType.
+func (s *WebhookSubscription) Wants(eventType DomainEventType) bool {
+	for _, e := range s.Events {
+		if e == eventType || e == WebhookAllEvents {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent, or being sent, to a subscription.
+// Attempts and the last response are updated on every try.
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      DomainEventType `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	DurationMs     int64           `json:"duration_ms"`
+	CreatedAt      time.Time       `json:"created_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+type CreateWebhookRequest struct {
+	URL         string            `json:"url"`
+	Description string            `json:"description,omitempty"`
+	Events      []DomainEventType `json:"events"`
+}
+
+// UpdateWebhookRequest changes a subscription. Setting active to true
+// re-enables a subscription that was disabled after repeated failures.
+type UpdateWebhookRequest struct {
+	URL         *string           `json:"url,omitempty"`
+	Description *string           `json:"description,omitempty"`
+	Events      []DomainEventType `json:"events,omitempty"`
+	Active      *bool             `json:"active,omitempty"`
+}
@@ -0,0 +1,136 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+// Message is a record as a NATS or Kafka client sends it. Key is the
+// aggregate ID, so a partitioned broker keeps each aggregate's events on
+// one partition and in order.
+type Message struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string]string
+}
+
+// Broker is the part of a message broker client the relay needs. A NATS
+// JetStream or Kafka producer can be adapted to it in a few lines.
+type Broker interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// BrokerSink publishes every event to the topic
+// "<prefix>.<aggregate_type>", e.g. "goapi.reservation".
+type BrokerSink struct {
+	broker      Broker
+	topicPrefix string
+}
+
+func NewBrokerSink(broker Broker, topicPrefix string) *BrokerSink {
+	return &BrokerSink{
+		broker:      broker,
+		topicPrefix: topicPrefix,
+	}
+}
+
+func (s *BrokerSink) Name() string {
+	return "broker"
+}
+
+func (s *BrokerSink) Publish(ctx context.Context, event *model.DomainEvent) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return s.broker.Publish(ctx, Message{
+		Topic: s.topicPrefix + "." + event.AggregateType,
+		Key:   event.AggregateID.String(),
+		Value: value,
+		Headers: map[string]string{
+			"event-id":   event.ID.String(),
+			"event-type": string(event.Type),
+		},
+	})
+}
+
+// memoryBrokerLimit is how many messages a MemoryBroker keeps per topic.
+const memoryBrokerLimit = 1000
+
+// MemoryBroker is a local stand-in for a real broker. It keeps the most
+// recent messages per topic and hands every message to local consumers.
+type MemoryBroker struct {
+	mu        sync.Mutex
+	limit     int
+	messages  map[string]*messageRing
+	consumers map[string][]func(Message)
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		limit:     memoryBrokerLimit,
+		messages:  make(map[string]*messageRing),
+		consumers: make(map[string][]func(Message)),
+	}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, msg Message) error {
+	b.mu.Lock()
+	ring, ok := b.messages[msg.Topic]
+	if !ok {
+		ring = &messageRing{}
+		b.messages[msg.Topic] = ring
+	}
+	ring.push(msg, b.limit)
+	consumers := append([]func(Message){}, b.consumers[msg.Topic]...)
+	b.mu.Unlock()
+
+	for _, consume := range consumers {
+		consume(msg)
+	}
+	return nil
+}
+
+// Consume calls fn for every message published to topic from now on.
+func (b *MemoryBroker) Consume(topic string, fn func(Message)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consumers[topic] = append(b.consumers[topic], fn)
+}
+
+// Messages returns the most recent messages published to topic, oldest
+// first.
+func (b *MemoryBroker) Messages(topic string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ring, ok := b.messages[topic]
+	if !ok {
+		return nil
+	}
+	return ring.list()
+}
+
+// messageRing holds up to a limit of messages, overwriting the oldest once
+// full. start is the index of the oldest message.
+type messageRing struct {
+	buf   []Message
+	start int
+}
+
+func (r *messageRing) push(msg Message, limit int) {
+	if len(r.buf) < limit {
+		r.buf = append(r.buf, msg)
+		return
+	}
+	r.buf[r.start] = msg
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+func (r *messageRing) list() []Message {
+	return append(append([]Message{}, r.buf[r.start:]...), r.buf[:r.start]...)
+}
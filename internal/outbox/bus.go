@@ -0,0 +1,60 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+// Subscriber handles a domain event inside this process. An error makes the
+// relay deliver the event again later, to every subscriber of the bus.
+type Subscriber func(ctx context.Context, event *model.DomainEvent) error
+
+// Bus is the sink for in-process subscribers.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[model.DomainEventType][]Subscriber
+	all         []Subscriber
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[model.DomainEventType][]Subscriber),
+	}
+}
+
+func (b *Bus) Name() string {
+	return "bus"
+}
+
+// Subscribe calls fn for events of the given types, or for every event when
+// no type is given.
+func (b *Bus) Subscribe(fn Subscriber, eventTypes ...model.DomainEventType) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(eventTypes) == 0 {
+		b.all = append(b.all, fn)
+		return
+	}
+	for _, eventType := range eventTypes {
+		b.subscribers[eventType] = append(b.subscribers[eventType], fn)
+	}
+}
+
+func (b *Bus) Publish(ctx context.Context, event *model.DomainEvent) error {
+	b.mu.RLock()
+	subscribers := append(append([]Subscriber{}, b.subscribers[event.Type]...), b.all...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, fn := range subscribers {
+		if err := fn(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
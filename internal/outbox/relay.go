@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/config"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
+	"github.com/abneribeiro/goapi/internal/queue"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+// Sink receives domain events from the relay. Publish may be called more
+// than once for an event, so consumers should deduplicate on the event ID.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event *model.DomainEvent) error
+}
+
+// Locker is satisfied by jobs.PostgresLocker. Only the replica holding the
+// relay's lock delivers events, which keeps them in order.
+type Locker interface {
+	TryLock(ctx context.Context, name string) (unlock func(), acquired bool, err error)
+}
+
+const lockName = "outbox_relay"
+
+// Relay delivers outbox events to every sink, at least once and in
+// sequence order per aggregate. An event that fails holds back the later
+// events of its aggregate until it is retried, while other aggregates carry
+// on. After cfg.MaxAttempts failures the event is dead-lettered and its
+// aggregate moves on without it.
+type Relay struct {
+	repo   *repository.OutboxRepository
+	locker Locker
+	cfg    config.OutboxConfig
+	sinks  []Sink
+}
+
+func NewRelay(repo *repository.OutboxRepository, locker Locker, cfg config.OutboxConfig, sinks ...Sink) *Relay {
+	return &Relay{
+		repo:   repo,
+		locker: locker,
+		cfg:    cfg,
+		sinks:  sinks,
+	}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	for {
+		published, err := r.runOnce(ctx)
+		if err != nil {
+			logger.Error("outbox relay error", logger.WithFields(map[string]interface{}{
+				"error": err.Error(),
+			}))
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		if published > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.cfg.PollInterval):
+		}
+	}
+}
+
+func (r *Relay) runOnce(ctx context.Context) (int, error) {
+	unlock, acquired, err := r.locker.TryLock(ctx, lockName)
+	if err != nil || !acquired {
+		return 0, err
+	}
+	defer unlock()
+
+	return r.RelayBatch(ctx)
+}
+
+// RelayBatch delivers the next batch of pending events and returns how many
+// were fully published.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	entries, err := r.repo.ListPending(ctx, r.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	blocked := make(map[uuid.UUID]bool)
+	published := 0
+	var errs []error
+	for _, entry := range entries {
+		aggregate := entry.Event.AggregateID
+		if blocked[aggregate] {
+			continue
+		}
+
+		if err := r.deliver(ctx, entry); err != nil {
+			blocked[aggregate] = true
+			errs = append(errs, fmt.Errorf("event %d: %w", entry.Event.Sequence, err))
+			continue
+		}
+		published++
+	}
+
+	return published, errors.Join(errs...)
+}
+
+// deliver publishes an entry to the sinks that do not have it yet, stopping
+// at the first failure.
+func (r *Relay) deliver(ctx context.Context, entry *model.OutboxEntry) error {
+	sequence := entry.Event.Sequence
+	for _, sink := range r.sinks {
+		if slices.Contains(entry.DeliveredTo, sink.Name()) {
+			continue
+		}
+
+		if err := sink.Publish(ctx, &entry.Event); err != nil {
+			err = fmt.Errorf("%s: %w", sink.Name(), err)
+			attempts := entry.Attempts + 1
+			if exhausted(attempts, r.cfg.MaxAttempts) {
+				logger.Error("outbox event dead-lettered", logger.WithFields(map[string]interface{}{
+					"sequence": sequence,
+					"type":     entry.Event.Type,
+					"attempt":  attempts,
+					"error":    err.Error(),
+				}))
+				return errors.Join(err, r.repo.MarkDead(ctx, sequence, err.Error()))
+			}
+			retryAt := time.Now().Add(queue.Backoff(attempts))
+			return errors.Join(err, r.repo.MarkFailed(ctx, sequence, err.Error(), retryAt))
+		}
+
+		if err := r.repo.MarkDelivered(ctx, sequence, sink.Name()); err != nil {
+			return err
+		}
+		entry.DeliveredTo = append(entry.DeliveredTo, sink.Name())
+	}
+
+	return r.repo.MarkPublished(ctx, sequence)
+}
+
+// exhausted reports whether an event that has failed attempts times should
+// be dead-lettered. A maxAttempts of zero or less retries for ever.
+func exhausted(attempts, maxAttempts int) bool {
+	return maxAttempts > 0 && attempts >= maxAttempts
+}
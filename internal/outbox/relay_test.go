@@ -0,0 +1,25 @@
+package outbox
+
+import "testing"
+
+func TestExhausted(t *testing.T) {
+	tests := []struct {
+		name        string
+		attempts    int
+		maxAttempts int
+		want        bool
+	}{
+		{"attempts left", 3, 10, false},
+		{"last attempt", 10, 10, true},
+		{"past the limit", 12, 10, true},
+		{"no limit", 100, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exhausted(tt.attempts, tt.maxAttempts); got != tt.want {
+				t.Errorf("exhausted(%d, %d) = %v, want %v", tt.attempts, tt.maxAttempts, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"fmt"
+
+	"github.com/abneribeiro/goapi/internal/config"
+)
+
+// NewBroker returns the broker selected in cfg, or nil when none is.
+func NewBroker(cfg config.OutboxConfig) (Broker, error) {
+	switch cfg.Broker {
+	case "":
+		return nil, nil
+	case "memory":
+		return NewMemoryBroker(), nil
+	}
+	return nil, fmt.Errorf("unknown outbox broker %q", cfg.Broker)
+}
+
+// NewSinks returns the bus, then the broker if there is one, then a webhook
+// sink per configured URL.
+func NewSinks(cfg config.OutboxConfig, bus *Bus, broker Broker) []Sink {
+	sinks := []Sink{bus}
+	if broker != nil {
+		sinks = append(sinks, NewBrokerSink(broker, cfg.TopicPrefix))
+	}
+	for _, url := range cfg.WebhookURLs {
+		sinks = append(sinks, NewWebhookSink(url))
+	}
+	return sinks
+}
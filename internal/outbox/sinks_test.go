@@ -0,0 +1,164 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/config"
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func testEvent(eventType model.DomainEventType) *model.DomainEvent {
+	return &model.DomainEvent{
+		ID:            uuid.New(),
+		Sequence:      1,
+		Type:          eventType,
+		AggregateType: eventType.Aggregate(),
+		AggregateID:   uuid.New(),
+		Data:          json.RawMessage(`{}`),
+	}
+}
+
+func TestBus_Publish(t *testing.T) {
+	bus := NewBus()
+
+	var approved, all int
+	bus.Subscribe(func(ctx context.Context, event *model.DomainEvent) error {
+		approved++
+		return nil
+	}, model.EventReservationApproved)
+	bus.Subscribe(func(ctx context.Context, event *model.DomainEvent) error {
+		all++
+		return nil
+	})
+
+	bus.Publish(context.Background(), testEvent(model.EventReservationApproved))
+	bus.Publish(context.Background(), testEvent(model.EventEquipmentUpdated))
+
+	if approved != 1 {
+		t.Errorf("expected typed subscriber to see 1 event, got %d", approved)
+	}
+	if all != 2 {
+		t.Errorf("expected catch-all subscriber to see 2 events, got %d", all)
+	}
+}
+
+func TestBus_PublishReportsSubscriberErrors(t *testing.T) {
+	bus := NewBus()
+
+	called := false
+	bus.Subscribe(func(ctx context.Context, event *model.DomainEvent) error {
+		return errors.New("subscriber down")
+	})
+	bus.Subscribe(func(ctx context.Context, event *model.DomainEvent) error {
+		called = true
+		return nil
+	})
+
+	if err := bus.Publish(context.Background(), testEvent(model.EventReservationCreated)); err == nil {
+		t.Error("expected an error from the failing subscriber")
+	}
+	if !called {
+		t.Error("expected later subscribers to run after a failure")
+	}
+}
+
+func TestBrokerSink_Publish(t *testing.T) {
+	broker := NewMemoryBroker()
+	sink := NewBrokerSink(broker, "goapi")
+
+	var consumed []Message
+	broker.Consume("goapi.reservation", func(msg Message) {
+		consumed = append(consumed, msg)
+	})
+
+	event := testEvent(model.EventReservationApproved)
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := broker.Messages("goapi.reservation")
+	if len(messages) != 1 || len(consumed) != 1 {
+		t.Fatalf("expected 1 stored and 1 consumed message, got %d and %d", len(messages), len(consumed))
+	}
+
+	msg := messages[0]
+	if msg.Key != event.AggregateID.String() {
+		t.Errorf("expected key %s, got %s", event.AggregateID, msg.Key)
+	}
+	if msg.Headers["event-type"] != string(model.EventReservationApproved) {
+		t.Errorf("expected event-type header, got %v", msg.Headers)
+	}
+}
+
+func TestMemoryBroker_KeepsRecentMessages(t *testing.T) {
+	broker := NewMemoryBroker()
+	broker.limit = 3
+
+	consumed := 0
+	broker.Consume("goapi.reservation", func(Message) { consumed++ })
+
+	for i := range 5 {
+		msg := Message{Topic: "goapi.reservation", Key: strconv.Itoa(i)}
+		if err := broker.Publish(context.Background(), msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	messages := broker.Messages("goapi.reservation")
+	var keys []string
+	for _, msg := range messages {
+		keys = append(keys, msg.Key)
+	}
+	if want := []string{"2", "3", "4"}; !slices.Equal(keys, want) {
+		t.Errorf("expected messages %v, got %v", want, keys)
+	}
+	if consumed != 5 {
+		t.Errorf("expected every message to be consumed, got %d", consumed)
+	}
+}
+
+func TestWebhookSink_Publish(t *testing.T) {
+	var gotType string
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.Header.Get("X-Event-Type")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL)
+	event := testEvent(model.EventEquipmentCreated)
+
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotType != string(model.EventEquipmentCreated) {
+		t.Errorf("expected X-Event-Type %s, got %s", model.EventEquipmentCreated, gotType)
+	}
+
+	status = http.StatusInternalServerError
+	if err := sink.Publish(context.Background(), event); err == nil {
+		t.Error("expected an error for a 500 response")
+	}
+}
+
+func TestNewBroker(t *testing.T) {
+	if broker, err := NewBroker(config.OutboxConfig{}); broker != nil || err != nil {
+		t.Errorf("expected no broker, got %v, %v", broker, err)
+	}
+	if _, err := NewBroker(config.OutboxConfig{Broker: "memory"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := NewBroker(config.OutboxConfig{Broker: "carrier-pigeon"}); err == nil {
+		t.Error("expected an error for an unknown broker")
+	}
+}
@@ -0,0 +1,149 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+const (
+	FakeSignatureHeader = "X-Fake-Signature"
+
+	// FakeDeclineMethod is a payment method the fake provider always
+	// declines, for exercising failure paths locally.
+	FakeDeclineMethod = "tok_decline"
+)
+
+type fakePayment struct {
+	status     Status
+	authorized money.Money
+	captured   money.Money
+	refunded   money.Money
+}
+
+// FakeProvider is an in-memory provider for development and tests. Every
+// payment method except FakeDeclineMethod is accepted. Payments are lost
+// when the process restarts.
+type FakeProvider struct {
+	secret []byte
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		secret:   []byte(webhookSecret),
+		payments: make(map[string]*fakePayment),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error) {
+	if req.Amount.IsNegative() || req.Amount.IsZero() {
+		return nil, ErrInvalidAmount
+	}
+	if req.PaymentMethod == "" || req.PaymentMethod == FakeDeclineMethod {
+		return nil, fmt.Errorf("%w: card was declined", ErrDeclined)
+	}
+
+	reference := "fake_" + uuid.NewString()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.payments[reference] = &fakePayment{
+		status:     StatusAuthorized,
+		authorized: req.Amount,
+		captured:   money.New(0, req.Amount.Currency),
+		refunded:   money.New(0, req.Amount.Currency),
+	}
+
+	return &Result{Reference: reference, Status: StatusAuthorized}, nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, reference string, amount money.Money) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[reference]
+	if !ok {
+		return nil, ErrUnknownPayment
+	}
+	if payment.status != StatusAuthorized {
+		return nil, fmt.Errorf("%w: payment is %s", ErrInvalidState, payment.status)
+	}
+	if amount.Currency != payment.authorized.Currency || amount.IsZero() || amount.Amount > payment.authorized.Amount {
+		return nil, ErrInvalidAmount
+	}
+
+	payment.status = StatusCaptured
+	payment.captured = amount
+	return &Result{Reference: reference, Status: StatusCaptured}, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, reference string, amount money.Money) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[reference]
+	if !ok {
+		return nil, ErrUnknownPayment
+	}
+
+	switch payment.status {
+	case StatusAuthorized:
+		payment.status = StatusVoided
+		return &Result{Reference: reference, Status: StatusVoided}, nil
+	case StatusCaptured:
+		remaining := payment.captured.Amount - payment.refunded.Amount
+		if amount.Currency != payment.captured.Currency || amount.IsZero() || amount.Amount > remaining {
+			return nil, ErrInvalidAmount
+		}
+		payment.refunded.Amount += amount.Amount
+		status := StatusCaptured
+		if payment.refunded.Amount == payment.captured.Amount {
+			status = StatusRefunded
+			payment.status = StatusRefunded
+		}
+		return &Result{Reference: reference, Status: status}, nil
+	default:
+		return nil, fmt.Errorf("%w: payment is %s", ErrInvalidState, payment.status)
+	}
+}
+
+func (p *FakeProvider) ParseWebhook(payload []byte, header http.Header) (*Event, error) {
+	signature, err := hex.DecodeString(header.Get(FakeSignatureHeader))
+	if err != nil || !hmac.Equal(signature, p.sign(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	return &event, nil
+}
+
+// Signature returns the header value the fake provider expects for payload,
+// so webhooks can be simulated with curl or from tests.
+func (p *FakeProvider) Signature(payload []byte) string {
+	return hex.EncodeToString(p.sign(payload))
+}
+
+func (p *FakeProvider) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,115 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+func TestFakeProvider_AuthorizeCaptureRefund(t *testing.T) {
+	p := NewFakeProvider("secret")
+	ctx := context.Background()
+	amount := money.MustParse("100.00", "USD")
+
+	auth, err := p.Authorize(ctx, AuthorizeRequest{Reference: "r1", Amount: amount, PaymentMethod: "tok_visa"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth.Status != StatusAuthorized {
+		t.Errorf("expected authorized, got %s", auth.Status)
+	}
+
+	if _, err := p.Capture(ctx, auth.Reference, money.MustParse("150.00", "USD")); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("expected ErrInvalidAmount when capturing more than authorized, got %v", err)
+	}
+
+	if _, err := p.Capture(ctx, auth.Reference, amount); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	partial, err := p.Refund(ctx, auth.Reference, money.MustParse("40.00", "USD"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if partial.Status != StatusCaptured {
+		t.Errorf("expected captured after partial refund, got %s", partial.Status)
+	}
+
+	full, err := p.Refund(ctx, auth.Reference, money.MustParse("60.00", "USD"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if full.Status != StatusRefunded {
+		t.Errorf("expected refunded, got %s", full.Status)
+	}
+
+	if _, err := p.Refund(ctx, auth.Reference, money.MustParse("1.00", "USD")); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("expected ErrInvalidState, got %v", err)
+	}
+}
+
+func TestFakeProvider_RefundVoidsAuthorization(t *testing.T) {
+	p := NewFakeProvider("secret")
+	ctx := context.Background()
+	amount := money.MustParse("25.00", "EUR")
+
+	auth, _ := p.Authorize(ctx, AuthorizeRequest{Reference: "r1", Amount: amount, PaymentMethod: "tok_visa"})
+
+	result, err := p.Refund(ctx, auth.Reference, amount)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != StatusVoided {
+		t.Errorf("expected voided, got %s", result.Status)
+	}
+
+	if _, err := p.Capture(ctx, auth.Reference, amount); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("expected ErrInvalidState capturing a voided payment, got %v", err)
+	}
+}
+
+func TestFakeProvider_Decline(t *testing.T) {
+	p := NewFakeProvider("secret")
+
+	_, err := p.Authorize(context.Background(), AuthorizeRequest{
+		Reference:     "r1",
+		Amount:        money.MustParse("10.00", "USD"),
+		PaymentMethod: FakeDeclineMethod,
+	})
+	if !errors.Is(err, ErrDeclined) {
+		t.Errorf("expected ErrDeclined, got %v", err)
+	}
+}
+
+func TestFakeProvider_ParseWebhook(t *testing.T) {
+	p := NewFakeProvider("secret")
+	payload := []byte(`{"type":"payment.captured","reference":"fake_1","amount":{"amount":12.50,"currency":"USD"}}`)
+
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, p.Signature(payload))
+
+	event, err := p.ParseWebhook(payload, header)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Type != EventCaptured || event.Reference != "fake_1" || event.Amount.Amount != 1250 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	header.Set(FakeSignatureHeader, NewFakeProvider("other").Signature(payload))
+	if _, err := p.ParseWebhook(payload, header); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider("fake", "secret"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := NewProvider("acme", "secret"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("expected ErrUnknownProvider, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package payment
+
+import (
+	"errors"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+var ErrUnbalanced = errors.New("ledger transaction does not balance")
+
+type Account string
+
+const (
+	// AccountRenterCharges holds what renters paid, net of refunds.
+	AccountRenterCharges Account = "renter_charges"
+	AccountPlatformFees  Account = "platform_fees"
+	AccountTaxesPayable  Account = "taxes_payable"
+	AccountOwnerPayable  Account = "owner_payable"
+)
+
+type Direction string
+
+const (
+	Debit  Direction = "debit"
+	Credit Direction = "credit"
+)
+
+type Entry struct {
+	Account     Account
+	Direction   Direction
+	Amount      money.Money
+	Description string
+}
+
+// Split is how a renter charge is shared between the platform, the tax
+// authority and the owner.
+type Split struct {
+	Fees  money.Money
+	Taxes money.Money
+}
+
+// Balanced checks that entries are in a single currency and that debits
+// equal credits.
+func Balanced(entries []Entry) error {
+	if len(entries) == 0 {
+		return ErrUnbalanced
+	}
+
+	var balance int64
+	currency := entries[0].Amount.Currency
+	for _, e := range entries {
+		if e.Amount.Currency != currency || e.Amount.IsNegative() {
+			return ErrUnbalanced
+		}
+		switch e.Direction {
+		case Debit:
+			balance += e.Amount.Amount
+		case Credit:
+			balance -= e.Amount.Amount
+		default:
+			return ErrUnbalanced
+		}
+	}
+
+	if balance != 0 {
+		return ErrUnbalanced
+	}
+	return nil
+}
+
+// CaptureEntries records a captured renter charge: the full amount is
+// debited to renter charges and credited to fees, taxes and the owner.
+func CaptureEntries(amount money.Money, split Split) []Entry {
+	return postings(amount, split, Debit, Credit, "Rental charge captured")
+}
+
+// RefundEntries reverses part of a capture. Fees and taxes are given back
+// in proportion to the refunded share of total, and the owner covers the
+// rest.
+func RefundEntries(refund, total money.Money, split Split) []Entry {
+	scaled := Split{
+		Fees:  money.New(proportion(split.Fees.Amount, refund.Amount, total.Amount), refund.Currency),
+		Taxes: money.New(proportion(split.Taxes.Amount, refund.Amount, total.Amount), refund.Currency),
+	}
+	return postings(refund, scaled, Credit, Debit, "Rental charge refunded")
+}
+
+// DepositCaptureEntries records the part of a deposit kept by the owner.
+// It is all owed to the owner; no fees or taxes are taken from it.
+func DepositCaptureEntries(amount money.Money) []Entry {
+	return postings(amount, Split{}, Debit, Credit, "Deposit captured")
+}
+
+// DepositRefundEntries reverses part of a captured deposit.
+func DepositRefundEntries(amount money.Money) []Entry {
+	return postings(amount, Split{}, Credit, Debit, "Deposit refunded")
+}
+
+func postings(amount money.Money, split Split, renterSide, otherSide Direction, description string) []Entry {
+	owner := amount.Amount - split.Fees.Amount - split.Taxes.Amount
+
+	entries := []Entry{{Account: AccountRenterCharges, Direction: renterSide, Amount: amount, Description: description}}
+	for _, part := range []struct {
+		account Account
+		amount  int64
+	}{
+		{AccountPlatformFees, split.Fees.Amount},
+		{AccountTaxesPayable, split.Taxes.Amount},
+		{AccountOwnerPayable, owner},
+	} {
+		if part.amount == 0 {
+			continue
+		}
+		entries = append(entries, Entry{
+			Account:     part.account,
+			Direction:   otherSide,
+			Amount:      money.New(part.amount, amount.Currency),
+			Description: description,
+		})
+	}
+	return entries
+}
+
+func proportion(part, numerator, denominator int64) int64 {
+	if denominator == 0 {
+		return 0
+	}
+	return part * numerator / denominator
+}
@@ -0,0 +1,124 @@
+package payment
+
+import (
+	"testing"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+func usd(s string) money.Money {
+	return money.MustParse(s, "USD")
+}
+
+func totalFor(entries []Entry, account Account) int64 {
+	var total int64
+	for _, e := range entries {
+		if e.Account == account {
+			total += e.Amount.Amount
+		}
+	}
+	return total
+}
+
+func TestCaptureEntries(t *testing.T) {
+	entries := CaptureEntries(usd("118.80"), Split{Fees: usd("10.00"), Taxes: usd("8.80")})
+
+	if err := Balanced(entries); err != nil {
+		t.Fatalf("expected balanced entries: %v", err)
+	}
+
+	if got := totalFor(entries, AccountRenterCharges); got != 11880 {
+		t.Errorf("expected renter charge 11880, got %d", got)
+	}
+	if got := totalFor(entries, AccountPlatformFees); got != 1000 {
+		t.Errorf("expected platform fees 1000, got %d", got)
+	}
+	if got := totalFor(entries, AccountTaxesPayable); got != 880 {
+		t.Errorf("expected taxes 880, got %d", got)
+	}
+	if got := totalFor(entries, AccountOwnerPayable); got != 10000 {
+		t.Errorf("expected owner payable 10000, got %d", got)
+	}
+}
+
+func TestCaptureEntries_NoFees(t *testing.T) {
+	entries := CaptureEntries(usd("50.00"), Split{Fees: usd("0"), Taxes: usd("0")})
+
+	if len(entries) != 2 {
+		t.Errorf("expected 2 entries without fees or taxes, got %d", len(entries))
+	}
+	if err := Balanced(entries); err != nil {
+		t.Errorf("expected balanced entries: %v", err)
+	}
+}
+
+func TestRefundEntries(t *testing.T) {
+	split := Split{Fees: usd("10.00"), Taxes: usd("8.80")}
+	entries := RefundEntries(usd("59.40"), usd("118.80"), split)
+
+	if err := Balanced(entries); err != nil {
+		t.Fatalf("expected balanced entries: %v", err)
+	}
+
+	for _, e := range entries {
+		want := Debit
+		if e.Account == AccountRenterCharges {
+			want = Credit
+		}
+		if e.Direction != want {
+			t.Errorf("expected %s to be a %s, got %s", e.Account, want, e.Direction)
+		}
+	}
+
+	if got := totalFor(entries, AccountPlatformFees); got != 500 {
+		t.Errorf("expected half the fees refunded, got %d", got)
+	}
+	if got := totalFor(entries, AccountOwnerPayable); got != 5000 {
+		t.Errorf("expected owner to cover 5000, got %d", got)
+	}
+}
+
+func TestDepositEntries(t *testing.T) {
+	captured := DepositCaptureEntries(usd("75.00"))
+	refunded := DepositRefundEntries(usd("25.00"))
+
+	for name, entries := range map[string][]Entry{"capture": captured, "refund": refunded} {
+		if err := Balanced(entries); err != nil {
+			t.Errorf("%s: expected balanced entries: %v", name, err)
+		}
+		if totalFor(entries, AccountPlatformFees) != 0 || totalFor(entries, AccountTaxesPayable) != 0 {
+			t.Errorf("%s: expected no fees or taxes on a deposit", name)
+		}
+	}
+
+	if got := totalFor(captured, AccountOwnerPayable); got != 7500 {
+		t.Errorf("expected owner payable 7500, got %d", got)
+	}
+	for _, e := range refunded {
+		if e.Account == AccountRenterCharges && e.Direction != Credit {
+			t.Error("expected the refund to credit renter charges")
+		}
+	}
+}
+
+func TestBalanced(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+		wantErr bool
+	}{
+		{"empty", nil, true},
+		{"balanced", []Entry{{Direction: Debit, Amount: usd("5")}, {Direction: Credit, Amount: usd("5")}}, false},
+		{"unbalanced", []Entry{{Direction: Debit, Amount: usd("5")}, {Direction: Credit, Amount: usd("4")}}, true},
+		{"mixed currency", []Entry{{Direction: Debit, Amount: usd("5")}, {Direction: Credit, Amount: money.MustParse("5", "EUR")}}, true},
+		{"unknown direction", []Entry{{Direction: "sideways", Amount: usd("0")}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Balanced(tt.entries); (err != nil) != tt.wantErr {
+				t.Errorf("Balanced() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
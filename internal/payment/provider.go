@@ -0,0 +1,79 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+var (
+	ErrDeclined         = errors.New("payment declined")
+	ErrUnknownPayment   = errors.New("unknown payment reference")
+	ErrInvalidAmount    = errors.New("invalid payment amount")
+	ErrInvalidState     = errors.New("payment cannot be changed in its current state")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrUnknownProvider  = errors.New("unknown payment provider")
+)
+
+type Status string
+
+const (
+	StatusAuthorized Status = "authorized"
+	StatusCaptured   Status = "captured"
+	StatusVoided     Status = "voided"
+	StatusRefunded   Status = "refunded"
+	StatusFailed     Status = "failed"
+)
+
+type EventType string
+
+const (
+	EventCaptured EventType = "payment.captured"
+	EventRefunded EventType = "payment.refunded"
+	EventFailed   EventType = "payment.failed"
+)
+
+type AuthorizeRequest struct {
+	// Reference identifies the payment on our side, typically the
+	// reservation ID, and is echoed back in provider dashboards.
+	Reference     string
+	Amount        money.Money
+	PaymentMethod string
+}
+
+type Result struct {
+	Reference string
+	Status    Status
+}
+
+// Event is a provider notification about a payment that changed outside
+// of our own calls, for example a capture made from the provider dashboard.
+type Event struct {
+	Type      EventType   `json:"type"`
+	Reference string      `json:"reference"`
+	Amount    money.Money `json:"amount"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// PaymentProvider is implemented by each payment gateway. Refunding a
+// payment that was only authorized releases the authorization instead of
+// moving money.
+type PaymentProvider interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, reference string, amount money.Money) (*Result, error)
+	Refund(ctx context.Context, reference string, amount money.Money) (*Result, error)
+	ParseWebhook(payload []byte, header http.Header) (*Event, error)
+}
+
+func NewProvider(name, webhookSecret string) (PaymentProvider, error) {
+	switch name {
+	case "fake":
+		return NewFakeProvider(webhookSecret), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+}
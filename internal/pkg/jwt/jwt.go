@@ -18,12 +18,21 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// PurposeStream marks a token that only opens a live connection. Browsers
+// cannot set headers on WebSocket or EventSource requests, so these tokens
+// travel in the URL and are kept short-lived.
+const PurposeStream = "stream"
+
+// StreamTokenTTL is how long a stream token can be used to connect.
+const StreamTokenTTL = time.Minute
+
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
-	Role   string    `json:"role"`
-	Exp    int64     `json:"exp"`
-	Iat    int64     `json:"iat"`
+	UserID  uuid.UUID `json:"user_id"`
+	Email   string    `json:"email"`
+	Role    string    `json:"role"`
+	Purpose string    `json:"purpose,omitempty"`
+	Exp     int64     `json:"exp"`
+	Iat     int64     `json:"iat"`
 }
 
 type Manager struct {
@@ -40,14 +49,32 @@ func NewManager(secret string, expiration time.Duration) *Manager {
 
 func (m *Manager) Generate(userID uuid.UUID, email, role string) (string, error) {
 	now := time.Now()
-	claims := Claims{
+	return m.encode(Claims{
 		UserID: userID,
 		Email:  email,
 		Role:   role,
 		Iat:    now.Unix(),
 		Exp:    now.Add(m.expiration).Unix(),
-	}
+	})
+}
+
+// GenerateStreamToken issues a stream token for the user of claims, valid
+// for StreamTokenTTL.
+func (m *Manager) GenerateStreamToken(claims *Claims) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(StreamTokenTTL).Truncate(time.Second)
+	token, err := m.encode(Claims{
+		UserID:  claims.UserID,
+		Email:   claims.Email,
+		Role:    claims.Role,
+		Purpose: PurposeStream,
+		Iat:     now.Unix(),
+		Exp:     expiresAt.Unix(),
+	})
+	return token, expiresAt, err
+}
 
+func (m *Manager) encode(claims Claims) (string, error) {
 	header := map[string]string{
 		"alg": "HS256",
 		"typ": "JWT",
@@ -90,3 +90,32 @@ func TestManager_ValidateWrongSecret(t *testing.T) {
 		t.Errorf("expected ErrInvalidToken, got %v", err)
 	}
 }
+
+func TestManager_GenerateStreamToken(t *testing.T) {
+	manager := NewManager("test-secret", 24*time.Hour)
+	userID := uuid.New()
+
+	token, expiresAt, err := manager.GenerateStreamToken(&Claims{UserID: userID, Email: "test@example.com", Role: "renter"})
+	if err != nil {
+		t.Fatalf("failed to generate stream token: %v", err)
+	}
+
+	if ttl := time.Until(expiresAt); ttl > StreamTokenTTL || ttl < StreamTokenTTL-2*time.Second {
+		t.Errorf("expected stream token to expire in %v, got %v", StreamTokenTTL, ttl)
+	}
+
+	claims, err := manager.Validate(token)
+	if err != nil {
+		t.Fatalf("failed to validate stream token: %v", err)
+	}
+
+	if claims.Purpose != PurposeStream {
+		t.Errorf("expected purpose %q, got %q", PurposeStream, claims.Purpose)
+	}
+	if claims.UserID != userID {
+		t.Errorf("expected user ID %s, got %s", userID, claims.UserID)
+	}
+	if claims.Exp != expiresAt.Unix() {
+		t.Errorf("expected exp %d, got %d", expiresAt.Unix(), claims.Exp)
+	}
+}
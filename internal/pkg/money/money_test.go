@@ -0,0 +1,151 @@
+package money
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		currency string
+		want     int64
+		wantErr  bool
+	}{
+		{"12.50", "USD", 1250, false},
+		{"12.5", "USD", 1250, false},
+		{"12", "USD", 1200, false},
+		{".99", "USD", 99, false},
+		{"-3.10", "EUR", -310, false},
+		{"0.1", "USD", 10, false},
+		{"1500", "JPY", 1500, false},
+		{"1.234", "KWD", 1234, false},
+		{"1.230000", "USD", 123, false},
+		{"1.234", "USD", 0, true},
+		{"1.5", "JPY", 0, true},
+		{"abc", "USD", 0, true},
+		{"", "USD", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input+" "+tt.currency, func(t *testing.T) {
+			got, err := Parse(tt.input, tt.currency)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Amount != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.input, got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{New(1250, "USD"), "12.50"},
+		{New(5, "USD"), "0.05"},
+		{New(-5, "USD"), "-0.05"},
+		{New(0, "USD"), "0.00"},
+		{New(1500, "JPY"), "1500"},
+		{New(1234, "KWD"), "1.234"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestFromFloat_AvoidsDrift(t *testing.T) {
+	if got := FromFloat(0.1+0.2, "USD"); got.Amount != 30 {
+		t.Errorf("expected 30 cents, got %d", got.Amount)
+	}
+	if got := FromFloat(19.99, "USD"); got.Amount != 1999 {
+		t.Errorf("expected 1999 cents, got %d", got.Amount)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a := New(1000, "USD")
+
+	sum, err := a.Add(New(250, "USD"))
+	if err != nil || sum.Amount != 1250 {
+		t.Errorf("Add = %v, %v", sum, err)
+	}
+
+	if _, err := a.Add(New(250, "EUR")); err != ErrCurrencyMismatch {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+
+	if got := a.Multiply(3); got.Amount != 3000 {
+		t.Errorf("Multiply = %d", got.Amount)
+	}
+
+	if got := New(999, "USD").Percent(10); got.Amount != 100 {
+		t.Errorf("Percent = %d, want 100", got.Amount)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(New(1250, "EUR"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"amount":12.50,"currency":"EUR","formatted":"12.50 EUR"}`
+	if string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+
+	var m Money
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 1250 || m.Currency != "EUR" {
+		t.Errorf("Unmarshal = %+v", m)
+	}
+}
+
+func TestRates_Convert(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	os.WriteFile(path, []byte(`{"base": "USD", "rates": {"EUR": 0.5, "JPY": 150}}`), 0o644)
+
+	rates, err := LoadRates(path)
+	if err != nil {
+		t.Fatalf("LoadRates: %v", err)
+	}
+
+	got, err := rates.Convert(New(1000, "USD"), "EUR")
+	if err != nil || got.Amount != 500 || got.Currency != "EUR" {
+		t.Errorf("USD->EUR = %+v, %v", got, err)
+	}
+
+	got, err = rates.Convert(New(500, "EUR"), "JPY")
+	if err != nil || got.Amount != 1500 {
+		t.Errorf("EUR->JPY = %+v, %v", got, err)
+	}
+
+	if _, err := rates.Convert(New(100, "USD"), "GBP"); err == nil {
+		t.Error("expected error for missing rate")
+	}
+
+	var none *Rates
+	if got, err := none.Convert(New(100, "USD"), "USD"); err != nil || got.Amount != 100 {
+		t.Errorf("identity conversion without rates = %+v, %v", got, err)
+	}
+}
+
+func TestLoadRates_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	os.WriteFile(path, []byte(`{"base": "XXX", "rates": {}}`), 0o644)
+
+	if _, err := LoadRates(path); err == nil {
+		t.Error("expected error for unknown base currency")
+	}
+}
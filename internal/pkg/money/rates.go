@@ -0,0 +1,81 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Rates converts between currencies for display. Each rate is the number of
+// units of that currency per one unit of Base.
+type Rates struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+func LoadRates(path string) (*Rates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates Rates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("failed to parse exchange rates: %w", err)
+	}
+
+	if !ValidCurrency(rates.Base) {
+		return nil, fmt.Errorf("%w: base %q", ErrUnknownCurrency, rates.Base)
+	}
+	for code, rate := range rates.Rates {
+		if !ValidCurrency(code) || rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate for %q", code)
+		}
+	}
+
+	return &rates, nil
+}
+
+func (r *Rates) rate(currency string) (float64, bool) {
+	if r == nil {
+		return 0, false
+	}
+	if currency == r.Base {
+		return 1, true
+	}
+	rate, ok := r.Rates[currency]
+	return rate, ok
+}
+
+func (r *Rates) Supports(currency string) bool {
+	_, ok := r.rate(currency)
+	return ok
+}
+
+func (r *Rates) Convert(m Money, to string) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	from, ok := r.rate(m.Currency)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: no rate for %s", ErrUnknownCurrency, m.Currency)
+	}
+	target, ok := r.rate(to)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: no rate for %s", ErrUnknownCurrency, to)
+	}
+
+	return FromFloat(m.Float()/from*target, to), nil
+}
+
+func (r *Rates) ConvertPtr(m *Money, to string) (*Money, error) {
+	if m == nil {
+		return nil, nil
+	}
+	converted, err := r.Convert(*m, to)
+	if err != nil {
+		return nil, err
+	}
+	return &converted, nil
+}
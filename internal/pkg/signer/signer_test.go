@@ -0,0 +1,79 @@
+package signer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type payload struct {
+	ID    string  `json:"id"`
+	Total float64 `json:"total"`
+}
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	s := NewSigner("test-secret", time.Minute)
+
+	token, expiresAt, err := s.Sign(payload{ID: "abc", Total: 42.5})
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	if time.Until(expiresAt) > time.Minute || time.Until(expiresAt) < 58*time.Second {
+		t.Errorf("unexpected expiry %v", expiresAt)
+	}
+
+	var got payload
+	if err := s.Verify(token, &got); err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+
+	if got.ID != "abc" || got.Total != 42.5 {
+		t.Errorf("unexpected payload %+v", got)
+	}
+}
+
+func TestSigner_Verify_Tampered(t *testing.T) {
+	s := NewSigner("test-secret", time.Minute)
+
+	token, _, _ := s.Sign(payload{ID: "abc", Total: 42.5})
+	other, _, _ := s.Sign(payload{ID: "abc", Total: 1})
+
+	forged := strings.Split(other, ".")[0] + "." + strings.Split(token, ".")[1]
+
+	var got payload
+	if err := s.Verify(forged, &got); err != ErrInvalidToken {
+		t.Errorf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestSigner_Verify_WrongSecret(t *testing.T) {
+	token, _, _ := NewSigner("secret-1", time.Minute).Sign(payload{ID: "abc"})
+
+	var got payload
+	if err := NewSigner("secret-2", time.Minute).Verify(token, &got); err != ErrInvalidToken {
+		t.Errorf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestSigner_Verify_Expired(t *testing.T) {
+	s := NewSigner("test-secret", -2*time.Second)
+
+	token, _, _ := s.Sign(payload{ID: "abc"})
+
+	var got payload
+	if err := s.Verify(token, &got); err != ErrExpiredToken {
+		t.Errorf("expected ErrExpiredToken, got %v", err)
+	}
+}
+
+func TestSigner_Verify_Malformed(t *testing.T) {
+	s := NewSigner("test-secret", time.Minute)
+
+	var got payload
+	for _, token := range []string{"", "abc", "a.b.c", "!!!.???"} {
+		if err := s.Verify(token, &got); err != ErrInvalidToken {
+			t.Errorf("expected ErrInvalidToken for %q, got %v", token, err)
+		}
+	}
+}
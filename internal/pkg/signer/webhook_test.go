@@ -0,0 +1,41 @@
+package signer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWebhookSignature_Verify(t *testing.T) {
+	body := []byte(`{"type":"reservation.approved"}`)
+	now := time.Unix(1700000000, 0)
+
+	header := WebhookSignature("whsec_test", now, body)
+
+	if err := VerifyWebhookSignature("whsec_test", header, body, 5*time.Minute, now.Add(time.Minute)); err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		header   string
+		body     []byte
+		now      time.Time
+		expected error
+	}{
+		{"wrong secret", "other", header, body, now, ErrInvalidToken},
+		{"tampered body", "whsec_test", header, []byte(`{}`), now, ErrInvalidToken},
+		{"malformed header", "whsec_test", "v1=abc", body, now, ErrInvalidToken},
+		{"too old", "whsec_test", header, body, now.Add(10 * time.Minute), ErrExpiredToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyWebhookSignature(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+func Make(value string) string {
+	var b strings.Builder
+	lastDash := true
+
+	for _, r := range strings.ToLower(strings.TrimSpace(value)) {
+		switch {
+		case r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			lastDash = false
+		case !lastDash:
+			b.WriteByte('-')
+			lastDash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+func Valid(value string) bool {
+	return value != "" && Make(value) == value
+}
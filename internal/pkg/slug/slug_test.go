@@ -0,0 +1,48 @@
+package slug
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"simple word", "Excavator", "excavator"},
+		{"trailing whitespace", "Excavators ", "excavators"},
+		{"multiple words", "Audio / Video", "audio-video"},
+		{"leading symbols", "--Power Tools!", "power-tools"},
+		{"digits kept", "3D Printers", "3d-printers"},
+		{"empty string", "   ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Make(tt.value); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"power-tools", true},
+		{"excavator", true},
+		{"Power-Tools", false},
+		{"power--tools", false},
+		{"-power", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := Valid(tt.value); got != tt.want {
+				t.Errorf("expected %v for %q, got %v", tt.want, tt.value, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package timeslot
+
+import (
+	"sort"
+	"time"
+
+	_ "time/tzdata"
+)
+
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+func (i Interval) Overlaps(other Interval) bool {
+	return i.Start.Before(other.End) && i.End.After(other.Start)
+}
+
+func (i Interval) Clip(window Interval) (Interval, bool) {
+	if i.Start.Before(window.Start) {
+		i.Start = window.Start
+	}
+	if i.End.After(window.End) {
+		i.End = window.End
+	}
+	return i, i.Start.Before(i.End)
+}
+
+// Merge sorts intervals and joins any that overlap or touch.
+func Merge(intervals []Interval) []Interval {
+	if len(intervals) == 0 {
+		return nil
+	}
+
+	sorted := make([]Interval, len(intervals))
+	copy(sorted, intervals)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	merged := []Interval{sorted[0]}
+	for _, next := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if next.Start.After(last.End) {
+			merged = append(merged, next)
+			continue
+		}
+		if next.End.After(last.End) {
+			last.End = next.End
+		}
+	}
+
+	return merged
+}
+
+// Subtract returns the parts of window not covered by any busy interval.
+func Subtract(window Interval, busy []Interval) []Interval {
+	var free []Interval
+	cursor := window.Start
+
+	for _, b := range Merge(busy) {
+		b, ok := b.Clip(window)
+		if !ok {
+			continue
+		}
+		if b.Start.After(cursor) {
+			free = append(free, Interval{Start: cursor, End: b.Start})
+		}
+		if b.End.After(cursor) {
+			cursor = b.End
+		}
+	}
+
+	if cursor.Before(window.End) {
+		free = append(free, Interval{Start: cursor, End: window.End})
+	}
+
+	return free
+}
+
+type Load struct {
+	Interval
+	Quantity int
+}
+
+// Saturated returns the intervals during which the summed quantity of the
+// loads reaches capacity.
+func Saturated(loads []Load, capacity int) []Interval {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+
+	events := make([]event, 0, len(loads)*2)
+	for _, l := range loads {
+		events = append(events, event{l.Start, l.Quantity}, event{l.End, -l.Quantity})
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at.Equal(events[j].at) {
+			return events[i].delta < events[j].delta
+		}
+		return events[i].at.Before(events[j].at)
+	})
+
+	var saturated []Interval
+	var openedAt time.Time
+	current := 0
+	for _, e := range events {
+		wasFull := current >= capacity
+		current += e.delta
+		isFull := current >= capacity
+		switch {
+		case !wasFull && isFull:
+			openedAt = e.at
+		case wasFull && !isFull && e.at.After(openedAt):
+			saturated = append(saturated, Interval{Start: openedAt, End: e.at})
+		}
+	}
+
+	return Merge(saturated)
+}
+
+type DailyHours struct {
+	Weekday time.Weekday
+	Opens   time.Duration
+	Closes  time.Duration
+}
+
+// OpenIntervals expands weekly opening hours into absolute intervals within
+// window. Hours are wall-clock offsets from local midnight in loc, so opening
+// times stay fixed across daylight saving changes. No hours means always open.
+func OpenIntervals(hours []DailyHours, window Interval, loc *time.Location) []Interval {
+	if len(hours) == 0 {
+		return []Interval{window}
+	}
+
+	var open []Interval
+	start := window.Start.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+
+	for ; day.Before(window.End); day = day.AddDate(0, 0, 1) {
+		for _, h := range hours {
+			if h.Weekday != day.Weekday() {
+				continue
+			}
+			interval := Interval{
+				Start: wallClock(day, h.Opens, loc),
+				End:   wallClock(day, h.Closes, loc),
+			}
+			if clipped, ok := interval.Clip(window); ok {
+				open = append(open, clipped)
+			}
+		}
+	}
+
+	return Merge(open)
+}
+
+func wallClock(day time.Time, offset time.Duration, loc *time.Location) time.Time {
+	hours := int(offset / time.Hour)
+	minutes := int((offset % time.Hour) / time.Minute)
+	return time.Date(day.Year(), day.Month(), day.Day(), hours, minutes, 0, 0, loc)
+}
+
+// Slots returns every interval of the given duration that fits inside a free
+// interval, starting at multiples of step from the beginning of each one.
+func Slots(free []Interval, duration, step time.Duration) []Interval {
+	if duration <= 0 || step <= 0 {
+		return nil
+	}
+
+	var slots []Interval
+	for _, f := range free {
+		for start := f.Start; !start.Add(duration).After(f.End); start = start.Add(step) {
+			slots = append(slots, Interval{Start: start, End: start.Add(duration)})
+		}
+	}
+
+	return slots
+}
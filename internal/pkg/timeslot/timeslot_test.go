@@ -0,0 +1,98 @@
+package timeslot
+
+import (
+	"testing"
+	"time"
+)
+
+var base = time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC) // Monday
+
+func at(hour int) time.Time {
+	return base.Add(time.Duration(hour) * time.Hour)
+}
+
+func span(from, to int) Interval {
+	return Interval{Start: at(from), End: at(to)}
+}
+
+func equalIntervals(t *testing.T, got, want []Interval) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d intervals, got %d: %v", len(want), len(got), got)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("interval %d: expected %v-%v, got %v-%v", i, want[i].Start, want[i].End, got[i].Start, got[i].End)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	got := Merge([]Interval{span(10, 12), span(1, 3), span(2, 5), span(5, 6)})
+	equalIntervals(t, got, []Interval{span(1, 6), span(10, 12)})
+}
+
+func TestSubtract(t *testing.T) {
+	got := Subtract(span(8, 18), []Interval{span(9, 11), span(10, 12), span(17, 20)})
+	equalIntervals(t, got, []Interval{span(8, 9), span(12, 17)})
+}
+
+func TestSubtract_NoBusy(t *testing.T) {
+	equalIntervals(t, Subtract(span(8, 18), nil), []Interval{span(8, 18)})
+}
+
+func TestSaturated(t *testing.T) {
+	loads := []Load{
+		{span(9, 12), 1},
+		{span(10, 14), 1},
+		{span(14, 16), 2},
+	}
+
+	equalIntervals(t, Saturated(loads, 2), []Interval{span(10, 12), span(14, 16)})
+	equalIntervals(t, Saturated(loads, 1), []Interval{span(9, 16)})
+	if got := Saturated(loads, 3); len(got) != 0 {
+		t.Errorf("expected no saturated intervals, got %v", got)
+	}
+}
+
+func TestOpenIntervals(t *testing.T) {
+	hours := []DailyHours{
+		{Weekday: time.Monday, Opens: 9 * time.Hour, Closes: 17 * time.Hour},
+		{Weekday: time.Tuesday, Opens: 9*time.Hour + 30*time.Minute, Closes: 12 * time.Hour},
+	}
+
+	got := OpenIntervals(hours, span(0, 72), time.UTC)
+	equalIntervals(t, got, []Interval{
+		span(9, 17),
+		{Start: at(33).Add(30 * time.Minute), End: at(36)},
+	})
+}
+
+func TestOpenIntervals_TimeZone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	hours := []DailyHours{{Weekday: time.Monday, Opens: 9 * time.Hour, Closes: 17 * time.Hour}}
+	got := OpenIntervals(hours, span(0, 48), loc)
+
+	// 09:00-17:00 EST on Monday 2024-03-04 is 14:00-22:00 UTC.
+	equalIntervals(t, got, []Interval{span(14, 22)})
+}
+
+func TestOpenIntervals_NoHours(t *testing.T) {
+	equalIntervals(t, OpenIntervals(nil, span(0, 24), time.UTC), []Interval{span(0, 24)})
+}
+
+func TestSlots(t *testing.T) {
+	free := []Interval{span(9, 12), {Start: at(14), End: at(15).Add(30 * time.Minute)}}
+
+	got := Slots(free, 2*time.Hour, time.Hour)
+	equalIntervals(t, got, []Interval{span(9, 11), span(10, 12)})
+
+	got = Slots(free, time.Hour, 30*time.Minute)
+	if len(got) != 7 {
+		t.Errorf("expected 7 slots, got %d", len(got))
+	}
+}
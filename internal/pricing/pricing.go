@@ -0,0 +1,531 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+var (
+	ErrNoRates       = errors.New("equipment has no price configured")
+	ErrInvalidPeriod = errors.New("end must be after start")
+	ErrMixedCurrency = errors.New("rates use different currencies")
+)
+
+type RuleType string
+
+const (
+	RuleWeekendSurcharge   RuleType = "weekend_surcharge"
+	RuleSeasonal           RuleType = "seasonal"
+	RuleLongRentalDiscount RuleType = "long_rental_discount"
+	RuleMinimumCharge      RuleType = "minimum_charge"
+	RuleLateFee            RuleType = "late_fee"
+)
+
+type Rule struct {
+	Type     RuleType
+	Name     string
+	Percent  float64
+	Amount   int64
+	MinDays  int
+	StartsOn time.Time
+	EndsOn   time.Time
+}
+
+type Rates struct {
+	Hour *money.Money
+	Day  *money.Money
+	Week *money.Money
+}
+
+func (r Rates) currency() (string, error) {
+	currency := ""
+	for _, rate := range []*money.Money{r.Hour, r.Day, r.Week} {
+		if rate == nil {
+			continue
+		}
+		if currency != "" && rate.Currency != currency {
+			return "", ErrMixedCurrency
+		}
+		currency = rate.Currency
+	}
+	if currency == "" {
+		return "", ErrNoRates
+	}
+	return currency, nil
+}
+
+// Period is the rental being priced. Currency is filled in by the engine
+// from the rates so evaluators can describe amounts.
+type Period struct {
+	Start    time.Time
+	End      time.Time
+	Quantity int
+	Location *time.Location
+	Currency string
+}
+
+func (p Period) Hours() int {
+	return int(math.Ceil(p.End.Sub(p.Start).Hours()))
+}
+
+func (p Period) Days() int {
+	return int(math.Ceil(p.End.Sub(p.Start).Hours() / 24))
+}
+
+// Portion returns the fraction of the period falling on local calendar days
+// for which match returns true.
+func (p Period) Portion(match func(day time.Time) bool) float64 {
+	total := p.End.Sub(p.Start)
+	if total <= 0 {
+		return 0
+	}
+
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	start := p.Start.In(loc)
+	var matched time.Duration
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Before(p.End); day = day.AddDate(0, 0, 1) {
+		if !match(day) {
+			continue
+		}
+		from, to := day, day.AddDate(0, 0, 1)
+		if from.Before(p.Start) {
+			from = p.Start
+		}
+		if to.After(p.End) {
+			to = p.End
+		}
+		matched += to.Sub(from)
+	}
+
+	return float64(matched) / float64(total)
+}
+
+type LineItem struct {
+	Kind        string       `json:"kind"`
+	Description string       `json:"description"`
+	Quantity    int          `json:"quantity,omitempty"`
+	UnitPrice   *money.Money `json:"unit_price,omitempty"`
+	Amount      money.Money  `json:"amount"`
+}
+
+// Breakdown itemizes a price. Subtotal is the rental price after seasonal
+// and weekend adjustments but before discounts and minimum charges: adding
+// the discount and minimum lines that follow it, then Fees and Taxes, gives
+// Total.
+type Breakdown struct {
+	Currency string      `json:"currency"`
+	Items    []LineItem  `json:"items"`
+	Subtotal money.Money `json:"subtotal"`
+	Fees     money.Money `json:"fees"`
+	Taxes    money.Money `json:"taxes"`
+	Total    money.Money `json:"total"`
+}
+
+// Charges are platform-wide percentages added on top of the rental price
+// once every rule has been applied. Tax is charged on the fee as well.
+type Charges struct {
+	ServiceFeePercent float64
+	TaxPercent        float64
+}
+
+// Line is a breakdown entry in minor units; evaluators work in minor units
+// so that rounding happens once per line rather than on the running total.
+type Line struct {
+	Kind        string
+	Description string
+	Quantity    int
+	UnitPrice   int64
+	Amount      int64
+}
+
+type Stage int
+
+const (
+	StageAdjustment Stage = iota
+	StageDiscount
+	StageMinimum
+)
+
+// Evaluator prices one rule type. base is the best rate-only price and
+// running is the total so far, including earlier stages.
+type Evaluator interface {
+	Stage() Stage
+	Evaluate(period Period, rules []Rule, base, running int64) []Line
+}
+
+type registration struct {
+	ruleType  RuleType
+	evaluator Evaluator
+}
+
+// Engine evaluates rules stage by stage; within a stage, rule types run in
+// the order they were registered.
+type Engine struct {
+	evaluators []registration
+	charges    Charges
+}
+
+func NewEngine() *Engine {
+	e := &Engine{}
+	e.Register(RuleSeasonal, seasonalEvaluator{})
+	e.Register(RuleWeekendSurcharge, weekendEvaluator{})
+	e.Register(RuleLongRentalDiscount, longRentalEvaluator{})
+	e.Register(RuleMinimumCharge, minimumChargeEvaluator{})
+	return e
+}
+
+func (e *Engine) WithCharges(charges Charges) *Engine {
+	e.charges = charges
+	return e
+}
+
+func (e *Engine) Register(ruleType RuleType, evaluator Evaluator) {
+	for i := range e.evaluators {
+		if e.evaluators[i].ruleType == ruleType {
+			e.evaluators[i].evaluator = evaluator
+			return
+		}
+	}
+	e.evaluators = append(e.evaluators, registration{ruleType: ruleType, evaluator: evaluator})
+}
+
+func (e *Engine) Supports(ruleType RuleType) bool {
+	for _, reg := range e.evaluators {
+		if reg.ruleType == ruleType {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Engine) Calculate(rates Rates, rules []Rule, period Period) (*Breakdown, error) {
+	if !period.End.After(period.Start) {
+		return nil, ErrInvalidPeriod
+	}
+	if period.Quantity < 1 {
+		period.Quantity = 1
+	}
+
+	currency, err := rates.currency()
+	if err != nil {
+		return nil, err
+	}
+	period.Currency = currency
+
+	lines, err := baseLines(rates, period)
+	if err != nil {
+		return nil, err
+	}
+
+	var base int64
+	for _, l := range lines {
+		base += l.Amount
+	}
+
+	byType := make(map[RuleType][]Rule)
+	for _, r := range rules {
+		byType[r.Type] = append(byType[r.Type], r)
+	}
+
+	running := base
+	var subtotal int64
+	for _, stage := range []Stage{StageAdjustment, StageDiscount, StageMinimum} {
+		if stage == StageDiscount {
+			subtotal = running
+		}
+		for _, reg := range e.evaluators {
+			if reg.evaluator.Stage() != stage || len(byType[reg.ruleType]) == 0 {
+				continue
+			}
+			for _, l := range reg.evaluator.Evaluate(period, byType[reg.ruleType], base, running) {
+				lines = append(lines, l)
+				running += l.Amount
+			}
+		}
+	}
+
+	var fees, taxes int64
+	if e.charges.ServiceFeePercent > 0 {
+		fees = percentOf(float64(running), e.charges.ServiceFeePercent)
+		lines = append(lines, Line{Kind: "service_fee", Description: "Service fee", Amount: fees})
+	}
+	if e.charges.TaxPercent > 0 {
+		taxes = percentOf(float64(running+fees), e.charges.TaxPercent)
+		lines = append(lines, Line{Kind: "tax", Description: "Tax", Amount: taxes})
+	}
+
+	breakdown := &Breakdown{
+		Currency: currency,
+		Items:    make([]LineItem, len(lines)),
+		Subtotal: money.New(subtotal, currency),
+		Fees:     money.New(fees, currency),
+		Taxes:    money.New(taxes, currency),
+		Total:    money.New(running+fees+taxes, currency),
+	}
+	for i, l := range lines {
+		breakdown.Items[i] = LineItem{
+			Kind:        l.Kind,
+			Description: l.Description,
+			Quantity:    l.Quantity,
+			Amount:      money.New(l.Amount, currency),
+		}
+		if l.UnitPrice != 0 {
+			unitPrice := money.New(l.UnitPrice, currency)
+			breakdown.Items[i].UnitPrice = &unitPrice
+		}
+	}
+
+	return breakdown, nil
+}
+
+// LateFee prices time kept past the end of a rental. The overdue period is
+// priced at the listing's rates and scaled by the late_fee rule's percent,
+// plus its flat amount; without a rule it is charged at the normal rate.
+// When several rules apply, the highest fee wins. Late fees are not
+// subject to platform charges.
+func LateFee(rates Rates, rules []Rule, period Period) (money.Money, error) {
+	if !period.End.After(period.Start) {
+		return money.Money{}, ErrInvalidPeriod
+	}
+	if period.Quantity < 1 {
+		period.Quantity = 1
+	}
+
+	currency, err := rates.currency()
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	lines, err := baseLines(rates, period)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	var base int64
+	for _, l := range lines {
+		base += l.Amount
+	}
+
+	fee := int64(-1)
+	for _, r := range rules {
+		if r.Type != RuleLateFee {
+			continue
+		}
+		if amount := percentOf(float64(base), r.Percent) + r.Amount; amount > fee {
+			fee = amount
+		}
+	}
+	if fee < 0 {
+		fee = base
+	}
+
+	return money.New(fee, currency), nil
+}
+
+// baseLines picks the cheapest mix of weekly, daily and hourly rates that
+// covers the rental, so a six-day rental uses the weekly rate when that is
+// cheaper than six days. Only the counts from splits are tried for each
+// unit, so the cost does not grow with the length of the rental.
+func baseLines(rates Rates, period Period) ([]Line, error) {
+	hourRate, hasHour := minorUnits(rates.Hour)
+	dayRate, hasDay := minorUnits(rates.Day)
+	weekRate, hasWeek := minorUnits(rates.Week)
+	if !hasHour && !hasDay && !hasWeek {
+		return nil, ErrNoRates
+	}
+
+	hours := period.Hours()
+	best := struct {
+		weeks, days, hours int
+		cost               int64
+	}{cost: -1}
+
+	weekCounts := []int{0}
+	if hasWeek {
+		weekCounts = splits(hours, 168)
+	}
+	for _, w := range weekCounts {
+		remaining := max(hours-w*168, 0)
+
+		dayCounts := []int{0}
+		if hasDay {
+			dayCounts = splits(remaining, 24)
+		}
+		for _, d := range dayCounts {
+			h := max(remaining-d*24, 0)
+			if h > 0 && !hasHour {
+				continue
+			}
+			cost := int64(w)*weekRate + int64(d)*dayRate + int64(h)*hourRate
+			if best.cost < 0 || cost < best.cost {
+				best.weeks, best.days, best.hours, best.cost = w, d, h, cost
+			}
+		}
+	}
+
+	if best.cost < 0 {
+		return nil, ErrNoRates
+	}
+
+	q := int64(period.Quantity)
+	var lines []Line
+	add := func(kind string, count int, rate int64) {
+		if count == 0 {
+			return
+		}
+		lines = append(lines, Line{
+			Kind:        kind,
+			Description: fmt.Sprintf("%d %s x %d unit(s)", count, plural(kind, count), period.Quantity),
+			Quantity:    count,
+			UnitPrice:   rate,
+			Amount:      int64(count) * rate * q,
+		})
+	}
+	add("week", best.weeks, weekRate)
+	add("day", best.days, dayRate)
+	add("hour", best.hours, hourRate)
+
+	return lines, nil
+}
+
+type seasonalEvaluator struct{}
+
+func (seasonalEvaluator) Stage() Stage { return StageAdjustment }
+
+func (seasonalEvaluator) Evaluate(period Period, rules []Rule, base, _ int64) []Line {
+	var lines []Line
+	for _, r := range rules {
+		portion := period.Portion(func(day time.Time) bool {
+			return !day.Before(dateIn(r.StartsOn, day.Location())) && !day.After(dateIn(r.EndsOn, day.Location()))
+		})
+		if portion == 0 {
+			continue
+		}
+		lines = append(lines, Line{
+			Kind:        string(RuleSeasonal),
+			Description: describe(r, "Seasonal rate"),
+			Amount:      percentOf(float64(base)*portion, r.Percent),
+		})
+	}
+	return lines
+}
+
+type weekendEvaluator struct{}
+
+func (weekendEvaluator) Stage() Stage { return StageAdjustment }
+
+func (weekendEvaluator) Evaluate(period Period, rules []Rule, base, _ int64) []Line {
+	portion := period.Portion(func(day time.Time) bool {
+		return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+	})
+	if portion == 0 {
+		return nil
+	}
+
+	var lines []Line
+	for _, r := range rules {
+		lines = append(lines, Line{
+			Kind:        string(RuleWeekendSurcharge),
+			Description: describe(r, "Weekend surcharge"),
+			Amount:      percentOf(float64(base)*portion, r.Percent),
+		})
+	}
+	return lines
+}
+
+type longRentalEvaluator struct{}
+
+func (longRentalEvaluator) Stage() Stage { return StageDiscount }
+
+// Evaluate applies only the most generous discount the rental qualifies for.
+func (longRentalEvaluator) Evaluate(period Period, rules []Rule, _, running int64) []Line {
+	days := period.Days()
+
+	var best *Rule
+	for i := range rules {
+		if days >= rules[i].MinDays && (best == nil || rules[i].Percent > best.Percent) {
+			best = &rules[i]
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	return []Line{{
+		Kind:        string(RuleLongRentalDiscount),
+		Description: describe(*best, fmt.Sprintf("%d+ day discount", best.MinDays)),
+		Amount:      -percentOf(float64(running), best.Percent),
+	}}
+}
+
+type minimumChargeEvaluator struct{}
+
+func (minimumChargeEvaluator) Stage() Stage { return StageMinimum }
+
+func (minimumChargeEvaluator) Evaluate(period Period, rules []Rule, _, running int64) []Line {
+	var minimum int64
+	for _, r := range rules {
+		if r.Amount > minimum {
+			minimum = r.Amount
+		}
+	}
+	if running >= minimum {
+		return nil
+	}
+
+	return []Line{{
+		Kind:        string(RuleMinimumCharge),
+		Description: "Minimum charge of " + money.New(minimum, period.Currency).Format(),
+		Amount:      minimum - running,
+	}}
+}
+
+func describe(r Rule, fallback string) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fallback
+}
+
+func dateIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+func percentOf(value float64, percent float64) int64 {
+	return int64(math.Round(value * percent / 100))
+}
+
+func minorUnits(value *money.Money) (int64, bool) {
+	if value == nil {
+		return 0, false
+	}
+	return value.Amount, true
+}
+
+// splits returns the counts of a size-hour unit worth trying for a rental
+// of hours: none, as many as fit, or one more to cover the rest. Each
+// whole unit swapped for smaller ones changes the cost by the same amount,
+// so the cost is linear up to as many as fit and the cheapest mix always
+// uses one of these counts.
+func splits(hours, size int) []int {
+	return []int{0, hours / size, ceilDiv(hours, size)}
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+func plural(unit string, count int) string {
+	if count == 1 {
+		return unit
+	}
+	return unit + "s"
+}
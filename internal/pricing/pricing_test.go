@@ -0,0 +1,364 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+func usd(v float64) *money.Money {
+	m := money.FromFloat(v, "USD")
+	return &m
+}
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCalculate_BestBasePrice(t *testing.T) {
+	rates := Rates{Hour: usd(15), Day: usd(50), Week: usd(200)}
+
+	tests := []struct {
+		name  string
+		start string
+		end   string
+		want  float64
+	}{
+		{"three hours", "2024-03-04T09:00:00Z", "2024-03-04T12:00:00Z", 45},
+		{"day cheaper than hours", "2024-03-04T09:00:00Z", "2024-03-04T14:00:00Z", 50},
+		{"day plus hours", "2024-03-04T09:00:00Z", "2024-03-05T11:00:00Z", 80},
+		{"six days uses weekly rate", "2024-03-04T09:00:00Z", "2024-03-10T09:00:00Z", 200},
+		{"week plus one day", "2024-03-04T09:00:00Z", "2024-03-12T09:00:00Z", 250},
+	}
+
+	engine := NewEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := engine.Calculate(rates, nil, Period{Start: at(tt.start), End: at(tt.end)})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if b.Total.Float() != tt.want {
+				t.Errorf("expected total %.2f, got %.2f", tt.want, b.Total.Float())
+			}
+		})
+	}
+}
+
+func TestBaseLines_MatchesExhaustiveSearch(t *testing.T) {
+	rateSets := []Rates{
+		{Hour: usd(15), Day: usd(50), Week: usd(200)},
+		{Hour: usd(1), Day: usd(50), Week: usd(200)},
+		{Hour: usd(15), Day: usd(20), Week: usd(200)},
+		{Day: usd(50), Week: usd(280)},
+		{Hour: usd(3), Week: usd(400)},
+		{Hour: usd(15), Day: usd(50)},
+		{Week: usd(200)},
+	}
+	start := at("2024-03-04T00:00:00Z")
+
+	for _, rates := range rateSets {
+		for hours := 1; hours <= 24*30; hours += 5 {
+			period := Period{Start: start, End: start.Add(time.Duration(hours) * time.Hour), Quantity: 1}
+
+			lines, err := baseLines(rates, period)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got int64
+			for _, l := range lines {
+				got += l.Amount
+			}
+
+			if want := exhaustiveCost(rates, hours); got != want {
+				t.Errorf("%d hours at %+v: expected %d, got %d", hours, rates, want, got)
+			}
+		}
+	}
+}
+
+func TestBaseLines_LongRental(t *testing.T) {
+	rates := Rates{Hour: usd(15), Day: usd(50), Week: usd(200)}
+	start := at("2024-01-01T00:00:00Z")
+
+	lines, err := baseLines(rates, Period{Start: start, End: start.AddDate(1, 0, 0), Quantity: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 366 days is 52 weeks and 2 days.
+	if len(lines) != 2 || lines[0].Quantity != 52 || lines[1].Quantity != 2 {
+		t.Errorf("expected 52 weeks and 2 days, got %+v", lines)
+	}
+}
+
+// exhaustiveCost tries every mix of weeks, days and hours covering hours.
+func exhaustiveCost(rates Rates, hours int) int64 {
+	hourRate, hasHour := minorUnits(rates.Hour)
+	dayRate, hasDay := minorUnits(rates.Day)
+	weekRate, hasWeek := minorUnits(rates.Week)
+
+	best := int64(-1)
+	for w := 0; w <= ceilDiv(hours, 168); w++ {
+		if w > 0 && !hasWeek {
+			break
+		}
+		remaining := max(hours-w*168, 0)
+		for d := 0; d <= ceilDiv(remaining, 24); d++ {
+			if d > 0 && !hasDay {
+				break
+			}
+			h := max(remaining-d*24, 0)
+			if h > 0 && !hasHour {
+				continue
+			}
+			if cost := int64(w)*weekRate + int64(d)*dayRate + int64(h)*hourRate; best < 0 || cost < best {
+				best = cost
+			}
+		}
+	}
+	return best
+}
+
+func TestCalculate_MissingRates(t *testing.T) {
+	engine := NewEngine()
+
+	b, err := engine.Calculate(Rates{Day: usd(30)}, nil, Period{
+		Start: at("2024-03-04T09:00:00Z"),
+		End:   at("2024-03-04T11:00:00Z"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Total.Float() != 30 {
+		t.Errorf("expected a full day to be charged, got %.2f", b.Total.Float())
+	}
+
+	if _, err := engine.Calculate(Rates{}, nil, Period{Start: at("2024-03-04T09:00:00Z"), End: at("2024-03-05T09:00:00Z")}); err != ErrNoRates {
+		t.Errorf("expected ErrNoRates, got %v", err)
+	}
+	if _, err := engine.Calculate(Rates{Day: usd(30)}, nil, Period{Start: at("2024-03-05T09:00:00Z"), End: at("2024-03-04T09:00:00Z")}); err != ErrInvalidPeriod {
+		t.Errorf("expected ErrInvalidPeriod, got %v", err)
+	}
+}
+
+func TestCalculate_MixedCurrencies(t *testing.T) {
+	eur := money.New(5000, "EUR")
+
+	_, err := NewEngine().Calculate(Rates{Hour: usd(10), Day: &eur}, nil, Period{
+		Start: at("2024-03-04T09:00:00Z"),
+		End:   at("2024-03-04T11:00:00Z"),
+	})
+	if err != ErrMixedCurrency {
+		t.Errorf("expected ErrMixedCurrency, got %v", err)
+	}
+}
+
+func TestCalculate_Quantity(t *testing.T) {
+	b, err := NewEngine().Calculate(Rates{Day: usd(30)}, nil, Period{
+		Start:    at("2024-03-04T09:00:00Z"),
+		End:      at("2024-03-06T09:00:00Z"),
+		Quantity: 3,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Total.Float() != 180 {
+		t.Errorf("expected 180, got %.2f", b.Total.Float())
+	}
+}
+
+func TestCalculate_Rules(t *testing.T) {
+	rates := Rates{Day: usd(100)}
+	engine := NewEngine()
+
+	tests := []struct {
+		name     string
+		rules    []Rule
+		start    string
+		end      string
+		want     float64
+		subtotal float64
+	}{
+		{
+			name:     "weekend surcharge on saturday only",
+			rules:    []Rule{{Type: RuleWeekendSurcharge, Percent: 20}},
+			start:    "2024-03-08T00:00:00Z",
+			end:      "2024-03-10T00:00:00Z",
+			want:     220,
+			subtotal: 220,
+		},
+		{
+			name: "seasonal rate on overlapping days",
+			rules: []Rule{{
+				Type:     RuleSeasonal,
+				Percent:  50,
+				StartsOn: at("2024-03-05T00:00:00Z"),
+				EndsOn:   at("2024-03-05T00:00:00Z"),
+			}},
+			start:    "2024-03-04T00:00:00Z",
+			end:      "2024-03-06T00:00:00Z",
+			want:     250,
+			subtotal: 250,
+		},
+		{
+			name: "best qualifying long rental discount",
+			rules: []Rule{
+				{Type: RuleLongRentalDiscount, MinDays: 3, Percent: 5},
+				{Type: RuleLongRentalDiscount, MinDays: 5, Percent: 10},
+				{Type: RuleLongRentalDiscount, MinDays: 10, Percent: 25},
+			},
+			start:    "2024-03-04T00:00:00Z",
+			end:      "2024-03-09T00:00:00Z",
+			want:     450,
+			subtotal: 500,
+		},
+		{
+			name:     "minimum charge tops up total",
+			rules:    []Rule{{Type: RuleMinimumCharge, Amount: 15000}},
+			start:    "2024-03-04T00:00:00Z",
+			end:      "2024-03-05T00:00:00Z",
+			want:     150,
+			subtotal: 100,
+		},
+		{
+			name:     "minimum charge below total is ignored",
+			rules:    []Rule{{Type: RuleMinimumCharge, Amount: 5000}},
+			start:    "2024-03-04T00:00:00Z",
+			end:      "2024-03-05T00:00:00Z",
+			want:     100,
+			subtotal: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := engine.Calculate(rates, tt.rules, Period{Start: at(tt.start), End: at(tt.end)})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if b.Total.Float() != tt.want {
+				t.Errorf("expected total %.2f, got %.2f", tt.want, b.Total.Float())
+			}
+			if b.Subtotal.Float() != tt.subtotal {
+				t.Errorf("expected subtotal %.2f, got %.2f", tt.subtotal, b.Subtotal.Float())
+			}
+		})
+	}
+}
+
+func TestCalculate_WeekendUsesLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Friday 22:00 to Saturday 02:00 in UTC is entirely Friday in New York.
+	b, err := NewEngine().Calculate(Rates{Hour: usd(10)}, []Rule{{Type: RuleWeekendSurcharge, Percent: 100}}, Period{
+		Start:    at("2024-03-08T22:00:00Z"),
+		End:      at("2024-03-09T02:00:00Z"),
+		Location: loc,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Total.Float() != 40 {
+		t.Errorf("expected no surcharge, got total %.2f", b.Total.Float())
+	}
+}
+
+func TestCalculate_Charges(t *testing.T) {
+	engine := NewEngine().WithCharges(Charges{ServiceFeePercent: 10, TaxPercent: 20})
+
+	b, err := engine.Calculate(Rates{Day: usd(100)}, []Rule{{Type: RuleLongRentalDiscount, MinDays: 1, Percent: 50}}, Period{
+		Start: at("2024-03-04T00:00:00Z"),
+		End:   at("2024-03-05T00:00:00Z"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Fees.Float() != 5 {
+		t.Errorf("expected fees 5.00, got %.2f", b.Fees.Float())
+	}
+	if b.Taxes.Float() != 11 {
+		t.Errorf("expected taxes 11.00, got %.2f", b.Taxes.Float())
+	}
+	if b.Total.Float() != 66 {
+		t.Errorf("expected total 66.00, got %.2f", b.Total.Float())
+	}
+}
+
+type flatFee struct{}
+
+func (flatFee) Stage() Stage { return StageAdjustment }
+
+func (flatFee) Evaluate(_ Period, rules []Rule, _, _ int64) []Line {
+	return []Line{{Kind: "cleaning", Description: "Cleaning fee", Amount: rules[0].Amount}}
+}
+
+func TestEngine_Register(t *testing.T) {
+	engine := NewEngine()
+	engine.Register("cleaning", flatFee{})
+
+	if !engine.Supports("cleaning") {
+		t.Fatal("expected registered rule type to be supported")
+	}
+
+	b, err := engine.Calculate(Rates{Day: usd(100)}, []Rule{
+		{Type: "cleaning", Amount: 2500},
+		{Type: RuleLongRentalDiscount, MinDays: 1, Percent: 10},
+	}, Period{Start: at("2024-03-04T00:00:00Z"), End: at("2024-03-05T00:00:00Z")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Total.Float() != 112.5 {
+		t.Errorf("expected 112.50, got %.2f", b.Total.Float())
+	}
+	if len(b.Items) != 3 {
+		t.Errorf("expected 3 line items, got %d", len(b.Items))
+	}
+}
+
+func TestLateFee(t *testing.T) {
+	rates := Rates{Hour: usd(15), Day: usd(50)}
+	period := Period{Start: at("2024-03-04T09:00:00Z"), End: at("2024-03-04T11:30:00Z"), Quantity: 2}
+
+	tests := []struct {
+		name  string
+		rules []Rule
+		want  float64
+	}{
+		{"normal rate without rule", nil, 90},
+		{"percent of rate", []Rule{{Type: RuleLateFee, Percent: 150}}, 135},
+		{"flat amount", []Rule{{Type: RuleLateFee, Percent: 100, Amount: 2000}}, 110},
+		{"highest rule wins", []Rule{
+			{Type: RuleLateFee, Percent: 150},
+			{Type: RuleLateFee, Percent: 200},
+		}, 180},
+		{"other rules ignored", []Rule{{Type: RuleWeekendSurcharge, Percent: 50}}, 90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee, err := LateFee(rates, tt.rules, period)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fee.Float() != tt.want {
+				t.Errorf("expected fee %.2f, got %.2f", tt.want, fee.Float())
+			}
+		})
+	}
+}
+
+func TestLateFee_InvalidPeriod(t *testing.T) {
+	_, err := LateFee(Rates{Hour: usd(15)}, nil, Period{Start: at("2024-03-04T09:00:00Z"), End: at("2024-03-04T09:00:00Z")})
+	if err != ErrInvalidPeriod {
+		t.Errorf("expected ErrInvalidPeriod, got %v", err)
+	}
+}
@@ -0,0 +1,309 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/config"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
+	"github.com/abneribeiro/goapi/internal/pkg/pagination"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobNotDead  = errors.New("only dead-lettered jobs can be retried")
+	ErrJobExists   = errors.New("an unfinished job with the same unique key exists")
+)
+
+const (
+	minBackoff = 10 * time.Second
+	maxBackoff = time.Hour
+)
+
+// Handler runs one job. Returning an error schedules a retry unless the
+// error is Permanent or the job is out of attempts.
+type Handler func(ctx context.Context, job *model.Job) error
+
+// Option adjusts a job before it is enqueued.
+type Option func(*model.Job)
+
+// UniqueKey makes Enqueue a no-op while an unfinished job holds key.
+func UniqueKey(key string) Option {
+	return func(job *model.Job) { job.UniqueKey = key }
+}
+
+// RunAt delays the job until t.
+func RunAt(t time.Time) Option {
+	return func(job *model.Job) { job.RunAt = t }
+}
+
+func MaxAttempts(n int) Option {
+	return func(job *model.Job) { job.MaxAttempts = n }
+}
+
+// Queue is a durable job queue stored in Postgres. Any number of workers,
+// in this process or others, can run against the same table.
+type Queue struct {
+	repo     *repository.JobRepository
+	cfg      config.QueueConfig
+	handlers map[string]Handler
+}
+
+func New(repo *repository.JobRepository, cfg config.QueueConfig) *Queue {
+	return &Queue{
+		repo:     repo,
+		cfg:      cfg,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Register sets the handler for a job type. Register every handler before
+// calling Run.
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.handlers[jobType] = handler
+}
+
+// Handle registers fn for a job type, decoding each job's payload into T.
+// A payload that does not decode is dead-lettered straight away.
+func Handle[T any](q *Queue, jobType string, fn func(ctx context.Context, payload T) error) {
+	q.Register(jobType, func(ctx context.Context, job *model.Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("decode payload: %w", err))
+		}
+		return fn(ctx, payload)
+	})
+}
+
+// Enqueue stores a job to run as soon as a worker is free, or at the time
+// given with RunAt. Enqueueing a job whose UniqueKey is held by an
+// unfinished job does nothing.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...Option) error {
+	job, err := q.NewJob(jobType, payload, opts...)
+	if err != nil {
+		return err
+	}
+
+	if err := q.repo.Create(ctx, job); err != nil && !errors.Is(err, repository.ErrJobExists) {
+		return err
+	}
+
+	return nil
+}
+
+// NewJob builds a job without storing it, for a repository to enqueue in
+// the transaction that calls for it.
+func (q *Queue) NewJob(jobType string, payload interface{}, opts ...Option) (*model.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &model.Job{
+		Type:        jobType,
+		Payload:     data,
+		MaxAttempts: q.cfg.MaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	return job, nil
+}
+
+// Run starts the configured number of workers and blocks until ctx is
+// cancelled.
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		ran, err := q.RunNext(ctx)
+		if err != nil {
+			logger.Error("job queue error", logger.WithFields(map[string]interface{}{
+				"error": err.Error(),
+			}))
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		if ran && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(q.cfg.PollInterval):
+		}
+	}
+}
+
+// RunNext claims the next due job and runs it. It reports whether there
+// was a job to run.
+func (q *Queue) RunNext(ctx context.Context) (bool, error) {
+	now := time.Now()
+	job, err := q.repo.Claim(ctx, now, now.Add(-q.cfg.LockTimeout))
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	// The handler runs under a heartbeat that keeps the claim fresh, and is
+	// cancelled if the claim is lost anyway.
+	runCtx, cancel := context.WithCancel(ctx)
+	stop := heartbeat(runCtx, q.cfg.LockTimeout/3, func(ctx context.Context) error {
+		return q.repo.Extend(ctx, job)
+	}, cancel)
+	runErr := q.execute(runCtx, job)
+	stop()
+	cancel()
+
+	// The outcome is saved even if ctx was cancelled while the job ran.
+	return true, q.settle(context.WithoutCancel(ctx), job, runErr)
+}
+
+// heartbeat calls extend every interval until the returned stop is called
+// or ctx is done. When extend reports the claim lost, it calls lost and
+// stops. stop waits for a pending extend, so the job's lock is not read and
+// written at once.
+func heartbeat(ctx context.Context, interval time.Duration, extend func(context.Context) error, lost context.CancelFunc) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := extend(ctx); err != nil {
+				if errors.Is(err, repository.ErrJobLost) {
+					lost()
+					return
+				}
+				logger.Error("job heartbeat failed", logger.WithFields(map[string]interface{}{
+					"error": err.Error(),
+				}))
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (q *Queue) execute(ctx context.Context, job *model.Job) (err error) {
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for job type %q", job.Type))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return handler(ctx, job)
+}
+
+func (q *Queue) settle(ctx context.Context, job *model.Job, err error) error {
+	if err == nil {
+		return q.repo.Complete(ctx, job)
+	}
+
+	fields := map[string]interface{}{
+		"job_id":   job.ID.String(),
+		"job_type": job.Type,
+		"attempt":  job.Attempts,
+		"error":    err.Error(),
+	}
+
+	if !retryable(job, err) {
+		logger.Error("job dead-lettered", logger.WithFields(fields))
+		return q.repo.Bury(ctx, job, err.Error())
+	}
+
+	logger.Info("job failed, retrying", logger.WithFields(fields))
+	return q.repo.Reschedule(ctx, job, time.Now().Add(Backoff(job.Attempts)), err.Error())
+}
+
+func retryable(job *model.Job, err error) bool {
+	return !IsPermanent(err) && job.Attempts < job.MaxAttempts
+}
+
+// Backoff is the delay before retrying a job that failed its nth attempt:
+// it doubles with every attempt, up to an hour.
+func Backoff(attempt int) time.Duration {
+	delay := minBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+func (q *Queue) List(ctx context.Context, filter *model.JobFilter, pag pagination.Params) ([]*model.Job, int64, error) {
+	return q.repo.List(ctx, filter, pag)
+}
+
+func (q *Queue) Get(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	job, err := q.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrJobNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// Retry puts a dead-lettered job back in the queue with its attempts reset.
+func (q *Queue) Retry(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	job, err := q.repo.Retry(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrJobNotFound):
+			return nil, ErrJobNotFound
+		case errors.Is(err, repository.ErrJobNotDead):
+			return nil, ErrJobNotDead
+		case errors.Is(err, repository.ErrJobExists):
+			return nil, ErrJobExists
+		}
+		return nil, err
+	}
+	return job, nil
+}
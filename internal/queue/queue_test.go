@@ -0,0 +1,152 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/config"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{4, 80 * time.Second},
+		{9, 2560 * time.Second},
+		{10, time.Hour},
+		{50, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempt); got != tt.expected {
+			t.Errorf("Backoff(%d) = %v, expected %v", tt.attempt, got, tt.expected)
+		}
+	}
+}
+
+func TestHandle_DecodesPayload(t *testing.T) {
+	q := New(nil, config.QueueConfig{})
+
+	var got struct {
+		Name string `json:"name"`
+	}
+	Handle(q, "greet", func(ctx context.Context, payload struct {
+		Name string `json:"name"`
+	}) error {
+		got = payload
+		return nil
+	})
+
+	err := q.execute(context.Background(), &model.Job{Type: "greet", Payload: json.RawMessage(`{"name":"drill"}`)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "drill" {
+		t.Errorf("expected payload name drill, got %q", got.Name)
+	}
+}
+
+func TestExecute_PermanentFailures(t *testing.T) {
+	q := New(nil, config.QueueConfig{})
+	Handle(q, "typed", func(ctx context.Context, payload []int) error { return nil })
+
+	tests := []struct {
+		name string
+		job  *model.Job
+	}{
+		{"unknown type", &model.Job{Type: "missing", Payload: json.RawMessage(`{}`)}},
+		{"bad payload", &model.Job{Type: "typed", Payload: json.RawMessage(`{"not":"a list"}`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := q.execute(context.Background(), tt.job)
+			if !IsPermanent(err) {
+				t.Errorf("expected a permanent error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExecute_RecoversPanic(t *testing.T) {
+	q := New(nil, config.QueueConfig{})
+	q.Register("boom", func(ctx context.Context, job *model.Job) error { panic("boom") })
+
+	err := q.execute(context.Background(), &model.Job{Type: "boom"})
+	if err == nil || IsPermanent(err) {
+		t.Errorf("expected a retryable error, got %v", err)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	transient := errors.New("connection reset")
+
+	tests := []struct {
+		name     string
+		attempts int
+		err      error
+		expected bool
+	}{
+		{"attempts left", 2, transient, true},
+		{"out of attempts", 5, transient, false},
+		{"permanent", 1, Permanent(transient), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &model.Job{Attempts: tt.attempts, MaxAttempts: 5}
+			if got := retryable(job, tt.err); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHeartbeat_ExtendsUntilStopped(t *testing.T) {
+	extended := make(chan struct{}, 10)
+	stop := heartbeat(context.Background(), time.Millisecond, func(ctx context.Context) error {
+		extended <- struct{}{}
+		return nil
+	}, func() { t.Error("expected the claim to be kept") })
+
+	for range 3 {
+		select {
+		case <-extended:
+		case <-time.After(time.Second):
+			t.Fatal("expected the lock to be extended while the job runs")
+		}
+	}
+	stop()
+
+	for len(extended) > 0 {
+		<-extended
+	}
+	time.Sleep(5 * time.Millisecond)
+	if len(extended) != 0 {
+		t.Error("expected no extensions after stop")
+	}
+}
+
+func TestHeartbeat_CancelsOnLostClaim(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stop := heartbeat(ctx, time.Millisecond, func(ctx context.Context) error {
+		return repository.ErrJobLost
+	}, cancel)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the handler to be cancelled once the claim is lost")
+	}
+}
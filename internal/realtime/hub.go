@@ -0,0 +1,108 @@
+package realtime
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+// Hub wakes the notification streams open on this replica when their
+// user's notifications change. A wake-up carries no data: the stream reads
+// what changed from the database, so wake-ups that arrive while one is
+// already pending are merged and none can be lost to a slow reader.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[*Subscription]struct{}
+}
+
+type Subscription struct {
+	C <-chan struct{}
+
+	hub    *Hub
+	userID uuid.UUID
+	wake   chan struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[uuid.UUID]map[*Subscription]struct{})}
+}
+
+func (h *Hub) Subscribe(userID uuid.UUID) *Subscription {
+	wake := make(chan struct{}, 1)
+	sub := &Subscription{C: wake, hub: h, userID: userID, wake: wake}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+
+	return sub
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	delete(s.hub.subs[s.userID], s)
+	if len(s.hub.subs[s.userID]) == 0 {
+		delete(s.hub.subs, s.userID)
+	}
+}
+
+// Notify wakes every subscription of the user.
+func (h *Hub) Notify(userID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs[userID] {
+		sub.signal()
+	}
+}
+
+// NotifyAll wakes every subscription, for when changes may have been
+// missed.
+func (h *Hub) NotifyAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subs := range h.subs {
+		for sub := range subs {
+			sub.signal()
+		}
+	}
+}
+
+func (h *Hub) Name() string {
+	return repository.NotificationChannel
+}
+
+func (h *Hub) Receive(payload string) {
+	var change model.NotificationChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		logger.Error("invalid notification change", logger.WithFields(map[string]interface{}{
+			"payload": payload,
+			"error":   err.Error(),
+		}))
+		return
+	}
+	h.Notify(change.UserID)
+}
+
+func (h *Hub) Reconnected() {
+	h.NotifyAll()
+}
+
+func (s *Subscription) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
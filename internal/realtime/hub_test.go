@@ -0,0 +1,79 @@
+package realtime
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func woken(sub *Subscription) bool {
+	select {
+	case <-sub.C:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHubNotifyWakesOnlyThatUser(t *testing.T) {
+	hub := NewHub()
+	alice, bob := uuid.New(), uuid.New()
+
+	first := hub.Subscribe(alice)
+	second := hub.Subscribe(alice)
+	other := hub.Subscribe(bob)
+
+	hub.Notify(alice)
+
+	if !woken(first) || !woken(second) {
+		t.Error("expected both of the user's subscriptions to be woken")
+	}
+	if woken(other) {
+		t.Error("expected another user's subscription not to be woken")
+	}
+}
+
+func TestHubMergesPendingWakeUps(t *testing.T) {
+	hub := NewHub()
+	userID := uuid.New()
+	sub := hub.Subscribe(userID)
+
+	hub.Notify(userID)
+	hub.Notify(userID)
+	hub.Notify(userID)
+
+	if !woken(sub) {
+		t.Fatal("expected a wake-up")
+	}
+	if woken(sub) {
+		t.Error("expected pending wake-ups to be merged into one")
+	}
+}
+
+func TestHubNotifyAll(t *testing.T) {
+	hub := NewHub()
+	first := hub.Subscribe(uuid.New())
+	second := hub.Subscribe(uuid.New())
+
+	hub.NotifyAll()
+
+	if !woken(first) || !woken(second) {
+		t.Error("expected every subscription to be woken")
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	hub := NewHub()
+	userID := uuid.New()
+	sub := hub.Subscribe(userID)
+
+	sub.Close()
+	hub.Notify(userID)
+
+	if woken(sub) {
+		t.Error("expected a closed subscription not to be woken")
+	}
+	if len(hub.subs) != 0 {
+		t.Errorf("expected no users left in the hub, got %d", len(hub.subs))
+	}
+}
@@ -0,0 +1,69 @@
+package realtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
+)
+
+// pingInterval is how often an idle listener checks its connection.
+const pingInterval = 90 * time.Second
+
+// Channel handles the announcements made on one Postgres channel.
+// Reconnected is called after the connection drops and comes back, since
+// announcements sent in between are lost.
+type Channel interface {
+	Name() string
+	Receive(payload string)
+	Reconnected()
+}
+
+// Listen relays announcements made by any replica through Postgres
+// LISTEN/NOTIFY to the channels' handlers, until ctx is cancelled.
+func Listen(ctx context.Context, dsn string, channels ...Channel) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Error("listener connection error", logger.WithFields(map[string]interface{}{
+				"error": err.Error(),
+			}))
+		}
+	})
+	defer listener.Close()
+
+	byName := make(map[string]Channel, len(channels))
+	for _, channel := range channels {
+		if err := listener.Listen(channel.Name()); err != nil {
+			return err
+		}
+		byName[channel.Name()] = channel
+	}
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case n := <-listener.Notify:
+			// A nil notification means the connection was re-established.
+			if n == nil {
+				for _, channel := range channels {
+					channel.Reconnected()
+				}
+				continue
+			}
+
+			if channel, ok := byName[n.Channel]; ok {
+				channel.Receive(n.Extra)
+			}
+
+		case <-ticker.C:
+			go listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package realtime
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrTooManyTopics = errors.New("too many topics")
+
+// Topics fans messages out to the clients subscribed to each topic on this
+// replica. Publishing never waits for a client: one whose buffer is full is
+// dropped, and its connection should be closed so it reconnects and
+// reloads what it missed.
+type Topics struct {
+	buffer    int
+	maxTopics int
+
+	mu   sync.Mutex
+	subs map[string]map[*Client]struct{}
+}
+
+// Client is one connection's subscriptions. Messages for it arrive on Send
+// until Dropped is closed.
+type Client struct {
+	Send    <-chan []byte
+	Dropped <-chan struct{}
+
+	topics  *Topics
+	send    chan []byte
+	dropped chan struct{}
+	joined  map[string]struct{}
+	once    sync.Once
+}
+
+func NewTopics(buffer, maxTopics int) *Topics {
+	return &Topics{
+		buffer:    buffer,
+		maxTopics: maxTopics,
+		subs:      make(map[string]map[*Client]struct{}),
+	}
+}
+
+func (t *Topics) NewClient() *Client {
+	send := make(chan []byte, t.buffer)
+	dropped := make(chan struct{})
+	return &Client{
+		Send:    send,
+		Dropped: dropped,
+		topics:  t,
+		send:    send,
+		dropped: dropped,
+		joined:  make(map[string]struct{}),
+	}
+}
+
+func (t *Topics) Subscribe(c *Client, topic string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := c.joined[topic]; ok || c.isDropped() {
+		return nil
+	}
+	if len(c.joined) >= t.maxTopics {
+		return ErrTooManyTopics
+	}
+
+	if t.subs[topic] == nil {
+		t.subs[topic] = make(map[*Client]struct{})
+	}
+	t.subs[topic][c] = struct{}{}
+	c.joined[topic] = struct{}{}
+
+	return nil
+}
+
+func (t *Topics) Unsubscribe(c *Client, topic string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.leave(c, topic)
+}
+
+// Publish queues msg for every client subscribed to topic.
+func (t *Topics) Publish(topic string, msg []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for c := range t.subs[topic] {
+		t.deliver(c, msg)
+	}
+}
+
+// PublishAll queues msg for every client with a subscription.
+func (t *Topics) PublishAll(msg []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seen := make(map[*Client]struct{})
+	for _, clients := range t.subs {
+		for c := range clients {
+			if _, ok := seen[c]; ok {
+				continue
+			}
+			seen[c] = struct{}{}
+			t.deliver(c, msg)
+		}
+	}
+}
+
+// Reply queues msg for c alone, such as the answer to a request.
+func (c *Client) Reply(msg []byte) {
+	c.topics.mu.Lock()
+	defer c.topics.mu.Unlock()
+
+	c.topics.deliver(c, msg)
+}
+
+// Close removes every subscription of c.
+func (c *Client) Close() {
+	c.topics.mu.Lock()
+	defer c.topics.mu.Unlock()
+
+	c.drop()
+}
+
+func (t *Topics) deliver(c *Client, msg []byte) {
+	select {
+	case <-c.dropped:
+	case c.send <- msg:
+	default:
+		c.drop()
+	}
+}
+
+func (c *Client) isDropped() bool {
+	select {
+	case <-c.dropped:
+		return true
+	default:
+		return false
+	}
+}
+
+// drop must be called with the lock held.
+func (c *Client) drop() {
+	for topic := range c.joined {
+		c.topics.leave(c, topic)
+	}
+	c.once.Do(func() { close(c.dropped) })
+}
+
+func (t *Topics) leave(c *Client, topic string) {
+	delete(t.subs[topic], c)
+	if len(t.subs[topic]) == 0 {
+		delete(t.subs, topic)
+	}
+	delete(c.joined, topic)
+}
@@ -0,0 +1,116 @@
+package realtime
+
+import (
+	"errors"
+	"testing"
+)
+
+func received(c *Client) []string {
+	var msgs []string
+	for {
+		select {
+		case msg := <-c.Send:
+			msgs = append(msgs, string(msg))
+		default:
+			return msgs
+		}
+	}
+}
+
+func TestTopicsPublishReachesSubscribersOnly(t *testing.T) {
+	topics := NewTopics(8, 10)
+	subscribed := topics.NewClient()
+	other := topics.NewClient()
+
+	if err := topics.Subscribe(subscribed, "reservation:1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := topics.Subscribe(other, "reservation:2"); err != nil {
+		t.Fatal(err)
+	}
+
+	topics.Publish("reservation:1", []byte("update"))
+
+	if got := received(subscribed); len(got) != 1 || got[0] != "update" {
+		t.Errorf("expected the subscriber to receive the update, got %v", got)
+	}
+	if got := received(other); len(got) != 0 {
+		t.Errorf("expected no messages for another topic, got %v", got)
+	}
+}
+
+func TestTopicsUnsubscribe(t *testing.T) {
+	topics := NewTopics(8, 10)
+	c := topics.NewClient()
+	topics.Subscribe(c, "reservation:1")
+
+	topics.Unsubscribe(c, "reservation:1")
+	topics.Publish("reservation:1", []byte("update"))
+
+	if got := received(c); len(got) != 0 {
+		t.Errorf("expected no messages after unsubscribing, got %v", got)
+	}
+	if len(topics.subs) != 0 {
+		t.Errorf("expected no topics left, got %d", len(topics.subs))
+	}
+}
+
+func TestTopicsDropsSlowClient(t *testing.T) {
+	topics := NewTopics(2, 10)
+	slow := topics.NewClient()
+	fast := topics.NewClient()
+	topics.Subscribe(slow, "equipment:1:availability")
+	topics.Subscribe(fast, "equipment:1:availability")
+
+	for i := 0; i < 3; i++ {
+		topics.Publish("equipment:1:availability", []byte("update"))
+		received(fast)
+	}
+
+	select {
+	case <-slow.Dropped:
+	default:
+		t.Fatal("expected the client with a full buffer to be dropped")
+	}
+	select {
+	case <-fast.Dropped:
+		t.Fatal("expected a client keeping up not to be dropped")
+	default:
+	}
+
+	topics.Publish("equipment:1:availability", []byte("update"))
+	if got := received(fast); len(got) != 1 {
+		t.Errorf("expected the other client to keep receiving, got %v", got)
+	}
+	if _, ok := topics.subs["equipment:1:availability"][slow]; ok {
+		t.Error("expected the dropped client to be unsubscribed")
+	}
+}
+
+func TestTopicsMaxTopics(t *testing.T) {
+	topics := NewTopics(8, 1)
+	c := topics.NewClient()
+
+	if err := topics.Subscribe(c, "reservation:1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := topics.Subscribe(c, "reservation:1"); err != nil {
+		t.Errorf("expected subscribing again to be a no-op, got %v", err)
+	}
+	if err := topics.Subscribe(c, "reservation:2"); !errors.Is(err, ErrTooManyTopics) {
+		t.Errorf("expected ErrTooManyTopics, got %v", err)
+	}
+}
+
+func TestTopicsPublishAll(t *testing.T) {
+	topics := NewTopics(8, 10)
+	c := topics.NewClient()
+	topics.Subscribe(c, "reservation:1")
+	topics.Subscribe(c, "reservation:2")
+
+	topics.PublishAll([]byte("resync"))
+
+	if got := received(c); len(got) != 1 {
+		t.Errorf("expected one message per client, got %v", got)
+	}
+}
@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+var (
+	ErrCategoryNotFound   = errors.New("category not found")
+	ErrCategorySlugExists = errors.New("category slug already exists")
+)
+
+type CategoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+const categoryColumns = `id, parent_id, name, slug, icon, attributes, created_at, updated_at`
+
+func (r *CategoryRepository) Create(ctx context.Context, category *model.Category) error {
+	query := `
+		INSERT INTO categories (id, parent_id, name, slug, icon, attributes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	attributes, err := json.Marshal(nonNilAttributes(category.Attributes))
+	if err != nil {
+		return err
+	}
+
+	category.ID = uuid.New()
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = time.Now()
+
+	_, err = r.db.ExecContext(ctx, query,
+		category.ID,
+		category.ParentID,
+		category.Name,
+		category.Slug,
+		category.Icon,
+		attributes,
+		category.CreatedAt,
+		category.UpdatedAt,
+	)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrCategorySlugExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	query := `SELECT ` + categoryColumns + ` FROM categories WHERE id = $1`
+	return r.getOne(ctx, query, id)
+}
+
+func (r *CategoryRepository) GetBySlug(ctx context.Context, slug string) (*model.Category, error) {
+	query := `SELECT ` + categoryColumns + ` FROM categories WHERE slug = $1`
+	return r.getOne(ctx, query, slug)
+}
+
+func (r *CategoryRepository) List(ctx context.Context) ([]*model.Category, error) {
+	query := `SELECT ` + categoryColumns + ` FROM categories ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*model.Category
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+func (r *CategoryRepository) Update(ctx context.Context, category *model.Category) error {
+	query := `
+		UPDATE categories
+		SET parent_id = $1, name = $2, slug = $3, icon = $4, attributes = $5, updated_at = $6
+		WHERE id = $7
+	`
+
+	attributes, err := json.Marshal(nonNilAttributes(category.Attributes))
+	if err != nil {
+		return err
+	}
+
+	category.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		category.ParentID,
+		category.Name,
+		category.Slug,
+		category.Icon,
+		attributes,
+		category.UpdatedAt,
+		category.ID,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrCategorySlugExists
+		}
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM categories WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) IsInUse(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM equipment WHERE category_id = $1)
+		    OR EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)
+	`
+
+	var inUse bool
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&inUse); err != nil {
+		return false, err
+	}
+
+	return inUse, nil
+}
+
+func (r *CategoryRepository) IsDescendant(ctx context.Context, ancestorID, id uuid.UUID) (bool, error) {
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE parent_id = $1
+			UNION ALL
+			SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT EXISTS (SELECT 1 FROM tree WHERE id = $2)
+	`
+
+	var descendant bool
+	if err := r.db.QueryRowContext(ctx, query, ancestorID, id).Scan(&descendant); err != nil {
+		return false, err
+	}
+
+	return descendant, nil
+}
+
+func (r *CategoryRepository) GetAttributeSchema(ctx context.Context, id uuid.UUID) ([]model.CategoryAttribute, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, attributes, 0 AS depth FROM categories WHERE id = $1
+			UNION ALL
+			SELECT c.id, c.parent_id, c.attributes, a.depth + 1
+			FROM categories c JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT attributes FROM ancestors ORDER BY depth DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schema []model.CategoryAttribute
+	index := make(map[string]int)
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+
+		var attributes []model.CategoryAttribute
+		if err := json.Unmarshal(raw, &attributes); err != nil {
+			return nil, err
+		}
+
+		for _, attr := range attributes {
+			if i, ok := index[attr.Key]; ok {
+				schema[i] = attr
+				continue
+			}
+			index[attr.Key] = len(schema)
+			schema = append(schema, attr)
+		}
+	}
+
+	return schema, rows.Err()
+}
+
+func (r *CategoryRepository) getOne(ctx context.Context, query string, arg interface{}) (*model.Category, error) {
+	category, err := scanCategory(r.db.QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCategory(row rowScanner) (*model.Category, error) {
+	category := &model.Category{}
+	var icon sql.NullString
+	var attributes []byte
+
+	err := row.Scan(
+		&category.ID,
+		&category.ParentID,
+		&category.Name,
+		&category.Slug,
+		&icon,
+		&attributes,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	category.Icon = icon.String
+	if err := json.Unmarshal(attributes, &category.Attributes); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func nonNilAttributes(attributes []model.CategoryAttribute) []model.CategoryAttribute {
+	if attributes == nil {
+		return []model.CategoryAttribute{}
+	}
+	return attributes
+}
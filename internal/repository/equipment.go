@@ -3,14 +3,17 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
 	"github.com/abneribeiro/goapi/internal/pkg/pagination"
 )
 
@@ -24,41 +27,175 @@ func NewEquipmentRepository(db *sql.DB) *EquipmentRepository {
 	return &EquipmentRepository{db: db}
 }
 
+const equipmentColumns = `e.id, e.owner_id, e.name, e.description, e.category_id, e.category, e.currency, e.price_per_hour, e.price_per_day, e.price_per_week, e.deposit_amount, e.location, e.latitude, e.longitude, e.available, e.auto_approve, e.require_prepayment, e.cancellation_policy, e.inventory_mode, e.quantity, e.time_zone, e.specifications, e.created_at, e.updated_at`
+
+func scanEquipment(row rowScanner, e *model.Equipment, extra ...interface{}) error {
+	var specifications, cancellationPolicy []byte
+	var pricePerHour, pricePerDay, pricePerWeek, depositAmount sql.NullString
+	dest := []interface{}{
+		&e.ID,
+		&e.OwnerID,
+		&e.Name,
+		&e.Description,
+		&e.CategoryID,
+		&e.Category,
+		&e.Currency,
+		&pricePerHour,
+		&pricePerDay,
+		&pricePerWeek,
+		&depositAmount,
+		&e.Location,
+		&e.Latitude,
+		&e.Longitude,
+		&e.Available,
+		&e.AutoApprove,
+		&e.RequirePrepayment,
+		&cancellationPolicy,
+		&e.InventoryMode,
+		&e.Quantity,
+		&e.TimeZone,
+		&specifications,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	if err := scanPrices(e, pricePerHour, pricePerDay, pricePerWeek); err != nil {
+		return err
+	}
+	var err error
+	if e.DepositAmount, err = parseMoney(depositAmount, e.Currency); err != nil {
+		return err
+	}
+	if e.CancellationPolicy, err = unmarshalCancellationPolicy(cancellationPolicy); err != nil {
+		return err
+	}
+	return json.Unmarshal(specifications, &e.Specifications)
+}
+
+// unmarshalCancellationPolicy rebuilds a stored policy so presets and
+// descriptions always reflect the current definitions.
+func unmarshalCancellationPolicy(data []byte) (model.CancellationPolicy, error) {
+	var stored model.CancellationPolicyRequest
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return model.CancellationPolicy{}, err
+	}
+	return model.NewCancellationPolicy(stored.Type, stored.Tiers), nil
+}
+
+func scanPrices(e *model.Equipment, hour, day, week sql.NullString) error {
+	var err error
+	if e.PricePerHour, err = parseMoney(hour, e.Currency); err != nil {
+		return err
+	}
+	if e.PricePerDay, err = parseMoney(day, e.Currency); err != nil {
+		return err
+	}
+	e.PricePerWeek, err = parseMoney(week, e.Currency)
+	return err
+}
+
+func parseMoney(value sql.NullString, currency string) (*money.Money, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	m, err := money.Parse(value.String, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func marshalSpecifications(specs model.Specifications) ([]byte, error) {
+	if specs == nil {
+		specs = model.Specifications{}
+	}
+	return json.Marshal(specs)
+}
+
 func (r *EquipmentRepository) Create(ctx context.Context, equipment *model.Equipment) error {
 	query := `
-		INSERT INTO equipment (id, owner_id, name, description, category, price_per_hour, price_per_day, price_per_week, location, latitude, longitude, available, auto_approve, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		INSERT INTO equipment (id, owner_id, name, description, category_id, category, currency, price_per_hour, price_per_day, price_per_week, deposit_amount, location, latitude, longitude, available, auto_approve, require_prepayment, cancellation_policy, inventory_mode, quantity, time_zone, specifications, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 	`
 
+	specifications, err := marshalSpecifications(equipment.Specifications)
+	if err != nil {
+		return err
+	}
+
+	if equipment.CancellationPolicy.Type == "" {
+		equipment.CancellationPolicy = model.DefaultCancellationPolicy
+	}
+	cancellationPolicy, err := json.Marshal(equipment.CancellationPolicy)
+	if err != nil {
+		return err
+	}
+
 	equipment.ID = uuid.New()
 	equipment.CreatedAt = time.Now()
 	equipment.UpdatedAt = time.Now()
 	equipment.Available = true
+	if equipment.InventoryMode == "" {
+		equipment.InventoryMode = model.InventoryPooled
+	}
+	if equipment.InventoryMode == model.InventoryPooled && equipment.Quantity == 0 {
+		equipment.Quantity = 1
+	}
+	if equipment.TimeZone == "" {
+		equipment.TimeZone = "UTC"
+	}
+	if equipment.Currency == "" {
+		equipment.Currency = money.DefaultCurrency
+	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query,
 		equipment.ID,
 		equipment.OwnerID,
 		equipment.Name,
 		equipment.Description,
+		equipment.CategoryID,
 		equipment.Category,
+		equipment.Currency,
 		equipment.PricePerHour,
 		equipment.PricePerDay,
 		equipment.PricePerWeek,
+		equipment.DepositAmount,
 		equipment.Location,
 		equipment.Latitude,
 		equipment.Longitude,
 		equipment.Available,
 		equipment.AutoApprove,
+		equipment.RequirePrepayment,
+		cancellationPolicy,
+		equipment.InventoryMode,
+		equipment.Quantity,
+		equipment.TimeZone,
+		specifications,
 		equipment.CreatedAt,
 		equipment.UpdatedAt,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := insertDomainEvent(ctx, tx, model.EventEquipmentCreated, equipment.ID, equipmentEventData(equipment)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *EquipmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Equipment, error) {
 	query := `
-		SELECT e.id, e.owner_id, e.name, e.description, e.category, e.price_per_hour, e.price_per_day, e.price_per_week, e.location, e.latitude, e.longitude, e.available, e.auto_approve, e.created_at, e.updated_at,
+		SELECT ` + equipmentColumns + `,
 		       u.id, u.email, u.name, u.phone, u.role, u.verified, u.created_at, u.updated_at
 		FROM equipment e
 		LEFT JOIN users u ON e.owner_id = u.id
@@ -66,22 +203,7 @@ func (r *EquipmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 	`
 
 	equipment := &model.Equipment{Owner: &model.User{}}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&equipment.ID,
-		&equipment.OwnerID,
-		&equipment.Name,
-		&equipment.Description,
-		&equipment.Category,
-		&equipment.PricePerHour,
-		&equipment.PricePerDay,
-		&equipment.PricePerWeek,
-		&equipment.Location,
-		&equipment.Latitude,
-		&equipment.Longitude,
-		&equipment.Available,
-		&equipment.AutoApprove,
-		&equipment.CreatedAt,
-		&equipment.UpdatedAt,
+	err := scanEquipment(r.db.QueryRowContext(ctx, query, id), equipment,
 		&equipment.Owner.ID,
 		&equipment.Owner.Email,
 		&equipment.Owner.Name,
@@ -114,10 +236,23 @@ func (r *EquipmentRepository) List(ctx context.Context, filter *model.EquipmentF
 	argCount := 0
 
 	if filter != nil {
-		if filter.Category != "" {
+		if filter.Category != "" || filter.CategoryID != nil {
 			argCount++
-			baseQuery += fmt.Sprintf(" AND e.category = $%d", argCount)
-			args = append(args, filter.Category)
+			condition := fmt.Sprintf("c.slug = $%d OR lower(c.name) = lower($%d)", argCount, argCount)
+			var value interface{} = filter.Category
+			if filter.CategoryID != nil {
+				condition = fmt.Sprintf("c.id = $%d", argCount)
+				value = *filter.CategoryID
+			}
+			baseQuery += ` AND e.category_id IN (
+				WITH RECURSIVE tree AS (
+					SELECT c.id FROM categories c WHERE ` + condition + `
+					UNION ALL
+					SELECT child.id FROM categories child JOIN tree t ON child.parent_id = t.id
+				)
+				SELECT id FROM tree
+			)`
+			args = append(args, value)
 		}
 		if filter.Location != "" {
 			argCount++
@@ -144,6 +279,21 @@ func (r *EquipmentRepository) List(ctx context.Context, filter *model.EquipmentF
 			baseQuery += fmt.Sprintf(" AND (e.price_per_day <= $%d OR e.price_per_hour <= $%d OR e.price_per_week <= $%d)", argCount, argCount, argCount)
 			args = append(args, *filter.MaxPrice)
 		}
+		if filter.StartDate != nil && filter.EndDate != nil {
+			baseQuery += " AND NOT " + blackoutOverlap(argCount+1, argCount+2)
+			baseQuery += " AND e.quantity > " + fmt.Sprintf(peakReservedQuantity, argCount+1, argCount+2)
+			argCount += 2
+			args = append(args, *filter.StartDate, *filter.EndDate)
+		}
+		for _, spec := range filter.Specs {
+			condition, specArgs, err := specCondition(spec, argCount)
+			if err != nil {
+				return nil, 0, err
+			}
+			baseQuery += " AND " + condition
+			argCount += len(specArgs)
+			args = append(args, specArgs...)
+		}
 	}
 
 	countQuery := "SELECT COUNT(*) " + baseQuery
@@ -153,7 +303,7 @@ func (r *EquipmentRepository) List(ctx context.Context, filter *model.EquipmentF
 		return nil, 0, err
 	}
 
-	selectQuery := `SELECT e.id, e.owner_id, e.name, e.description, e.category, e.price_per_hour, e.price_per_day, e.price_per_week, e.location, e.latitude, e.longitude, e.available, e.auto_approve, e.created_at, e.updated_at ` + baseQuery
+	selectQuery := `SELECT ` + equipmentColumns + ` ` + baseQuery
 	selectQuery += " ORDER BY e.created_at DESC"
 	selectQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount+1, argCount+2)
 	args = append(args, pag.PerPage, pag.Offset)
@@ -167,24 +317,7 @@ func (r *EquipmentRepository) List(ctx context.Context, filter *model.EquipmentF
 	var equipment []*model.Equipment
 	for rows.Next() {
 		e := &model.Equipment{}
-		err := rows.Scan(
-			&e.ID,
-			&e.OwnerID,
-			&e.Name,
-			&e.Description,
-			&e.Category,
-			&e.PricePerHour,
-			&e.PricePerDay,
-			&e.PricePerWeek,
-			&e.Location,
-			&e.Latitude,
-			&e.Longitude,
-			&e.Available,
-			&e.AutoApprove,
-			&e.CreatedAt,
-			&e.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanEquipment(rows, e); err != nil {
 			return nil, 0, err
 		}
 		equipment = append(equipment, e)
@@ -193,27 +326,88 @@ func (r *EquipmentRepository) List(ctx context.Context, filter *model.EquipmentF
 	return equipment, total, nil
 }
 
+var specOperators = map[model.SpecOperator]string{
+	model.SpecLt:  "<",
+	model.SpecLte: "<=",
+	model.SpecGt:  ">",
+	model.SpecGte: ">=",
+}
+
+func specCondition(spec model.SpecFilter, argCount int) (string, []interface{}, error) {
+	if spec.Operator.IsRange() {
+		value, err := strconv.ParseFloat(spec.Value, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("spec.%s: %w", spec.Key, err)
+		}
+		condition := fmt.Sprintf(
+			"(CASE WHEN jsonb_typeof(e.specifications->$%d) = 'number' THEN (e.specifications->>$%d)::numeric END) %s $%d",
+			argCount+1, argCount+1, specOperators[spec.Operator], argCount+2,
+		)
+		return condition, []interface{}{spec.Key, value}, nil
+	}
+
+	value, err := spec.TypedValue()
+	if err != nil {
+		return "", nil, fmt.Errorf("spec.%s: %w", spec.Key, err)
+	}
+	contains, err := json.Marshal(map[string]interface{}{spec.Key: value})
+	if err != nil {
+		return "", nil, err
+	}
+
+	condition := fmt.Sprintf("e.specifications @> $%d::jsonb", argCount+1)
+	if spec.Operator == model.SpecNe {
+		condition = "NOT " + condition
+	}
+	return condition, []interface{}{string(contains)}, nil
+}
+
 func (r *EquipmentRepository) Update(ctx context.Context, equipment *model.Equipment) error {
 	query := `
 		UPDATE equipment
-		SET name = $1, description = $2, category = $3, price_per_hour = $4, price_per_day = $5, price_per_week = $6, location = $7, latitude = $8, longitude = $9, available = $10, auto_approve = $11, updated_at = $12
-		WHERE id = $13
+		SET name = $1, description = $2, category_id = $3, category = $4, currency = $5, price_per_hour = $6, price_per_day = $7, price_per_week = $8, deposit_amount = $9, location = $10, latitude = $11, longitude = $12, available = $13, auto_approve = $14, require_prepayment = $15, cancellation_policy = $16, inventory_mode = $17, quantity = $18, time_zone = $19, specifications = $20, updated_at = $21
+		WHERE id = $22
 	`
 
+	specifications, err := marshalSpecifications(equipment.Specifications)
+	if err != nil {
+		return err
+	}
+
+	cancellationPolicy, err := json.Marshal(equipment.CancellationPolicy)
+	if err != nil {
+		return err
+	}
+
 	equipment.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(ctx, query,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query,
 		equipment.Name,
 		equipment.Description,
+		equipment.CategoryID,
 		equipment.Category,
+		equipment.Currency,
 		equipment.PricePerHour,
 		equipment.PricePerDay,
 		equipment.PricePerWeek,
+		equipment.DepositAmount,
 		equipment.Location,
 		equipment.Latitude,
 		equipment.Longitude,
 		equipment.Available,
 		equipment.AutoApprove,
+		equipment.RequirePrepayment,
+		cancellationPolicy,
+		equipment.InventoryMode,
+		equipment.Quantity,
+		equipment.TimeZone,
+		specifications,
 		equipment.UpdatedAt,
 		equipment.ID,
 	)
@@ -230,27 +424,36 @@ func (r *EquipmentRepository) Update(ctx context.Context, equipment *model.Equip
 		return ErrEquipmentNotFound
 	}
 
-	return nil
+	if err := insertDomainEvent(ctx, tx, model.EventEquipmentUpdated, equipment.ID, equipmentEventData(equipment)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *EquipmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM equipment WHERE id = $1`
+	query := `DELETE FROM equipment WHERE id = $1 RETURNING owner_id`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
-	if err != nil {
+	var ownerID uuid.UUID
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&ownerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEquipmentNotFound
+		}
 		return err
 	}
 
-	if rows == 0 {
-		return ErrEquipmentNotFound
+	data := map[string]uuid.UUID{"id": id, "owner_id": ownerID}
+	if err := insertDomainEvent(ctx, tx, model.EventEquipmentDeleted, id, data); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 func (r *EquipmentRepository) AddPhoto(ctx context.Context, photo *model.EquipmentPhoto) error {
@@ -312,85 +515,152 @@ func (r *EquipmentRepository) DeletePhoto(ctx context.Context, photoID uuid.UUID
 	return err
 }
 
-func (r *EquipmentRepository) GetCategories(ctx context.Context) ([]string, error) {
-	query := `SELECT DISTINCT category FROM equipment ORDER BY category`
+func (r *EquipmentRepository) CheckAvailability(ctx context.Context, equipmentID uuid.UUID, startDate, endDate time.Time, quantity int) (bool, error) {
+	return r.CheckAvailabilityExcluding(ctx, equipmentID, uuid.Nil, startDate, endDate, quantity)
+}
+
+// CheckAvailabilityExcluding is CheckAvailability ignoring one reservation,
+// so a reservation can be checked against its own new dates.
+func (r *EquipmentRepository) CheckAvailabilityExcluding(ctx context.Context, equipmentID, reservationID uuid.UUID, startDate, endDate time.Time, quantity int) (bool, error) {
+	return checkAvailability(ctx, r.db, equipmentID, reservationID, startDate, endDate, quantity)
+}
+
+// querier runs read queries on the database or inside a transaction, so
+// availability can be checked again while a booking holds the equipment
+// lock.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
 
-	rows, err := r.db.QueryContext(ctx, query)
+func checkAvailability(ctx context.Context, q querier, equipmentID, reservationID uuid.UUID, startDate, endDate time.Time, quantity int) (bool, error) {
+	capacity, err := getQuantity(ctx, q, equipmentID)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	defer rows.Close()
 
-	var categories []string
-	for rows.Next() {
-		var category string
-		if err := rows.Scan(&category); err != nil {
-			return nil, err
+	blackouts, err := blackoutsBetween(ctx, q, equipmentID, startDate, endDate)
+	if err != nil {
+		return false, err
+	}
+	for _, b := range blackouts {
+		if b.Overlaps(startDate, endDate) {
+			return false, nil
 		}
-		categories = append(categories, category)
 	}
 
-	return categories, nil
+	reserved, err := reservedQuantities(ctx, q, equipmentID, reservationID, startDate, endDate)
+	if err != nil {
+		return false, err
+	}
+
+	return model.PeakQuantity(reserved, startDate, endDate)+quantity <= capacity, nil
 }
 
-func (r *EquipmentRepository) CheckAvailability(ctx context.Context, equipmentID uuid.UUID, startDate, endDate time.Time) (bool, error) {
-	query := `
-		SELECT COUNT(*)
-		FROM reservations
-		WHERE equipment_id = $1
-		AND status IN ('pending', 'approved')
-		AND (
-			(start_date <= $2 AND end_date >= $2) OR
-			(start_date <= $3 AND end_date >= $3) OR
-			(start_date >= $2 AND end_date <= $3)
-		)
-	`
+func (r *EquipmentRepository) GetAvailabilityCalendar(ctx context.Context, equipmentID uuid.UUID, startDate, endDate time.Time) ([]model.EquipmentAvailability, error) {
+	capacity, err := getQuantity(ctx, r.db, equipmentID)
+	if err != nil {
+		return nil, err
+	}
 
-	var count int
-	err := r.db.QueryRowContext(ctx, query, equipmentID, startDate, endDate).Scan(&count)
+	reserved, err := r.ReservedQuantities(ctx, equipmentID, startDate, endDate.AddDate(0, 0, 1))
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	blackouts, err := r.BlackoutsBetween(ctx, equipmentID, startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
 	}
 
-	return count == 0, nil
+	var availability []model.EquipmentAvailability
+	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+		next := d.AddDate(0, 0, 1)
+		remaining := capacity - model.PeakQuantity(reserved, d, next)
+		for _, b := range blackouts {
+			if b.Overlaps(d, next) {
+				remaining = 0
+				break
+			}
+		}
+		if remaining < 0 {
+			remaining = 0
+		}
+		availability = append(availability, model.EquipmentAvailability{
+			Date:              d,
+			Available:         remaining > 0,
+			AvailableQuantity: remaining,
+		})
+	}
+
+	return availability, nil
 }
 
-func (r *EquipmentRepository) GetAvailabilityCalendar(ctx context.Context, equipmentID uuid.UUID, startDate, endDate time.Time) ([]model.EquipmentAvailability, error) {
+// peakReservedQuantity computes the maximum number of units of e held at once
+// by active reservations within [$start, $end) using a running sum over
+// start (+quantity) and end (-quantity) events. Ends sort before starts so
+// back-to-back reservations do not count as concurrent.
+const peakReservedQuantity = `COALESCE((
+	SELECT MAX(running)
+	FROM (
+		SELECT SUM(delta) OVER (ORDER BY at, delta) AS running
+		FROM (
+			SELECT GREATEST(r.start_date, $%[1]d) AS at, r.quantity AS delta
+			FROM reservations r
+			WHERE r.equipment_id = e.id AND r.status IN ('pending', 'approved', 'active')
+			AND r.start_date < $%[2]d AND r.end_date > $%[1]d
+			UNION ALL
+			SELECT LEAST(r.end_date, $%[2]d), -r.quantity
+			FROM reservations r
+			WHERE r.equipment_id = e.id AND r.status IN ('pending', 'approved', 'active')
+			AND r.start_date < $%[2]d AND r.end_date > $%[1]d
+		) events
+	) running_totals
+), 0)`
+
+func getQuantity(ctx context.Context, q querier, equipmentID uuid.UUID) (int, error) {
+	var quantity int
+	err := q.QueryRowContext(ctx, `SELECT quantity FROM equipment WHERE id = $1`, equipmentID).Scan(&quantity)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrEquipmentNotFound
+		}
+		return 0, err
+	}
+	return quantity, nil
+}
+
+func (r *EquipmentRepository) ReservedQuantities(ctx context.Context, equipmentID uuid.UUID, startDate, endDate time.Time) ([]model.ReservedQuantity, error) {
+	return reservedQuantities(ctx, r.db, equipmentID, uuid.Nil, startDate, endDate)
+}
+
+func reservedQuantities(ctx context.Context, q querier, equipmentID, excludeID uuid.UUID, startDate, endDate time.Time) ([]model.ReservedQuantity, error) {
 	query := `
-		SELECT start_date, end_date
+		SELECT start_date, end_date, quantity
 		FROM reservations
 		WHERE equipment_id = $1
-		AND status IN ('pending', 'approved')
-		AND start_date <= $3
-		AND end_date >= $2
+		AND status IN ('pending', 'approved', 'active')
+		AND start_date < $3
+		AND end_date > $2
+		AND id <> $4
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, equipmentID, startDate, endDate)
+	rows, err := q.QueryContext(ctx, query, equipmentID, startDate, endDate, excludeID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	reservedDates := make(map[string]bool)
+	var reserved []model.ReservedQuantity
 	for rows.Next() {
-		var resStart, resEnd time.Time
-		if err := rows.Scan(&resStart, &resEnd); err != nil {
+		var rq model.ReservedQuantity
+		if err := rows.Scan(&rq.StartDate, &rq.EndDate, &rq.Quantity); err != nil {
 			return nil, err
 		}
-		for d := resStart; !d.After(resEnd); d = d.AddDate(0, 0, 1) {
-			reservedDates[d.Format("2006-01-02")] = true
-		}
+		reserved = append(reserved, rq)
 	}
 
-	var availability []model.EquipmentAvailability
-	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
-		availability = append(availability, model.EquipmentAvailability{
-			Date:      d,
-			Available: !reservedDates[d.Format("2006-01-02")],
-		})
-	}
-
-	return availability, nil
+	return reserved, rows.Err()
 }
 
 func (r *EquipmentRepository) Search(ctx context.Context, query string, pag pagination.Params) ([]*model.Equipment, int64, error) {
@@ -417,7 +687,7 @@ func (r *EquipmentRepository) Search(ctx context.Context, query string, pag pagi
 	}
 
 	argCount := len(args)
-	selectQuery := `SELECT e.id, e.owner_id, e.name, e.description, e.category, e.price_per_hour, e.price_per_day, e.price_per_week, e.location, e.latitude, e.longitude, e.available, e.auto_approve, e.created_at, e.updated_at ` + baseQuery
+	selectQuery := `SELECT ` + equipmentColumns + ` ` + baseQuery
 	selectQuery += " ORDER BY e.created_at DESC"
 	selectQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount+1, argCount+2)
 	args = append(args, pag.PerPage, pag.Offset)
@@ -431,24 +701,7 @@ func (r *EquipmentRepository) Search(ctx context.Context, query string, pag pagi
 	var equipment []*model.Equipment
 	for rows.Next() {
 		e := &model.Equipment{}
-		err := rows.Scan(
-			&e.ID,
-			&e.OwnerID,
-			&e.Name,
-			&e.Description,
-			&e.Category,
-			&e.PricePerHour,
-			&e.PricePerDay,
-			&e.PricePerWeek,
-			&e.Location,
-			&e.Latitude,
-			&e.Longitude,
-			&e.Available,
-			&e.AutoApprove,
-			&e.CreatedAt,
-			&e.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanEquipment(rows, e); err != nil {
 			return nil, 0, err
 		}
 		equipment = append(equipment, e)
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+var ErrBlackoutNotFound = errors.New("blackout not found")
+
+// blackoutColumns are selected from blackoutTables, which brings in the
+// equipment's time zone for weekly occurrences.
+const blackoutColumns = `b.id, b.equipment_id, b.start_date, b.end_date, b.reason, b.notes, b.recur_weekly, b.recur_until, b.created_at, b.updated_at, e.time_zone`
+
+const blackoutTables = `equipment_blackouts b JOIN equipment e ON e.id = b.equipment_id`
+
+// blackoutOverlapCondition matches equipment with a blackout occurrence
+// intersecting [$start, $end). For weekly blackouts it only has to test the
+// first occurrence ending after $start, since occurrences are shorter than a
+// week and never overlap each other. Occurrences are k weeks after the first
+// in the equipment's wall-clock time, as in Blackout.Overlaps; k is
+// estimated from a fixed week, which a clock change can put one week off,
+// so the neighbouring occurrences are tested too.
+const blackoutOverlapCondition = `EXISTS (
+	SELECT 1
+	FROM equipment_blackouts b
+	CROSS JOIN LATERAL (
+		SELECT CASE WHEN b.recur_weekly AND $%[1]d >= b.end_date
+			THEN FLOOR(EXTRACT(EPOCH FROM ($%[1]d - b.end_date)) / 604800)::int + 1
+			ELSE 0 END AS k
+	) n
+	CROSS JOIN LATERAL generate_series(GREATEST(n.k - 1, 0), CASE WHEN b.recur_weekly THEN n.k + 1 ELSE 0 END) AS o(k)
+	CROSS JOIN LATERAL (
+		SELECT
+			CASE WHEN o.k = 0 THEN b.start_date
+				ELSE ((b.start_date AT TIME ZONE e.time_zone) + o.k * INTERVAL '7 days') AT TIME ZONE e.time_zone END AS start_date,
+			CASE WHEN o.k = 0 THEN b.end_date
+				ELSE ((b.end_date AT TIME ZONE e.time_zone) + o.k * INTERVAL '7 days') AT TIME ZONE e.time_zone END AS end_date
+	) occurrence
+	WHERE b.equipment_id = e.id
+	AND occurrence.start_date < $%[2]d
+	AND occurrence.end_date > $%[1]d
+	AND (NOT b.recur_weekly OR b.recur_until IS NULL OR occurrence.start_date < b.recur_until)
+)`
+
+func blackoutOverlap(startArg, endArg int) string {
+	return fmt.Sprintf(blackoutOverlapCondition, startArg, endArg)
+}
+
+func scanBlackout(row rowScanner, b *model.Blackout) error {
+	var notes sql.NullString
+	var recurUntil sql.NullTime
+	var timeZone string
+	err := row.Scan(
+		&b.ID,
+		&b.EquipmentID,
+		&b.StartDate,
+		&b.EndDate,
+		&b.Reason,
+		&notes,
+		&b.RecurWeekly,
+		&recurUntil,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+		&timeZone,
+	)
+	if err != nil {
+		return err
+	}
+	if b.Location, err = time.LoadLocation(timeZone); err != nil {
+		return err
+	}
+	b.Notes = notes.String
+	if recurUntil.Valid {
+		b.RecurUntil = &recurUntil.Time
+	}
+	return nil
+}
+
+func (r *EquipmentRepository) CreateBlackout(ctx context.Context, blackout *model.Blackout) error {
+	query := `
+		INSERT INTO equipment_blackouts (id, equipment_id, start_date, end_date, reason, notes, recur_weekly, recur_until, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	blackout.ID = uuid.New()
+	blackout.CreatedAt = time.Now()
+	blackout.UpdatedAt = time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query,
+		blackout.ID,
+		blackout.EquipmentID,
+		blackout.StartDate,
+		blackout.EndDate,
+		blackout.Reason,
+		blackout.Notes,
+		blackout.RecurWeekly,
+		blackout.RecurUntil,
+		blackout.CreatedAt,
+		blackout.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := insertBlackoutEvent(ctx, tx, model.EventBlackoutCreated, blackout); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *EquipmentRepository) GetBlackout(ctx context.Context, equipmentID, blackoutID uuid.UUID) (*model.Blackout, error) {
+	query := `SELECT ` + blackoutColumns + ` FROM ` + blackoutTables + ` WHERE b.id = $1 AND b.equipment_id = $2`
+
+	blackout := &model.Blackout{}
+	if err := scanBlackout(r.db.QueryRowContext(ctx, query, blackoutID, equipmentID), blackout); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBlackoutNotFound
+		}
+		return nil, err
+	}
+
+	return blackout, nil
+}
+
+func (r *EquipmentRepository) ListBlackouts(ctx context.Context, equipmentID uuid.UUID) ([]model.Blackout, error) {
+	query := `SELECT ` + blackoutColumns + ` FROM ` + blackoutTables + ` WHERE b.equipment_id = $1 ORDER BY b.start_date`
+	return queryBlackouts(ctx, r.db, query, equipmentID)
+}
+
+// BlackoutsBetween returns the blackouts that may have an occurrence inside
+// [startDate, endDate); callers still check each with Blackout.Overlaps.
+func (r *EquipmentRepository) BlackoutsBetween(ctx context.Context, equipmentID uuid.UUID, startDate, endDate time.Time) ([]model.Blackout, error) {
+	return blackoutsBetween(ctx, r.db, equipmentID, startDate, endDate)
+}
+
+func blackoutsBetween(ctx context.Context, q querier, equipmentID uuid.UUID, startDate, endDate time.Time) ([]model.Blackout, error) {
+	query := `
+		SELECT ` + blackoutColumns + `
+		FROM ` + blackoutTables + `
+		WHERE b.equipment_id = $1
+		AND b.start_date < $3
+		AND (b.end_date > $2 OR (b.recur_weekly AND (b.recur_until IS NULL OR b.recur_until > $2)))
+	`
+	return queryBlackouts(ctx, q, query, equipmentID, startDate, endDate)
+}
+
+func queryBlackouts(ctx context.Context, q querier, query string, args ...interface{}) ([]model.Blackout, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blackouts := []model.Blackout{}
+	for rows.Next() {
+		var blackout model.Blackout
+		if err := scanBlackout(rows, &blackout); err != nil {
+			return nil, err
+		}
+		blackouts = append(blackouts, blackout)
+	}
+
+	return blackouts, rows.Err()
+}
+
+func (r *EquipmentRepository) UpdateBlackout(ctx context.Context, blackout *model.Blackout) error {
+	query := `
+		UPDATE equipment_blackouts
+		SET start_date = $1, end_date = $2, reason = $3, notes = $4, recur_weekly = $5, recur_until = $6, updated_at = $7
+		WHERE id = $8 AND equipment_id = $9
+	`
+
+	blackout.UpdatedAt = time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query,
+		blackout.StartDate,
+		blackout.EndDate,
+		blackout.Reason,
+		blackout.Notes,
+		blackout.RecurWeekly,
+		blackout.RecurUntil,
+		blackout.UpdatedAt,
+		blackout.ID,
+		blackout.EquipmentID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrBlackoutNotFound
+	}
+
+	if err := insertBlackoutEvent(ctx, tx, model.EventBlackoutUpdated, blackout); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *EquipmentRepository) DeleteBlackout(ctx context.Context, equipmentID, blackoutID uuid.UUID) error {
+	query := `
+		DELETE FROM equipment_blackouts
+		WHERE id = $1 AND equipment_id = $2
+		RETURNING id, equipment_id, start_date, end_date, reason, recur_weekly, recur_until
+	`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	blackout := &model.Blackout{}
+	var recurUntil sql.NullTime
+	err = tx.QueryRowContext(ctx, query, blackoutID, equipmentID).Scan(
+		&blackout.ID,
+		&blackout.EquipmentID,
+		&blackout.StartDate,
+		&blackout.EndDate,
+		&blackout.Reason,
+		&blackout.RecurWeekly,
+		&recurUntil,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrBlackoutNotFound
+		}
+		return err
+	}
+	if recurUntil.Valid {
+		blackout.RecurUntil = &recurUntil.Time
+	}
+
+	if err := insertBlackoutEvent(ctx, tx, model.EventBlackoutDeleted, blackout); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertBlackoutEvent records a blackout change as an event of its
+// equipment, so it is ordered with the equipment's other events.
+func insertBlackoutEvent(ctx context.Context, tx *sql.Tx, eventType model.DomainEventType, blackout *model.Blackout) error {
+	var ownerID uuid.UUID
+	if err := tx.QueryRowContext(ctx, `SELECT owner_id FROM equipment WHERE id = $1`, blackout.EquipmentID).Scan(&ownerID); err != nil {
+		return err
+	}
+
+	return insertDomainEvent(ctx, tx, eventType, blackout.EquipmentID, model.BlackoutEventData{
+		ID:          blackout.ID,
+		EquipmentID: blackout.EquipmentID,
+		OwnerID:     ownerID,
+		StartDate:   blackout.StartDate,
+		EndDate:     blackout.EndDate,
+		Reason:      blackout.Reason,
+		RecurWeekly: blackout.RecurWeekly,
+		RecurUntil:  blackout.RecurUntil,
+	})
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+var ErrPricingRuleNotFound = errors.New("pricing rule not found")
+
+// Rule amounts are denominated in the currency of their equipment, so rules
+// are always read joined with it.
+const pricingRuleColumns = `p.id, p.equipment_id, p.type, p.name, p.percent, p.amount, p.min_days, p.starts_on, p.ends_on, p.active, p.created_at, p.updated_at, e.currency`
+
+const pricingRuleFrom = ` FROM pricing_rules p JOIN equipment e ON e.id = p.equipment_id`
+
+func scanPricingRule(row rowScanner, rule *model.PricingRule) error {
+	var name, amount sql.NullString
+	var currency string
+	var percent sql.NullFloat64
+	var minDays sql.NullInt64
+	var startsOn, endsOn sql.NullTime
+	err := row.Scan(
+		&rule.ID,
+		&rule.EquipmentID,
+		&rule.Type,
+		&name,
+		&percent,
+		&amount,
+		&minDays,
+		&startsOn,
+		&endsOn,
+		&rule.Active,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+		&currency,
+	)
+	if err != nil {
+		return err
+	}
+	if rule.Amount, err = parseMoney(amount, currency); err != nil {
+		return err
+	}
+	rule.Name = name.String
+	if percent.Valid {
+		rule.Percent = &percent.Float64
+	}
+	if minDays.Valid {
+		days := int(minDays.Int64)
+		rule.MinDays = &days
+	}
+	if startsOn.Valid {
+		rule.StartsOn = &startsOn.Time
+	}
+	if endsOn.Valid {
+		rule.EndsOn = &endsOn.Time
+	}
+	return nil
+}
+
+func (r *EquipmentRepository) CreatePricingRule(ctx context.Context, rule *model.PricingRule) error {
+	query := `
+		INSERT INTO pricing_rules (id, equipment_id, type, name, percent, amount, min_days, starts_on, ends_on, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	rule.ID = uuid.New()
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, query,
+		rule.ID,
+		rule.EquipmentID,
+		rule.Type,
+		rule.Name,
+		rule.Percent,
+		rule.Amount,
+		rule.MinDays,
+		rule.StartsOn,
+		rule.EndsOn,
+		rule.Active,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *EquipmentRepository) GetPricingRule(ctx context.Context, equipmentID, ruleID uuid.UUID) (*model.PricingRule, error) {
+	query := `SELECT ` + pricingRuleColumns + pricingRuleFrom + ` WHERE p.id = $1 AND p.equipment_id = $2`
+
+	rule := &model.PricingRule{}
+	if err := scanPricingRule(r.db.QueryRowContext(ctx, query, ruleID, equipmentID), rule); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPricingRuleNotFound
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (r *EquipmentRepository) ListPricingRules(ctx context.Context, equipmentID uuid.UUID, activeOnly bool) ([]model.PricingRule, error) {
+	query := `SELECT ` + pricingRuleColumns + pricingRuleFrom + ` WHERE p.equipment_id = $1`
+	if activeOnly {
+		query += ` AND p.active`
+	}
+	query += ` ORDER BY p.created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []model.PricingRule{}
+	for rows.Next() {
+		var rule model.PricingRule
+		if err := scanPricingRule(rows, &rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *EquipmentRepository) UpdatePricingRule(ctx context.Context, rule *model.PricingRule) error {
+	query := `
+		UPDATE pricing_rules
+		SET name = $1, percent = $2, amount = $3, min_days = $4, starts_on = $5, ends_on = $6, active = $7, updated_at = $8
+		WHERE id = $9 AND equipment_id = $10
+	`
+
+	rule.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		rule.Name,
+		rule.Percent,
+		rule.Amount,
+		rule.MinDays,
+		rule.StartsOn,
+		rule.EndsOn,
+		rule.Active,
+		rule.UpdatedAt,
+		rule.ID,
+		rule.EquipmentID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrPricingRuleNotFound
+	}
+
+	return nil
+}
+
+func (r *EquipmentRepository) DeletePricingRule(ctx context.Context, equipmentID, ruleID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM pricing_rules WHERE id = $1 AND equipment_id = $2`, ruleID, equipmentID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrPricingRuleNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func (r *EquipmentRepository) GetOpeningHours(ctx context.Context, equipmentID uuid.UUID) ([]model.OpeningHours, error) {
+	query := `
+		SELECT weekday, opens_minute, closes_minute
+		FROM equipment_opening_hours
+		WHERE equipment_id = $1
+		ORDER BY weekday, opens_minute
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := []model.OpeningHours{}
+	for rows.Next() {
+		var weekday, opens, closes int
+		if err := rows.Scan(&weekday, &opens, &closes); err != nil {
+			return nil, err
+		}
+		hours = append(hours, model.OpeningHours{
+			Weekday: time.Weekday(weekday),
+			Opens:   model.FormatClock(time.Duration(opens) * time.Minute),
+			Closes:  model.FormatClock(time.Duration(closes) * time.Minute),
+		})
+	}
+
+	return hours, rows.Err()
+}
+
+func (r *EquipmentRepository) ReplaceOpeningHours(ctx context.Context, equipmentID uuid.UUID, hours []model.OpeningHours) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM equipment_opening_hours WHERE equipment_id = $1`, equipmentID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO equipment_opening_hours (id, equipment_id, weekday, opens_minute, closes_minute)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, h := range hours {
+		daily, err := h.DailyHours()
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, query,
+			uuid.New(),
+			equipmentID,
+			int(daily.Weekday),
+			int(daily.Opens/time.Minute),
+			int(daily.Closes/time.Minute),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+var (
+	ErrUnitNotFound     = errors.New("equipment unit not found")
+	ErrUnitSerialExists = errors.New("serial number already exists for this equipment")
+)
+
+const unitColumns = `id, equipment_id, serial_number, status, notes, created_at, updated_at`
+
+func scanUnit(row rowScanner, u *model.EquipmentUnit) error {
+	var notes sql.NullString
+	if err := row.Scan(&u.ID, &u.EquipmentID, &u.SerialNumber, &u.Status, &notes, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		return err
+	}
+	u.Notes = notes.String
+	return nil
+}
+
+func (r *EquipmentRepository) CreateUnit(ctx context.Context, unit *model.EquipmentUnit) error {
+	query := `
+		INSERT INTO equipment_units (id, equipment_id, serial_number, status, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	unit.ID = uuid.New()
+	unit.CreatedAt = time.Now()
+	unit.UpdatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, query,
+		unit.ID,
+		unit.EquipmentID,
+		unit.SerialNumber,
+		unit.Status,
+		unit.Notes,
+		unit.CreatedAt,
+		unit.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrUnitSerialExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *EquipmentRepository) GetUnit(ctx context.Context, equipmentID, unitID uuid.UUID) (*model.EquipmentUnit, error) {
+	query := `SELECT ` + unitColumns + ` FROM equipment_units WHERE id = $1 AND equipment_id = $2`
+
+	unit := &model.EquipmentUnit{}
+	if err := scanUnit(r.db.QueryRowContext(ctx, query, unitID, equipmentID), unit); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUnitNotFound
+		}
+		return nil, err
+	}
+
+	return unit, nil
+}
+
+func (r *EquipmentRepository) ListUnits(ctx context.Context, equipmentID uuid.UUID) ([]model.EquipmentUnit, error) {
+	query := `SELECT ` + unitColumns + ` FROM equipment_units WHERE equipment_id = $1 ORDER BY serial_number`
+
+	rows, err := r.db.QueryContext(ctx, query, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := []model.EquipmentUnit{}
+	for rows.Next() {
+		var unit model.EquipmentUnit
+		if err := scanUnit(rows, &unit); err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+
+	return units, rows.Err()
+}
+
+func (r *EquipmentRepository) UpdateUnit(ctx context.Context, unit *model.EquipmentUnit) error {
+	query := `
+		UPDATE equipment_units
+		SET serial_number = $1, status = $2, notes = $3, updated_at = $4
+		WHERE id = $5 AND equipment_id = $6
+	`
+
+	unit.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		unit.SerialNumber,
+		unit.Status,
+		unit.Notes,
+		unit.UpdatedAt,
+		unit.ID,
+		unit.EquipmentID,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrUnitSerialExists
+		}
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrUnitNotFound
+	}
+
+	return nil
+}
+
+func (r *EquipmentRepository) DeleteUnit(ctx context.Context, equipmentID, unitID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM equipment_units WHERE id = $1 AND equipment_id = $2`, unitID, equipmentID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrUnitNotFound
+	}
+
+	return nil
+}
+
+func (r *EquipmentRepository) IsUnitReserved(ctx context.Context, unitID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM reservation_units WHERE unit_id = $1)`, unitID).Scan(&exists)
+	return exists, err
+}
+
+// SyncUnitQuantity keeps equipment.quantity equal to the number of units that
+// can currently be rented, so availability checks only ever read one column.
+func (r *EquipmentRepository) SyncUnitQuantity(ctx context.Context, equipmentID uuid.UUID) (int, error) {
+	query := `
+		UPDATE equipment
+		SET quantity = (SELECT COUNT(*) FROM equipment_units WHERE equipment_id = $1 AND status = 'available'),
+		    updated_at = $2
+		WHERE id = $1
+		RETURNING quantity
+	`
+
+	var quantity int
+	if err := r.db.QueryRowContext(ctx, query, equipmentID, time.Now()).Scan(&quantity); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrEquipmentNotFound
+		}
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+func (r *EquipmentRepository) FindFreeUnits(ctx context.Context, equipmentID uuid.UUID, startDate, endDate time.Time, quantity int) ([]uuid.UUID, error) {
+	return findFreeUnits(ctx, r.db, equipmentID, startDate, endDate, quantity)
+}
+
+func findFreeUnits(ctx context.Context, q querier, equipmentID uuid.UUID, startDate, endDate time.Time, quantity int) ([]uuid.UUID, error) {
+	query := `
+		SELECT u.id
+		FROM equipment_units u
+		WHERE u.equipment_id = $1
+		AND u.status = 'available'
+		AND NOT EXISTS (
+			SELECT 1
+			FROM reservation_units ru
+			JOIN reservations r ON r.id = ru.reservation_id
+			WHERE ru.unit_id = u.id
+			AND r.status IN ('pending', 'approved', 'active')
+			AND r.start_date < $3
+			AND r.end_date > $2
+		)
+		ORDER BY u.serial_number
+		LIMIT $4
+	`
+
+	rows, err := q.QueryContext(ctx, query, equipmentID, startDate, endDate, quantity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// UnitsBooked reports whether any of the units is held by a reservation
+// other than excludeID during [startDate, endDate).
+func (r *EquipmentRepository) UnitsBooked(ctx context.Context, unitIDs []uuid.UUID, excludeID uuid.UUID, startDate, endDate time.Time) (bool, error) {
+	return unitsBooked(ctx, r.db, unitIDs, excludeID, startDate, endDate)
+}
+
+func unitsBooked(ctx context.Context, q querier, unitIDs []uuid.UUID, excludeID uuid.UUID, startDate, endDate time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM reservation_units ru
+			JOIN reservations r ON r.id = ru.reservation_id
+			WHERE ru.unit_id = ANY($1::uuid[])
+			AND r.id <> $2
+			AND r.status IN ('pending', 'approved', 'active')
+			AND r.start_date < $4
+			AND r.end_date > $3
+		)
+	`
+
+	ids := make([]string, len(unitIDs))
+	for i, id := range unitIDs {
+		ids[i] = id.String()
+	}
+
+	var booked bool
+	err := q.QueryRowContext(ctx, query, pq.Array(ids), excludeID, startDate, endDate).Scan(&booked)
+	return booked, err
+}
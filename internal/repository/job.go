@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/pagination"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobExists   = errors.New("an unfinished job with this unique key already exists")
+	ErrJobNotDead  = errors.New("job is not dead-lettered")
+	ErrJobLost     = errors.New("job was reclaimed by another worker")
+)
+
+const jobColumns = `id, type, payload, unique_key, status, attempts, max_attempts, run_at, last_error, locked_at, completed_at, created_at, updated_at`
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+func (r *JobRepository) Create(ctx context.Context, job *model.Job) error {
+	return insertJob(ctx, r.db, job)
+}
+
+// execer runs statements on the database or inside a transaction, so a job
+// can be enqueued together with the change that calls for it.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertJob(ctx context.Context, e execer, job *model.Job) error {
+	job.ID = uuid.New()
+	job.Status = model.JobQueued
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	if job.RunAt.IsZero() {
+		job.RunAt = job.CreatedAt
+	}
+
+	_, err := e.ExecContext(ctx, `
+		INSERT INTO jobs (id, type, payload, unique_key, status, max_attempts, run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		job.ID,
+		job.Type,
+		[]byte(job.Payload),
+		nullString(job.UniqueKey),
+		job.Status,
+		job.MaxAttempts,
+		job.RunAt,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrJobExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Claim locks the next job due by now and marks it running. Jobs left
+// running since before staleBefore belong to a worker that died and are
+// claimed again. SKIP LOCKED lets concurrent workers claim different jobs
+// without waiting on each other. It returns nil when nothing is due.
+func (r *JobRepository) Claim(ctx context.Context, now, staleBefore time.Time) (*model.Job, error) {
+	query := `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, locked_at = $1, updated_at = $1
+		WHERE id = (
+			SELECT id
+			FROM jobs
+			WHERE (status = 'queued' AND run_at <= $1)
+			OR (status = 'running' AND locked_at < $2)
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, now, staleBefore))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// Extend moves a running job's lock to now while the caller's claim on it
+// still holds, so a job whose handler is still working is not reclaimed as
+// stale. It returns ErrJobLost once the claim is gone.
+func (r *JobRepository) Extend(ctx context.Context, job *model.Job) error {
+	var lockedAt time.Time
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE jobs SET locked_at = $1, updated_at = $1
+		WHERE id = $2 AND status = 'running' AND locked_at = $3
+		RETURNING locked_at
+	`, time.Now(), job.ID, job.LockedAt).Scan(&lockedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrJobLost
+	}
+	if err != nil {
+		return err
+	}
+
+	job.LockedAt = &lockedAt
+	return nil
+}
+
+// Complete marks a claimed job succeeded.
+func (r *JobRepository) Complete(ctx context.Context, job *model.Job) error {
+	now := time.Now()
+	return r.release(ctx, job, `status = 'succeeded', completed_at = $1, updated_at = $1`, now)
+}
+
+// Reschedule puts a failed job back in the queue to run again at runAt.
+func (r *JobRepository) Reschedule(ctx context.Context, job *model.Job, runAt time.Time, lastError string) error {
+	return r.release(ctx, job, `status = 'queued', locked_at = NULL, run_at = $1, last_error = $2, updated_at = $3`, runAt, lastError, time.Now())
+}
+
+// Bury dead-letters a job that will not be retried automatically.
+func (r *JobRepository) Bury(ctx context.Context, job *model.Job, lastError string) error {
+	now := time.Now()
+	return r.release(ctx, job, `status = 'dead', locked_at = NULL, last_error = $1, completed_at = $2, updated_at = $2`, lastError, now)
+}
+
+// release applies set to a job only while the caller's claim on it still
+// holds, so a worker that overran cannot undo another worker's result.
+func (r *JobRepository) release(ctx context.Context, job *model.Job, set string, args ...interface{}) error {
+	n := len(args)
+	query := fmt.Sprintf(`UPDATE jobs SET %s WHERE id = $%d AND status = 'running' AND locked_at = $%d`, set, n+1, n+2)
+
+	result, err := r.db.ExecContext(ctx, query, append(args, job.ID, job.LockedAt)...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrJobLost
+	}
+
+	return nil
+}
+
+// Retry moves a dead job back to the queue with a fresh set of attempts.
+func (r *JobRepository) Retry(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	query := `
+		UPDATE jobs
+		SET status = 'queued', attempts = 0, run_at = $1, locked_at = NULL, completed_at = NULL, updated_at = $1
+		WHERE id = $2 AND status = 'dead'
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, time.Now(), id))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrJobExists
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrJobNotDead
+	}
+
+	return job, nil
+}
+
+func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE id = $1`
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (r *JobRepository) List(ctx context.Context, filter *model.JobFilter, pag pagination.Params) ([]*model.Job, int64, error) {
+	baseQuery := `FROM jobs WHERE 1=1`
+	args := []interface{}{}
+	argCount := 0
+
+	if filter != nil {
+		if filter.Status != nil {
+			argCount++
+			baseQuery += fmt.Sprintf(" AND status = $%d", argCount)
+			args = append(args, *filter.Status)
+		}
+		if filter.Type != "" {
+			argCount++
+			baseQuery += fmt.Sprintf(" AND type = $%d", argCount)
+			args = append(args, filter.Type)
+		}
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) "+baseQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	selectQuery := `SELECT ` + jobColumns + ` ` + baseQuery
+	selectQuery += " ORDER BY created_at DESC"
+	selectQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount+1, argCount+2)
+	args = append(args, pag.PerPage, pag.Offset)
+
+	rows, err := r.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	jobs := []*model.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, total, rows.Err()
+}
+
+func scanJob(row rowScanner) (*model.Job, error) {
+	var job model.Job
+	var payload []byte
+	var uniqueKey, lastError sql.NullString
+
+	err := row.Scan(
+		&job.ID,
+		&job.Type,
+		&payload,
+		&uniqueKey,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.RunAt,
+		&lastError,
+		&job.LockedAt,
+		&job.CompletedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Payload = payload
+	job.UniqueKey = uniqueKey.String
+	job.LastError = lastError.String
+
+	return &job, nil
+}
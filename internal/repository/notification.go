@@ -3,11 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 
 	"github.com/abneribeiro/goapi/internal/model"
 	"github.com/abneribeiro/goapi/internal/pkg/pagination"
@@ -15,6 +17,10 @@ import (
 
 var ErrNotificationNotFound = errors.New("notification not found")
 
+// NotificationChannel is the Postgres channel notification changes are
+// announced on. The announcement is sent when the change commits.
+const NotificationChannel = "notification_changes"
+
 type NotificationRepository struct {
 	db *sql.DB
 }
@@ -23,39 +29,75 @@ func NewNotificationRepository(db *sql.DB) *NotificationRepository {
 	return &NotificationRepository{db: db}
 }
 
+// Create stores a notification. An ID and creation time set by the caller
+// are kept, and creating the same ID again does nothing, so a queued
+// notification is stored once however often its job is retried. One not
+// shown in the app is kept only for its deliveries.
 func (r *NotificationRepository) Create(ctx context.Context, notification *model.Notification) error {
 	query := `
-		INSERT INTO notifications (id, user_id, type, title, message, read, reference_id, reference_type, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO notifications (id, user_id, type, title, message, data, read, reference_id, reference_type, created_at, in_app)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (id) DO NOTHING
+		RETURNING sequence
 	`
 
-	notification.ID = uuid.New()
+	if notification.ID == uuid.Nil {
+		notification.ID = uuid.New()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
 	notification.Read = false
-	notification.CreatedAt = time.Now()
 
-	_, err := r.db.ExecContext(ctx, query,
+	data, err := marshalNotificationData(notification.Data)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query,
 		notification.ID,
 		notification.UserID,
 		notification.Type,
 		notification.Title,
 		notification.Message,
+		data,
 		notification.Read,
 		notification.ReferenceID,
 		notification.ReferenceType,
 		notification.CreatedAt,
-	)
+		notification.InApp,
+	).Scan(&notification.Sequence)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 
-	return err
+	if notification.InApp {
+		if err := notifyChange(ctx, tx, notification.UserID, model.NotificationChangeCreated); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *NotificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Notification, error) {
 	query := `
-		SELECT id, user_id, type, title, message, read, reference_id, reference_type, created_at
+		SELECT id, user_id, type, title, message, data, read, reference_id, reference_type, created_at
 		FROM notifications
 		WHERE id = $1
 	`
 
 	notification := &model.Notification{}
+	var data []byte
 	var refID sql.NullString
 	var refType sql.NullString
 
@@ -65,6 +107,7 @@ func (r *NotificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 		&notification.Type,
 		&notification.Title,
 		&notification.Message,
+		&data,
 		&notification.Read,
 		&refID,
 		&refType,
@@ -85,32 +128,16 @@ func (r *NotificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 	if refType.Valid {
 		notification.ReferenceType = refType.String
 	}
+	if notification.Data, err = unmarshalNotificationData(data); err != nil {
+		return nil, err
+	}
 
 	return notification, nil
 }
 
 func (r *NotificationRepository) List(ctx context.Context, filter *model.NotificationFilter, pag pagination.Params) ([]*model.Notification, int64, error) {
-	baseQuery := `FROM notifications WHERE 1=1`
-	args := []interface{}{}
-	argCount := 0
-
-	if filter != nil {
-		if filter.UserID != nil {
-			argCount++
-			baseQuery += fmt.Sprintf(" AND user_id = $%d", argCount)
-			args = append(args, *filter.UserID)
-		}
-		if filter.Read != nil {
-			argCount++
-			baseQuery += fmt.Sprintf(" AND read = $%d", argCount)
-			args = append(args, *filter.Read)
-		}
-		if filter.Type != nil {
-			argCount++
-			baseQuery += fmt.Sprintf(" AND type = $%d", argCount)
-			args = append(args, *filter.Type)
-		}
-	}
+	where, args := notificationConditions(filter)
+	baseQuery := `FROM notifications ` + where
 
 	countQuery := "SELECT COUNT(*) " + baseQuery
 	var total int64
@@ -119,9 +146,9 @@ func (r *NotificationRepository) List(ctx context.Context, filter *model.Notific
 		return nil, 0, err
 	}
 
-	selectQuery := `SELECT id, user_id, type, title, message, read, reference_id, reference_type, created_at ` + baseQuery
+	selectQuery := `SELECT id, user_id, type, title, message, data, read, reference_id, reference_type, created_at ` + baseQuery
 	selectQuery += " ORDER BY created_at DESC"
-	selectQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount+1, argCount+2)
+	selectQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
 	args = append(args, pag.PerPage, pag.Offset)
 
 	rows, err := r.db.QueryContext(ctx, selectQuery, args...)
@@ -133,6 +160,7 @@ func (r *NotificationRepository) List(ctx context.Context, filter *model.Notific
 	var notifications []*model.Notification
 	for rows.Next() {
 		n := &model.Notification{}
+		var data []byte
 		var refID sql.NullString
 		var refType sql.NullString
 
@@ -142,6 +170,7 @@ func (r *NotificationRepository) List(ctx context.Context, filter *model.Notific
 			&n.Type,
 			&n.Title,
 			&n.Message,
+			&data,
 			&n.Read,
 			&refID,
 			&refType,
@@ -158,6 +187,9 @@ func (r *NotificationRepository) List(ctx context.Context, filter *model.Notific
 		if refType.Valid {
 			n.ReferenceType = refType.String
 		}
+		if n.Data, err = unmarshalNotificationData(data); err != nil {
+			return nil, 0, err
+		}
 
 		notifications = append(notifications, n)
 	}
@@ -166,9 +198,21 @@ func (r *NotificationRepository) List(ctx context.Context, filter *model.Notific
 }
 
 func (r *NotificationRepository) MarkAsRead(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE notifications SET read = true WHERE id = $1`
+	query := `UPDATE notifications SET read = true WHERE id = $1 RETURNING user_id`
+
+	return r.changeOne(ctx, query, id, model.NotificationChangeRead)
+}
+
+func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE notifications SET read = true WHERE user_id = $1 AND read = false`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := tx.ExecContext(ctx, query, userID)
 	if err != nil {
 		return err
 	}
@@ -178,21 +222,57 @@ func (r *NotificationRepository) MarkAsRead(ctx context.Context, id uuid.UUID) e
 		return err
 	}
 
-	if rows == 0 {
-		return ErrNotificationNotFound
+	if rows > 0 {
+		if err := notifyChange(ctx, tx, userID, model.NotificationChangeRead); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
-	query := `UPDATE notifications SET read = true WHERE user_id = $1 AND read = false`
-	_, err := r.db.ExecContext(ctx, query, userID)
-	return err
+// MarkAsReadMatching marks the user's unread notifications matching the
+// filter as read, and returns how many it changed. The filter must set
+// UserID.
+func (r *NotificationRepository) MarkAsReadMatching(ctx context.Context, filter *model.NotificationFilter) (int64, error) {
+	where, args := notificationConditions(filter)
+	query := `UPDATE notifications SET read = true ` + where + ` AND read = false`
+
+	return r.changeMatching(ctx, query, args, *filter.UserID, model.NotificationChangeRead)
+}
+
+// DeleteMatching deletes the user's notifications matching the filter, and
+// returns how many it deleted. The filter must set UserID.
+func (r *NotificationRepository) DeleteMatching(ctx context.Context, filter *model.NotificationFilter) (int64, error) {
+	where, args := notificationConditions(filter)
+	query := `DELETE FROM notifications ` + where
+
+	return r.changeMatching(ctx, query, args, *filter.UserID, model.NotificationChangeDeleted)
+}
+
+// Purge deletes up to limit notifications created before the given time
+// that were read, or never shown in the app, and returns how many it
+// deleted. Their deliveries go with them. Open streams are not told, as
+// none of them were unread.
+func (r *NotificationRepository) Purge(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM notifications
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE (read OR NOT in_app) AND created_at < $1
+			LIMIT $2
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 func (r *NotificationRepository) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
-	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read = false`
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read = false AND in_app = true`
 
 	var count int64
 	err := r.db.QueryRowContext(ctx, query, userID).Scan(&count)
@@ -204,21 +284,195 @@ func (r *NotificationRepository) GetUnreadCount(ctx context.Context, userID uuid
 }
 
 func (r *NotificationRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM notifications WHERE id = $1`
+	query := `DELETE FROM notifications WHERE id = $1 RETURNING user_id`
+
+	return r.changeOne(ctx, query, id, model.NotificationChangeDeleted)
+}
 
-	result, err := r.db.ExecContext(ctx, query, id)
+// ListSince returns up to limit of the user's notifications created after
+// the given sequence, oldest first.
+func (r *NotificationRepository) ListSince(ctx context.Context, userID uuid.UUID, after int64, limit int) ([]*model.Notification, error) {
+	query := `
+		SELECT id, user_id, type, title, message, data, read, reference_id, reference_type, created_at, sequence
+		FROM notifications
+		WHERE user_id = $1 AND sequence > $2 AND in_app = true
+		ORDER BY sequence ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*model.Notification
+	for rows.Next() {
+		n := &model.Notification{}
+		var data []byte
+		var refID sql.NullString
+		var refType sql.NullString
+
+		err := rows.Scan(
+			&n.ID,
+			&n.UserID,
+			&n.Type,
+			&n.Title,
+			&n.Message,
+			&data,
+			&n.Read,
+			&refID,
+			&refType,
+			&n.CreatedAt,
+			&n.Sequence,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if refID.Valid {
+			id, _ := uuid.Parse(refID.String)
+			n.ReferenceID = &id
+		}
+		if refType.Valid {
+			n.ReferenceType = refType.String
+		}
+		if n.Data, err = unmarshalNotificationData(data); err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// LatestSequence returns the sequence of the user's newest notification, or
+// zero if they have none.
+func (r *NotificationRepository) LatestSequence(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `SELECT COALESCE(MAX(sequence), 0) FROM notifications WHERE user_id = $1`
+
+	var sequence int64
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&sequence)
+	return sequence, err
+}
+
+// changeOne runs a statement on one notification that returns its user_id
+// and announces the change.
+func (r *NotificationRepository) changeOne(ctx context.Context, query string, id uuid.UUID, kind model.NotificationChangeKind) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	var userID uuid.UUID
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotificationNotFound
+		}
+		return err
+	}
+
+	if err := notifyChange(ctx, tx, userID, kind); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// changeMatching runs a statement on several of a user's notifications and
+// announces the change if any were affected.
+func (r *NotificationRepository) changeMatching(ctx context.Context, query string, args []interface{}, userID uuid.UUID, kind model.NotificationChangeKind) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
 
 	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rows > 0 {
+		if err := notifyChange(ctx, tx, userID, kind); err != nil {
+			return 0, err
+		}
+	}
+
+	return rows, tx.Commit()
+}
+
+// notificationConditions builds the WHERE clause selecting the
+// notifications shown in the app that match the filter.
+func notificationConditions(filter *model.NotificationFilter) (string, []interface{}) {
+	where := `WHERE in_app = true`
+	args := []interface{}{}
+
+	if filter == nil {
+		return where, args
+	}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		where += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if len(filter.IDs) > 0 {
+		args = append(args, pq.Array(filter.IDs))
+		where += fmt.Sprintf(" AND id = ANY($%d::uuid[])", len(args))
+	}
+	if filter.Read != nil {
+		args = append(args, *filter.Read)
+		where += fmt.Sprintf(" AND read = $%d", len(args))
+	}
+	if filter.Type != nil {
+		args = append(args, *filter.Type)
+		where += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+	if filter.Before != nil {
+		args = append(args, *filter.Before)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+
+	return where, args
+}
+
+func notifyChange(ctx context.Context, tx *sql.Tx, userID uuid.UUID, kind model.NotificationChangeKind) error {
+	payload, err := json.Marshal(model.NotificationChange{UserID: userID, Kind: kind})
 	if err != nil {
 		return err
 	}
 
-	if rows == 0 {
-		return ErrNotificationNotFound
+	_, err = tx.ExecContext(ctx, `SELECT pg_notify($1, $2)`, NotificationChannel, string(payload))
+	return err
+}
+
+// marshalNotificationData stores data as JSON, or NULL for notifications
+// without any.
+func marshalNotificationData(data map[string]interface{}) (interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
 	}
+	return raw, nil
+}
 
-	return nil
+func unmarshalNotificationData(raw []byte) (map[string]interface{}, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
 }
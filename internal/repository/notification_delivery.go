@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+type NotificationDeliveryRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationDeliveryRepository(db *sql.DB) *NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{db: db}
+}
+
+// Get returns the delivery of a notification on a channel, or a new pending
+// one if it has not been attempted.
+func (r *NotificationDeliveryRepository) Get(ctx context.Context, notificationID uuid.UUID, channel model.DeliveryChannel) (*model.NotificationDelivery, error) {
+	query := `
+		SELECT id, notification_id, channel, status, attempts, error, sent_at, scheduled_for, created_at, updated_at
+		FROM notification_deliveries
+		WHERE notification_id = $1 AND channel = $2
+	`
+
+	delivery := &model.NotificationDelivery{}
+	var lastError sql.NullString
+	err := r.db.QueryRowContext(ctx, query, notificationID, channel).Scan(
+		&delivery.ID,
+		&delivery.NotificationID,
+		&delivery.Channel,
+		&delivery.Status,
+		&delivery.Attempts,
+		&lastError,
+		&delivery.SentAt,
+		&delivery.ScheduledFor,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &model.NotificationDelivery{
+			ID:             uuid.New(),
+			NotificationID: notificationID,
+			Channel:        channel,
+			Status:         model.DeliveryPending,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	delivery.Error = lastError.String
+
+	return delivery, nil
+}
+
+// Save records the outcome of an attempt.
+func (r *NotificationDeliveryRepository) Save(ctx context.Context, delivery *model.NotificationDelivery) error {
+	query := `
+		INSERT INTO notification_deliveries (id, notification_id, channel, status, attempts, error, sent_at, scheduled_for, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (notification_id, channel) DO UPDATE
+		SET status = EXCLUDED.status, attempts = EXCLUDED.attempts, error = EXCLUDED.error,
+			sent_at = EXCLUDED.sent_at, scheduled_for = EXCLUDED.scheduled_for, updated_at = EXCLUDED.updated_at
+	`
+
+	now := time.Now()
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = now
+	}
+	delivery.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, query,
+		delivery.ID,
+		delivery.NotificationID,
+		delivery.Channel,
+		delivery.Status,
+		delivery.Attempts,
+		nullString(delivery.Error),
+		delivery.SentAt,
+		delivery.ScheduledFor,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+	)
+	return err
+}
+
+// ListDue returns the user's unsent deliveries on a channel held until no
+// later than before, with their notifications, oldest first.
+func (r *NotificationDeliveryRepository) ListDue(ctx context.Context, userID uuid.UUID, channel model.DeliveryChannel, before time.Time) ([]*model.NotificationDelivery, []*model.Notification, error) {
+	query := `
+		SELECT d.id, d.notification_id, d.channel, d.status, d.attempts, d.error, d.sent_at, d.scheduled_for, d.created_at, d.updated_at,
+			n.id, n.user_id, n.type, n.title, n.message, n.data, n.read, n.reference_id, n.reference_type, n.created_at
+		FROM notification_deliveries d
+		JOIN notifications n ON n.id = d.notification_id
+		WHERE n.user_id = $1 AND d.channel = $2 AND d.status IN ('pending', 'failed') AND d.scheduled_for <= $3
+		ORDER BY n.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, channel, before)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*model.NotificationDelivery
+	var notifications []*model.Notification
+	for rows.Next() {
+		d := &model.NotificationDelivery{}
+		n := &model.Notification{}
+		var lastError, refID, refType sql.NullString
+		var data []byte
+
+		err := rows.Scan(
+			&d.ID,
+			&d.NotificationID,
+			&d.Channel,
+			&d.Status,
+			&d.Attempts,
+			&lastError,
+			&d.SentAt,
+			&d.ScheduledFor,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+			&n.ID,
+			&n.UserID,
+			&n.Type,
+			&n.Title,
+			&n.Message,
+			&data,
+			&n.Read,
+			&refID,
+			&refType,
+			&n.CreatedAt,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		d.Error = lastError.String
+		if refID.Valid {
+			id, _ := uuid.Parse(refID.String)
+			n.ReferenceID = &id
+		}
+		n.ReferenceType = refType.String
+		if n.Data, err = unmarshalNotificationData(data); err != nil {
+			return nil, nil, err
+		}
+
+		deliveries = append(deliveries, d)
+		notifications = append(notifications, n)
+	}
+
+	return deliveries, notifications, rows.Err()
+}
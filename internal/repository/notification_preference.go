@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+type NotificationPreferenceRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationPreferenceRepository(db *sql.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{db: db}
+}
+
+// Get returns the user's settings and the preferences they changed. A user
+// who never saved any gets UTC and no preferences.
+func (r *NotificationPreferenceRepository) Get(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error) {
+	prefs := &model.NotificationPreferences{
+		UserID:   userID,
+		TimeZone: "UTC",
+	}
+
+	var quietStart, quietEnd sql.NullString
+	err := r.db.QueryRowContext(ctx,
+		`SELECT time_zone, quiet_start, quiet_end FROM notification_settings WHERE user_id = $1`, userID,
+	).Scan(&prefs.TimeZone, &quietStart, &quietEnd)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if quietStart.Valid && quietEnd.Valid {
+		prefs.QuietHours = &model.QuietHours{Start: quietStart.String, End: quietEnd.String}
+	}
+
+	query := `
+		SELECT type, channel, enabled, digest
+		FROM notification_preferences
+		WHERE user_id = $1
+		ORDER BY type, channel
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pref model.NotificationPreference
+		if err := rows.Scan(&pref.Type, &pref.Channel, &pref.Enabled, &pref.Digest); err != nil {
+			return nil, err
+		}
+		prefs.Preferences = append(prefs.Preferences, pref)
+	}
+
+	return prefs, rows.Err()
+}
+
+// Save replaces the user's settings and stores each of the given
+// preferences, leaving others as they were.
+func (r *NotificationPreferenceRepository) Save(ctx context.Context, prefs *model.NotificationPreferences) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var quietStart, quietEnd interface{}
+	if prefs.QuietHours != nil {
+		quietStart, quietEnd = prefs.QuietHours.Start, prefs.QuietHours.End
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO notification_settings (user_id, time_zone, quiet_start, quiet_end, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET time_zone = EXCLUDED.time_zone, quiet_start = EXCLUDED.quiet_start,
+			quiet_end = EXCLUDED.quiet_end, updated_at = EXCLUDED.updated_at
+	`, prefs.UserID, prefs.TimeZone, quietStart, quietEnd, now)
+	if err != nil {
+		return err
+	}
+
+	for _, pref := range prefs.Preferences {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO notification_preferences (user_id, type, channel, enabled, digest, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (user_id, type, channel) DO UPDATE
+			SET enabled = EXCLUDED.enabled, digest = EXCLUDED.digest, updated_at = EXCLUDED.updated_at
+		`, prefs.UserID, pref.Type, pref.Channel, pref.Enabled, pref.Digest, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
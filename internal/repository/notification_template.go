@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+var ErrNotificationTemplateNotFound = errors.New("notification template not found")
+
+type NotificationTemplateRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationTemplateRepository(db *sql.DB) *NotificationTemplateRepository {
+	return &NotificationTemplateRepository{db: db}
+}
+
+// Create stores the template as the next version for its type and locale.
+// Two versions created at once conflict on the version number, and the
+// later one is retried.
+func (r *NotificationTemplateRepository) Create(ctx context.Context, tmpl *model.NotificationTemplate) error {
+	query := `
+		INSERT INTO notification_templates (id, type, locale, version, title, message, created_by, created_at)
+		SELECT $1, $2, $3, COALESCE(MAX(version), 0) + 1, $4, $5, $6, $7
+		FROM notification_templates
+		WHERE type = $2 AND locale = $3
+		RETURNING version
+	`
+
+	tmpl.ID = uuid.New()
+	tmpl.CreatedAt = time.Now()
+
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		err = r.db.QueryRowContext(ctx, query,
+			tmpl.ID,
+			tmpl.Type,
+			tmpl.Locale,
+			tmpl.Title,
+			tmpl.Message,
+			tmpl.CreatedBy,
+			tmpl.CreatedAt,
+		).Scan(&tmpl.Version)
+		if !isUniqueViolation(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// Latest returns the newest version of a type's template in a locale.
+func (r *NotificationTemplateRepository) Latest(ctx context.Context, notifType model.NotificationType, locale string) (*model.NotificationTemplate, error) {
+	query := `
+		SELECT id, type, locale, version, title, message, created_by, created_at
+		FROM notification_templates
+		WHERE type = $1 AND locale = $2
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	tmpl, err := scanNotificationTemplate(r.db.QueryRowContext(ctx, query, notifType, locale))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotificationTemplateNotFound
+	}
+	return tmpl, err
+}
+
+// List returns every stored version matching the filter, newest first.
+func (r *NotificationTemplateRepository) List(ctx context.Context, filter *model.NotificationTemplateFilter) ([]*model.NotificationTemplate, error) {
+	query := `
+		SELECT id, type, locale, version, title, message, created_by, created_at
+		FROM notification_templates
+		WHERE true
+	`
+	args := []interface{}{}
+
+	if filter != nil {
+		if filter.Type != nil {
+			args = append(args, *filter.Type)
+			query += fmt.Sprintf(" AND type = $%d", len(args))
+		}
+		if filter.Locale != nil {
+			args = append(args, *filter.Locale)
+			query += fmt.Sprintf(" AND locale = $%d", len(args))
+		}
+	}
+	query += " ORDER BY type, locale, version DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*model.NotificationTemplate
+	for rows.Next() {
+		tmpl, err := scanNotificationTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tmpl)
+	}
+
+	return templates, rows.Err()
+}
+
+func scanNotificationTemplate(row rowScanner) (*model.NotificationTemplate, error) {
+	tmpl := &model.NotificationTemplate{}
+	var createdBy sql.NullString
+
+	err := row.Scan(
+		&tmpl.ID,
+		&tmpl.Type,
+		&tmpl.Locale,
+		&tmpl.Version,
+		&tmpl.Title,
+		&tmpl.Message,
+		&createdBy,
+		&tmpl.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if createdBy.Valid {
+		id, _ := uuid.Parse(createdBy.String)
+		tmpl.CreatedBy = &id
+	}
+
+	return tmpl, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+var ErrDomainEventNotFound = errors.New("domain event not found")
+
+// DomainEventChannel is the Postgres channel each domain event's sequence
+// is announced on when it commits, for consumers that want it straight away
+// rather than through the relay.
+const DomainEventChannel = "domain_events"
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// insertDomainEvent records an event in the outbox as part of tx. Callers
+// write it after the change to the aggregate's row, so concurrent changes
+// to one aggregate get sequences in commit order.
+func insertDomainEvent(ctx context.Context, tx *sql.Tx, eventType model.DomainEventType, aggregateID uuid.UUID, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		WITH inserted AS (
+			INSERT INTO outbox_events (id, type, aggregate_type, aggregate_id, data, occurred_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING sequence
+		)
+		SELECT pg_notify($7, sequence::text) FROM inserted
+	`, uuid.New(), eventType, eventType.Aggregate(), aggregateID, payload, time.Now(), DomainEventChannel)
+	return err
+}
+
+func (r *OutboxRepository) GetBySequence(ctx context.Context, sequence int64) (*model.DomainEvent, error) {
+	query := `
+		SELECT sequence, id, type, aggregate_type, aggregate_id, data, occurred_at
+		FROM outbox_events
+		WHERE sequence = $1
+	`
+
+	event := &model.DomainEvent{}
+	var data []byte
+	err := r.db.QueryRowContext(ctx, query, sequence).Scan(
+		&event.Sequence,
+		&event.ID,
+		&event.Type,
+		&event.AggregateType,
+		&event.AggregateID,
+		&data,
+		&event.OccurredAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDomainEventNotFound
+		}
+		return nil, err
+	}
+	event.Data = data
+
+	return event, nil
+}
+
+// ListPending returns undelivered events that are due, in sequence order.
+// Events waiting for a retry are left out, and so are the later events of
+// their aggregates, so a batch is never filled with events that cannot be
+// sent yet. Dead-lettered events are skipped and hold nothing back.
+func (r *OutboxRepository) ListPending(ctx context.Context, limit int) ([]*model.OutboxEntry, error) {
+	query := `
+		SELECT e.sequence, e.id, e.type, e.aggregate_type, e.aggregate_id, e.data, e.occurred_at, e.delivered_to, e.attempts, e.last_error, e.next_attempt_at
+		FROM outbox_events e
+		WHERE e.published_at IS NULL AND e.dead_at IS NULL AND e.next_attempt_at <= $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM outbox_events waiting
+			WHERE waiting.aggregate_id = e.aggregate_id
+			  AND waiting.sequence < e.sequence
+			  AND waiting.published_at IS NULL AND waiting.dead_at IS NULL
+			  AND waiting.next_attempt_at > $1
+		  )
+		ORDER BY e.sequence
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*model.OutboxEntry
+	for rows.Next() {
+		entry := &model.OutboxEntry{}
+		var data []byte
+		var lastError sql.NullString
+		err := rows.Scan(
+			&entry.Event.Sequence,
+			&entry.Event.ID,
+			&entry.Event.Type,
+			&entry.Event.AggregateType,
+			&entry.Event.AggregateID,
+			&data,
+			&entry.Event.OccurredAt,
+			pq.Array(&entry.DeliveredTo),
+			&entry.Attempts,
+			&lastError,
+			&entry.NextAttemptAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entry.Event.Data = data
+		entry.LastError = lastError.String
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// MarkDelivered records that sink has the event, so a retry for another
+// sink does not send it again.
+func (r *OutboxRepository) MarkDelivered(ctx context.Context, sequence int64, sink string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox_events SET delivered_to = array_append(delivered_to, $1)
+		WHERE sequence = $2 AND NOT ($1 = ANY(delivered_to))
+	`, sink, sequence)
+	return err
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, sequence int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE outbox_events SET published_at = $1, last_error = NULL WHERE sequence = $2`, time.Now(), sequence)
+	return err
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, sequence int64, lastError string, nextAttemptAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox_events SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2
+		WHERE sequence = $3
+	`, lastError, nextAttemptAt, sequence)
+	return err
+}
+
+// MarkDead dead-letters an event the relay will not retry. It stays in the
+// table with its last error for an operator to inspect.
+func (r *OutboxRepository) MarkDead(ctx context.Context, sequence int64, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox_events SET attempts = attempts + 1, last_error = $1, dead_at = $2
+		WHERE sequence = $3
+	`, lastError, time.Now(), sequence)
+	return err
+}
+
+func reservationEventData(reservation *model.Reservation, event *model.ReservationEvent) model.ReservationEventData {
+	return model.ReservationEventData{
+		ID:          reservation.ID,
+		EquipmentID: reservation.EquipmentID,
+		RenterID:    reservation.RenterID,
+		Status:      event.ToStatus,
+		FromStatus:  event.FromStatus,
+		StartDate:   reservation.StartDate,
+		EndDate:     reservation.EndDate,
+		Quantity:    reservation.Quantity,
+		TotalPrice:  reservation.TotalPrice,
+		ActorID:     event.ActorID,
+		Reason:      event.Reason,
+	}
+}
+
+// equipmentEventData is the listing as the API returns it, without the
+// owner's profile or photos.
+func equipmentEventData(equipment *model.Equipment) model.Equipment {
+	data := *equipment
+	data.Owner = nil
+	data.Photos = nil
+	data.DisplayPrices = nil
+	return data
+}
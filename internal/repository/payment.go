@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/payment"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+var ErrPaymentNotFound = errors.New("payment not found")
+
+type PaymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+const updatePaymentQuery = `
+	UPDATE payments
+	SET status = $1, captured = $2, refunded = $3, failure_reason = $4, updated_at = $5
+	WHERE id = $6
+`
+
+const paymentColumns = `id, reservation_id, kind, provider, provider_ref, status, currency, amount, captured, refunded, failure_reason, created_at, updated_at`
+
+func scanPayment(row rowScanner) (*model.Payment, error) {
+	p := &model.Payment{}
+	var providerRef, failureReason sql.NullString
+	var currency, amount, captured, refunded string
+
+	err := row.Scan(
+		&p.ID,
+		&p.ReservationID,
+		&p.Kind,
+		&p.Provider,
+		&providerRef,
+		&p.Status,
+		&currency,
+		&amount,
+		&captured,
+		&refunded,
+		&failureReason,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ProviderRef = providerRef.String
+	p.FailureReason = failureReason.String
+	if p.Amount, err = money.Parse(amount, currency); err != nil {
+		return nil, err
+	}
+	if p.Captured, err = money.Parse(captured, currency); err != nil {
+		return nil, err
+	}
+	if p.Refunded, err = money.Parse(refunded, currency); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *PaymentRepository) Create(ctx context.Context, p *model.Payment) error {
+	query := `
+		INSERT INTO payments (id, reservation_id, kind, provider, provider_ref, status, currency, amount, captured, refunded, failure_reason, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	p.ID = uuid.New()
+	if p.Kind == "" {
+		p.Kind = model.PaymentRental
+	}
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = time.Now()
+	if p.Captured.Currency == "" {
+		p.Captured = money.New(0, p.Amount.Currency)
+	}
+	if p.Refunded.Currency == "" {
+		p.Refunded = money.New(0, p.Amount.Currency)
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		p.ID,
+		p.ReservationID,
+		p.Kind,
+		p.Provider,
+		nullString(p.ProviderRef),
+		p.Status,
+		p.Amount.Currency,
+		p.Amount,
+		p.Captured,
+		p.Refunded,
+		nullString(p.FailureReason),
+		p.CreatedAt,
+		p.UpdatedAt,
+	)
+	return err
+}
+
+func (r *PaymentRepository) Update(ctx context.Context, p *model.Payment) error {
+	p.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, updatePaymentQuery, p.Status, p.Captured, p.Refunded, nullString(p.FailureReason), p.UpdatedAt, p.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrPaymentNotFound
+	}
+
+	return nil
+}
+
+func (r *PaymentRepository) GetByProviderRef(ctx context.Context, provider, providerRef string) (*model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE provider = $1 AND provider_ref = $2`
+
+	p, err := scanPayment(r.db.QueryRowContext(ctx, query, provider, providerRef))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetLatest returns the most recent payment of a kind for a reservation.
+func (r *PaymentRepository) GetLatest(ctx context.Context, reservationID uuid.UUID, kind model.PaymentKind) (*model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE reservation_id = $1 AND kind = $2 ORDER BY created_at DESC LIMIT 1`
+
+	p, err := scanPayment(r.db.QueryRowContext(ctx, query, reservationID, kind))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *PaymentRepository) ListByReservation(ctx context.Context, reservationID uuid.UUID) ([]model.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE reservation_id = $1 ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []model.Payment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, *p)
+	}
+
+	return payments, rows.Err()
+}
+
+// Record updates a payment and appends the matching ledger transaction
+// atomically, so the ledger never disagrees with payment state.
+func (r *PaymentRepository) Record(ctx context.Context, p *model.Payment, entries []payment.Entry) error {
+	if err := payment.Balanced(entries); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	p.UpdatedAt = time.Now()
+	_, err = tx.ExecContext(ctx, updatePaymentQuery, p.Status, p.Captured, p.Refunded, nullString(p.FailureReason), p.UpdatedAt, p.ID)
+	if err != nil {
+		return err
+	}
+
+	transactionID := uuid.New()
+	for _, e := range entries {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO ledger_entries (id, transaction_id, reservation_id, payment_id, account, direction, currency, amount, description, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, uuid.New(), transactionID, p.ReservationID, p.ID, e.Account, e.Direction, e.Amount.Currency, e.Amount, nullString(e.Description), p.UpdatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PaymentRepository) ListLedgerEntries(ctx context.Context, reservationID uuid.UUID) ([]model.LedgerEntry, error) {
+	query := `
+		SELECT id, transaction_id, reservation_id, payment_id, account, direction, currency, amount, description, created_at
+		FROM ledger_entries
+		WHERE reservation_id = $1
+		ORDER BY created_at, transaction_id, direction DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []model.LedgerEntry{}
+	for rows.Next() {
+		var e model.LedgerEntry
+		var currency, amount string
+		var description sql.NullString
+		if err := rows.Scan(&e.ID, &e.TransactionID, &e.ReservationID, &e.PaymentID, &e.Account, &e.Direction, &currency, &amount, &description, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if e.Amount, err = money.Parse(amount, currency); err != nil {
+			return nil, err
+		}
+		e.Description = description.String
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
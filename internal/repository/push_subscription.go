@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+type PushSubscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewPushSubscriptionRepository(db *sql.DB) *PushSubscriptionRepository {
+	return &PushSubscriptionRepository{db: db}
+}
+
+// Save stores a subscription. Browsers keep one endpoint per subscription,
+// so saving an endpoint again replaces its keys and owner.
+func (r *PushSubscriptionRepository) Save(ctx context.Context, sub *model.PushSubscription) error {
+	query := `
+		INSERT INTO push_subscriptions (id, user_id, endpoint, p256dh, auth, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (endpoint) DO UPDATE
+		SET user_id = EXCLUDED.user_id, p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth, user_agent = EXCLUDED.user_agent
+		RETURNING id, created_at
+	`
+
+	sub.ID = uuid.New()
+	sub.CreatedAt = time.Now()
+
+	return r.db.QueryRowContext(ctx, query,
+		sub.ID,
+		sub.UserID,
+		sub.Endpoint,
+		sub.Keys.P256dh,
+		sub.Keys.Auth,
+		nullString(sub.UserAgent),
+		sub.CreatedAt,
+	).Scan(&sub.ID, &sub.CreatedAt)
+}
+
+func (r *PushSubscriptionRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.PushSubscription, error) {
+	query := `
+		SELECT id, user_id, endpoint, p256dh, auth, user_agent, created_at
+		FROM push_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*model.PushSubscription
+	for rows.Next() {
+		sub := &model.PushSubscription{}
+		var userAgent sql.NullString
+		err := rows.Scan(
+			&sub.ID,
+			&sub.UserID,
+			&sub.Endpoint,
+			&sub.Keys.P256dh,
+			&sub.Keys.Auth,
+			&userAgent,
+			&sub.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sub.UserAgent = userAgent.String
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// DeleteByEndpoint removes the user's subscription for endpoint, if any.
+func (r *PushSubscriptionRepository) DeleteByEndpoint(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = $2`, userID, endpoint)
+	return err
+}
@@ -3,17 +3,24 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 
 	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
 	"github.com/abneribeiro/goapi/internal/pkg/pagination"
 )
 
-var ErrReservationNotFound = errors.New("reservation not found")
+var (
+	ErrReservationNotFound = errors.New("reservation not found")
+	ErrStatusChanged       = errors.New("reservation status changed concurrently")
+	ErrNoAvailability      = errors.New("equipment not available for selected dates")
+)
 
 type ReservationRepository struct {
 	db *sql.DB
@@ -23,35 +30,165 @@ func NewReservationRepository(db *sql.DB) *ReservationRepository {
 	return &ReservationRepository{db: db}
 }
 
+// Create inserts a reservation. Availability is checked again, and units
+// of serialized equipment allocated, while the equipment row is locked, so
+// concurrent bookings of the same listing cannot both take the last units.
+// It returns ErrNoAvailability when the dates are no longer free.
 func (r *ReservationRepository) Create(ctx context.Context, reservation *model.Reservation) error {
 	query := `
-		INSERT INTO reservations (id, equipment_id, renter_id, start_date, end_date, status, total_price, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO reservations (id, equipment_id, renter_id, start_date, end_date, quantity, status, currency, total_price, price_breakdown, deposit_status, deposit_amount, deposit_captured, cancellation_policy, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
 
+	var breakdown, cancellationPolicy []byte
+	if reservation.PriceBreakdown != nil {
+		var err error
+		if breakdown, err = json.Marshal(reservation.PriceBreakdown); err != nil {
+			return err
+		}
+	}
+	if reservation.CancellationPolicy != nil {
+		var err error
+		if cancellationPolicy, err = json.Marshal(reservation.CancellationPolicy); err != nil {
+			return err
+		}
+	}
+
 	reservation.ID = uuid.New()
 	reservation.CreatedAt = time.Now()
 	reservation.UpdatedAt = time.Now()
+	if reservation.Quantity == 0 {
+		reservation.Quantity = 1
+	}
+	if reservation.TotalPrice.Currency == "" {
+		reservation.TotalPrice.Currency = money.DefaultCurrency
+	}
+
+	var depositStatus *model.DepositStatus
+	var depositAmount, depositCaptured *money.Money
+	if d := reservation.Deposit; d != nil {
+		depositStatus, depositAmount, depositCaptured = &d.Status, &d.Amount, &d.Captured
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := allocate(ctx, tx, reservation); err != nil {
+		return err
+	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		reservation.ID,
 		reservation.EquipmentID,
 		reservation.RenterID,
 		reservation.StartDate,
 		reservation.EndDate,
+		reservation.Quantity,
 		reservation.Status,
+		reservation.TotalPrice.Currency,
 		reservation.TotalPrice,
+		breakdown,
+		depositStatus,
+		depositAmount,
+		depositCaptured,
+		cancellationPolicy,
 		reservation.CreatedAt,
 		reservation.UpdatedAt,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	for _, unitID := range reservation.UnitIDs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO reservation_units (reservation_id, unit_id, period)
+			VALUES ($1, $2, tstzrange($3, $4))
+		`, reservation.ID, unitID, reservation.StartDate, reservation.EndDate)
+		if isExclusionViolation(err) {
+			return ErrNoAvailability
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	event := &model.ReservationEvent{
+		ReservationID: reservation.ID,
+		ToStatus:      reservation.Status,
+		ActorID:       &reservation.RenterID,
+	}
+	if err := insertEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := insertDomainEvent(ctx, tx, model.EventReservationCreated, reservation.ID, reservationEventData(reservation, event)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockEquipment locks the equipment row until tx ends, so bookings of one
+// listing are checked and written one at a time. It returns the listing's
+// inventory mode.
+func lockEquipment(ctx context.Context, tx *sql.Tx, equipmentID uuid.UUID) (model.InventoryMode, error) {
+	var mode model.InventoryMode
+	err := tx.QueryRowContext(ctx, `SELECT inventory_mode FROM equipment WHERE id = $1 FOR UPDATE`, equipmentID).Scan(&mode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrEquipmentNotFound
+	}
+	return mode, err
+}
+
+// allocate checks a new reservation's dates under the equipment lock and
+// picks free units for serialized equipment.
+func allocate(ctx context.Context, tx *sql.Tx, reservation *model.Reservation) error {
+	mode, err := lockEquipment(ctx, tx, reservation.EquipmentID)
+	if err != nil {
+		return err
+	}
+
+	available, err := checkAvailability(ctx, tx, reservation.EquipmentID, uuid.Nil, reservation.StartDate, reservation.EndDate, reservation.Quantity)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return ErrNoAvailability
+	}
+
+	reservation.UnitIDs = nil
+	if mode != model.InventorySerialized {
+		return nil
+	}
+
+	unitIDs, err := findFreeUnits(ctx, tx, reservation.EquipmentID, reservation.StartDate, reservation.EndDate, reservation.Quantity)
+	if err != nil {
+		return err
+	}
+	if len(unitIDs) < reservation.Quantity {
+		return ErrNoAvailability
+	}
+	reservation.UnitIDs = unitIDs
+
+	return nil
+}
+
+// isExclusionViolation reports whether err broke an exclusion constraint,
+// such as two active reservations holding one unit at the same time.
+func isExclusionViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23P01"
 }
 
 func (r *ReservationRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Reservation, error) {
 	query := `
-		SELECT r.id, r.equipment_id, r.renter_id, r.start_date, r.end_date, r.status, r.total_price, r.cancellation_reason, r.created_at, r.updated_at,
-		       e.id, e.name, e.category, e.price_per_hour, e.price_per_day, e.price_per_week, e.location, e.owner_id,
+		SELECT r.id, r.equipment_id, r.renter_id, r.start_date, r.end_date, r.quantity, r.status, r.currency, r.total_price, r.price_breakdown, r.cancellation_reason, r.created_at, r.updated_at,
+		       r.deposit_status, r.deposit_amount, r.deposit_captured, r.deposit_capture_reason, r.deposit_settled_at,
+		       r.cancellation_policy, r.cancellation, r.picked_up_at, r.returned_at, r.late_return,
+		       e.id, e.name, e.category, e.currency, e.price_per_hour, e.price_per_day, e.price_per_week, e.location, e.owner_id, e.auto_approve, e.require_prepayment,
 		       u.id, u.email, u.name, u.phone
 		FROM reservations r
 		LEFT JOIN equipment e ON r.equipment_id = e.id
@@ -63,7 +200,9 @@ func (r *ReservationRepository) GetByID(ctx context.Context, id uuid.UUID) (*mod
 		Equipment: &model.Equipment{},
 		Renter:    &model.User{},
 	}
-	var cancellationReason sql.NullString
+	var cancellationReason, totalPrice, pricePerHour, pricePerDay, pricePerWeek sql.NullString
+	var breakdown, cancellationPolicy, cancellation, lateReturn []byte
+	var deposit depositColumns
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&reservation.ID,
@@ -71,19 +210,35 @@ func (r *ReservationRepository) GetByID(ctx context.Context, id uuid.UUID) (*mod
 		&reservation.RenterID,
 		&reservation.StartDate,
 		&reservation.EndDate,
+		&reservation.Quantity,
 		&reservation.Status,
-		&reservation.TotalPrice,
+		&reservation.TotalPrice.Currency,
+		&totalPrice,
+		&breakdown,
 		&cancellationReason,
 		&reservation.CreatedAt,
 		&reservation.UpdatedAt,
+		&deposit.status,
+		&deposit.amount,
+		&deposit.captured,
+		&deposit.reason,
+		&deposit.settledAt,
+		&cancellationPolicy,
+		&cancellation,
+		&reservation.PickedUpAt,
+		&reservation.ReturnedAt,
+		&lateReturn,
 		&reservation.Equipment.ID,
 		&reservation.Equipment.Name,
 		&reservation.Equipment.Category,
-		&reservation.Equipment.PricePerHour,
-		&reservation.Equipment.PricePerDay,
-		&reservation.Equipment.PricePerWeek,
+		&reservation.Equipment.Currency,
+		&pricePerHour,
+		&pricePerDay,
+		&pricePerWeek,
 		&reservation.Equipment.Location,
 		&reservation.Equipment.OwnerID,
+		&reservation.Equipment.AutoApprove,
+		&reservation.Equipment.RequirePrepayment,
 		&reservation.Renter.ID,
 		&reservation.Renter.Email,
 		&reservation.Renter.Name,
@@ -101,6 +256,36 @@ func (r *ReservationRepository) GetByID(ctx context.Context, id uuid.UUID) (*mod
 		reservation.CancellationReason = cancellationReason.String
 	}
 
+	if reservation.TotalPrice, err = money.Parse(totalPrice.String, reservation.TotalPrice.Currency); err != nil {
+		return nil, err
+	}
+	if err := scanPrices(reservation.Equipment, pricePerHour, pricePerDay, pricePerWeek); err != nil {
+		return nil, err
+	}
+	if reservation.Deposit, err = deposit.toDeposit(reservation.TotalPrice.Currency); err != nil {
+		return nil, err
+	}
+
+	if breakdown != nil {
+		if err := json.Unmarshal(breakdown, &reservation.PriceBreakdown); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanCancellation(reservation, cancellationPolicy, cancellation); err != nil {
+		return nil, err
+	}
+	if lateReturn != nil {
+		if err := json.Unmarshal(lateReturn, &reservation.LateReturn); err != nil {
+			return nil, err
+		}
+	}
+
+	unitIDs, err := r.getUnitIDs(ctx, reservation.ID)
+	if err != nil {
+		return nil, err
+	}
+	reservation.UnitIDs = unitIDs
+
 	return reservation, nil
 }
 
@@ -141,7 +326,9 @@ func (r *ReservationRepository) List(ctx context.Context, filter *model.Reservat
 		return nil, 0, err
 	}
 
-	selectQuery := `SELECT r.id, r.equipment_id, r.renter_id, r.start_date, r.end_date, r.status, r.total_price, r.cancellation_reason, r.created_at, r.updated_at,
+	selectQuery := `SELECT r.id, r.equipment_id, r.renter_id, r.start_date, r.end_date, r.quantity, r.status, r.currency, r.total_price, r.cancellation_reason, r.created_at, r.updated_at,
+		r.deposit_status, r.deposit_amount, r.deposit_captured, r.deposit_capture_reason, r.deposit_settled_at,
+		r.cancellation_policy, r.cancellation, r.picked_up_at, r.returned_at, r.late_return,
 		e.id, e.name, e.category, e.location ` + baseQuery
 	selectQuery += " ORDER BY r.created_at DESC"
 	selectQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount+1, argCount+2)
@@ -156,7 +343,9 @@ func (r *ReservationRepository) List(ctx context.Context, filter *model.Reservat
 	var reservations []*model.Reservation
 	for rows.Next() {
 		res := &model.Reservation{Equipment: &model.Equipment{}}
-		var cancellationReason sql.NullString
+		var cancellationReason, totalPrice sql.NullString
+		var cancellationPolicy, cancellation, lateReturn []byte
+		var deposit depositColumns
 
 		err := rows.Scan(
 			&res.ID,
@@ -164,11 +353,23 @@ func (r *ReservationRepository) List(ctx context.Context, filter *model.Reservat
 			&res.RenterID,
 			&res.StartDate,
 			&res.EndDate,
+			&res.Quantity,
 			&res.Status,
-			&res.TotalPrice,
+			&res.TotalPrice.Currency,
+			&totalPrice,
 			&cancellationReason,
 			&res.CreatedAt,
 			&res.UpdatedAt,
+			&deposit.status,
+			&deposit.amount,
+			&deposit.captured,
+			&deposit.reason,
+			&deposit.settledAt,
+			&cancellationPolicy,
+			&cancellation,
+			&res.PickedUpAt,
+			&res.ReturnedAt,
+			&lateReturn,
 			&res.Equipment.ID,
 			&res.Equipment.Name,
 			&res.Equipment.Category,
@@ -182,25 +383,166 @@ func (r *ReservationRepository) List(ctx context.Context, filter *model.Reservat
 			res.CancellationReason = cancellationReason.String
 		}
 
+		if res.TotalPrice, err = money.Parse(totalPrice.String, res.TotalPrice.Currency); err != nil {
+			return nil, 0, err
+		}
+		if res.Deposit, err = deposit.toDeposit(res.TotalPrice.Currency); err != nil {
+			return nil, 0, err
+		}
+		if err := scanCancellation(res, cancellationPolicy, cancellation); err != nil {
+			return nil, 0, err
+		}
+		if lateReturn != nil {
+			if err := json.Unmarshal(lateReturn, &res.LateReturn); err != nil {
+				return nil, 0, err
+			}
+		}
+
 		reservations = append(reservations, res)
 	}
 
 	return reservations, total, nil
 }
 
-func (r *ReservationRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status model.ReservationStatus, reason string) error {
+// Transition saves the status change described by event, along with the
+// reservation's cancellation, handover and late return details, appends the
+// event to its history and records the matching domain event.
+// It fails with ErrStatusChanged when the reservation is no longer in
+// event.FromStatus, so two concurrent changes cannot both apply.
+// Transition saves a status change. jobs are enqueued in the same
+// transaction, so work that must follow the change is never lost.
+func (r *ReservationRepository) Transition(ctx context.Context, reservation *model.Reservation, event *model.ReservationEvent, jobs ...*model.Job) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := transition(ctx, tx, reservation, event); err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if err := insertJob(ctx, tx, job); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func transition(ctx context.Context, tx *sql.Tx, reservation *model.Reservation, event *model.ReservationEvent) error {
 	query := `
 		UPDATE reservations
-		SET status = $1, cancellation_reason = $2, updated_at = $3
-		WHERE id = $4
+		SET status = $1, cancellation_reason = $2, cancellation = $3, picked_up_at = $4, returned_at = $5, late_return = $6, updated_at = $7
+		WHERE id = $8 AND status = $9
 	`
 
 	var reasonPtr *string
-	if reason != "" {
-		reasonPtr = &reason
+	if reservation.CancellationReason != "" {
+		reasonPtr = &reservation.CancellationReason
+	}
+
+	var cancellation []byte
+	if reservation.Cancellation != nil {
+		var err error
+		if cancellation, err = json.Marshal(reservation.Cancellation); err != nil {
+			return err
+		}
 	}
 
-	result, err := r.db.ExecContext(ctx, query, status, reasonPtr, time.Now(), id)
+	var lateReturn []byte
+	if reservation.LateReturn != nil {
+		var err error
+		if lateReturn, err = json.Marshal(reservation.LateReturn); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, query,
+		event.ToStatus,
+		reasonPtr,
+		cancellation,
+		reservation.PickedUpAt,
+		reservation.ReturnedAt,
+		lateReturn,
+		time.Now(),
+		event.ReservationID,
+		event.FromStatus,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrStatusChanged
+	}
+
+	if err := insertEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return insertDomainEvent(ctx, tx, model.StatusEvent(event.ToStatus), event.ReservationID, reservationEventData(reservation, event))
+}
+
+func (r *ReservationRepository) ListEvents(ctx context.Context, reservationID uuid.UUID) ([]model.ReservationEvent, error) {
+	query := `
+		SELECT id, reservation_id, from_status, to_status, actor_id, reason, created_at
+		FROM reservation_events
+		WHERE reservation_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []model.ReservationEvent{}
+	for rows.Next() {
+		var e model.ReservationEvent
+		var fromStatus, reason sql.NullString
+		if err := rows.Scan(&e.ID, &e.ReservationID, &fromStatus, &e.ToStatus, &e.ActorID, &reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.FromStatus = model.ReservationStatus(fromStatus.String)
+		e.Reason = reason.String
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+func insertEvent(ctx context.Context, tx *sql.Tx, event *model.ReservationEvent) error {
+	event.ID = uuid.New()
+	event.CreatedAt = time.Now()
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO reservation_events (id, reservation_id, from_status, to_status, actor_id, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, event.ID, event.ReservationID, nullString(string(event.FromStatus)), event.ToStatus, event.ActorID, nullString(event.Reason), event.CreatedAt)
+	return err
+}
+
+func (r *ReservationRepository) UpdateDeposit(ctx context.Context, id uuid.UUID, deposit *model.Deposit) error {
+	query := `
+		UPDATE reservations
+		SET deposit_status = $1, deposit_captured = $2, deposit_capture_reason = $3, deposit_settled_at = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	var reasonPtr *string
+	if deposit.CaptureReason != "" {
+		reasonPtr = &deposit.CaptureReason
+	}
+
+	result, err := r.db.ExecContext(ctx, query, deposit.Status, deposit.Captured, reasonPtr, deposit.SettledAt, time.Now(), id)
 	if err != nil {
 		return err
 	}
@@ -217,6 +559,69 @@ func (r *ReservationRepository) UpdateStatus(ctx context.Context, id uuid.UUID,
 	return nil
 }
 
+func scanCancellation(reservation *model.Reservation, policy, cancellation []byte) error {
+	if policy != nil {
+		if err := json.Unmarshal(policy, &reservation.CancellationPolicy); err != nil {
+			return err
+		}
+	}
+	if cancellation != nil {
+		if err := json.Unmarshal(cancellation, &reservation.Cancellation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type depositColumns struct {
+	status    sql.NullString
+	amount    sql.NullString
+	captured  sql.NullString
+	reason    sql.NullString
+	settledAt sql.NullTime
+}
+
+func (c depositColumns) toDeposit(currency string) (*model.Deposit, error) {
+	if !c.status.Valid {
+		return nil, nil
+	}
+
+	deposit := &model.Deposit{
+		Status:        model.DepositStatus(c.status.String),
+		CaptureReason: c.reason.String,
+	}
+	var err error
+	if deposit.Amount, err = money.Parse(c.amount.String, currency); err != nil {
+		return nil, err
+	}
+	if deposit.Captured, err = money.Parse(c.captured.String, currency); err != nil {
+		return nil, err
+	}
+	if c.settledAt.Valid {
+		deposit.SettledAt = &c.settledAt.Time
+	}
+	return deposit, nil
+}
+
+func (r *ReservationRepository) getUnitIDs(ctx context.Context, reservationID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT unit_id FROM reservation_units WHERE reservation_id = $1`, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
 func (r *ReservationRepository) GetEquipmentOwnerID(ctx context.Context, reservationID uuid.UUID) (uuid.UUID, error) {
 	query := `
 		SELECT e.owner_id
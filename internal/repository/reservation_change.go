@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+)
+
+var (
+	ErrChangeNotFound = errors.New("change request not found")
+	ErrChangePending  = errors.New("reservation already has a pending change request")
+	ErrChangeConflict = errors.New("reservation or change request changed concurrently")
+)
+
+const changeColumns = `id, reservation_id, requested_by, status, from_start_date, from_end_date, start_date, end_date,
+	currency, price_delta, price_breakdown, payment_id, reason, decided_by, decided_at, created_at`
+
+func (r *ReservationRepository) CreateChange(ctx context.Context, change *model.ReservationChange) error {
+	breakdown, err := json.Marshal(change.Price)
+	if err != nil {
+		return err
+	}
+
+	change.ID = uuid.New()
+	change.CreatedAt = time.Now()
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO reservation_changes (id, reservation_id, requested_by, status, from_start_date, from_end_date, start_date, end_date, currency, total_price, price_delta, price_breakdown, payment_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`,
+		change.ID,
+		change.ReservationID,
+		change.RequestedBy,
+		change.Status,
+		change.FromStartDate,
+		change.FromEndDate,
+		change.StartDate,
+		change.EndDate,
+		change.Price.Currency,
+		change.Price.Total,
+		change.PriceDelta,
+		breakdown,
+		change.PaymentID,
+		change.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrChangePending
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *ReservationRepository) GetChange(ctx context.Context, reservationID, changeID uuid.UUID) (*model.ReservationChange, error) {
+	query := `SELECT ` + changeColumns + ` FROM reservation_changes WHERE id = $1 AND reservation_id = $2`
+
+	change, err := scanChange(r.db.QueryRowContext(ctx, query, changeID, reservationID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrChangeNotFound
+		}
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (r *ReservationRepository) ListChanges(ctx context.Context, reservationID uuid.UUID) ([]model.ReservationChange, error) {
+	query := `SELECT ` + changeColumns + ` FROM reservation_changes WHERE reservation_id = $1 ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []model.ReservationChange{}
+	for rows.Next() {
+		change, err := scanChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, *change)
+	}
+
+	return changes, rows.Err()
+}
+
+// ApplyChange moves the reservation to the change's dates and price, marks
+// the change approved and records the event in the reservation's history
+// and the outbox, all or nothing. It fails with ErrChangeConflict when the reservation's
+// status or dates, or the change itself, moved on since they were read, and
+// with ErrNoAvailability when the new dates are taken by the time the
+// equipment is locked.
+func (r *ReservationRepository) ApplyChange(ctx context.Context, reservation *model.Reservation, change *model.ReservationChange, event *model.ReservationEvent) error {
+	breakdown, err := json.Marshal(change.Price)
+	if err != nil {
+		return err
+	}
+
+	var lateReturn []byte
+	if reservation.LateReturn != nil {
+		if lateReturn, err = json.Marshal(reservation.LateReturn); err != nil {
+			return err
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkChange(ctx, tx, reservation, change); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE reservations
+		SET start_date = $1, end_date = $2, total_price = $3, price_breakdown = $4, late_return = $5, updated_at = $6
+		WHERE id = $7 AND status = $8 AND start_date = $9 AND end_date = $10
+	`,
+		change.StartDate,
+		change.EndDate,
+		change.Price.Total,
+		breakdown,
+		lateReturn,
+		now,
+		reservation.ID,
+		reservation.Status,
+		change.FromStartDate,
+		change.FromEndDate,
+	)
+	if isExclusionViolation(err) {
+		return ErrNoAvailability
+	}
+	if err := expectRow(result, err); err != nil {
+		return err
+	}
+
+	result, err = tx.ExecContext(ctx, `
+		UPDATE reservation_changes
+		SET status = $1, decided_by = $2, decided_at = $3
+		WHERE id = $4 AND status = $5
+	`, model.ChangeApproved, change.DecidedBy, now, change.ID, model.ChangePending)
+	if err := expectRow(result, err); err != nil {
+		return err
+	}
+
+	if err := insertEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	data := reservationEventData(reservation, event)
+	data.StartDate = change.StartDate
+	data.EndDate = change.EndDate
+	data.TotalPrice = change.Price.Total
+	if err := insertDomainEvent(ctx, tx, model.EventReservationRescheduled, reservation.ID, data); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	change.Status = model.ChangeApproved
+	change.DecidedAt = &now
+	return nil
+}
+
+func (r *ReservationRepository) RejectChange(ctx context.Context, change *model.ReservationChange) error {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE reservation_changes
+		SET status = $1, reason = $2, decided_by = $3, decided_at = $4
+		WHERE id = $5 AND status = $6
+	`, model.ChangeRejected, nullString(change.Reason), change.DecidedBy, now, change.ID, model.ChangePending)
+	if err := expectRow(result, err); err != nil {
+		return err
+	}
+
+	change.Status = model.ChangeRejected
+	change.DecidedAt = &now
+	return nil
+}
+
+func expectRow(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrChangeConflict
+	}
+
+	return nil
+}
+
+func scanChange(row rowScanner) (*model.ReservationChange, error) {
+	var change model.ReservationChange
+	var requestedBy uuid.NullUUID
+	var priceDelta string
+	var breakdown []byte
+	var reason sql.NullString
+
+	err := row.Scan(
+		&change.ID,
+		&change.ReservationID,
+		&requestedBy,
+		&change.Status,
+		&change.FromStartDate,
+		&change.FromEndDate,
+		&change.StartDate,
+		&change.EndDate,
+		&change.PriceDelta.Currency,
+		&priceDelta,
+		&breakdown,
+		&change.PaymentID,
+		&reason,
+		&change.DecidedBy,
+		&change.DecidedAt,
+		&change.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	change.RequestedBy = requestedBy.UUID
+	change.Reason = reason.String
+	if change.PriceDelta, err = money.Parse(priceDelta, change.PriceDelta.Currency); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(breakdown, &change.Price); err != nil {
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+// checkChange checks a change's dates under the equipment lock against every
+// booking but the reservation's own, including the units it keeps.
+func checkChange(ctx context.Context, tx *sql.Tx, reservation *model.Reservation, change *model.ReservationChange) error {
+	if _, err := lockEquipment(ctx, tx, reservation.EquipmentID); err != nil {
+		return err
+	}
+
+	available, err := checkAvailability(ctx, tx, reservation.EquipmentID, reservation.ID, change.StartDate, change.EndDate, reservation.Quantity)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return ErrNoAvailability
+	}
+
+	if len(reservation.UnitIDs) == 0 {
+		return nil
+	}
+	booked, err := unitsBooked(ctx, tx, reservation.UnitIDs, reservation.ID, change.StartDate, change.EndDate)
+	if err != nil {
+		return err
+	}
+	if booked {
+		return ErrNoAvailability
+	}
+
+	return nil
+}
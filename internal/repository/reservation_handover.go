@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+// RecordHandover saves a pickup or return report together with the status
+// change it causes, so a reservation is never active or returned without
+// its check-in on file.
+func (r *ReservationRepository) RecordHandover(ctx context.Context, reservation *model.Reservation, event *model.ReservationEvent, report *model.HandoverReport) error {
+	checklist, err := json.Marshal(report.Checklist)
+	if err != nil {
+		return err
+	}
+	photos, err := json.Marshal(report.Photos)
+	if err != nil {
+		return err
+	}
+	renterSignature, err := json.Marshal(report.RenterSignature)
+	if err != nil {
+		return err
+	}
+	ownerSignature, err := json.Marshal(report.OwnerSignature)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := transition(ctx, tx, reservation, event); err != nil {
+		return err
+	}
+
+	report.ID = uuid.New()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO handover_reports (id, reservation_id, kind, recorded_by, recorded_at, meter_type, meter_reading, fuel_level, checklist, notes, photos, renter_signature, owner_signature)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`,
+		report.ID,
+		report.ReservationID,
+		report.Kind,
+		report.RecordedBy,
+		report.RecordedAt,
+		nullString(string(report.MeterType)),
+		report.MeterReading,
+		report.FuelLevel,
+		checklist,
+		nullString(report.Notes),
+		photos,
+		renterSignature,
+		ownerSignature,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ReservationRepository) ListHandovers(ctx context.Context, reservationID uuid.UUID) ([]model.HandoverReport, error) {
+	query := `
+		SELECT id, reservation_id, kind, recorded_by, recorded_at, meter_type, meter_reading, fuel_level, checklist, notes, photos, renter_signature, owner_signature
+		FROM handover_reports
+		WHERE reservation_id = $1
+		ORDER BY recorded_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []model.HandoverReport{}
+	for rows.Next() {
+		var report model.HandoverReport
+		var recordedBy uuid.NullUUID
+		var meterType, notes sql.NullString
+		var checklist, photos, renterSignature, ownerSignature []byte
+
+		err := rows.Scan(
+			&report.ID,
+			&report.ReservationID,
+			&report.Kind,
+			&recordedBy,
+			&report.RecordedAt,
+			&meterType,
+			&report.MeterReading,
+			&report.FuelLevel,
+			&checklist,
+			&notes,
+			&photos,
+			&renterSignature,
+			&ownerSignature,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		report.RecordedBy = recordedBy.UUID
+		report.MeterType = model.MeterType(meterType.String)
+		report.Notes = notes.String
+		for _, field := range []struct {
+			data []byte
+			dest interface{}
+		}{
+			{checklist, &report.Checklist},
+			{photos, &report.Photos},
+			{renterSignature, &report.RenterSignature},
+			{ownerSignature, &report.OwnerSignature},
+		} {
+			if err := json.Unmarshal(field.data, field.dest); err != nil {
+				return nil, err
+			}
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+// ListOverdue returns the reservations that should have ended by now but
+// have no return on file, oldest first.
+func (r *ReservationRepository) ListOverdue(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	return r.listIDs(ctx, `
+		SELECT id
+		FROM reservations
+		WHERE status IN ('approved', 'active')
+		AND returned_at IS NULL
+		AND end_date < $1
+		ORDER BY end_date
+	`, now)
+}
+
+// UpdateLateReturn saves the accrued late fee. It does nothing once the
+// return has been recorded, so a detection run cannot overwrite the final
+// fee.
+func (r *ReservationRepository) UpdateLateReturn(ctx context.Context, id uuid.UUID, lateReturn *model.LateReturn) error {
+	data, err := json.Marshal(lateReturn)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE reservations
+		SET late_return = $1, updated_at = $2
+		WHERE id = $3 AND status IN ('approved', 'active') AND returned_at IS NULL
+	`
+
+	_, err = r.db.ExecContext(ctx, query, data, time.Now(), id)
+	return err
+}
+
+// ListUpcoming returns the pending and approved bookings of an equipment
+// starting in [from, until), excluding the given reservation.
+func (r *ReservationRepository) ListUpcoming(ctx context.Context, equipmentID, excludeID uuid.UUID, from, until time.Time) ([]*model.Reservation, error) {
+	query := `
+		SELECT id, equipment_id, renter_id, start_date, end_date, quantity, status
+		FROM reservations
+		WHERE equipment_id = $1
+		AND id <> $2
+		AND status IN ('pending', 'approved')
+		AND start_date >= $3
+		AND start_date < $4
+		ORDER BY start_date
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, equipmentID, excludeID, from, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []*model.Reservation
+	for rows.Next() {
+		res := &model.Reservation{}
+		if err := rows.Scan(&res.ID, &res.EquipmentID, &res.RenterID, &res.StartDate, &res.EndDate, &res.Quantity, &res.Status); err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, res := range reservations {
+		if res.UnitIDs, err = r.getUnitIDs(ctx, res.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return reservations, nil
+}
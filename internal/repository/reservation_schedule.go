@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReminderKind string
+
+const (
+	ReminderPickup ReminderKind = "pickup"
+	ReminderReturn ReminderKind = "return"
+)
+
+// ListStalePending returns pending reservations that were never decided:
+// those whose start date has passed or that were requested before
+// createdBefore.
+func (r *ReservationRepository) ListStalePending(ctx context.Context, now, createdBefore time.Time) ([]uuid.UUID, error) {
+	return r.listIDs(ctx, `
+		SELECT id
+		FROM reservations
+		WHERE status = 'pending'
+		AND (start_date <= $1 OR created_at <= $2)
+		ORDER BY created_at
+	`, now, createdBefore)
+}
+
+// ListDueReminders returns reservations whose pickup or return falls in
+// (now, until] and that have not been reminded of it yet.
+func (r *ReservationRepository) ListDueReminders(ctx context.Context, kind ReminderKind, now, until time.Time) ([]uuid.UUID, error) {
+	query := `
+		SELECT id
+		FROM reservations
+		WHERE status = 'approved'
+		AND start_date > $1 AND start_date <= $2
+		AND pickup_reminded_at IS NULL
+		ORDER BY start_date
+	`
+	if kind == ReminderReturn {
+		query = `
+			SELECT id
+			FROM reservations
+			WHERE status IN ('approved', 'active')
+			AND end_date > $1 AND end_date <= $2
+			AND return_reminded_at IS NULL
+			ORDER BY end_date
+		`
+	}
+
+	return r.listIDs(ctx, query, now, until)
+}
+
+// MarkReminded records that a reminder was sent. It reports false when the
+// reminder had already been recorded, so it is sent at most once.
+func (r *ReservationRepository) MarkReminded(ctx context.Context, id uuid.UUID, kind ReminderKind, at time.Time) (bool, error) {
+	query := `UPDATE reservations SET pickup_reminded_at = $1 WHERE id = $2 AND pickup_reminded_at IS NULL`
+	if kind == ReminderReturn {
+		query = `UPDATE reservations SET return_reminded_at = $1 WHERE id = $2 AND return_reminded_at IS NULL`
+	}
+
+	result, err := r.db.ExecContext(ctx, query, at, id)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ListReturnedBefore returns reservations returned before the given time
+// that are still waiting to be completed.
+func (r *ReservationRepository) ListReturnedBefore(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	return r.listIDs(ctx, `
+		SELECT id
+		FROM reservations
+		WHERE status = 'returned'
+		AND returned_at <= $1
+		ORDER BY returned_at
+	`, before)
+}
+
+func (r *ReservationRepository) listIDs(ctx context.Context, query string, args ...interface{}) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
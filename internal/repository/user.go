@@ -24,8 +24,8 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, name, phone, role, verified, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (id, email, password_hash, name, phone, locale, role, verified, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	user.ID = uuid.New()
@@ -38,6 +38,7 @@ func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 		user.PasswordHash,
 		user.Name,
 		user.Phone,
+		user.Locale,
 		user.Role,
 		user.Verified,
 		user.CreatedAt,
@@ -56,7 +57,7 @@ func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, phone, role, verified, created_at, updated_at
+		SELECT id, email, password_hash, name, phone, locale, role, verified, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -68,6 +69,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.User
 		&user.PasswordHash,
 		&user.Name,
 		&user.Phone,
+		&user.Locale,
 		&user.Role,
 		&user.Verified,
 		&user.CreatedAt,
@@ -86,7 +88,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.User
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, phone, role, verified, created_at, updated_at
+		SELECT id, email, password_hash, name, phone, locale, role, verified, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -98,6 +100,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.U
 		&user.PasswordHash,
 		&user.Name,
 		&user.Phone,
+		&user.Locale,
 		&user.Role,
 		&user.Verified,
 		&user.CreatedAt,
@@ -117,13 +120,13 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.U
 func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
 	query := `
 		UPDATE users
-		SET name = $1, phone = $2, updated_at = $3
-		WHERE id = $4
+		SET name = $1, phone = $2, locale = $3, updated_at = $4
+		WHERE id = $5
 	`
 
 	user.UpdatedAt = time.Now()
 
-	result, err := r.db.ExecContext(ctx, query, user.Name, user.Phone, user.UpdatedAt, user.ID)
+	result, err := r.db.ExecContext(ctx, query, user.Name, user.Phone, user.Locale, user.UpdatedAt, user.ID)
 	if err != nil {
 		return err
 	}
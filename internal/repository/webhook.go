@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/pagination"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+const (
+	subscriptionColumns = `id, user_id, url, description, events, secret, active, consecutive_failures, disabled_at, disabled_reason, created_at, updated_at`
+	deliveryColumns     = `id, subscription_id, event_id, event_type, payload, status, attempts, response_status, response_body, error, duration_ms, created_at, last_attempt_at, delivered_at`
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, sub *model.WebhookSubscription) error {
+	sub.ID = uuid.New()
+	sub.CreatedAt = time.Now()
+	sub.UpdatedAt = sub.CreatedAt
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_subscriptions (id, user_id, url, description, events, secret, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, sub.ID, sub.UserID, sub.URL, nullString(sub.Description), pq.Array(eventStrings(sub.Events)), sub.Secret, sub.Active, sub.CreatedAt, sub.UpdatedAt)
+	return err
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.WebhookSubscription, error) {
+	sub, err := scanSubscription(r.db.QueryRowContext(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (r *WebhookRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.WebhookSubscription, error) {
+	return r.list(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE user_id = $1 ORDER BY created_at`, userID)
+}
+
+// ListActiveForUsers returns the active subscriptions of any of userIDs.
+func (r *WebhookRepository) ListActiveForUsers(ctx context.Context, userIDs []uuid.UUID) ([]*model.WebhookSubscription, error) {
+	ids := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+	return r.list(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE user_id = ANY($1::uuid[]) AND active ORDER BY created_at`, pq.Array(ids))
+}
+
+func (r *WebhookRepository) list(ctx context.Context, query string, args ...interface{}) ([]*model.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []*model.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, sub *model.WebhookSubscription) error {
+	sub.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_subscriptions
+		SET url = $1, description = $2, events = $3, active = $4, consecutive_failures = $5, disabled_at = $6, disabled_reason = $7, updated_at = $8
+		WHERE id = $9
+	`, sub.URL, nullString(sub.Description), pq.Array(eventStrings(sub.Events)), sub.Active, sub.ConsecutiveFailures, sub.DisabledAt, nullString(sub.DisabledReason), sub.UpdatedAt, sub.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed attempt against the subscription and
+// disables it once disableAfter attempts in a row have failed. It reports
+// whether this failure disabled it.
+func (r *WebhookRepository) RecordFailure(ctx context.Context, id uuid.UUID, disableAfter int, reason string) (bool, error) {
+	var disabled bool
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE webhook_subscriptions
+		SET consecutive_failures = consecutive_failures + 1,
+			active = active AND consecutive_failures + 1 < $1,
+			disabled_at = CASE WHEN active AND consecutive_failures + 1 >= $1 THEN $2 ELSE disabled_at END,
+			disabled_reason = CASE WHEN active AND consecutive_failures + 1 >= $1 THEN $3 ELSE disabled_reason END,
+			updated_at = $2
+		WHERE id = $4
+		RETURNING disabled_at = $2 AND NOT active
+	`, disableAfter, time.Now(), reason, id).Scan(&disabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrWebhookNotFound
+	}
+	return disabled, err
+}
+
+func (r *WebhookRepository) RecordSuccess(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE webhook_subscriptions SET consecutive_failures = 0 WHERE id = $1 AND consecutive_failures > 0`, id)
+	return err
+}
+
+// CreateDelivery logs a delivery of an event to a subscription. If the
+// event was already logged for it, delivery is filled in from the existing
+// entry instead.
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (subscription_id, event_id) DO UPDATE SET event_type = EXCLUDED.event_type
+		RETURNING ` + deliveryColumns
+
+	existing, err := scanDelivery(r.db.QueryRowContext(ctx, query,
+		uuid.New(),
+		delivery.SubscriptionID,
+		delivery.EventID,
+		delivery.EventType,
+		[]byte(delivery.Payload),
+		model.DeliveryPending,
+		time.Now(),
+	))
+	if err != nil {
+		return err
+	}
+
+	*delivery = *existing
+	return nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error) {
+	delivery, err := scanDelivery(r.db.QueryRowContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// UpdateDelivery saves the outcome of the latest attempt.
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, response_status = $3, response_body = $4, error = $5, duration_ms = $6, last_attempt_at = $7, delivered_at = $8
+		WHERE id = $9
+	`,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		nullString(delivery.ResponseBody),
+		nullString(delivery.Error),
+		delivery.DurationMs,
+		delivery.LastAttemptAt,
+		delivery.DeliveredAt,
+		delivery.ID,
+	)
+	return err
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, pag pagination.Params) ([]*model.WebhookDelivery, int64, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhook_deliveries WHERE subscription_id = $1`, subscriptionID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, subscriptionID, pag.PerPage, pag.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []*model.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, total, rows.Err()
+}
+
+func scanSubscription(row rowScanner) (*model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	var events []string
+	var description, disabledReason sql.NullString
+
+	err := row.Scan(
+		&sub.ID,
+		&sub.UserID,
+		&sub.URL,
+		&description,
+		pq.Array(&events),
+		&sub.Secret,
+		&sub.Active,
+		&sub.ConsecutiveFailures,
+		&sub.DisabledAt,
+		&disabledReason,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.Description = description.String
+	sub.DisabledReason = disabledReason.String
+	sub.Events = make([]model.DomainEventType, len(events))
+	for i, e := range events {
+		sub.Events[i] = model.DomainEventType(e)
+	}
+
+	return &sub, nil
+}
+
+func scanDelivery(row rowScanner) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	var payload []byte
+	var responseStatus sql.NullInt64
+	var responseBody, deliveryErr sql.NullString
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&responseStatus,
+		&responseBody,
+		&deliveryErr,
+		&delivery.DurationMs,
+		&delivery.CreatedAt,
+		&delivery.LastAttemptAt,
+		&delivery.DeliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = payload
+	delivery.ResponseBody = responseBody.String
+	delivery.Error = deliveryErr.String
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		delivery.ResponseStatus = &status
+	}
+
+	return &delivery, nil
+}
+
+func eventStrings(events []model.DomainEventType) []string {
+	values := make([]string, len(events))
+	for i, e := range events {
+		values[i] = string(e)
+	}
+	return values
+}
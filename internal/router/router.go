@@ -5,6 +5,7 @@ import (
 
 	"github.com/abneribeiro/goapi/internal/handler"
 	"github.com/abneribeiro/goapi/internal/middleware"
+	"github.com/abneribeiro/goapi/internal/model"
 )
 
 type Router struct {
@@ -13,8 +14,13 @@ type Router struct {
 	authHandler    *handler.AuthHandler
 	userHandler    *handler.UserHandler
 	equipHandler   *handler.EquipmentHandler
+	catHandler     *handler.CategoryHandler
 	resHandler     *handler.ReservationHandler
 	notifHandler   *handler.NotificationHandler
+	payHandler     *handler.PaymentHandler
+	jobHandler     *handler.JobHandler
+	webhookHandler *handler.WebhookHandler
+	liveHandler    *handler.LiveHandler
 	docsHandler    *handler.DocsHandler
 }
 
@@ -23,8 +29,13 @@ func New(
 	authHandler *handler.AuthHandler,
 	userHandler *handler.UserHandler,
 	equipHandler *handler.EquipmentHandler,
+	catHandler *handler.CategoryHandler,
 	resHandler *handler.ReservationHandler,
 	notifHandler *handler.NotificationHandler,
+	payHandler *handler.PaymentHandler,
+	jobHandler *handler.JobHandler,
+	webhookHandler *handler.WebhookHandler,
+	liveHandler *handler.LiveHandler,
 	docsHandler *handler.DocsHandler,
 ) *Router {
 	return &Router{
@@ -33,8 +44,13 @@ func New(
 		authHandler:    authHandler,
 		userHandler:    userHandler,
 		equipHandler:   equipHandler,
+		catHandler:     catHandler,
 		resHandler:     resHandler,
 		notifHandler:   notifHandler,
+		payHandler:     payHandler,
+		jobHandler:     jobHandler,
+		webhookHandler: webhookHandler,
+		liveHandler:    liveHandler,
 		docsHandler:    docsHandler,
 	}
 }
@@ -49,19 +65,47 @@ func (r *Router) Setup() http.Handler {
 
 	r.mux.HandleFunc("POST /api/v1/auth/register", r.authHandler.Register)
 	r.mux.HandleFunc("POST /api/v1/auth/login", r.authHandler.Login)
+	r.mux.Handle("POST /api/v1/auth/stream-token", r.authMiddleware.Authenticate(http.HandlerFunc(r.authHandler.StreamToken)))
 
 	r.mux.Handle("GET /api/v1/users/me", r.authMiddleware.Authenticate(http.HandlerFunc(r.userHandler.GetMe)))
 	r.mux.Handle("PUT /api/v1/users/me", r.authMiddleware.Authenticate(http.HandlerFunc(r.userHandler.UpdateMe)))
+	r.mux.Handle("GET /api/v1/users/me/notification-preferences", r.authMiddleware.Authenticate(http.HandlerFunc(r.notifHandler.GetPreferences)))
+	r.mux.Handle("PUT /api/v1/users/me/notification-preferences", r.authMiddleware.Authenticate(http.HandlerFunc(r.notifHandler.UpdatePreferences)))
+	r.mux.Handle("POST /api/v1/users/me/push-subscriptions", r.authMiddleware.Authenticate(http.HandlerFunc(r.notifHandler.SubscribePush)))
+	r.mux.Handle("DELETE /api/v1/users/me/push-subscriptions", r.authMiddleware.Authenticate(http.HandlerFunc(r.notifHandler.UnsubscribePush)))
 
 	r.mux.HandleFunc("GET /api/v1/equipment", r.equipHandler.List)
 	r.mux.HandleFunc("GET /api/v1/equipment/search", r.equipHandler.Search)
 	r.mux.HandleFunc("GET /api/v1/equipment/categories", r.equipHandler.GetCategories)
 	r.mux.HandleFunc("GET /api/v1/equipment/{id}", r.equipHandler.GetByID)
 	r.mux.HandleFunc("GET /api/v1/equipment/{id}/availability", r.equipHandler.GetAvailability)
+	r.mux.HandleFunc("GET /api/v1/equipment/{id}/schedule", r.equipHandler.GetSchedule)
+	r.mux.HandleFunc("GET /api/v1/equipment/{id}/slots", r.equipHandler.GetSlots)
+	r.mux.HandleFunc("GET /api/v1/equipment/{id}/opening-hours", r.equipHandler.GetOpeningHours)
+	r.mux.HandleFunc("POST /api/v1/equipment/{id}/quote", r.resHandler.Quote)
 	r.mux.Handle("POST /api/v1/equipment", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.Create)))
 	r.mux.Handle("PUT /api/v1/equipment/{id}", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.Update)))
 	r.mux.Handle("DELETE /api/v1/equipment/{id}", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.Delete)))
 	r.mux.Handle("POST /api/v1/equipment/{id}/photos", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.UploadPhoto)))
+	r.mux.Handle("GET /api/v1/equipment/{id}/units", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.ListUnits)))
+	r.mux.Handle("POST /api/v1/equipment/{id}/units", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.CreateUnit)))
+	r.mux.Handle("PUT /api/v1/equipment/{id}/units/{unitId}", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.UpdateUnit)))
+	r.mux.Handle("DELETE /api/v1/equipment/{id}/units/{unitId}", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.DeleteUnit)))
+	r.mux.Handle("PUT /api/v1/equipment/{id}/opening-hours", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.SetOpeningHours)))
+	r.mux.Handle("GET /api/v1/equipment/{id}/blackouts", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.ListBlackouts)))
+	r.mux.Handle("POST /api/v1/equipment/{id}/blackouts", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.CreateBlackout)))
+	r.mux.Handle("PUT /api/v1/equipment/{id}/blackouts/{blackoutId}", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.UpdateBlackout)))
+	r.mux.Handle("DELETE /api/v1/equipment/{id}/blackouts/{blackoutId}", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.DeleteBlackout)))
+	r.mux.Handle("GET /api/v1/equipment/{id}/pricing-rules", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.ListPricingRules)))
+	r.mux.Handle("POST /api/v1/equipment/{id}/pricing-rules", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.CreatePricingRule)))
+	r.mux.Handle("PUT /api/v1/equipment/{id}/pricing-rules/{ruleId}", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.UpdatePricingRule)))
+	r.mux.Handle("DELETE /api/v1/equipment/{id}/pricing-rules/{ruleId}", r.authMiddleware.Authenticate(http.HandlerFunc(r.equipHandler.DeletePricingRule)))
+
+	r.mux.HandleFunc("GET /api/v1/categories", r.catHandler.List)
+	r.mux.HandleFunc("GET /api/v1/categories/{id}", r.catHandler.GetByID)
+	r.mux.Handle("POST /api/v1/categories", r.adminOnly(r.catHandler.Create))
+	r.mux.Handle("PUT /api/v1/categories/{id}", r.adminOnly(r.catHandler.Update))
+	r.mux.Handle("DELETE /api/v1/categories/{id}", r.adminOnly(r.catHandler.Delete))
 
 	r.mux.Handle("GET /api/v1/reservations", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.ListMyReservations)))
 	r.mux.Handle("GET /api/v1/reservations/owner", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.ListOwnerReservations)))
@@ -71,19 +115,60 @@ func (r *Router) Setup() http.Handler {
 	r.mux.Handle("PUT /api/v1/reservations/{id}/reject", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.Reject)))
 	r.mux.Handle("PUT /api/v1/reservations/{id}/cancel", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.Cancel)))
 	r.mux.Handle("PUT /api/v1/reservations/{id}/complete", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.Complete)))
+	r.mux.Handle("POST /api/v1/reservations/{id}/dispute", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.Dispute)))
+	r.mux.Handle("PUT /api/v1/reservations/{id}/dispute/resolve", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.ResolveDispute)))
+	r.mux.Handle("GET /api/v1/reservations/{id}/history", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.History)))
+	r.mux.Handle("POST /api/v1/reservations/{id}/pickup", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.Pickup)))
+	r.mux.Handle("POST /api/v1/reservations/{id}/return", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.Return)))
+	r.mux.Handle("GET /api/v1/reservations/{id}/handovers", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.ListHandovers)))
+	r.mux.Handle("GET /api/v1/reservations/{id}/changes", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.ListChanges)))
+	r.mux.Handle("POST /api/v1/reservations/{id}/changes", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.RequestChange)))
+	r.mux.Handle("PUT /api/v1/reservations/{id}/changes/{changeId}/approve", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.ApproveChange)))
+	r.mux.Handle("PUT /api/v1/reservations/{id}/changes/{changeId}/reject", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.RejectChange)))
+	r.mux.Handle("PUT /api/v1/reservations/{id}/deposit/capture", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.CaptureDeposit)))
+	r.mux.Handle("PUT /api/v1/reservations/{id}/deposit/release", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.ReleaseDeposit)))
+	r.mux.Handle("GET /api/v1/reservations/{id}/payments", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.ListPayments)))
+	r.mux.Handle("POST /api/v1/reservations/{id}/payments", r.authMiddleware.Authenticate(http.HandlerFunc(r.resHandler.Pay)))
+
+	r.mux.HandleFunc("POST /api/v1/payments/webhook", r.payHandler.Webhook)
 
 	r.mux.Handle("GET /api/v1/notifications", r.authMiddleware.Authenticate(http.HandlerFunc(r.notifHandler.List)))
+	r.mux.Handle("GET /api/v1/notifications/stream", r.authMiddleware.AuthenticateStream(http.HandlerFunc(r.notifHandler.Stream)))
+	r.mux.HandleFunc("GET /api/v1/notifications/push/public-key", r.notifHandler.PushPublicKey)
 	r.mux.Handle("GET /api/v1/notifications/unread-count", r.authMiddleware.Authenticate(http.HandlerFunc(r.notifHandler.GetUnreadCount)))
 	r.mux.Handle("PUT /api/v1/notifications/{id}/read", r.authMiddleware.Authenticate(http.HandlerFunc(r.notifHandler.MarkAsRead)))
 	r.mux.Handle("PUT /api/v1/notifications/read-all", r.authMiddleware.Authenticate(http.HandlerFunc(r.notifHandler.MarkAllAsRead)))
+	r.mux.Handle("POST /api/v1/notifications/bulk-read", r.authMiddleware.Authenticate(http.HandlerFunc(r.notifHandler.BulkMarkAsRead)))
+	r.mux.Handle("POST /api/v1/notifications/bulk-delete", r.authMiddleware.Authenticate(http.HandlerFunc(r.notifHandler.BulkDelete)))
 	r.mux.Handle("DELETE /api/v1/notifications/{id}", r.authMiddleware.Authenticate(http.HandlerFunc(r.notifHandler.Delete)))
 
+	r.mux.Handle("GET /api/v1/live", r.authMiddleware.AuthenticateStream(http.HandlerFunc(r.liveHandler.Connect)))
+
+	r.mux.Handle("GET /api/v1/webhooks", r.authMiddleware.Authenticate(http.HandlerFunc(r.webhookHandler.List)))
+	r.mux.Handle("POST /api/v1/webhooks", r.authMiddleware.Authenticate(http.HandlerFunc(r.webhookHandler.Create)))
+	r.mux.Handle("GET /api/v1/webhooks/{id}", r.authMiddleware.Authenticate(http.HandlerFunc(r.webhookHandler.GetByID)))
+	r.mux.Handle("PUT /api/v1/webhooks/{id}", r.authMiddleware.Authenticate(http.HandlerFunc(r.webhookHandler.Update)))
+	r.mux.Handle("DELETE /api/v1/webhooks/{id}", r.authMiddleware.Authenticate(http.HandlerFunc(r.webhookHandler.Delete)))
+	r.mux.Handle("GET /api/v1/webhooks/{id}/deliveries", r.authMiddleware.Authenticate(http.HandlerFunc(r.webhookHandler.ListDeliveries)))
+	r.mux.Handle("POST /api/v1/webhooks/{id}/test", r.authMiddleware.Authenticate(http.HandlerFunc(r.webhookHandler.SendTest)))
+
+	r.mux.Handle("GET /api/v1/admin/jobs", r.adminOnly(r.jobHandler.List))
+	r.mux.Handle("GET /api/v1/admin/queue", r.adminOnly(r.jobHandler.ListQueue))
+	r.mux.Handle("GET /api/v1/admin/queue/{id}", r.adminOnly(r.jobHandler.GetQueued))
+	r.mux.Handle("POST /api/v1/admin/queue/{id}/retry", r.adminOnly(r.jobHandler.Retry))
+	r.mux.Handle("GET /api/v1/admin/notification-templates", r.adminOnly(r.notifHandler.ListTemplates))
+	r.mux.Handle("POST /api/v1/admin/notification-templates", r.adminOnly(r.notifHandler.CreateTemplate))
+
 	fs := http.FileServer(http.Dir("./uploads"))
 	r.mux.Handle("GET /uploads/", http.StripPrefix("/uploads/", fs))
 
 	return middleware.CORS(middleware.Logger(middleware.Recovery(r.mux)))
 }
 
+func (r *Router) adminOnly(h http.HandlerFunc) http.Handler {
+	return r.authMiddleware.Authenticate(r.authMiddleware.RequireRole(model.RoleAdmin)(h))
+}
+
 func (r *Router) healthCheck(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
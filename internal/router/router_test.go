@@ -4,6 +4,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/abneribeiro/goapi/internal/handler"
 	"github.com/abneribeiro/goapi/internal/middleware"
@@ -18,8 +21,13 @@ func setupTestRouter() *Router {
 	authHandler := &handler.AuthHandler{}
 	userHandler := &handler.UserHandler{}
 	equipHandler := &handler.EquipmentHandler{}
+	catHandler := &handler.CategoryHandler{}
 	resHandler := &handler.ReservationHandler{}
 	notifHandler := &handler.NotificationHandler{}
+	payHandler := &handler.PaymentHandler{}
+	jobHandler := &handler.JobHandler{}
+	webhookHandler := &handler.WebhookHandler{}
+	liveHandler := &handler.LiveHandler{}
 	docsHandler := handler.NewDocsHandler("../../docs")
 
 	return New(
@@ -27,8 +35,13 @@ func setupTestRouter() *Router {
 		authHandler,
 		userHandler,
 		equipHandler,
+		catHandler,
 		resHandler,
 		notifHandler,
+		payHandler,
+		jobHandler,
+		webhookHandler,
+		liveHandler,
 		docsHandler,
 	)
 }
@@ -105,9 +118,35 @@ func TestProtectedRoutesRequireAuth(t *testing.T) {
 	}{
 		{http.MethodGet, "/api/v1/users/me"},
 		{http.MethodPut, "/api/v1/users/me"},
+		{http.MethodGet, "/api/v1/users/me/notification-preferences"},
+		{http.MethodPut, "/api/v1/users/me/notification-preferences"},
+		{http.MethodPost, "/api/v1/users/me/push-subscriptions"},
+		{http.MethodDelete, "/api/v1/users/me/push-subscriptions"},
 		{http.MethodPost, "/api/v1/equipment"},
 		{http.MethodGet, "/api/v1/reservations"},
+		{http.MethodPut, "/api/v1/reservations/00000000-0000-0000-0000-000000000001/deposit/capture"},
+		{http.MethodPost, "/api/v1/reservations/00000000-0000-0000-0000-000000000001/payments"},
+		{http.MethodGet, "/api/v1/reservations/00000000-0000-0000-0000-000000000001/history"},
+		{http.MethodPost, "/api/v1/reservations/00000000-0000-0000-0000-000000000001/pickup"},
+		{http.MethodPost, "/api/v1/reservations/00000000-0000-0000-0000-000000000001/changes"},
 		{http.MethodGet, "/api/v1/notifications"},
+		{http.MethodGet, "/api/v1/notifications/stream"},
+		{http.MethodPost, "/api/v1/notifications/bulk-read"},
+		{http.MethodPost, "/api/v1/notifications/bulk-delete"},
+		{http.MethodPost, "/api/v1/categories"},
+		{http.MethodGet, "/api/v1/equipment/00000000-0000-0000-0000-000000000001/units"},
+		{http.MethodPost, "/api/v1/equipment/00000000-0000-0000-0000-000000000001/blackouts"},
+		{http.MethodPut, "/api/v1/equipment/00000000-0000-0000-0000-000000000001/opening-hours"},
+		{http.MethodGet, "/api/v1/equipment/00000000-0000-0000-0000-000000000001/pricing-rules"},
+		{http.MethodDelete, "/api/v1/categories/00000000-0000-0000-0000-000000000001"},
+		{http.MethodGet, "/api/v1/live"},
+		{http.MethodGet, "/api/v1/webhooks"},
+		{http.MethodPost, "/api/v1/webhooks/00000000-0000-0000-0000-000000000001/test"},
+		{http.MethodGet, "/api/v1/webhooks/00000000-0000-0000-0000-000000000001/deliveries"},
+		{http.MethodGet, "/api/v1/admin/jobs"},
+		{http.MethodPost, "/api/v1/admin/queue/00000000-0000-0000-0000-000000000001/retry"},
+		{http.MethodGet, "/api/v1/admin/notification-templates"},
+		{http.MethodPost, "/api/v1/admin/notification-templates"},
 	}
 
 	for _, route := range protectedRoutes {
@@ -137,6 +176,7 @@ func TestPublicRoutes(t *testing.T) {
 		{http.MethodGet, "/api/v1/equipment"},
 		{http.MethodGet, "/api/v1/equipment/categories"},
 		{http.MethodGet, "/api/v1/equipment/search"},
+		{http.MethodPost, "/api/v1/equipment/00000000-0000-0000-0000-000000000001/quote"},
 	}
 
 	for _, route := range publicRoutes {
@@ -153,3 +193,21 @@ func TestPublicRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestAdminRoutesRequireAdminRole(t *testing.T) {
+	r := setupTestRouter()
+	handler := r.Setup()
+
+	jwtManager := jwt.NewManager("test-secret", time.Hour)
+	token, _ := jwtManager.Generate(uuid.New(), "owner@example.com", "owner")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
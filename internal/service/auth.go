@@ -6,6 +6,7 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/abneribeiro/goapi/internal/i18n"
 	"github.com/abneribeiro/goapi/internal/model"
 	"github.com/abneribeiro/goapi/internal/pkg/jwt"
 	"github.com/abneribeiro/goapi/internal/pkg/validator"
@@ -38,6 +39,9 @@ func (s *AuthService) Register(ctx context.Context, req *model.CreateUserRequest
 	if req.Role != "" {
 		v.InList("role", string(req.Role), []string{string(model.RoleOwner), string(model.RoleRenter)})
 	}
+	if req.Locale != "" {
+		v.InList("locale", req.Locale, i18n.Locales())
+	}
 
 	if v.Errors().HasErrors() {
 		return nil, v.Errors()
@@ -53,11 +57,17 @@ func (s *AuthService) Register(ctx context.Context, req *model.CreateUserRequest
 		role = model.RoleRenter
 	}
 
+	locale := req.Locale
+	if locale == "" {
+		locale = i18n.DefaultLocale
+	}
+
 	user := &model.User{
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
 		Name:         req.Name,
 		Phone:        req.Phone,
+		Locale:       locale,
 		Role:         role,
 		Verified:     false,
 	}
@@ -111,3 +121,17 @@ func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 		User:  *user,
 	}, nil
 }
+
+// StreamToken issues a stream token for the authenticated user, to open the
+// live connections browsers cannot send an Authorization header on.
+func (s *AuthService) StreamToken(claims *jwt.Claims) (*model.StreamTokenResponse, error) {
+	token, expiresAt, err := s.jwtManager.GenerateStreamToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StreamTokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
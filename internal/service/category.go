@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/slug"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+var (
+	ErrCategoryNotFound   = errors.New("category not found")
+	ErrCategorySlugExists = errors.New("category slug already exists")
+	ErrCategoryInUse      = errors.New("category is still referenced by equipment or subcategories")
+	ErrCategoryCycle      = errors.New("category cannot be its own ancestor")
+)
+
+var attributeKeyRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type CategoryService struct {
+	categoryRepo *repository.CategoryRepository
+}
+
+func NewCategoryService(categoryRepo *repository.CategoryRepository) *CategoryService {
+	return &CategoryService{
+		categoryRepo: categoryRepo,
+	}
+}
+
+func (s *CategoryService) List(ctx context.Context) ([]*model.Category, error) {
+	return s.categoryRepo.List(ctx)
+}
+
+func (s *CategoryService) Tree(ctx context.Context) ([]*model.Category, error) {
+	categories, err := s.categoryRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.BuildCategoryTree(categories), nil
+}
+
+func (s *CategoryService) GetByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	category, err := s.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *CategoryService) Create(ctx context.Context, req *model.CreateCategoryRequest) (*model.Category, error) {
+	categorySlug := req.Slug
+	if categorySlug == "" {
+		categorySlug = slug.Make(req.Name)
+	}
+
+	v := validator.New()
+	v.Required("name", req.Name).MaxLength("name", req.Name, 100)
+	if !slug.Valid(categorySlug) {
+		v.AddError("slug", "must contain only lowercase letters, digits and dashes")
+	}
+	validateAttributes(v, req.Attributes)
+
+	if v.Errors().HasErrors() {
+		return nil, v.Errors()
+	}
+
+	if req.ParentID != nil {
+		if _, err := s.GetByID(ctx, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	category := &model.Category{
+		ParentID:   req.ParentID,
+		Name:       strings.TrimSpace(req.Name),
+		Slug:       categorySlug,
+		Icon:       req.Icon,
+		Attributes: req.Attributes,
+	}
+
+	if err := s.categoryRepo.Create(ctx, category); err != nil {
+		if errors.Is(err, repository.ErrCategorySlugExists) {
+			return nil, ErrCategorySlugExists
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *CategoryService) Update(ctx context.Context, id uuid.UUID, req *model.UpdateCategoryRequest) (*model.Category, error) {
+	category, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+	v.MaxLength("name", req.Name, 100)
+	if req.Slug != "" && !slug.Valid(req.Slug) {
+		v.AddError("slug", "must contain only lowercase letters, digits and dashes")
+	}
+	if req.Attributes != nil {
+		validateAttributes(v, *req.Attributes)
+	}
+
+	if v.Errors().HasErrors() {
+		return nil, v.Errors()
+	}
+
+	if req.Name != "" {
+		category.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Slug != "" {
+		category.Slug = req.Slug
+	}
+	if req.Icon != nil {
+		category.Icon = *req.Icon
+	}
+	if req.Attributes != nil {
+		category.Attributes = *req.Attributes
+	}
+
+	if req.MakeRoot {
+		category.ParentID = nil
+	} else if req.ParentID != nil {
+		if *req.ParentID == id {
+			return nil, ErrCategoryCycle
+		}
+		if _, err := s.GetByID(ctx, *req.ParentID); err != nil {
+			return nil, err
+		}
+		descendant, err := s.categoryRepo.IsDescendant(ctx, id, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if descendant {
+			return nil, ErrCategoryCycle
+		}
+		category.ParentID = req.ParentID
+	}
+
+	if err := s.categoryRepo.Update(ctx, category); err != nil {
+		if errors.Is(err, repository.ErrCategorySlugExists) {
+			return nil, ErrCategorySlugExists
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *CategoryService) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	inUse, err := s.categoryRepo.IsInUse(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrCategoryInUse
+	}
+
+	return s.categoryRepo.Delete(ctx, id)
+}
+
+func validateAttributes(v *validator.Validator, attributes []model.CategoryAttribute) {
+	allowedTypes := []string{
+		string(model.AttributeNumber),
+		string(model.AttributeEnum),
+		string(model.AttributeBoolean),
+		string(model.AttributeText),
+	}
+
+	seen := make(map[string]bool)
+	for _, attr := range attributes {
+		field := "attributes." + attr.Key
+		if !attributeKeyRegex.MatchString(attr.Key) {
+			v.AddError("attributes", "key "+attr.Key+" must be snake_case")
+			continue
+		}
+		if seen[attr.Key] {
+			v.AddError(field, "is defined more than once")
+		}
+		seen[attr.Key] = true
+
+		v.Required(field+".type", string(attr.Type)).InList(field+".type", string(attr.Type), allowedTypes)
+		if attr.Type == model.AttributeEnum && len(attr.Options) == 0 {
+			v.AddError(field+".options", "is required for enum attributes")
+		}
+		if attr.Unit != "" && attr.Type != model.AttributeNumber {
+			v.AddError(field+".unit", "is only allowed on number attributes")
+		}
+	}
+}
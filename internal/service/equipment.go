@@ -2,63 +2,140 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"os"
-	"path/filepath"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
 	"github.com/abneribeiro/goapi/internal/pkg/pagination"
+	"github.com/abneribeiro/goapi/internal/pkg/slug"
 	"github.com/abneribeiro/goapi/internal/pkg/validator"
 	"github.com/abneribeiro/goapi/internal/repository"
 )
 
 var (
-	ErrEquipmentNotFound = errors.New("equipment not found")
-	ErrNotOwner          = errors.New("not the owner of this equipment")
-	ErrNoAvailability    = errors.New("equipment not available for selected dates")
+	ErrEquipmentNotFound   = errors.New("equipment not found")
+	ErrNotOwner            = errors.New("not the owner of this equipment")
+	ErrNoAvailability      = errors.New("equipment not available for selected dates")
+	ErrUnitNotFound        = errors.New("equipment unit not found")
+	ErrUnitSerialExists    = errors.New("serial number already exists for this equipment")
+	ErrUnitInUse           = errors.New("unit has reservations and can only be retired")
+	ErrUnitsNotTracked     = errors.New("equipment does not track individual units")
+	ErrUnsupportedCurrency = errors.New("no exchange rate for currency")
 )
 
 type EquipmentService struct {
 	equipmentRepo *repository.EquipmentRepository
+	categoryRepo  *repository.CategoryRepository
 	uploadPath    string
+	rates         *money.Rates
 }
 
-func NewEquipmentService(equipmentRepo *repository.EquipmentRepository, uploadPath string) *EquipmentService {
+func NewEquipmentService(equipmentRepo *repository.EquipmentRepository, categoryRepo *repository.CategoryRepository, uploadPath string, rates *money.Rates) *EquipmentService {
 	return &EquipmentService{
 		equipmentRepo: equipmentRepo,
+		categoryRepo:  categoryRepo,
 		uploadPath:    uploadPath,
+		rates:         rates,
 	}
 }
 
 func (s *EquipmentService) Create(ctx context.Context, ownerID uuid.UUID, req *model.CreateEquipmentRequest) (*model.Equipment, error) {
 	v := validator.New()
 	v.Required("name", req.Name)
-	v.Required("category", req.Category)
+
+	if req.CategoryID == nil && req.Category == "" {
+		v.AddError("category_id", "is required")
+	}
 
 	if req.PricePerHour == nil && req.PricePerDay == nil && req.PricePerWeek == nil {
 		v.AddError("price", "at least one price must be set")
 	}
 
+	if req.Currency == "" {
+		req.Currency = money.DefaultCurrency
+	}
+	if !money.ValidCurrency(req.Currency) {
+		v.AddError("currency", "must be a supported ISO 4217 currency code")
+	}
+	pricePerHour := parsePrice(v, "price_per_hour", req.PricePerHour, req.Currency)
+	pricePerDay := parsePrice(v, "price_per_day", req.PricePerDay, req.Currency)
+	pricePerWeek := parsePrice(v, "price_per_week", req.PricePerWeek, req.Currency)
+	depositAmount := parsePrice(v, "deposit_amount", req.DepositAmount, req.Currency)
+
+	if req.InventoryMode == "" {
+		req.InventoryMode = model.InventoryPooled
+	}
+	if !req.InventoryMode.Valid() {
+		v.AddError("inventory_mode", "must be pooled or serialized")
+	}
+
+	if req.TimeZone == "" {
+		req.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(req.TimeZone); err != nil {
+		v.AddError("time_zone", "must be a valid IANA time zone")
+	}
+
+	cancellationPolicy := model.DefaultCancellationPolicy
+	if req.CancellationPolicy != nil {
+		cancellationPolicy = parseCancellationPolicy(v, req.CancellationPolicy)
+	}
+
+	quantity := req.Quantity
+	switch {
+	case req.InventoryMode == model.InventorySerialized:
+		if quantity != 0 {
+			v.AddError("quantity", "is derived from units for serialized equipment")
+		}
+	case quantity == 0:
+		quantity = 1
+	case quantity < 1:
+		v.AddError("quantity", "must be at least 1")
+	}
+
 	if v.Errors().HasErrors() {
 		return nil, v.Errors()
 	}
 
+	category, err := s.resolveCategory(ctx, req.CategoryID, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	specifications, err := s.validateSpecifications(ctx, category.ID, req.Specifications)
+	if err != nil {
+		return nil, err
+	}
+
 	equipment := &model.Equipment{
-		OwnerID:      ownerID,
-		Name:         req.Name,
-		Description:  req.Description,
-		Category:     req.Category,
-		PricePerHour: req.PricePerHour,
-		PricePerDay:  req.PricePerDay,
-		PricePerWeek: req.PricePerWeek,
-		Location:     req.Location,
-		Latitude:     req.Latitude,
-		Longitude:    req.Longitude,
-		AutoApprove:  req.AutoApprove,
+		OwnerID:            ownerID,
+		Name:               req.Name,
+		Description:        req.Description,
+		CategoryID:         &category.ID,
+		Category:           category.Name,
+		Currency:           req.Currency,
+		PricePerHour:       pricePerHour,
+		PricePerDay:        pricePerDay,
+		PricePerWeek:       pricePerWeek,
+		DepositAmount:      depositAmount,
+		Location:           req.Location,
+		Latitude:           req.Latitude,
+		Longitude:          req.Longitude,
+		AutoApprove:        req.AutoApprove,
+		RequirePrepayment:  req.RequirePrepayment,
+		CancellationPolicy: cancellationPolicy,
+		InventoryMode:      req.InventoryMode,
+		Quantity:           quantity,
+		TimeZone:           req.TimeZone,
+		Specifications:     specifications,
 	}
 
 	if err := s.equipmentRepo.Create(ctx, equipment); err != nil {
@@ -80,9 +157,81 @@ func (s *EquipmentService) GetByID(ctx context.Context, id uuid.UUID) (*model.Eq
 }
 
 func (s *EquipmentService) List(ctx context.Context, filter *model.EquipmentFilter, pag pagination.Params) ([]*model.Equipment, int64, error) {
+	if len(filter.Specs) > 0 {
+		schema, err := s.specSchema(ctx, filter.CategoryID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := typeSpecFilters(filter.Specs, schema); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	return s.equipmentRepo.List(ctx, filter, pag)
 }
 
+// specSchema returns the attributes specification filters are typed from:
+// the filtered category's schema, or every category's attributes when the
+// listing is not filtered by category.
+func (s *EquipmentService) specSchema(ctx context.Context, categoryID *uuid.UUID) ([]model.CategoryAttribute, error) {
+	if categoryID != nil {
+		return s.categoryRepo.GetAttributeSchema(ctx, *categoryID)
+	}
+
+	categories, err := s.categoryRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var schema []model.CategoryAttribute
+	for _, category := range categories {
+		schema = append(schema, category.Attributes...)
+	}
+	return schema, nil
+}
+
+// typeSpecFilters sets each filter's type from the schema and checks its
+// value reads as that type. A key the schema does not define, or defines
+// with different types in different categories, is rejected.
+func typeSpecFilters(specs []model.SpecFilter, schema []model.CategoryAttribute) error {
+	types := make(map[string][]model.AttributeType)
+	for _, attr := range schema {
+		if !slices.Contains(types[attr.Key], attr.Type) {
+			types[attr.Key] = append(types[attr.Key], attr.Type)
+		}
+	}
+
+	v := validator.New()
+	for i := range specs {
+		spec := &specs[i]
+		field := "spec." + spec.Key
+
+		switch keyTypes := types[spec.Key]; {
+		case len(keyTypes) == 0:
+			v.AddError(field, "is not an attribute of the category")
+			continue
+		case len(keyTypes) > 1:
+			v.AddError(field, "has different types in different categories; filter by category_id")
+			continue
+		default:
+			spec.Type = keyTypes[0]
+		}
+
+		if spec.Operator.IsRange() && spec.Type != model.AttributeNumber {
+			v.AddError(field, "range operators need a number attribute")
+			continue
+		}
+		if _, err := spec.TypedValue(); err != nil {
+			v.AddError(field, err.Error())
+		}
+	}
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+
+	return nil
+}
+
 func (s *EquipmentService) Update(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, req *model.UpdateEquipmentRequest) (*model.Equipment, error) {
 	equipment, err := s.equipmentRepo.GetByID(ctx, id)
 	if err != nil {
@@ -102,17 +251,31 @@ func (s *EquipmentService) Update(ctx context.Context, id uuid.UUID, ownerID uui
 	if req.Description != "" {
 		equipment.Description = req.Description
 	}
-	if req.Category != "" {
-		equipment.Category = req.Category
+	if req.CategoryID != nil || req.Category != "" {
+		category, err := s.resolveCategory(ctx, req.CategoryID, req.Category)
+		if err != nil {
+			return nil, err
+		}
+		equipment.CategoryID = &category.ID
+		equipment.Category = category.Name
+	}
+	if req.Specifications != nil {
+		equipment.Specifications = req.Specifications
 	}
-	if req.PricePerHour != nil {
-		equipment.PricePerHour = req.PricePerHour
+	if equipment.CategoryID != nil && (req.Specifications != nil || req.CategoryID != nil || req.Category != "") {
+		specifications, err := s.validateSpecifications(ctx, *equipment.CategoryID, equipment.Specifications)
+		if err != nil {
+			return nil, err
+		}
+		equipment.Specifications = specifications
 	}
-	if req.PricePerDay != nil {
-		equipment.PricePerDay = req.PricePerDay
+	if req.Currency != "" && req.Currency != equipment.Currency {
+		if err := s.checkCurrencyChange(ctx, equipment.ID); err != nil {
+			return nil, err
+		}
 	}
-	if req.PricePerWeek != nil {
-		equipment.PricePerWeek = req.PricePerWeek
+	if err := applyPriceUpdate(equipment, req); err != nil {
+		return nil, err
 	}
 	if req.Location != "" {
 		equipment.Location = req.Location
@@ -129,14 +292,218 @@ func (s *EquipmentService) Update(ctx context.Context, id uuid.UUID, ownerID uui
 	if req.AutoApprove != nil {
 		equipment.AutoApprove = *req.AutoApprove
 	}
+	if req.RequirePrepayment != nil {
+		equipment.RequirePrepayment = *req.RequirePrepayment
+	}
+	if req.CancellationPolicy != nil {
+		v := validator.New()
+		cancellationPolicy := parseCancellationPolicy(v, req.CancellationPolicy)
+		if v.Errors().HasErrors() {
+			return nil, v.Errors()
+		}
+		equipment.CancellationPolicy = cancellationPolicy
+	}
+	if req.TimeZone != "" {
+		if _, err := time.LoadLocation(req.TimeZone); err != nil {
+			v := validator.New()
+			v.AddError("time_zone", "must be a valid IANA time zone")
+			return nil, v.Errors()
+		}
+		equipment.TimeZone = req.TimeZone
+	}
+	if err := s.applyInventoryUpdate(equipment, req); err != nil {
+		return nil, err
+	}
 
 	if err := s.equipmentRepo.Update(ctx, equipment); err != nil {
 		return nil, err
 	}
 
+	if equipment.InventoryMode == model.InventorySerialized {
+		quantity, err := s.equipmentRepo.SyncUnitQuantity(ctx, equipment.ID)
+		if err != nil {
+			return nil, err
+		}
+		equipment.Quantity = quantity
+	}
+
 	return equipment, nil
 }
 
+// checkCurrencyChange refuses a new currency while pricing rules have fixed
+// amounts, which are stored in the listing's currency and cannot be sent
+// again with it.
+func (s *EquipmentService) checkCurrencyChange(ctx context.Context, equipmentID uuid.UUID) error {
+	rules, err := s.equipmentRepo.ListPricingRules(ctx, equipmentID, false)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if rule.Amount != nil {
+			v := validator.New()
+			v.AddError("currency", "cannot change while pricing rules have fixed amounts")
+			return v.Errors()
+		}
+	}
+	return nil
+}
+
+// applyPriceUpdate sets new prices, deposit and currency. Prices are stored
+// in minor units of the currency, so a new currency needs every price that
+// is set to be sent with it; keeping the old amounts would silently reprice
+// the listing.
+func applyPriceUpdate(equipment *model.Equipment, req *model.UpdateEquipmentRequest) error {
+	v := validator.New()
+
+	currency := equipment.Currency
+	if req.Currency != "" {
+		if !money.ValidCurrency(req.Currency) {
+			v.AddError("currency", "must be a supported ISO 4217 currency code")
+			return v.Errors()
+		}
+		currency = req.Currency
+	}
+
+	pricePerHour := updatePrice(v, "price_per_hour", req.PricePerHour, equipment.PricePerHour, currency)
+	pricePerDay := updatePrice(v, "price_per_day", req.PricePerDay, equipment.PricePerDay, currency)
+	pricePerWeek := updatePrice(v, "price_per_week", req.PricePerWeek, equipment.PricePerWeek, currency)
+	depositAmount := updatePrice(v, "deposit_amount", req.DepositAmount, equipment.DepositAmount, currency)
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+
+	equipment.Currency = currency
+	equipment.PricePerHour = pricePerHour
+	equipment.PricePerDay = pricePerDay
+	equipment.PricePerWeek = pricePerWeek
+	equipment.DepositAmount = depositAmount
+	return nil
+}
+
+const maxRefundTiers = 10
+
+func parseCancellationPolicy(v *validator.Validator, req *model.CancellationPolicyRequest) model.CancellationPolicy {
+	if !req.Type.Valid() {
+		v.AddError("cancellation_policy.type", "must be flexible, moderate, strict or custom")
+		return model.CancellationPolicy{}
+	}
+
+	if req.Type != model.CancellationCustom {
+		if len(req.Tiers) > 0 {
+			v.AddError("cancellation_policy.tiers", "are only allowed for custom policies")
+		}
+		return model.NewCancellationPolicy(req.Type, nil)
+	}
+
+	if len(req.Tiers) == 0 || len(req.Tiers) > maxRefundTiers {
+		v.AddError("cancellation_policy.tiers", "must have between 1 and "+strconv.Itoa(maxRefundTiers)+" tiers")
+	}
+	seen := make(map[int]bool, len(req.Tiers))
+	for _, tier := range req.Tiers {
+		if tier.HoursBefore < 0 {
+			v.AddError("cancellation_policy.tiers", "hours_before must not be negative")
+		}
+		if tier.RefundPercent < 0 || tier.RefundPercent > 100 {
+			v.AddError("cancellation_policy.tiers", "refund_percent must be between 0 and 100")
+		}
+		if seen[tier.HoursBefore] {
+			v.AddError("cancellation_policy.tiers", "hours_before must be unique")
+		}
+		seen[tier.HoursBefore] = true
+	}
+
+	return model.NewCancellationPolicy(req.Type, req.Tiers)
+}
+
+func updatePrice(v *validator.Validator, field string, value *json.Number, current *money.Money, currency string) *money.Money {
+	if value != nil {
+		return parsePrice(v, field, value, currency)
+	}
+	if current != nil && current.Currency != currency {
+		v.AddError(field, "must be sent again when the currency changes")
+		return nil
+	}
+	return current
+}
+
+func parsePrice(v *validator.Validator, field string, value *json.Number, currency string) *money.Money {
+	if value == nil {
+		return nil
+	}
+	price, err := money.Parse(value.String(), currency)
+	if err != nil {
+		v.AddError(field, "must be an amount with at most "+strconv.Itoa(money.Exponent(currency))+" decimal places")
+		return nil
+	}
+	if price.IsNegative() {
+		v.AddError(field, "must not be negative")
+		return nil
+	}
+	return &price
+}
+
+// DisplayIn attaches prices converted to currency for display. Converting to
+// the listing's own currency is a no-op.
+func (s *EquipmentService) DisplayIn(currency string, items ...*model.Equipment) error {
+	if !money.ValidCurrency(currency) {
+		return ErrUnsupportedCurrency
+	}
+
+	for _, e := range items {
+		if e.Currency == currency {
+			continue
+		}
+		display := &model.DisplayPrices{Currency: currency}
+		var err error
+		if display.PricePerHour, err = s.rates.ConvertPtr(e.PricePerHour, currency); err != nil {
+			return ErrUnsupportedCurrency
+		}
+		if display.PricePerDay, err = s.rates.ConvertPtr(e.PricePerDay, currency); err != nil {
+			return ErrUnsupportedCurrency
+		}
+		if display.PricePerWeek, err = s.rates.ConvertPtr(e.PricePerWeek, currency); err != nil {
+			return ErrUnsupportedCurrency
+		}
+		e.DisplayPrices = display
+	}
+
+	return nil
+}
+
+func (s *EquipmentService) applyInventoryUpdate(equipment *model.Equipment, req *model.UpdateEquipmentRequest) error {
+	v := validator.New()
+
+	if req.InventoryMode != "" {
+		if !req.InventoryMode.Valid() {
+			v.AddError("inventory_mode", "must be pooled or serialized")
+		} else {
+			equipment.InventoryMode = req.InventoryMode
+		}
+	}
+
+	if req.Quantity != nil {
+		switch {
+		case equipment.InventoryMode == model.InventorySerialized:
+			v.AddError("quantity", "is derived from units for serialized equipment")
+		case *req.Quantity < 1:
+			v.AddError("quantity", "must be at least 1")
+		default:
+			equipment.Quantity = *req.Quantity
+		}
+	}
+
+	if equipment.InventoryMode == model.InventoryPooled && equipment.Quantity < 1 {
+		equipment.Quantity = 1
+	}
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+	return nil
+}
+
 func (s *EquipmentService) Delete(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) error {
 	equipment, err := s.equipmentRepo.GetByID(ctx, id)
 	if err != nil {
@@ -166,36 +533,163 @@ func (s *EquipmentService) AddPhoto(ctx context.Context, equipmentID uuid.UUID,
 		return nil, ErrNotOwner
 	}
 
-	if err := os.MkdirAll(s.uploadPath, 0755); err != nil {
+	url, filePath, err := saveUpload(s.uploadPath, file, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	photo := &model.EquipmentPhoto{
+		EquipmentID: equipmentID,
+		URL:         url,
+		IsPrimary:   isPrimary,
+	}
+
+	if err := s.equipmentRepo.AddPhoto(ctx, photo); err != nil {
+		os.Remove(filePath)
 		return nil, err
 	}
 
-	ext := filepath.Ext(filename)
-	newFilename := uuid.New().String() + ext
-	filePath := filepath.Join(s.uploadPath, newFilename)
+	return photo, nil
+}
+
+func (s *EquipmentService) ListUnits(ctx context.Context, equipmentID uuid.UUID, ownerID uuid.UUID) ([]model.EquipmentUnit, error) {
+	if _, err := s.getOwnedEquipment(ctx, equipmentID, ownerID); err != nil {
+		return nil, err
+	}
+	return s.equipmentRepo.ListUnits(ctx, equipmentID)
+}
 
-	dst, err := os.Create(filePath)
+func (s *EquipmentService) AddUnit(ctx context.Context, equipmentID uuid.UUID, ownerID uuid.UUID, req *model.CreateEquipmentUnitRequest) (*model.EquipmentUnit, error) {
+	equipment, err := s.getOwnedEquipment(ctx, equipmentID, ownerID)
 	if err != nil {
 		return nil, err
 	}
-	defer dst.Close()
 
-	if _, err := io.Copy(dst, file); err != nil {
+	if equipment.InventoryMode != model.InventorySerialized {
+		return nil, ErrUnitsNotTracked
+	}
+
+	if req.Status == "" {
+		req.Status = model.UnitAvailable
+	}
+
+	v := validator.New()
+	v.Required("serial_number", req.SerialNumber)
+	v.MaxLength("serial_number", req.SerialNumber, 100)
+	if !req.Status.Valid() {
+		v.AddError("status", "must be available, maintenance or retired")
+	}
+	if v.Errors().HasErrors() {
+		return nil, v.Errors()
+	}
+
+	unit := &model.EquipmentUnit{
+		EquipmentID:  equipmentID,
+		SerialNumber: req.SerialNumber,
+		Status:       req.Status,
+		Notes:        req.Notes,
+	}
+
+	if err := s.equipmentRepo.CreateUnit(ctx, unit); err != nil {
+		if errors.Is(err, repository.ErrUnitSerialExists) {
+			return nil, ErrUnitSerialExists
+		}
+		return nil, err
+	}
+
+	if _, err := s.equipmentRepo.SyncUnitQuantity(ctx, equipmentID); err != nil {
 		return nil, err
 	}
 
-	photo := &model.EquipmentPhoto{
-		EquipmentID: equipmentID,
-		URL:         "/uploads/" + newFilename,
-		IsPrimary:   isPrimary,
+	return unit, nil
+}
+
+func (s *EquipmentService) UpdateUnit(ctx context.Context, equipmentID, unitID uuid.UUID, ownerID uuid.UUID, req *model.UpdateEquipmentUnitRequest) (*model.EquipmentUnit, error) {
+	if _, err := s.getOwnedEquipment(ctx, equipmentID, ownerID); err != nil {
+		return nil, err
 	}
 
-	if err := s.equipmentRepo.AddPhoto(ctx, photo); err != nil {
-		os.Remove(filePath)
+	unit, err := s.equipmentRepo.GetUnit(ctx, equipmentID, unitID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUnitNotFound) {
+			return nil, ErrUnitNotFound
+		}
 		return nil, err
 	}
 
-	return photo, nil
+	v := validator.New()
+	if req.SerialNumber != "" {
+		v.MaxLength("serial_number", req.SerialNumber, 100)
+		unit.SerialNumber = req.SerialNumber
+	}
+	if req.Status != nil {
+		if !req.Status.Valid() {
+			v.AddError("status", "must be available, maintenance or retired")
+		}
+		unit.Status = *req.Status
+	}
+	if req.Notes != nil {
+		unit.Notes = *req.Notes
+	}
+	if v.Errors().HasErrors() {
+		return nil, v.Errors()
+	}
+
+	if err := s.equipmentRepo.UpdateUnit(ctx, unit); err != nil {
+		if errors.Is(err, repository.ErrUnitSerialExists) {
+			return nil, ErrUnitSerialExists
+		}
+		if errors.Is(err, repository.ErrUnitNotFound) {
+			return nil, ErrUnitNotFound
+		}
+		return nil, err
+	}
+
+	if _, err := s.equipmentRepo.SyncUnitQuantity(ctx, equipmentID); err != nil {
+		return nil, err
+	}
+
+	return unit, nil
+}
+
+func (s *EquipmentService) DeleteUnit(ctx context.Context, equipmentID, unitID uuid.UUID, ownerID uuid.UUID) error {
+	if _, err := s.getOwnedEquipment(ctx, equipmentID, ownerID); err != nil {
+		return err
+	}
+
+	reserved, err := s.equipmentRepo.IsUnitReserved(ctx, unitID)
+	if err != nil {
+		return err
+	}
+	if reserved {
+		return ErrUnitInUse
+	}
+
+	if err := s.equipmentRepo.DeleteUnit(ctx, equipmentID, unitID); err != nil {
+		if errors.Is(err, repository.ErrUnitNotFound) {
+			return ErrUnitNotFound
+		}
+		return err
+	}
+
+	_, err = s.equipmentRepo.SyncUnitQuantity(ctx, equipmentID)
+	return err
+}
+
+func (s *EquipmentService) getOwnedEquipment(ctx context.Context, equipmentID uuid.UUID, ownerID uuid.UUID) (*model.Equipment, error) {
+	equipment, err := s.equipmentRepo.GetByID(ctx, equipmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return nil, ErrEquipmentNotFound
+		}
+		return nil, err
+	}
+
+	if equipment.OwnerID != ownerID {
+		return nil, ErrNotOwner
+	}
+
+	return equipment, nil
 }
 
 func (s *EquipmentService) GetAvailability(ctx context.Context, equipmentID uuid.UUID, startDate, endDate time.Time) ([]model.EquipmentAvailability, error) {
@@ -210,10 +704,77 @@ func (s *EquipmentService) GetAvailability(ctx context.Context, equipmentID uuid
 	return s.equipmentRepo.GetAvailabilityCalendar(ctx, equipmentID, startDate, endDate)
 }
 
-func (s *EquipmentService) GetCategories(ctx context.Context) ([]string, error) {
-	return s.equipmentRepo.GetCategories(ctx)
+func (s *EquipmentService) GetCategories(ctx context.Context) ([]*model.Category, error) {
+	categories, err := s.categoryRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.BuildCategoryTree(categories), nil
 }
 
 func (s *EquipmentService) Search(ctx context.Context, query string, pag pagination.Params) ([]*model.Equipment, int64, error) {
 	return s.equipmentRepo.Search(ctx, query, pag)
 }
+
+func (s *EquipmentService) resolveCategory(ctx context.Context, id *uuid.UUID, name string) (*model.Category, error) {
+	var category *model.Category
+	var err error
+
+	if id != nil {
+		category, err = s.categoryRepo.GetByID(ctx, *id)
+	} else {
+		category, err = s.categoryRepo.GetBySlug(ctx, slug.Make(name))
+	}
+
+	if err != nil {
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			v := validator.New()
+			v.AddError("category_id", "must reference an existing category")
+			return nil, v.Errors()
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *EquipmentService) validateSpecifications(ctx context.Context, categoryID uuid.UUID, specs model.Specifications) (model.Specifications, error) {
+	schema, err := s.categoryRepo.GetAttributeSchema(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+	normalized := make(model.Specifications, len(specs))
+	known := make(map[string]bool, len(schema))
+
+	for _, attr := range schema {
+		known[attr.Key] = true
+		value, ok := specs[attr.Key]
+		if !ok || value == nil {
+			if attr.Required {
+				v.AddError("specifications."+attr.Key, "is required")
+			}
+			continue
+		}
+
+		normalizedValue, err := attr.Normalize(value)
+		if err != nil {
+			v.AddError("specifications."+attr.Key, err.Error())
+			continue
+		}
+		normalized[attr.Key] = normalizedValue
+	}
+
+	for key := range specs {
+		if !known[key] {
+			v.AddError("specifications."+key, "is not defined for this category")
+		}
+	}
+
+	if v.Errors().HasErrors() {
+		return nil, v.Errors()
+	}
+
+	return normalized, nil
+}
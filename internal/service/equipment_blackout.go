@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+var ErrBlackoutNotFound = errors.New("blackout not found")
+
+func (s *EquipmentService) ListBlackouts(ctx context.Context, equipmentID uuid.UUID, ownerID uuid.UUID) ([]model.Blackout, error) {
+	if _, err := s.getOwnedEquipment(ctx, equipmentID, ownerID); err != nil {
+		return nil, err
+	}
+	return s.equipmentRepo.ListBlackouts(ctx, equipmentID)
+}
+
+func (s *EquipmentService) CreateBlackout(ctx context.Context, equipmentID uuid.UUID, ownerID uuid.UUID, req *model.CreateBlackoutRequest) (*model.Blackout, error) {
+	if _, err := s.getOwnedEquipment(ctx, equipmentID, ownerID); err != nil {
+		return nil, err
+	}
+
+	blackout := &model.Blackout{
+		EquipmentID: equipmentID,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Reason:      req.Reason,
+		Notes:       req.Notes,
+		RecurWeekly: req.RecurWeekly,
+		RecurUntil:  req.RecurUntil,
+	}
+
+	if err := validateBlackout(blackout); err != nil {
+		return nil, err
+	}
+
+	if err := s.equipmentRepo.CreateBlackout(ctx, blackout); err != nil {
+		return nil, err
+	}
+
+	return blackout, nil
+}
+
+func (s *EquipmentService) UpdateBlackout(ctx context.Context, equipmentID, blackoutID uuid.UUID, ownerID uuid.UUID, req *model.UpdateBlackoutRequest) (*model.Blackout, error) {
+	if _, err := s.getOwnedEquipment(ctx, equipmentID, ownerID); err != nil {
+		return nil, err
+	}
+
+	blackout, err := s.equipmentRepo.GetBlackout(ctx, equipmentID, blackoutID)
+	if err != nil {
+		if errors.Is(err, repository.ErrBlackoutNotFound) {
+			return nil, ErrBlackoutNotFound
+		}
+		return nil, err
+	}
+
+	if req.StartDate != nil {
+		blackout.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		blackout.EndDate = *req.EndDate
+	}
+	if req.Reason != nil {
+		blackout.Reason = *req.Reason
+	}
+	if req.Notes != nil {
+		blackout.Notes = *req.Notes
+	}
+	if req.RecurWeekly != nil {
+		blackout.RecurWeekly = *req.RecurWeekly
+	}
+	if req.RecurUntil != nil {
+		blackout.RecurUntil = req.RecurUntil
+	}
+	if !blackout.RecurWeekly {
+		blackout.RecurUntil = nil
+	}
+
+	if err := validateBlackout(blackout); err != nil {
+		return nil, err
+	}
+
+	if err := s.equipmentRepo.UpdateBlackout(ctx, blackout); err != nil {
+		if errors.Is(err, repository.ErrBlackoutNotFound) {
+			return nil, ErrBlackoutNotFound
+		}
+		return nil, err
+	}
+
+	return blackout, nil
+}
+
+func (s *EquipmentService) DeleteBlackout(ctx context.Context, equipmentID, blackoutID uuid.UUID, ownerID uuid.UUID) error {
+	if _, err := s.getOwnedEquipment(ctx, equipmentID, ownerID); err != nil {
+		return err
+	}
+
+	if err := s.equipmentRepo.DeleteBlackout(ctx, equipmentID, blackoutID); err != nil {
+		if errors.Is(err, repository.ErrBlackoutNotFound) {
+			return ErrBlackoutNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func validateBlackout(b *model.Blackout) error {
+	v := validator.New()
+
+	if b.StartDate.IsZero() {
+		v.AddError("start_date", "is required")
+	}
+	if b.EndDate.IsZero() {
+		v.AddError("end_date", "is required")
+	}
+	if !b.StartDate.IsZero() && !b.EndDate.IsZero() && !b.EndDate.After(b.StartDate) {
+		v.AddError("end_date", "must be after start_date")
+	}
+	if !b.Reason.Valid() {
+		v.AddError("reason", "must be maintenance, personal_use, inspection or other")
+	}
+
+	if b.RecurWeekly {
+		if b.EndDate.Sub(b.StartDate) >= 7*24*time.Hour {
+			v.AddError("end_date", "recurring blackouts must be shorter than a week")
+		}
+		if b.RecurUntil != nil && !b.RecurUntil.After(b.StartDate) {
+			v.AddError("recur_until", "must be after start_date")
+		}
+	} else if b.RecurUntil != nil {
+		v.AddError("recur_until", "requires recur_weekly")
+	}
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/pricing"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+var ErrPricingRuleNotFound = errors.New("pricing rule not found")
+
+func (s *EquipmentService) ListPricingRules(ctx context.Context, equipmentID uuid.UUID, ownerID uuid.UUID) ([]model.PricingRule, error) {
+	if _, err := s.getOwnedEquipment(ctx, equipmentID, ownerID); err != nil {
+		return nil, err
+	}
+	return s.equipmentRepo.ListPricingRules(ctx, equipmentID, false)
+}
+
+func (s *EquipmentService) CreatePricingRule(ctx context.Context, equipmentID uuid.UUID, ownerID uuid.UUID, req *model.CreatePricingRuleRequest) (*model.PricingRule, error) {
+	equipment, err := s.getOwnedEquipment(ctx, equipmentID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+	amount := parsePrice(v, "amount", req.Amount, equipment.Currency)
+	if v.Errors().HasErrors() {
+		return nil, v.Errors()
+	}
+
+	rule := &model.PricingRule{
+		EquipmentID: equipmentID,
+		Type:        req.Type,
+		Name:        req.Name,
+		Percent:     req.Percent,
+		Amount:      amount,
+		MinDays:     req.MinDays,
+		StartsOn:    req.StartsOn,
+		EndsOn:      req.EndsOn,
+		Active:      true,
+	}
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+
+	if err := validatePricingRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.equipmentRepo.CreatePricingRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *EquipmentService) UpdatePricingRule(ctx context.Context, equipmentID, ruleID uuid.UUID, ownerID uuid.UUID, req *model.UpdatePricingRuleRequest) (*model.PricingRule, error) {
+	equipment, err := s.getOwnedEquipment(ctx, equipmentID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.equipmentRepo.GetPricingRule(ctx, equipmentID, ruleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPricingRuleNotFound) {
+			return nil, ErrPricingRuleNotFound
+		}
+		return nil, err
+	}
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Percent != nil {
+		rule.Percent = req.Percent
+	}
+	if req.Amount != nil {
+		v := validator.New()
+		rule.Amount = parsePrice(v, "amount", req.Amount, equipment.Currency)
+		if v.Errors().HasErrors() {
+			return nil, v.Errors()
+		}
+	}
+	if req.MinDays != nil {
+		rule.MinDays = req.MinDays
+	}
+	if req.StartsOn != nil {
+		rule.StartsOn = req.StartsOn
+	}
+	if req.EndsOn != nil {
+		rule.EndsOn = req.EndsOn
+	}
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+
+	if err := validatePricingRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.equipmentRepo.UpdatePricingRule(ctx, rule); err != nil {
+		if errors.Is(err, repository.ErrPricingRuleNotFound) {
+			return nil, ErrPricingRuleNotFound
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *EquipmentService) DeletePricingRule(ctx context.Context, equipmentID, ruleID uuid.UUID, ownerID uuid.UUID) error {
+	if _, err := s.getOwnedEquipment(ctx, equipmentID, ownerID); err != nil {
+		return err
+	}
+
+	if err := s.equipmentRepo.DeletePricingRule(ctx, equipmentID, ruleID); err != nil {
+		if errors.Is(err, repository.ErrPricingRuleNotFound) {
+			return ErrPricingRuleNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func validatePricingRule(r *model.PricingRule) error {
+	v := validator.New()
+
+	v.MaxLength("name", r.Name, 255)
+
+	switch r.Type {
+	case pricing.RuleWeekendSurcharge:
+		if r.Percent == nil || *r.Percent <= -100 {
+			v.AddError("percent", "is required and must be greater than -100")
+		}
+	case pricing.RuleSeasonal:
+		if r.Percent == nil || *r.Percent <= -100 {
+			v.AddError("percent", "is required and must be greater than -100")
+		}
+		if r.StartsOn == nil {
+			v.AddError("starts_on", "is required")
+		}
+		if r.EndsOn == nil {
+			v.AddError("ends_on", "is required")
+		}
+		if r.StartsOn != nil && r.EndsOn != nil && r.EndsOn.Before(*r.StartsOn) {
+			v.AddError("ends_on", "must not be before starts_on")
+		}
+	case pricing.RuleLongRentalDiscount:
+		if r.Percent == nil || *r.Percent <= 0 || *r.Percent > 100 {
+			v.AddError("percent", "is required and must be between 0 and 100")
+		}
+		if r.MinDays == nil || *r.MinDays < 1 {
+			v.AddError("min_days", "is required and must be at least 1")
+		}
+	case pricing.RuleMinimumCharge:
+		if r.Amount == nil || r.Amount.Amount <= 0 {
+			v.AddError("amount", "is required and must be positive")
+		}
+	case pricing.RuleLateFee:
+		if r.Percent == nil || *r.Percent < 0 {
+			v.AddError("percent", "is required and must not be negative")
+		}
+		if r.Amount != nil && r.Amount.Amount < 0 {
+			v.AddError("amount", "must not be negative")
+		}
+	default:
+		v.AddError("type", "must be weekend_surcharge, seasonal, long_rental_discount, minimum_charge or late_fee")
+	}
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/pricing"
+)
+
+func TestValidatePricingRule(t *testing.T) {
+	percent := func(p float64) *float64 { return &p }
+	days := func(d int) *int { return &d }
+	date := func(s string) *time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return &d
+	}
+	amount := func(s string) *money.Money {
+		m := money.MustParse(s, "EUR")
+		return &m
+	}
+
+	tests := []struct {
+		name      string
+		rule      model.PricingRule
+		wantField string
+	}{
+		{"weekend surcharge", model.PricingRule{Type: pricing.RuleWeekendSurcharge, Percent: percent(20)}, ""},
+		{"weekend discount", model.PricingRule{Type: pricing.RuleWeekendSurcharge, Percent: percent(-50)}, ""},
+		{"weekend free", model.PricingRule{Type: pricing.RuleWeekendSurcharge, Percent: percent(-100)}, "percent"},
+		{"weekend without percent", model.PricingRule{Type: pricing.RuleWeekendSurcharge}, "percent"},
+		{"season", model.PricingRule{Type: pricing.RuleSeasonal, Percent: percent(15), StartsOn: date("2024-07-01"), EndsOn: date("2024-08-31")}, ""},
+		{"one-day season", model.PricingRule{Type: pricing.RuleSeasonal, Percent: percent(15), StartsOn: date("2024-12-25"), EndsOn: date("2024-12-25")}, ""},
+		{"season ending before it starts", model.PricingRule{Type: pricing.RuleSeasonal, Percent: percent(15), StartsOn: date("2024-08-31"), EndsOn: date("2024-07-01")}, "ends_on"},
+		{"season without dates", model.PricingRule{Type: pricing.RuleSeasonal, Percent: percent(15)}, "starts_on"},
+		{"long rental discount", model.PricingRule{Type: pricing.RuleLongRentalDiscount, Percent: percent(10), MinDays: days(7)}, ""},
+		{"long rental surcharge", model.PricingRule{Type: pricing.RuleLongRentalDiscount, Percent: percent(-10), MinDays: days(7)}, "percent"},
+		{"discount over 100%", model.PricingRule{Type: pricing.RuleLongRentalDiscount, Percent: percent(101), MinDays: days(7)}, "percent"},
+		{"discount without min days", model.PricingRule{Type: pricing.RuleLongRentalDiscount, Percent: percent(10), MinDays: days(0)}, "min_days"},
+		{"minimum charge", model.PricingRule{Type: pricing.RuleMinimumCharge, Amount: amount("25.00")}, ""},
+		{"zero minimum charge", model.PricingRule{Type: pricing.RuleMinimumCharge, Amount: amount("0")}, "amount"},
+		{"late fee", model.PricingRule{Type: pricing.RuleLateFee, Percent: percent(150), Amount: amount("10.00")}, ""},
+		{"negative late fee", model.PricingRule{Type: pricing.RuleLateFee, Percent: percent(-5)}, "percent"},
+		{"unknown type", model.PricingRule{Type: "happy_hour", Percent: percent(10)}, "type"},
+		{"long name", model.PricingRule{Type: pricing.RuleWeekendSurcharge, Percent: percent(20), Name: strings.Repeat("a", 256)}, "name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePricingRule(&tt.rule)
+
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var validationErrors validator.ValidationErrors
+			if !errors.As(err, &validationErrors) {
+				t.Fatalf("expected validation errors, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantField) {
+				t.Errorf("expected an error on %s, got %v", tt.wantField, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/timeslot"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+)
+
+const (
+	maxScheduleWindow = 31 * 24 * time.Hour
+	defaultSlotStep   = 30 * time.Minute
+	minSlotStep       = 5 * time.Minute
+)
+
+func (s *EquipmentService) GetOpeningHours(ctx context.Context, equipmentID uuid.UUID) ([]model.OpeningHours, error) {
+	if _, err := s.GetByID(ctx, equipmentID); err != nil {
+		return nil, err
+	}
+	return s.equipmentRepo.GetOpeningHours(ctx, equipmentID)
+}
+
+func (s *EquipmentService) SetOpeningHours(ctx context.Context, equipmentID uuid.UUID, ownerID uuid.UUID, req *model.SetOpeningHoursRequest) ([]model.OpeningHours, error) {
+	if _, err := s.getOwnedEquipment(ctx, equipmentID, ownerID); err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+	for i, h := range req.Hours {
+		if _, err := h.DailyHours(); err != nil {
+			v.AddError(fmt.Sprintf("hours[%d]", i), err.Error())
+		}
+	}
+	if v.Errors().HasErrors() {
+		return nil, v.Errors()
+	}
+
+	if err := s.equipmentRepo.ReplaceOpeningHours(ctx, equipmentID, req.Hours); err != nil {
+		return nil, err
+	}
+
+	return s.equipmentRepo.GetOpeningHours(ctx, equipmentID)
+}
+
+func (s *EquipmentService) GetSchedule(ctx context.Context, equipmentID uuid.UUID, query model.ScheduleQuery) (*model.EquipmentSchedule, error) {
+	equipment, err := s.GetByID(ctx, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, window, err := scheduleWindow(equipment, query, 7*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	busy, err := s.busyIntervals(ctx, equipment, window, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	intervals := make([]timeslot.Interval, len(busy))
+	for i, b := range busy {
+		intervals[i] = b.Interval
+		busy[i].Start = b.Start.In(loc)
+		busy[i].End = b.End.In(loc)
+	}
+
+	free := inLocation(timeslot.Subtract(window, intervals), loc)
+	if free == nil {
+		free = []timeslot.Interval{}
+	}
+
+	return &model.EquipmentSchedule{
+		EquipmentID: equipment.ID,
+		TimeZone:    loc.String(),
+		Start:       window.Start.In(loc),
+		End:         window.End.In(loc),
+		Free:        free,
+		Busy:        busy,
+	}, nil
+}
+
+func (s *EquipmentService) GetSlots(ctx context.Context, equipmentID uuid.UUID, query model.ScheduleQuery) (*model.EquipmentSlots, error) {
+	equipment, err := s.GetByID(ctx, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Step == 0 {
+		query.Step = defaultSlotStep
+	}
+
+	v := validator.New()
+	if query.Duration <= 0 {
+		v.AddError("duration", "is required and must be positive")
+	}
+	if query.Step < minSlotStep {
+		v.AddError("step", "must be at least 5m")
+	}
+	if v.Errors().HasErrors() {
+		return nil, v.Errors()
+	}
+
+	loc, window, err := scheduleWindow(equipment, query, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	busy, err := s.busyIntervals(ctx, equipment, window, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	intervals := make([]timeslot.Interval, len(busy))
+	for i, b := range busy {
+		intervals[i] = b.Interval
+	}
+
+	free := timeslot.Subtract(window, intervals)
+	slots := inLocation(timeslot.Slots(free, query.Duration, query.Step), loc)
+	if slots == nil {
+		slots = []timeslot.Interval{}
+	}
+
+	return &model.EquipmentSlots{
+		EquipmentID:     equipment.ID,
+		TimeZone:        loc.String(),
+		DurationMinutes: int(query.Duration / time.Minute),
+		Slots:           slots,
+	}, nil
+}
+
+// busyIntervals collects every reason the equipment cannot take one more
+// unit inside window: fully booked periods, blackouts and closed hours.
+func (s *EquipmentService) busyIntervals(ctx context.Context, equipment *model.Equipment, window timeslot.Interval, loc *time.Location) ([]model.BusyInterval, error) {
+	busy := []model.BusyInterval{}
+
+	reserved, err := s.equipmentRepo.ReservedQuantities(ctx, equipment.ID, window.Start, window.End)
+	if err != nil {
+		return nil, err
+	}
+	loads := make([]timeslot.Load, 0, len(reserved))
+	for _, r := range reserved {
+		if interval, ok := (timeslot.Interval{Start: r.StartDate, End: r.EndDate}).Clip(window); ok {
+			loads = append(loads, timeslot.Load{Interval: interval, Quantity: r.Quantity})
+		}
+	}
+	saturated := []timeslot.Interval{window}
+	if equipment.Quantity > 0 {
+		saturated = timeslot.Saturated(loads, equipment.Quantity)
+	}
+	for _, interval := range saturated {
+		busy = append(busy, model.BusyInterval{Interval: interval, Reason: model.BusyReserved})
+	}
+
+	blackouts, err := s.equipmentRepo.BlackoutsBetween(ctx, equipment.ID, window.Start, window.End)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blackouts {
+		for _, interval := range b.Occurrences(window.Start, window.End) {
+			busy = append(busy, model.BusyInterval{Interval: interval, Reason: model.BusyBlackout})
+		}
+	}
+
+	openingHours, err := s.equipmentRepo.GetOpeningHours(ctx, equipment.ID)
+	if err != nil {
+		return nil, err
+	}
+	daily := make([]timeslot.DailyHours, 0, len(openingHours))
+	for _, h := range openingHours {
+		d, err := h.DailyHours()
+		if err != nil {
+			return nil, err
+		}
+		daily = append(daily, d)
+	}
+	for _, interval := range timeslot.Subtract(window, timeslot.OpenIntervals(daily, window, loc)) {
+		busy = append(busy, model.BusyInterval{Interval: interval, Reason: model.BusyClosed})
+	}
+
+	sort.SliceStable(busy, func(i, j int) bool { return busy[i].Start.Before(busy[j].Start) })
+	return busy, nil
+}
+
+// scheduleWindow resolves the query bounds in the equipment's time zone.
+// Dates without a time refer to local midnight, and a date-only end is
+// inclusive of that whole day.
+func scheduleWindow(equipment *model.Equipment, query model.ScheduleQuery, defaultLength time.Duration) (*time.Location, timeslot.Interval, error) {
+	loc, err := time.LoadLocation(equipment.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	v := validator.New()
+
+	now := time.Now().In(loc)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if query.Start != "" {
+		if start, err = parseScheduleTime(query.Start, loc, false); err != nil {
+			v.AddError("start", "must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+		}
+	}
+
+	end := start.Add(defaultLength)
+	if query.End != "" {
+		if end, err = parseScheduleTime(query.End, loc, true); err != nil {
+			v.AddError("end", "must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+		}
+	}
+
+	if !v.Errors().HasErrors() {
+		if !end.After(start) {
+			v.AddError("end", "must be after start")
+		} else if end.Sub(start) > maxScheduleWindow {
+			v.AddError("end", "window cannot exceed 31 days")
+		}
+	}
+
+	if v.Errors().HasErrors() {
+		return nil, timeslot.Interval{}, v.Errors()
+	}
+
+	return loc, timeslot.Interval{Start: start, End: end}, nil
+}
+
+func parseScheduleTime(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func inLocation(intervals []timeslot.Interval, loc *time.Location) []timeslot.Interval {
+	for i := range intervals {
+		intervals[i].Start = intervals[i].Start.In(loc)
+		intervals[i].End = intervals[i].End.In(loc)
+	}
+	return intervals
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+)
+
+func TestTypeSpecFilters(t *testing.T) {
+	schema := []model.CategoryAttribute{
+		{Key: "operating_weight", Type: model.AttributeNumber},
+		{Key: "fuel", Type: model.AttributeEnum, Options: []string{"diesel", "electric"}},
+		{Key: "model_code", Type: model.AttributeText},
+		{Key: "cab", Type: model.AttributeBoolean},
+		// Another category defines the same key with another type.
+		{Key: "size", Type: model.AttributeNumber},
+		{Key: "size", Type: model.AttributeEnum},
+		{Key: "fuel", Type: model.AttributeEnum},
+	}
+
+	tests := []struct {
+		name     string
+		filter   model.SpecFilter
+		wantType model.AttributeType
+		wantErr  bool
+	}{
+		{"number", model.SpecFilter{Key: "operating_weight", Operator: model.SpecLte, Value: "5000"}, model.AttributeNumber, false},
+		{"numeric-looking text", model.SpecFilter{Key: "model_code", Operator: model.SpecEq, Value: "320"}, model.AttributeText, false},
+		{"enum defined twice alike", model.SpecFilter{Key: "fuel", Operator: model.SpecEq, Value: "diesel"}, model.AttributeEnum, false},
+		{"boolean", model.SpecFilter{Key: "cab", Operator: model.SpecNe, Value: "false"}, model.AttributeBoolean, false},
+		{"unknown key", model.SpecFilter{Key: "colour", Operator: model.SpecEq, Value: "red"}, "", true},
+		{"conflicting types", model.SpecFilter{Key: "size", Operator: model.SpecEq, Value: "3"}, "", true},
+		{"range on text", model.SpecFilter{Key: "model_code", Operator: model.SpecGt, Value: "300"}, "", true},
+		{"number from text", model.SpecFilter{Key: "operating_weight", Operator: model.SpecEq, Value: "heavy"}, "", true},
+		{"boolean from text", model.SpecFilter{Key: "cab", Operator: model.SpecEq, Value: "closed"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specs := []model.SpecFilter{tt.filter}
+			err := typeSpecFilters(specs, schema)
+
+			if tt.wantErr {
+				var validationErrors validator.ValidationErrors
+				if !errors.As(err, &validationErrors) || validationErrors[0].Field != "spec."+tt.filter.Key {
+					t.Errorf("expected a spec.%s validation error, got %v", tt.filter.Key, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if specs[0].Type != tt.wantType {
+				t.Errorf("expected type %s, got %s", tt.wantType, specs[0].Type)
+			}
+		})
+	}
+}
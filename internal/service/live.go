@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
+	"github.com/abneribeiro/goapi/internal/realtime"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+// liveEventTimeout bounds loading one announced domain event.
+const liveEventTimeout = 5 * time.Second
+
+// LiveService turns domain events into updates on the WebSocket topics
+// clients subscribe to. It listens on the domain event channel directly, so
+// updates go out as soon as a change commits on any replica.
+type LiveService struct {
+	reservationRepo *repository.ReservationRepository
+	equipmentRepo   *repository.EquipmentRepository
+	outboxRepo      *repository.OutboxRepository
+	topics          *realtime.Topics
+}
+
+func NewLiveService(
+	reservationRepo *repository.ReservationRepository,
+	equipmentRepo *repository.EquipmentRepository,
+	outboxRepo *repository.OutboxRepository,
+	topics *realtime.Topics,
+) *LiveService {
+	return &LiveService{
+		reservationRepo: reservationRepo,
+		equipmentRepo:   equipmentRepo,
+		outboxRepo:      outboxRepo,
+		topics:          topics,
+	}
+}
+
+func (s *LiveService) NewClient() *realtime.Client {
+	return s.topics.NewClient()
+}
+
+// Subscribe adds topic to the client's subscriptions if the user may see
+// it: a reservation's renter and equipment owner may follow it, and any
+// user may follow a listing's availability.
+func (s *LiveService) Subscribe(ctx context.Context, client *realtime.Client, userID uuid.UUID, topic string) error {
+	kind, id, err := model.ParseTopic(topic)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case model.TopicReservation:
+		reservation, err := s.reservationRepo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrReservationNotFound) {
+				return ErrReservationNotFound
+			}
+			return err
+		}
+		if reservation.RenterID != userID && reservation.Equipment.OwnerID != userID {
+			return ErrNotAuthorized
+		}
+
+	case model.TopicAvailability:
+		if _, err := s.equipmentRepo.GetByID(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrEquipmentNotFound) {
+				return ErrEquipmentNotFound
+			}
+			return err
+		}
+	}
+
+	return s.topics.Subscribe(client, topic)
+}
+
+func (s *LiveService) Unsubscribe(client *realtime.Client, topic string) {
+	s.topics.Unsubscribe(client, topic)
+}
+
+func (s *LiveService) Name() string {
+	return repository.DomainEventChannel
+}
+
+// Receive publishes the updates for an announced domain event.
+func (s *LiveService) Receive(payload string) {
+	sequence, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), liveEventTimeout)
+	defer cancel()
+
+	event, err := s.outboxRepo.GetBySequence(ctx, sequence)
+	if err != nil {
+		logger.Error("failed to load domain event for live updates", logger.WithFields(map[string]interface{}{
+			"sequence": sequence,
+			"error":    err.Error(),
+		}))
+		return
+	}
+
+	if err := s.Publish(event); err != nil {
+		logger.Error("failed to publish live update", logger.WithFields(map[string]interface{}{
+			"event_id": event.ID.String(),
+			"error":    err.Error(),
+		}))
+	}
+}
+
+// Reconnected asks every client to reload, since events announced while
+// the listener was disconnected are lost.
+func (s *LiveService) Reconnected() {
+	msg, err := json.Marshal(model.LiveMessage{Type: model.LiveResync})
+	if err != nil {
+		return
+	}
+	s.topics.PublishAll(msg)
+}
+
+// Publish sends a reservation event to the reservation's topic, and the
+// change in held stock to its equipment's availability topic. Blackout
+// changes go to the equipment's availability topic too.
+func (s *LiveService) Publish(event *model.DomainEvent) error {
+	switch event.Type {
+	case model.EventBlackoutCreated, model.EventBlackoutUpdated, model.EventBlackoutDeleted:
+		return s.publishBlackout(event)
+	}
+
+	if event.AggregateType != "reservation" {
+		return nil
+	}
+
+	var data model.ReservationEventData
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return err
+	}
+
+	if err := s.publish(model.ReservationTopic(data.ID), event, data); err != nil {
+		return err
+	}
+
+	return s.publish(model.AvailabilityTopic(data.EquipmentID), event, model.AvailabilityChange{
+		ReservationID: data.ID,
+		Status:        data.Status,
+		StartDate:     data.StartDate,
+		EndDate:       data.EndDate,
+		Quantity:      data.Quantity,
+		HoldsStock:    data.Status.HoldsStock(),
+	})
+}
+
+func (s *LiveService) publishBlackout(event *model.DomainEvent) error {
+	var data model.BlackoutEventData
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return err
+	}
+
+	return s.publish(model.AvailabilityTopic(data.EquipmentID), event, model.BlackoutChange{
+		BlackoutID:  data.ID,
+		StartDate:   data.StartDate,
+		EndDate:     data.EndDate,
+		RecurWeekly: data.RecurWeekly,
+		RecurUntil:  data.RecurUntil,
+		Blocks:      event.Type != model.EventBlackoutDeleted,
+	})
+}
+
+func (s *LiveService) publish(topic string, event *model.DomainEvent, data interface{}) error {
+	msg, err := json.Marshal(model.LiveMessage{
+		Type:       model.LiveUpdate,
+		Topic:      topic,
+		Event:      event.Type,
+		Sequence:   event.Sequence,
+		Data:       data,
+		OccurredAt: &event.OccurredAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.topics.Publish(topic, msg)
+	return nil
+}
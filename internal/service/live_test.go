@@ -0,0 +1,84 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/realtime"
+)
+
+func TestLiveService_Publish_Blackouts(t *testing.T) {
+	equipmentID := uuid.New()
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		eventType  model.DomainEventType
+		wantBlocks bool
+	}{
+		{model.EventBlackoutCreated, true},
+		{model.EventBlackoutUpdated, true},
+		{model.EventBlackoutDeleted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.eventType), func(t *testing.T) {
+			topics := realtime.NewTopics(8, 10)
+			client := topics.NewClient()
+			defer client.Close()
+
+			topic := model.AvailabilityTopic(equipmentID)
+			if err := topics.Subscribe(client, topic); err != nil {
+				t.Fatalf("subscribe: %v", err)
+			}
+
+			data, _ := json.Marshal(model.BlackoutEventData{
+				ID:          uuid.New(),
+				EquipmentID: equipmentID,
+				StartDate:   start,
+				EndDate:     start.Add(4 * time.Hour),
+				RecurWeekly: true,
+			})
+			event := &model.DomainEvent{
+				Sequence:      7,
+				Type:          tt.eventType,
+				AggregateType: tt.eventType.Aggregate(),
+				AggregateID:   equipmentID,
+				Data:          data,
+			}
+
+			s := NewLiveService(nil, nil, nil, topics)
+			if err := s.Publish(event); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+
+			var msg struct {
+				Type  model.LiveMessageType `json:"type"`
+				Topic string                `json:"topic"`
+				Event model.DomainEventType `json:"event"`
+				Data  model.BlackoutChange  `json:"data"`
+			}
+			select {
+			case raw := <-client.Send:
+				if err := json.Unmarshal(raw, &msg); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+			default:
+				t.Fatal("expected an update on the availability topic")
+			}
+
+			if msg.Type != model.LiveUpdate || msg.Topic != topic || msg.Event != tt.eventType {
+				t.Errorf("unexpected message %+v", msg)
+			}
+			if msg.Data.Blocks != tt.wantBlocks {
+				t.Errorf("expected blocks %v, got %v", tt.wantBlocks, msg.Data.Blocks)
+			}
+			if !msg.Data.StartDate.Equal(start) || !msg.Data.RecurWeekly {
+				t.Errorf("unexpected blackout %+v", msg.Data)
+			}
+		})
+	}
+}
@@ -3,31 +3,96 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/abneribeiro/goapi/internal/delivery"
 	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
 	"github.com/abneribeiro/goapi/internal/pkg/pagination"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/queue"
+	"github.com/abneribeiro/goapi/internal/realtime"
 	"github.com/abneribeiro/goapi/internal/repository"
 )
 
 var ErrNotificationNotFound = errors.New("notification not found")
 
+// JobCreateNotification stores a notification queued by another service.
+const JobCreateNotification = "notification.create"
+
+// streamBatchSize caps how many notifications a stream reads at once; it
+// reads again until it has caught up.
+const streamBatchSize = 100
+
 type NotificationService struct {
 	notificationRepo *repository.NotificationRepository
+	userRepo         *repository.UserRepository
+	pushRepo         *repository.PushSubscriptionRepository
+	deliveryRepo     *repository.NotificationDeliveryRepository
+	preferenceRepo   *repository.NotificationPreferenceRepository
+	jobQueue         *queue.Queue
+	sender           *delivery.Sender
+	templates        *NotificationTemplateService
+	hub              *realtime.Hub
 }
 
-func NewNotificationService(notificationRepo *repository.NotificationRepository) *NotificationService {
+func NewNotificationService(
+	notificationRepo *repository.NotificationRepository,
+	userRepo *repository.UserRepository,
+	pushRepo *repository.PushSubscriptionRepository,
+	deliveryRepo *repository.NotificationDeliveryRepository,
+	preferenceRepo *repository.NotificationPreferenceRepository,
+	jobQueue *queue.Queue,
+	sender *delivery.Sender,
+	templates *NotificationTemplateService,
+	hub *realtime.Hub,
+) *NotificationService {
 	return &NotificationService{
 		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		pushRepo:         pushRepo,
+		deliveryRepo:     deliveryRepo,
+		preferenceRepo:   preferenceRepo,
+		jobQueue:         jobQueue,
+		sender:           sender,
+		templates:        templates,
+		hub:              hub,
 	}
 }
 
-func (s *NotificationService) List(ctx context.Context, userID uuid.UUID, pag pagination.Params) ([]*model.Notification, int64, error) {
-	filter := &model.NotificationFilter{
-		UserID: &userID,
+// RegisterJobs sets the queue handlers for notification jobs.
+func (s *NotificationService) RegisterJobs(q *queue.Queue) {
+	queue.Handle(q, JobCreateNotification, func(ctx context.Context, notification model.Notification) error {
+		return s.create(ctx, &notification)
+	})
+	queue.Handle(q, JobDeliverNotification, func(ctx context.Context, payload deliverNotificationPayload) error {
+		return s.deliver(ctx, payload.NotificationID, payload.Channel)
+	})
+	queue.Handle(q, JobSendDigest, func(ctx context.Context, payload sendDigestPayload) error {
+		return s.sendDigest(ctx, payload.UserID, payload.Channel, payload.At)
+	})
+}
+
+func (s *NotificationService) List(ctx context.Context, userID uuid.UUID, filter *model.NotificationFilter, pag pagination.Params) ([]*model.Notification, int64, error) {
+	if filter == nil {
+		filter = &model.NotificationFilter{}
+	}
+	filter.UserID = &userID
+
+	notifications, total, err := s.notificationRepo.List(ctx, filter, pag)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.localizeFor(ctx, userID, notifications); err != nil {
+		return nil, 0, err
 	}
-	return s.notificationRepo.List(ctx, filter, pag)
+
+	return notifications, total, nil
 }
 
 func (s *NotificationService) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
@@ -54,6 +119,81 @@ func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID uuid.UUI
 	return s.notificationRepo.MarkAllAsRead(ctx, userID)
 }
 
+// BulkMarkAsRead marks the user's notifications selected by req as read.
+func (s *NotificationService) BulkMarkAsRead(ctx context.Context, userID uuid.UUID, req *model.BulkNotificationRequest) (*model.BulkNotificationResult, error) {
+	filter, err := bulkFilter(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := s.notificationRepo.MarkAsReadMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.BulkNotificationResult{Affected: affected}, nil
+}
+
+// BulkDelete deletes the user's notifications selected by req.
+func (s *NotificationService) BulkDelete(ctx context.Context, userID uuid.UUID, req *model.BulkNotificationRequest) (*model.BulkNotificationResult, error) {
+	filter, err := bulkFilter(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := s.notificationRepo.DeleteMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.BulkNotificationResult{Affected: affected}, nil
+}
+
+func bulkFilter(userID uuid.UUID, req *model.BulkNotificationRequest) (*model.NotificationFilter, error) {
+	v := validator.New()
+
+	if len(req.IDs) == 0 && req.Read == nil && req.Type == nil && req.Before == nil {
+		v.AddError("ids", "select notifications by ids, read, type or before")
+	}
+	if len(req.IDs) > model.MaxBulkNotificationIDs {
+		v.AddError("ids", fmt.Sprintf("must contain at most %d ids", model.MaxBulkNotificationIDs))
+	}
+	if req.Type != nil && !slices.Contains(model.NotificationTypes(), *req.Type) {
+		v.AddError("type", "unknown notification type "+string(*req.Type))
+	}
+
+	if v.Errors().HasErrors() {
+		return nil, v.Errors()
+	}
+
+	return &model.NotificationFilter{
+		UserID: &userID,
+		IDs:    req.IDs,
+		Read:   req.Read,
+		Type:   req.Type,
+		Before: req.Before,
+	}, nil
+}
+
+// purgeBatchSize bounds how many notifications one purge statement deletes,
+// keeping each transaction short.
+const purgeBatchSize = 1000
+
+// PurgeRead deletes read notifications, and ones never shown in the app,
+// older than retention. It returns how many it deleted.
+func (s *NotificationService) PurgeRead(ctx context.Context, now time.Time, retention time.Duration) (int, error) {
+	before := now.Add(-retention)
+
+	purged := 0
+	for {
+		n, err := s.notificationRepo.Purge(ctx, before, purgeBatchSize)
+		purged += int(n)
+		if err != nil || n < purgeBatchSize {
+			return purged, err
+		}
+	}
+}
+
 func (s *NotificationService) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
 	notification, err := s.notificationRepo.GetByID(ctx, id)
 	if err != nil {
@@ -69,3 +209,67 @@ func (s *NotificationService) Delete(ctx context.Context, id uuid.UUID, userID u
 
 	return s.notificationRepo.Delete(ctx, id)
 }
+
+// Watch returns a subscription woken whenever the user's notifications
+// change on any replica. The caller must close it.
+func (s *NotificationService) Watch(userID uuid.UUID) *realtime.Subscription {
+	return s.hub.Subscribe(userID)
+}
+
+// ListSince returns the next batch of the user's notifications created
+// after the given sequence, oldest first.
+func (s *NotificationService) ListSince(ctx context.Context, userID uuid.UUID, after int64) ([]*model.Notification, error) {
+	notifications, err := s.notificationRepo.ListSince(ctx, userID, after, streamBatchSize)
+	if err != nil || len(notifications) == 0 {
+		return notifications, err
+	}
+
+	if err := s.localizeFor(ctx, userID, notifications); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (s *NotificationService) LatestSequence(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.notificationRepo.LatestSequence(ctx, userID)
+}
+
+func (s *NotificationService) ListTemplates(ctx context.Context, filter *model.NotificationTemplateFilter) ([]*model.NotificationTemplate, error) {
+	return s.templates.List(ctx, filter)
+}
+
+func (s *NotificationService) CreateTemplate(ctx context.Context, createdBy uuid.UUID, req *model.CreateNotificationTemplateRequest) (*model.NotificationTemplate, error) {
+	return s.templates.Create(ctx, createdBy, req)
+}
+
+// localizeFor renders notifications in the user's locale and time zone.
+func (s *NotificationService) localizeFor(ctx context.Context, userID uuid.UUID, notifications []*model.Notification) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	prefs, err := s.preferenceRepo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	s.localize(ctx, user.Locale, prefs.Location(), notifications...)
+	return nil
+}
+
+// localize renders notifications with the current templates. One that
+// cannot be rendered keeps the copy it was stored with.
+func (s *NotificationService) localize(ctx context.Context, locale string, loc *time.Location, notifications ...*model.Notification) {
+	for _, notification := range notifications {
+		if err := s.templates.Render(ctx, notification, locale, loc); err != nil {
+			logger.Error("failed to render notification", logger.WithFields(map[string]interface{}{
+				"notification_id": notification.ID.String(),
+				"type":            string(notification.Type),
+				"locale":          locale,
+				"error":           err.Error(),
+			}))
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/delivery"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/queue"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+var ErrPushNotConfigured = errors.New("web push is not configured")
+
+const (
+	// JobDeliverNotification sends a stored notification on one channel.
+	JobDeliverNotification = "notification.deliver"
+	// JobSendDigest sends a user's deliveries on a channel that were held
+	// for a digest or quiet hours.
+	JobSendDigest = "notification.digest"
+)
+
+type deliverNotificationPayload struct {
+	NotificationID uuid.UUID             `json:"notification_id"`
+	Channel        model.DeliveryChannel `json:"channel"`
+}
+
+type sendDigestPayload struct {
+	UserID  uuid.UUID             `json:"user_id"`
+	Channel model.DeliveryChannel `json:"channel"`
+	At      time.Time             `json:"at"`
+}
+
+// create stores a queued notification, with its copy rendered in the
+// user's locale, and queues its deliveries on the channels the user's
+// preferences allow. A notification the user wants on no channel is
+// dropped; one they only turned off in the app is stored but hidden, so its
+// other deliveries can be recorded.
+func (s *NotificationService) create(ctx context.Context, notification *model.Notification) error {
+	prefs, err := s.preferenceRepo.Get(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	notification.InApp = prefs.For(notification.Type, model.ChannelInApp).Enabled
+
+	var channels []model.DeliveryChannel
+	for _, channel := range model.OutboundChannels {
+		if s.sender.Supports(channel) && prefs.For(notification.Type, channel).Enabled {
+			channels = append(channels, channel)
+		}
+	}
+
+	if !notification.InApp && len(channels) == 0 {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, notification.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.templates.Render(ctx, notification, user.Locale, prefs.Location()); err != nil {
+		return err
+	}
+
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return err
+	}
+
+	for _, channel := range channels {
+		err := s.jobQueue.Enqueue(ctx, JobDeliverNotification,
+			deliverNotificationPayload{NotificationID: notification.ID, Channel: channel},
+			queue.UniqueKey(JobDeliverNotification+":"+notification.ID.String()+":"+string(channel)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deliver sends a notification on a channel unless that was already done,
+// or holds it for a digest or the end of quiet hours. A failed send is
+// recorded and returned so the queue retries it.
+func (s *NotificationService) deliver(ctx context.Context, notificationID uuid.UUID, channel model.DeliveryChannel) error {
+	record, err := s.deliveryRepo.Get(ctx, notificationID, channel)
+	if err != nil {
+		return err
+	}
+	if record.Status == model.DeliverySucceeded || record.Status == model.DeliverySkipped {
+		return nil
+	}
+
+	notification, err := s.notificationRepo.GetByID(ctx, notificationID)
+	if errors.Is(err, repository.ErrNotificationNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if record.ScheduledFor != nil {
+		return s.queueDigest(ctx, notification.UserID, channel, *record.ScheduledFor)
+	}
+
+	prefs, err := s.preferenceRepo.Get(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !prefs.For(notification.Type, channel).Enabled {
+		record.Status = model.DeliverySkipped
+		record.Error = "turned off in the user's preferences"
+		return s.deliveryRepo.Save(ctx, record)
+	}
+
+	now := time.Now()
+	if at := prefs.DeliverAt(notification.Type, channel, now); at.After(now) {
+		record.ScheduledFor = &at
+		if err := s.deliveryRepo.Save(ctx, record); err != nil {
+			return err
+		}
+		return s.queueDigest(ctx, notification.UserID, channel, at)
+	}
+
+	to, err := s.recipient(ctx, notification.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.localize(ctx, to.Locale, prefs.Location(), notification)
+
+	wording, err := s.templates.DeliveryCopy(ctx, notification, to.Name, to.Locale)
+	if err != nil {
+		return err
+	}
+
+	record.Attempts++
+	sendErr := s.sender.Send(ctx, channel, to, notification, wording)
+
+	return s.settle(ctx, notification.UserID, to, []*model.NotificationDelivery{record}, sendErr)
+}
+
+func (s *NotificationService) queueDigest(ctx context.Context, userID uuid.UUID, channel model.DeliveryChannel, at time.Time) error {
+	key := fmt.Sprintf("%s:%s:%s:%d", JobSendDigest, userID, channel, at.Unix())
+	return s.jobQueue.Enqueue(ctx, JobSendDigest,
+		sendDigestPayload{UserID: userID, Channel: channel, At: at},
+		queue.RunAt(at), queue.UniqueKey(key))
+}
+
+// sendDigest sends the user's deliveries on a channel that are due by at,
+// as one message. A single delivery is sent as it is.
+func (s *NotificationService) sendDigest(ctx context.Context, userID uuid.UUID, channel model.DeliveryChannel, at time.Time) error {
+	records, notifications, err := s.deliveryRepo.ListDue(ctx, userID, channel, at)
+	if err != nil || len(records) == 0 {
+		return err
+	}
+
+	to, err := s.recipient(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	prefs, err := s.preferenceRepo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	s.localize(ctx, to.Locale, prefs.Location(), notifications...)
+
+	var wording delivery.Copy
+	if len(notifications) == 1 {
+		wording, err = s.templates.DeliveryCopy(ctx, notifications[0], to.Name, to.Locale)
+	} else {
+		wording, err = s.templates.DigestCopy(ctx, len(notifications), to.Name, to.Locale)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		record.Attempts++
+	}
+
+	var sendErr error
+	if len(notifications) == 1 {
+		sendErr = s.sender.Send(ctx, channel, to, notifications[0], wording)
+	} else {
+		sendErr = s.sender.SendDigest(ctx, channel, to, notifications, wording)
+	}
+
+	return s.settle(ctx, userID, to, records, sendErr)
+}
+
+// settle records the outcome of sending the given deliveries together,
+// removing push subscriptions that have expired, and returns the error if
+// the send should be retried.
+func (s *NotificationService) settle(ctx context.Context, userID uuid.UUID, to delivery.Recipient, records []*model.NotificationDelivery, sendErr error) error {
+	var gone *delivery.GoneError
+	if errors.As(sendErr, &gone) {
+		s.removePushSubscriptions(ctx, userID, gone.Endpoints)
+		// Gone on its own means every other subscription got it.
+		if sendErr == error(gone) {
+			sendErr = nil
+			if len(gone.Endpoints) == len(to.PushSubscriptions) {
+				sendErr = delivery.ErrNoAddress
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, record := range records {
+		switch {
+		case sendErr == nil:
+			record.Status = model.DeliverySucceeded
+			record.Error = ""
+			record.SentAt = &now
+		case errors.Is(sendErr, delivery.ErrNoAddress):
+			record.Status = model.DeliverySkipped
+			record.Error = sendErr.Error()
+		default:
+			record.Status = model.DeliveryFailed
+			record.Error = sendErr.Error()
+		}
+
+		if err := s.deliveryRepo.Save(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	if sendErr != nil && !errors.Is(sendErr, delivery.ErrNoAddress) {
+		return sendErr
+	}
+	return nil
+}
+
+func (s *NotificationService) recipient(ctx context.Context, userID uuid.UUID) (delivery.Recipient, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return delivery.Recipient{}, err
+	}
+
+	subs, err := s.pushRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return delivery.Recipient{}, err
+	}
+
+	return delivery.Recipient{
+		Name:              user.Name,
+		Email:             user.Email,
+		Phone:             user.Phone,
+		Locale:            user.Locale,
+		PushSubscriptions: subs,
+	}, nil
+}
+
+func (s *NotificationService) removePushSubscriptions(ctx context.Context, userID uuid.UUID, endpoints []string) {
+	for _, endpoint := range endpoints {
+		if err := s.pushRepo.DeleteByEndpoint(ctx, userID, endpoint); err != nil {
+			logger.Error("failed to remove expired push subscription", logger.WithFields(map[string]interface{}{
+				"user_id": userID.String(),
+				"error":   err.Error(),
+			}))
+		}
+	}
+}
+
+// PushPublicKey returns the VAPID key browsers subscribe with.
+func (s *NotificationService) PushPublicKey() (string, error) {
+	key := s.sender.PushPublicKey()
+	if key == "" {
+		return "", ErrPushNotConfigured
+	}
+	return key, nil
+}
+
+// SubscribePush saves a browser's push subscription. The user agent is kept
+// so users can tell their devices apart.
+func (s *NotificationService) SubscribePush(ctx context.Context, userID uuid.UUID, userAgent string, req *model.CreatePushSubscriptionRequest) (*model.PushSubscription, error) {
+	sub := &model.PushSubscription{
+		UserID:    userID,
+		Endpoint:  req.Endpoint,
+		Keys:      req.Keys,
+		UserAgent: userAgent,
+	}
+
+	if err := validatePushSubscription(sub); err != nil {
+		return nil, err
+	}
+
+	if err := s.pushRepo.Save(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+func (s *NotificationService) UnsubscribePush(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	v := validator.New()
+	if v.Required("endpoint", endpoint); v.Errors().HasErrors() {
+		return v.Errors()
+	}
+
+	return s.pushRepo.DeleteByEndpoint(ctx, userID, endpoint)
+}
+
+func validatePushSubscription(sub *model.PushSubscription) error {
+	v := validator.New()
+
+	u, err := url.Parse(sub.Endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		v.AddError("endpoint", "must be an absolute https URL")
+	}
+	v.Required("keys.p256dh", sub.Keys.P256dh)
+	v.Required("keys.auth", sub.Keys.Auth)
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+)
+
+// GetPreferences returns the user's preferences for every notification
+// type and channel.
+func (s *NotificationService) GetPreferences(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error) {
+	prefs, err := s.preferenceRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return prefs.Resolved(), nil
+}
+
+// UpdatePreferences replaces the user's time zone and quiet hours and saves
+// the preferences given; preferences left out keep their current value.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, req *model.UpdateNotificationPreferencesRequest) (*model.NotificationPreferences, error) {
+	prefs := &model.NotificationPreferences{
+		UserID:      userID,
+		TimeZone:    req.TimeZone,
+		QuietHours:  req.QuietHours,
+		Preferences: req.Preferences,
+	}
+	if prefs.TimeZone == "" {
+		prefs.TimeZone = "UTC"
+	}
+	for i := range prefs.Preferences {
+		if prefs.Preferences[i].Digest == "" {
+			prefs.Preferences[i].Digest = model.DigestImmediate
+		}
+	}
+
+	if err := validatePreferences(prefs); err != nil {
+		return nil, err
+	}
+
+	if err := s.preferenceRepo.Save(ctx, prefs); err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(ctx, userID)
+}
+
+func validatePreferences(prefs *model.NotificationPreferences) error {
+	v := validator.New()
+
+	if _, err := time.LoadLocation(prefs.TimeZone); err != nil {
+		v.AddError("time_zone", "must be an IANA time zone such as Europe/Lisbon")
+	}
+	if prefs.QuietHours != nil && !prefs.QuietHours.Valid() {
+		v.AddError("quiet_hours", "start and end must be different times formatted as HH:MM")
+	}
+
+	types := model.NotificationTypes()
+	for i, pref := range prefs.Preferences {
+		field := fmt.Sprintf("preferences[%d]", i)
+
+		if !slices.Contains(types, pref.Type) {
+			v.AddError(field+".type", "unknown notification type "+string(pref.Type))
+			continue
+		}
+		if !slices.Contains(model.PreferenceChannels, pref.Channel) {
+			v.AddError(field+".channel", "must be one of in_app, email, sms or push")
+			continue
+		}
+		if !pref.Digest.IsValid() {
+			v.AddError(field+".digest", "must be one of immediate, hourly or daily")
+		}
+		if pref.Channel == model.ChannelInApp && pref.Digest != model.DigestImmediate {
+			v.AddError(field+".digest", "in-app notifications are always immediate")
+		}
+		if pref.Type.Required(pref.Channel) && (!pref.Enabled || pref.Digest != model.DigestImmediate) {
+			v.AddError(field, string(pref.Type)+" notifications cannot be turned off or delayed "+channelLabel(pref.Channel))
+		}
+	}
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+
+	return nil
+}
+
+func channelLabel(channel model.DeliveryChannel) string {
+	if channel == model.ChannelInApp {
+		return "in the app"
+	}
+	return "by " + string(channel)
+}
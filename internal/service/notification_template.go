@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/delivery"
+	"github.com/abneribeiro/goapi/internal/i18n"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+// templateCacheTTL bounds how long a replica keeps rendering with a
+// template after a newer version is added on another one.
+const templateCacheTTL = time.Minute
+
+type cachedTemplate struct {
+	tmpl    *i18n.Template
+	expires time.Time
+}
+
+// NotificationTemplateService renders notifications from the newest
+// version of their type's template in the reader's locale, falling back to
+// the copy shipped with the app.
+type NotificationTemplateService struct {
+	templateRepo *repository.NotificationTemplateRepository
+	catalog      *i18n.Catalog
+
+	mu    sync.Mutex
+	cache map[string]cachedTemplate
+}
+
+func NewNotificationTemplateService(templateRepo *repository.NotificationTemplateRepository, catalog *i18n.Catalog) *NotificationTemplateService {
+	return &NotificationTemplateService{
+		templateRepo: templateRepo,
+		catalog:      catalog,
+		cache:        make(map[string]cachedTemplate),
+	}
+}
+
+func (s *NotificationTemplateService) List(ctx context.Context, filter *model.NotificationTemplateFilter) ([]*model.NotificationTemplate, error) {
+	return s.templateRepo.List(ctx, filter)
+}
+
+// Create adds a new version of a type's template in a locale, used from
+// then on for notifications of that type, including ones already sent.
+func (s *NotificationTemplateService) Create(ctx context.Context, createdBy uuid.UUID, req *model.CreateNotificationTemplateRequest) (*model.NotificationTemplate, error) {
+	v := validator.New()
+	v.Required("type", string(req.Type))
+	v.Required("locale", req.Locale)
+	v.Required("title", req.Title)
+	v.Required("message", req.Message)
+	if req.Type != "" && !slices.Contains(model.TemplateTypes(), req.Type) {
+		v.AddError("type", "unknown notification type")
+	}
+	v.InList("locale", req.Locale, i18n.Locales())
+	if v.Errors().HasErrors() {
+		return nil, v.Errors()
+	}
+
+	if _, err := i18n.Parse(req.Type, req.Locale, req.Title, req.Message); err != nil {
+		v.AddError("template", err.Error())
+		return nil, v.Errors()
+	}
+
+	tmpl := &model.NotificationTemplate{
+		Type:      req.Type,
+		Locale:    req.Locale,
+		Title:     req.Title,
+		Message:   req.Message,
+		CreatedBy: &createdBy,
+	}
+	if err := s.templateRepo.Create(ctx, tmpl); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	delete(s.cache, templateKey(req.Type, req.Locale))
+	s.mu.Unlock()
+
+	return tmpl, nil
+}
+
+// Render sets a notification's title and message from its data, in locale
+// with dates in loc. Notifications without data keep their stored copy.
+func (s *NotificationTemplateService) Render(ctx context.Context, notification *model.Notification, locale string, loc *time.Location) error {
+	if notification.Data == nil {
+		return nil
+	}
+
+	tmpl, err := s.lookup(ctx, notification.Type, locale)
+	if err != nil || tmpl == nil {
+		return err
+	}
+
+	title, message, err := tmpl.Render(notification.Data, loc)
+	if err != nil {
+		return err
+	}
+
+	notification.Title = title
+	notification.Message = message
+	return nil
+}
+
+// DeliveryCopy renders the wording sent around a notification by email, SMS
+// and push. Reminders get a subject and advice of their own.
+func (s *NotificationTemplateService) DeliveryCopy(ctx context.Context, notification *model.Notification, name, locale string) (delivery.Copy, error) {
+	var wording delivery.Copy
+	var err error
+
+	wording.Greeting, wording.Link, err = s.renderCopy(ctx, model.CopyDeliveryEmail, locale, map[string]interface{}{"name": name})
+	if err != nil {
+		return delivery.Copy{}, err
+	}
+
+	if notification.Type == model.NotificationReservationReminder {
+		data := map[string]interface{}{"name": name, "title": notification.Title}
+		if wording.Subject, wording.Intro, err = s.renderCopy(ctx, model.CopyDeliveryReminder, locale, data); err != nil {
+			return delivery.Copy{}, err
+		}
+	}
+
+	return wording, nil
+}
+
+// DigestCopy renders the wording sent around a digest of count
+// notifications.
+func (s *NotificationTemplateService) DigestCopy(ctx context.Context, count int, name, locale string) (delivery.Copy, error) {
+	var wording delivery.Copy
+	var err error
+
+	wording.Greeting, wording.Link, err = s.renderCopy(ctx, model.CopyDeliveryEmail, locale, map[string]interface{}{"name": name})
+	if err != nil {
+		return delivery.Copy{}, err
+	}
+
+	data := map[string]interface{}{"name": name, "count": count}
+	if wording.Subject, wording.Intro, err = s.renderCopy(ctx, model.CopyDeliveryDigest, locale, data); err != nil {
+		return delivery.Copy{}, err
+	}
+
+	return wording, nil
+}
+
+// renderCopy renders the title and message of a delivery copy type. The
+// copy is not about a date, so it is rendered in UTC.
+func (s *NotificationTemplateService) renderCopy(ctx context.Context, copyType model.NotificationType, locale string, data map[string]interface{}) (string, string, error) {
+	tmpl, err := s.lookup(ctx, copyType, locale)
+	if err != nil {
+		return "", "", err
+	}
+	if tmpl == nil {
+		return "", "", fmt.Errorf("no %s template", copyType)
+	}
+
+	return tmpl.Render(data, time.UTC)
+}
+
+// lookup returns the template for a type in locale, or else in the
+// default locale, or nil if there is none.
+func (s *NotificationTemplateService) lookup(ctx context.Context, notifType model.NotificationType, locale string) (*i18n.Template, error) {
+	tmpl, err := s.template(ctx, notifType, locale)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil && locale != i18n.DefaultLocale {
+		return s.template(ctx, notifType, i18n.DefaultLocale)
+	}
+	return tmpl, nil
+}
+
+// template returns the template for a type in a locale, or nil if there is
+// none.
+func (s *NotificationTemplateService) template(ctx context.Context, notifType model.NotificationType, locale string) (*i18n.Template, error) {
+	key := templateKey(notifType, locale)
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.tmpl, nil
+	}
+
+	tmpl, _ := s.catalog.Builtin(notifType, locale)
+
+	stored, err := s.templateRepo.Latest(ctx, notifType, locale)
+	switch {
+	case errors.Is(err, repository.ErrNotificationTemplateNotFound):
+		// No version was added, so the built-in copy is used.
+	case err != nil:
+		return nil, err
+	default:
+		parsed, err := i18n.Parse(stored.Type, stored.Locale, stored.Title, stored.Message)
+		if err != nil {
+			logger.Error("invalid notification template", logger.WithFields(map[string]interface{}{
+				"type":    string(notifType),
+				"locale":  locale,
+				"version": stored.Version,
+				"error":   err.Error(),
+			}))
+			break
+		}
+		tmpl = parsed
+	}
+
+	s.mu.Lock()
+	s.cache[key] = cachedTemplate{tmpl: tmpl, expires: now.Add(templateCacheTTL)}
+	s.mu.Unlock()
+
+	return tmpl, nil
+}
+
+func templateKey(notifType model.NotificationType, locale string) string {
+	return string(notifType) + "/" + locale
+}
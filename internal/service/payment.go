@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/payment"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+var (
+	ErrPaymentDeclined    = errors.New("payment was declined")
+	ErrPaymentRequired    = errors.New("payment must be authorized before approval")
+	ErrPaymentNotRequired = errors.New("equipment does not require prepayment")
+	ErrAlreadyPaid        = errors.New("reservation already has an active payment")
+	ErrPaymentNotFound    = errors.New("payment not found")
+	ErrInvalidWebhook     = errors.New("invalid payment webhook")
+)
+
+// PaymentService moves renter money through the configured provider and
+// keeps the payments table and ledger in step with it.
+type PaymentService struct {
+	paymentRepo     *repository.PaymentRepository
+	reservationRepo *repository.ReservationRepository
+	provider        payment.PaymentProvider
+}
+
+func NewPaymentService(paymentRepo *repository.PaymentRepository, reservationRepo *repository.ReservationRepository, provider payment.PaymentProvider) *PaymentService {
+	return &PaymentService{
+		paymentRepo:     paymentRepo,
+		reservationRepo: reservationRepo,
+		provider:        provider,
+	}
+}
+
+// Authorize places a hold for the reservation total. A declined card is
+// recorded as a failed payment and reported as ErrPaymentDeclined.
+func (s *PaymentService) Authorize(ctx context.Context, reservation *model.Reservation, paymentMethod string) (*model.Payment, error) {
+	return s.authorize(ctx, reservation, model.PaymentRental, reservation.TotalPrice, paymentMethod)
+}
+
+// AuthorizeDeposit places a separate hold for the reservation's deposit,
+// which is later captured in part or voided. It is a no-op for
+// reservations without a deposit.
+func (s *PaymentService) AuthorizeDeposit(ctx context.Context, reservation *model.Reservation, paymentMethod string) (*model.Payment, error) {
+	if reservation.Deposit == nil {
+		return nil, nil
+	}
+	return s.authorize(ctx, reservation, model.PaymentDeposit, reservation.Deposit.Amount, paymentMethod)
+}
+
+func (s *PaymentService) authorize(ctx context.Context, reservation *model.Reservation, kind model.PaymentKind, amount money.Money, paymentMethod string) (*model.Payment, error) {
+	latest, err := s.latest(ctx, reservation.ID, kind)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && active(latest) {
+		return nil, ErrAlreadyPaid
+	}
+
+	return s.hold(ctx, reservation, kind, amount, paymentMethod)
+}
+
+// AuthorizeCharge places a hold for amount on top of the reservation's
+// rental payments, for a change that raised its price.
+func (s *PaymentService) AuthorizeCharge(ctx context.Context, reservation *model.Reservation, amount money.Money, paymentMethod string) (*model.Payment, error) {
+	return s.hold(ctx, reservation, model.PaymentRental, amount, paymentMethod)
+}
+
+func (s *PaymentService) hold(ctx context.Context, reservation *model.Reservation, kind model.PaymentKind, amount money.Money, paymentMethod string) (*model.Payment, error) {
+	p := &model.Payment{
+		ReservationID: reservation.ID,
+		Kind:          kind,
+		Provider:      s.provider.Name(),
+		Amount:        amount,
+	}
+
+	result, err := s.provider.Authorize(ctx, payment.AuthorizeRequest{
+		Reference:     reservation.ID.String(),
+		Amount:        amount,
+		PaymentMethod: paymentMethod,
+	})
+	if err != nil {
+		if !errors.Is(err, payment.ErrDeclined) {
+			return nil, err
+		}
+		p.Status = model.PaymentFailed
+		p.FailureReason = err.Error()
+		if err := s.paymentRepo.Create(ctx, p); err != nil {
+			return nil, err
+		}
+		return p, ErrPaymentDeclined
+	}
+
+	p.Status = model.PaymentAuthorized
+	p.ProviderRef = result.Reference
+	if err := s.paymentRepo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Capture takes the reservation's total from its rental holds, oldest
+// first. A total that dropped below the holds after a change of dates is
+// captured as is and the rest of the hold released. Holds the total does
+// not reach, placed for a change still pending, are left for the change.
+func (s *PaymentService) Capture(ctx context.Context, reservation *model.Reservation) (*model.Payment, error) {
+	payments, err := s.Rental(ctx, reservation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var captured *model.Payment
+	remaining := reservation.TotalPrice
+	for _, p := range payments {
+		if p.Status != model.PaymentAuthorized || remaining.IsZero() || remaining.IsNegative() {
+			continue
+		}
+
+		amount := p.Amount
+		if remaining.Currency == amount.Currency && remaining.Amount < amount.Amount {
+			amount = remaining
+		}
+
+		if err := s.CaptureCharge(ctx, reservation, p, amount); err != nil {
+			return nil, err
+		}
+		remaining.Amount -= amount.Amount
+		captured = p
+	}
+
+	if captured == nil {
+		return nil, ErrPaymentRequired
+	}
+	return captured, nil
+}
+
+// CaptureCharge takes amount from a single rental hold and releases the
+// rest.
+func (s *PaymentService) CaptureCharge(ctx context.Context, reservation *model.Reservation, p *model.Payment, amount money.Money) error {
+	if _, err := s.provider.Capture(ctx, p.ProviderRef, amount); err != nil {
+		return fmt.Errorf("failed to capture payment: %w", err)
+	}
+	return s.recordCapture(ctx, reservation, p, amount)
+}
+
+// Refund returns up to amount of the reservation's rental payments, newest
+// first. Uncaptured authorizations are voided in full. It returns the
+// latest payment, or nil when nothing was paid.
+func (s *PaymentService) Refund(ctx context.Context, reservation *model.Reservation, amount money.Money) (*model.Payment, error) {
+	payments, err := s.Rental(ctx, reservation.ID)
+	if err != nil || len(payments) == 0 {
+		return nil, err
+	}
+
+	for i := len(payments) - 1; i >= 0; i-- {
+		refunded, err := s.refund(ctx, reservation, payments[i], amount)
+		if err != nil {
+			return nil, err
+		}
+		amount.Amount -= refunded.Amount
+	}
+
+	return payments[len(payments)-1], nil
+}
+
+// RefundTo refunds the reservation's rental payments until refunded has
+// been returned in total, so repeating it after a failure does not refund
+// twice. Uncaptured authorizations are voided in full.
+func (s *PaymentService) RefundTo(ctx context.Context, reservation *model.Reservation, refunded money.Money) (*model.Payment, error) {
+	payments, err := s.Rental(ctx, reservation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Refund(ctx, reservation, refundOwed(payments, refunded))
+}
+
+// RefundTarget is the total refunded of the reservation's rental payments
+// once amount more is returned, for RefundTo.
+func (s *PaymentService) RefundTarget(ctx context.Context, reservationID uuid.UUID, amount money.Money) (money.Money, error) {
+	payments, err := s.Rental(ctx, reservationID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return refundTarget(payments, amount), nil
+}
+
+// refundTarget is the total refunded that refunding amount more of
+// payments brings them to.
+func refundTarget(payments []*model.Payment, amount money.Money) money.Money {
+	return money.New(refundedOf(payments, amount.Currency).Amount+amount.Amount, amount.Currency)
+}
+
+// refundOwed is what is left to refund of payments to reach refunded in
+// total.
+func refundOwed(payments []*model.Payment, refunded money.Money) money.Money {
+	return money.New(refunded.Amount-refundedOf(payments, refunded.Currency).Amount, refunded.Currency)
+}
+
+// refundedOf totals what has been refunded of payments in currency.
+func refundedOf(payments []*model.Payment, currency string) money.Money {
+	total := money.New(0, currency)
+	for _, p := range payments {
+		if p.Refunded.Currency == currency {
+			total.Amount += p.Refunded.Amount
+		}
+	}
+	return total
+}
+
+// Void releases a rental hold. It is a no-op for a payment that is no
+// longer authorized.
+func (s *PaymentService) Void(ctx context.Context, p *model.Payment) error {
+	if p.Status != model.PaymentAuthorized {
+		return nil
+	}
+
+	if _, err := s.provider.Refund(ctx, p.ProviderRef, p.Amount); err != nil {
+		return fmt.Errorf("failed to void payment: %w", err)
+	}
+	p.Status = model.PaymentVoided
+	return s.paymentRepo.Update(ctx, p)
+}
+
+// refund voids p or refunds up to amount of it, and returns how much of
+// amount it refunded.
+func (s *PaymentService) refund(ctx context.Context, reservation *model.Reservation, p *model.Payment, amount money.Money) (money.Money, error) {
+	refunded := money.New(0, amount.Currency)
+
+	switch p.Status {
+	case model.PaymentAuthorized:
+		if err := s.Void(ctx, p); err != nil {
+			return refunded, err
+		}
+	case model.PaymentCaptured, model.PaymentPartiallyRefunded:
+		if p.Captured.Currency != amount.Currency {
+			return refunded, nil
+		}
+		if remaining := p.Captured.Amount - p.Refunded.Amount; amount.Amount > remaining {
+			amount.Amount = remaining
+		}
+		if amount.IsZero() || amount.IsNegative() {
+			return refunded, nil
+		}
+		if _, err := s.provider.Refund(ctx, p.ProviderRef, amount); err != nil {
+			return refunded, fmt.Errorf("failed to refund payment: %w", err)
+		}
+		if err := s.recordRefund(ctx, reservation, p, amount); err != nil {
+			return refunded, err
+		}
+		refunded = amount
+	}
+
+	return refunded, nil
+}
+
+// CaptureDeposit takes amount from the reservation's deposit hold and
+// releases the rest. It returns nil when the deposit was never authorized
+// with the provider, as on listings without prepayment, where the deposit
+// is only recorded.
+func (s *PaymentService) CaptureDeposit(ctx context.Context, reservation *model.Reservation, amount money.Money) (*model.Payment, error) {
+	p, err := s.latest(ctx, reservation.ID, model.PaymentDeposit)
+	if err != nil || p == nil || p.Status != model.PaymentAuthorized {
+		return nil, err
+	}
+
+	if _, err := s.provider.Capture(ctx, p.ProviderRef, amount); err != nil {
+		return nil, fmt.Errorf("failed to capture deposit: %w", err)
+	}
+
+	if err := s.recordCapture(ctx, reservation, p, amount); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// DepositHeld reports whether the provider holds the reservation's deposit.
+func (s *PaymentService) DepositHeld(ctx context.Context, reservationID uuid.UUID) (bool, error) {
+	p, err := s.latest(ctx, reservationID, model.PaymentDeposit)
+	if err != nil || p == nil {
+		return false, err
+	}
+	return p.Status == model.PaymentAuthorized, nil
+}
+
+// ReleaseDeposit voids the reservation's deposit hold. It is a no-op when
+// there is none.
+func (s *PaymentService) ReleaseDeposit(ctx context.Context, reservation *model.Reservation) (*model.Payment, error) {
+	p, err := s.latest(ctx, reservation.ID, model.PaymentDeposit)
+	if err != nil || p == nil || p.Status != model.PaymentAuthorized {
+		return nil, err
+	}
+
+	if _, err := s.provider.Refund(ctx, p.ProviderRef, p.Amount); err != nil {
+		return nil, fmt.Errorf("failed to void deposit: %w", err)
+	}
+
+	p.Status = model.PaymentVoided
+	if err := s.paymentRepo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Latest returns the reservation's most recent rental payment, or nil when
+// it has none.
+func (s *PaymentService) Latest(ctx context.Context, reservationID uuid.UUID) (*model.Payment, error) {
+	return s.latest(ctx, reservationID, model.PaymentRental)
+}
+
+// Rental returns all of the reservation's rental payments, oldest first. A
+// reservation whose price went up after it was paid has more than one.
+func (s *PaymentService) Rental(ctx context.Context, reservationID uuid.UUID) ([]*model.Payment, error) {
+	payments, err := s.paymentRepo.ListByReservation(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rental []*model.Payment
+	for i := range payments {
+		if payments[i].Kind == model.PaymentRental {
+			rental = append(rental, &payments[i])
+		}
+	}
+	return rental, nil
+}
+
+func (s *PaymentService) latest(ctx context.Context, reservationID uuid.UUID, kind model.PaymentKind) (*model.Payment, error) {
+	p, err := s.paymentRepo.GetLatest(ctx, reservationID, kind)
+	if errors.Is(err, repository.ErrPaymentNotFound) {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (s *PaymentService) List(ctx context.Context, reservationID uuid.UUID) (*model.ReservationPayments, error) {
+	payments, err := s.paymentRepo.ListByReservation(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := s.paymentRepo.ListLedgerEntries(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ReservationPayments{Payments: payments, Ledger: ledger}, nil
+}
+
+// HandleWebhook applies a provider notification. Events that are already
+// reflected in our records are ignored, so deliveries can be retried.
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, header http.Header) error {
+	event, err := s.provider.ParseWebhook(payload, header)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	p, err := s.paymentRepo.GetByProviderRef(ctx, s.provider.Name(), event.Reference)
+	if err != nil {
+		if errors.Is(err, repository.ErrPaymentNotFound) {
+			return ErrPaymentNotFound
+		}
+		return err
+	}
+
+	reservation, err := s.reservationRepo.GetByID(ctx, p.ReservationID)
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case payment.EventCaptured:
+		if p.Status != model.PaymentAuthorized {
+			return nil
+		}
+		amount := p.Amount
+		if event.Amount.Currency == p.Amount.Currency && !event.Amount.IsZero() && event.Amount.Amount < amount.Amount {
+			amount = event.Amount
+		}
+		return s.recordCapture(ctx, reservation, p, amount)
+	case payment.EventRefunded:
+		if p.Status != model.PaymentCaptured && p.Status != model.PaymentPartiallyRefunded {
+			return nil
+		}
+		// The event carries the total refunded so far; only the part we
+		// have not recorded yet is new.
+		if event.Amount.Currency != p.Captured.Currency || event.Amount.Amount <= p.Refunded.Amount {
+			return nil
+		}
+		delta := money.New(min(event.Amount.Amount, p.Captured.Amount)-p.Refunded.Amount, p.Captured.Currency)
+		return s.recordRefund(ctx, reservation, p, delta)
+	case payment.EventFailed:
+		if p.Status != model.PaymentAuthorized {
+			return nil
+		}
+		p.Status = model.PaymentFailed
+		p.FailureReason = event.Reason
+		return s.paymentRepo.Update(ctx, p)
+	default:
+		return nil
+	}
+}
+
+func (s *PaymentService) recordCapture(ctx context.Context, reservation *model.Reservation, p *model.Payment, amount money.Money) error {
+	p.Status = model.PaymentCaptured
+	p.Captured = amount
+	return s.paymentRepo.Record(ctx, p, captureEntries(reservation, p, amount))
+}
+
+func (s *PaymentService) recordRefund(ctx context.Context, reservation *model.Reservation, p *model.Payment, amount money.Money) error {
+	p.Refunded.Amount += amount.Amount
+	p.Status = model.PaymentPartiallyRefunded
+	if p.Refunded.Amount >= p.Captured.Amount {
+		p.Status = model.PaymentRefunded
+	}
+	return s.paymentRepo.Record(ctx, p, refundEntries(reservation, p, amount))
+}
+
+func captureEntries(reservation *model.Reservation, p *model.Payment, amount money.Money) []payment.Entry {
+	if p.Kind == model.PaymentDeposit {
+		return payment.DepositCaptureEntries(amount)
+	}
+	return payment.CaptureEntries(amount, splitOf(reservation, amount))
+}
+
+func refundEntries(reservation *model.Reservation, p *model.Payment, amount money.Money) []payment.Entry {
+	if p.Kind == model.PaymentDeposit {
+		return payment.DepositRefundEntries(amount)
+	}
+	return payment.RefundEntries(amount, p.Captured, splitOf(reservation, p.Captured))
+}
+
+// splitOf takes fees and taxes from the stored price breakdown. A capture
+// smaller than the breakdown total has its fees and taxes scaled down.
+func splitOf(reservation *model.Reservation, captured money.Money) payment.Split {
+	split := payment.Split{
+		Fees:  money.New(0, captured.Currency),
+		Taxes: money.New(0, captured.Currency),
+	}
+
+	b := reservation.PriceBreakdown
+	if b == nil || b.Total.Currency != captured.Currency || b.Total.IsZero() {
+		return split
+	}
+
+	split.Fees.Amount = b.Fees.Amount * captured.Amount / b.Total.Amount
+	split.Taxes.Amount = b.Taxes.Amount * captured.Amount / b.Total.Amount
+	return split
+}
+
+func active(p *model.Payment) bool {
+	return p.Status == model.PaymentAuthorized || p.Status == model.PaymentCaptured || p.Status == model.PaymentPartiallyRefunded
+}
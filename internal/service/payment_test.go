@@ -0,0 +1,122 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/payment"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/pricing"
+)
+
+func accountTotal(entries []payment.Entry, account payment.Account) int64 {
+	var total int64
+	for _, e := range entries {
+		if e.Account == account {
+			total += e.Amount.Amount
+		}
+	}
+	return total
+}
+
+func TestCaptureEntries_ByKind(t *testing.T) {
+	reservation := &model.Reservation{
+		PriceBreakdown: &pricing.Breakdown{
+			Total: money.MustParse("118.80", "EUR"),
+			Fees:  money.MustParse("10.00", "EUR"),
+			Taxes: money.MustParse("8.80", "EUR"),
+		},
+	}
+
+	rental := captureEntries(reservation, &model.Payment{Kind: model.PaymentRental}, money.MustParse("118.80", "EUR"))
+	if got := accountTotal(rental, payment.AccountPlatformFees); got != 1000 {
+		t.Errorf("expected rental fees 1000, got %d", got)
+	}
+	if got := accountTotal(rental, payment.AccountOwnerPayable); got != 10000 {
+		t.Errorf("expected rental owner payable 10000, got %d", got)
+	}
+
+	deposit := captureEntries(reservation, &model.Payment{Kind: model.PaymentDeposit}, money.MustParse("60.00", "EUR"))
+	if got := accountTotal(deposit, payment.AccountPlatformFees); got != 0 {
+		t.Errorf("expected no fees on a captured deposit, got %d", got)
+	}
+	if got := accountTotal(deposit, payment.AccountOwnerPayable); got != 6000 {
+		t.Errorf("expected the owner to be owed the whole capture, got %d", got)
+	}
+	if err := payment.Balanced(deposit); err != nil {
+		t.Errorf("expected balanced entries: %v", err)
+	}
+}
+
+func TestSplitOf(t *testing.T) {
+	reservation := &model.Reservation{
+		PriceBreakdown: &pricing.Breakdown{
+			Total: money.MustParse("118.80", "EUR"),
+			Fees:  money.MustParse("10.00", "EUR"),
+			Taxes: money.MustParse("8.80", "EUR"),
+		},
+	}
+
+	tests := []struct {
+		name      string
+		captured  money.Money
+		wantFees  int64
+		wantTaxes int64
+	}{
+		{"whole total", money.MustParse("118.80", "EUR"), 1000, 880},
+		{"half the total", money.MustParse("59.40", "EUR"), 500, 440},
+		{"other currency", money.MustParse("118.80", "USD"), 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			split := splitOf(reservation, tt.captured)
+
+			if split.Fees.Amount != tt.wantFees || split.Taxes.Amount != tt.wantTaxes {
+				t.Errorf("expected fees %d and taxes %d, got %s and %s", tt.wantFees, tt.wantTaxes, split.Fees, split.Taxes)
+			}
+			if split.Fees.Currency != tt.captured.Currency {
+				t.Errorf("expected the split in %s, got %s", tt.captured.Currency, split.Fees.Currency)
+			}
+		})
+	}
+
+	if split := splitOf(&model.Reservation{}, money.MustParse("50.00", "EUR")); !split.Fees.IsZero() || !split.Taxes.IsZero() {
+		t.Errorf("expected no fees or taxes without a breakdown, got %+v", split)
+	}
+}
+
+func TestRefundTarget_RepeatsSafely(t *testing.T) {
+	captured := &model.Payment{
+		Status:   model.PaymentPartiallyRefunded,
+		Captured: money.MustParse("100.00", "EUR"),
+		Refunded: money.MustParse("20.00", "EUR"),
+	}
+	charge := &model.Payment{
+		Status:   model.PaymentPartiallyRefunded,
+		Captured: money.MustParse("30.00", "EUR"),
+		Refunded: money.MustParse("5.00", "EUR"),
+	}
+	payments := []*model.Payment{captured, charge}
+	refund := money.MustParse("50.00", "EUR")
+
+	target := refundTarget(payments, refund)
+	if target.Amount != 7500 {
+		t.Fatalf("expected a target of 75.00, got %s", target)
+	}
+
+	if owed := refundOwed(payments, target); owed.Amount != 5000 {
+		t.Errorf("expected 50.00 owed before the refund, got %s", owed)
+	}
+
+	// The job failed after the provider refunded, so it runs again.
+	charge.Refunded = money.MustParse("30.00", "EUR")
+	captured.Refunded = money.MustParse("45.00", "EUR")
+	if owed := refundOwed(payments, target); !owed.IsZero() {
+		t.Errorf("expected nothing owed once refunded, got %s", owed)
+	}
+
+	if target := refundTarget(nil, refund); target != refund {
+		t.Errorf("expected the refund itself without a payment, got %s", target)
+	}
+}
@@ -3,14 +3,20 @@ package service
 import (
 	"context"
 	"errors"
-	"math"
+	"fmt"
+	"maps"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/logger"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
 	"github.com/abneribeiro/goapi/internal/pkg/pagination"
+	"github.com/abneribeiro/goapi/internal/pkg/signer"
 	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/pricing"
+	"github.com/abneribeiro/goapi/internal/queue"
 	"github.com/abneribeiro/goapi/internal/repository"
 )
 
@@ -21,46 +27,133 @@ var (
 	ErrEquipmentUnavailable  = errors.New("equipment not available for selected dates")
 	ErrInvalidDateRange      = errors.New("invalid date range")
 	ErrReservationNotPending = errors.New("reservation is not in pending status")
+	ErrInvalidTransition     = errors.New("reservation cannot move to that status")
+	ErrInvalidQuote          = errors.New("quote is invalid or does not match the reservation")
+	ErrQuoteExpired          = errors.New("quote has expired")
 )
 
 type ReservationService struct {
-	reservationRepo   *repository.ReservationRepository
-	equipmentRepo     *repository.EquipmentRepository
-	notificationRepo  *repository.NotificationRepository
+	reservationRepo *repository.ReservationRepository
+	equipmentRepo   *repository.EquipmentRepository
+	jobQueue        *queue.Queue
+	paymentService  *PaymentService
+	pricingEngine   *pricing.Engine
+	quoteSigner     *signer.Signer
+	rates           *money.Rates
+	uploadPath      string
 }
 
 func NewReservationService(
 	reservationRepo *repository.ReservationRepository,
 	equipmentRepo *repository.EquipmentRepository,
-	notificationRepo *repository.NotificationRepository,
+	jobQueue *queue.Queue,
+	paymentService *PaymentService,
+	quoteSigner *signer.Signer,
+	charges pricing.Charges,
+	rates *money.Rates,
+	uploadPath string,
 ) *ReservationService {
 	return &ReservationService{
-		reservationRepo:   reservationRepo,
-		equipmentRepo:     equipmentRepo,
-		notificationRepo:  notificationRepo,
+		reservationRepo: reservationRepo,
+		equipmentRepo:   equipmentRepo,
+		jobQueue:        jobQueue,
+		paymentService:  paymentService,
+		pricingEngine:   pricing.NewEngine().WithCharges(charges),
+		quoteSigner:     quoteSigner,
+		rates:           rates,
+		uploadPath:      uploadPath,
 	}
 }
 
-func (s *ReservationService) Create(ctx context.Context, renterID uuid.UUID, req *model.CreateReservationRequest) (*model.Reservation, error) {
-	v := validator.New()
+// quotedPrice is the payload of a quote token. It carries the full
+// breakdown so a reservation made from the quote stores exactly what the
+// renter was shown.
+type quotedPrice struct {
+	EquipmentID uuid.UUID          `json:"equipment_id"`
+	StartDate   time.Time          `json:"start_date"`
+	EndDate     time.Time          `json:"end_date"`
+	Quantity    int                `json:"quantity"`
+	Price       *pricing.Breakdown `json:"price"`
+}
 
-	if req.StartDate.IsZero() {
-		v.AddError("start_date", "is required")
+func (s *ReservationService) Quote(ctx context.Context, equipmentID uuid.UUID, req *model.QuoteRequest) (*model.Quote, error) {
+	if err := validateBooking(req.StartDate, req.EndDate, &req.Quantity); err != nil {
+		return nil, err
 	}
-	if req.EndDate.IsZero() {
-		v.AddError("end_date", "is required")
+	if req.Currency != "" && !money.ValidCurrency(req.Currency) {
+		return nil, ErrUnsupportedCurrency
 	}
-	if !req.StartDate.IsZero() && !req.EndDate.IsZero() {
-		if !req.EndDate.After(req.StartDate) {
-			v.AddError("end_date", "must be after start_date")
+
+	equipment, err := s.equipmentRepo.GetByID(ctx, equipmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return nil, ErrEquipmentNotFound
 		}
-		if req.StartDate.Before(time.Now().Truncate(24 * time.Hour)) {
-			v.AddError("start_date", "must be in the future")
+		return nil, err
+	}
+
+	available := equipment.Available
+	if available {
+		_, err := s.allocate(ctx, equipment, req.StartDate, req.EndDate, req.Quantity)
+		if err != nil && !errors.Is(err, ErrEquipmentUnavailable) {
+			return nil, err
 		}
+		available = err == nil
 	}
 
-	if v.Errors().HasErrors() {
-		return nil, v.Errors()
+	breakdown, err := s.calculatePrice(ctx, equipment, req.StartDate, req.EndDate, req.Quantity)
+	if err != nil {
+		if errors.Is(err, pricing.ErrNoRates) {
+			return nil, ErrEquipmentUnavailable
+		}
+		return nil, err
+	}
+
+	deposit := money.New(0, breakdown.Currency)
+	if d := newDeposit(equipment, req.Quantity); d != nil {
+		deposit = d.Amount
+	}
+
+	quote := &model.Quote{
+		EquipmentID:        equipmentID,
+		StartDate:          req.StartDate,
+		EndDate:            req.EndDate,
+		Quantity:           req.Quantity,
+		Available:          available,
+		Price:              breakdown,
+		Deposit:            deposit,
+		CancellationPolicy: equipment.CancellationPolicy,
+	}
+
+	if req.Currency != "" && req.Currency != breakdown.Currency {
+		displayTotal, err := s.rates.Convert(breakdown.Total, req.Currency)
+		if err != nil {
+			return nil, ErrUnsupportedCurrency
+		}
+		quote.DisplayTotal = &displayTotal
+	}
+
+	if available {
+		token, expiresAt, err := s.quoteSigner.Sign(quotedPrice{
+			EquipmentID: equipmentID,
+			StartDate:   req.StartDate,
+			EndDate:     req.EndDate,
+			Quantity:    req.Quantity,
+			Price:       breakdown,
+		})
+		if err != nil {
+			return nil, err
+		}
+		quote.Token = token
+		quote.ExpiresAt = &expiresAt
+	}
+
+	return quote, nil
+}
+
+func (s *ReservationService) Create(ctx context.Context, renterID uuid.UUID, req *model.CreateReservationRequest) (*model.Reservation, error) {
+	if err := validateBooking(req.StartDate, req.EndDate, &req.Quantity); err != nil {
+		return nil, err
 	}
 
 	equipment, err := s.equipmentRepo.GetByID(ctx, req.EquipmentID)
@@ -75,44 +168,73 @@ func (s *ReservationService) Create(ctx context.Context, renterID uuid.UUID, req
 		return nil, ErrEquipmentUnavailable
 	}
 
-	available, err := s.equipmentRepo.CheckAvailability(ctx, req.EquipmentID, req.StartDate, req.EndDate)
+	var breakdown *pricing.Breakdown
+	if req.QuoteToken != "" {
+		breakdown, err = s.verifyQuote(req)
+	} else {
+		breakdown, err = s.calculatePrice(ctx, equipment, req.StartDate, req.EndDate, req.Quantity)
+		if errors.Is(err, pricing.ErrNoRates) {
+			err = ErrEquipmentUnavailable
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
-	if !available {
-		return nil, ErrEquipmentUnavailable
-	}
-
-	totalPrice := s.calculatePrice(equipment, req.StartDate, req.EndDate)
 
 	status := model.StatusPending
-	if equipment.AutoApprove {
+	deposit := newDeposit(equipment, req.Quantity)
+	cancellationPolicy := equipment.CancellationPolicy
+	if equipment.AutoApprove && !equipment.RequirePrepayment {
 		status = model.StatusApproved
 	}
 
 	reservation := &model.Reservation{
-		EquipmentID: req.EquipmentID,
-		RenterID:    renterID,
-		StartDate:   req.StartDate,
-		EndDate:     req.EndDate,
-		Status:      status,
-		TotalPrice:  totalPrice,
-	}
-
+		EquipmentID:        req.EquipmentID,
+		RenterID:           renterID,
+		StartDate:          req.StartDate,
+		EndDate:            req.EndDate,
+		Quantity:           req.Quantity,
+		Status:             status,
+		TotalPrice:         breakdown.Total,
+		PriceBreakdown:     breakdown,
+		Deposit:            deposit,
+		CancellationPolicy: &cancellationPolicy,
+	}
+
+	// The repository checks the dates and picks units under a lock on the
+	// equipment, so two renters cannot both book the last free units.
 	if err := s.reservationRepo.Create(ctx, reservation); err != nil {
+		if errors.Is(err, repository.ErrNoAvailability) {
+			return nil, ErrEquipmentUnavailable
+		}
 		return nil, err
 	}
 
 	s.createNotification(ctx, equipment.OwnerID, model.NotificationReservationCreated,
-		"New Reservation Request",
-		"You have a new reservation request for "+equipment.Name,
+		map[string]interface{}{
+			"equipment":  equipment.Name,
+			"quantity":   reservation.Quantity,
+			"start_date": reservation.StartDate,
+			"end_date":   reservation.EndDate,
+		},
 		&reservation.ID, "reservation")
 
 	if status == model.StatusApproved {
 		s.createNotification(ctx, renterID, model.NotificationReservationApproved,
-			"Reservation Approved",
-			"Your reservation for "+equipment.Name+" has been automatically approved",
+			map[string]interface{}{"equipment": equipment.Name, "start_date": reservation.StartDate, "automatic": true},
 			&reservation.ID, "reservation")
+
+		if err := s.holdDeposit(ctx, reservation, equipment); err != nil {
+			return nil, err
+		}
+	}
+
+	// A declined card leaves the reservation pending with a failed payment,
+	// so the renter can retry with another method.
+	if equipment.RequirePrepayment && req.PaymentMethod != "" {
+		if err := s.pay(ctx, reservation, equipment, req.PaymentMethod); err != nil && !errors.Is(err, ErrPaymentDeclined) {
+			return nil, err
+		}
 	}
 
 	return reservation, nil
@@ -131,9 +253,21 @@ func (s *ReservationService) GetByID(ctx context.Context, id uuid.UUID, userID u
 		return nil, ErrNotAuthorized
 	}
 
+	if reservation.Payment, err = s.paymentService.Latest(ctx, id); err != nil {
+		return nil, err
+	}
+
 	return reservation, nil
 }
 
+// History returns the reservation's status changes, oldest first.
+func (s *ReservationService) History(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]model.ReservationEvent, error) {
+	if _, err := s.getParticipantReservation(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.reservationRepo.ListEvents(ctx, id)
+}
+
 func (s *ReservationService) ListMyReservations(ctx context.Context, userID uuid.UUID, pag pagination.Params) ([]*model.Reservation, int64, error) {
 	filter := &model.ReservationFilter{
 		RenterID: &userID,
@@ -161,20 +295,14 @@ func (s *ReservationService) Approve(ctx context.Context, id uuid.UUID, ownerID
 		return nil, ErrNotAuthorized
 	}
 
-	if reservation.Status != model.StatusPending {
-		return nil, ErrReservationNotPending
+	if err := checkTransition(reservation, model.StatusApproved); err != nil {
+		return nil, err
 	}
 
-	if err := s.reservationRepo.UpdateStatus(ctx, id, model.StatusApproved, ""); err != nil {
+	if err := s.approve(ctx, reservation, reservation.Equipment, &ownerID); err != nil {
 		return nil, err
 	}
 
-	s.createNotification(ctx, reservation.RenterID, model.NotificationReservationApproved,
-		"Reservation Approved",
-		"Your reservation for "+reservation.Equipment.Name+" has been approved",
-		&id, "reservation")
-
-	reservation.Status = model.StatusApproved
 	return reservation, nil
 }
 
@@ -191,21 +319,20 @@ func (s *ReservationService) Reject(ctx context.Context, id uuid.UUID, ownerID u
 		return nil, ErrNotAuthorized
 	}
 
-	if reservation.Status != model.StatusPending {
-		return nil, ErrReservationNotPending
+	settle, err := s.settlement(ctx, reservation, &reservation.TotalPrice)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.reservationRepo.UpdateStatus(ctx, id, model.StatusRejected, reason); err != nil {
+	reservation.CancellationReason = reason
+	if err := s.transition(ctx, reservation, model.StatusRejected, &ownerID, reason, settle); err != nil {
 		return nil, err
 	}
 
 	s.createNotification(ctx, reservation.RenterID, model.NotificationReservationRejected,
-		"Reservation Rejected",
-		"Your reservation for "+reservation.Equipment.Name+" has been rejected",
+		map[string]interface{}{"equipment": reservation.Equipment.Name},
 		&id, "reservation")
 
-	reservation.Status = model.StatusRejected
-	reservation.CancellationReason = reason
 	return reservation, nil
 }
 
@@ -222,15 +349,20 @@ func (s *ReservationService) Cancel(ctx context.Context, id uuid.UUID, userID uu
 		return nil, ErrNotAuthorized
 	}
 
-	if reservation.Status != model.StatusPending && reservation.Status != model.StatusApproved {
+	now := time.Now()
+	if reservation.Status == model.StatusApproved && !now.Before(reservation.StartDate) {
 		return nil, ErrCannotCancel
 	}
 
-	if reservation.Status == model.StatusApproved && reservation.StartDate.Before(time.Now().Add(24*time.Hour)) {
-		return nil, ErrCannotCancel
+	cancellation := settleCancellation(reservation, userID, now)
+	settle, err := s.settlement(ctx, reservation, &cancellation.RefundAmount)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.reservationRepo.UpdateStatus(ctx, id, model.StatusCancelled, reason); err != nil {
+	reservation.CancellationReason = reason
+	reservation.Cancellation = cancellation
+	if err := s.transition(ctx, reservation, model.StatusCancelled, &userID, reason, settle); err != nil {
 		return nil, err
 	}
 
@@ -240,12 +372,9 @@ func (s *ReservationService) Cancel(ctx context.Context, id uuid.UUID, userID uu
 	}
 
 	s.createNotification(ctx, notifyUserID, model.NotificationReservationCancelled,
-		"Reservation Cancelled",
-		"A reservation for "+reservation.Equipment.Name+" has been cancelled",
+		map[string]interface{}{"equipment": reservation.Equipment.Name},
 		&id, "reservation")
 
-	reservation.Status = model.StatusCancelled
-	reservation.CancellationReason = reason
 	return reservation, nil
 }
 
@@ -262,58 +391,311 @@ func (s *ReservationService) Complete(ctx context.Context, id uuid.UUID, ownerID
 		return nil, ErrNotAuthorized
 	}
 
-	if reservation.Status != model.StatusApproved {
-		return nil, errors.New("can only complete approved reservations")
+	// A dispute ends through ResolveDispute, which records how it was settled.
+	if reservation.Status == model.StatusDisputed {
+		return nil, fmt.Errorf("%w: disputed reservations are completed by resolving the dispute", ErrInvalidTransition)
+	}
+
+	// Completing means the owner has no claim on a deposit they have not
+	// captured by now.
+	settle, err := s.settlement(ctx, reservation, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.reservationRepo.UpdateStatus(ctx, id, model.StatusCompleted, ""); err != nil {
+	if err := s.transition(ctx, reservation, model.StatusCompleted, &ownerID, "", settle); err != nil {
 		return nil, err
 	}
 
 	s.createNotification(ctx, reservation.RenterID, model.NotificationReservationCompleted,
-		"Reservation Completed",
-		"Your reservation for "+reservation.Equipment.Name+" has been marked as completed",
+		map[string]interface{}{"equipment": reservation.Equipment.Name},
 		&id, "reservation")
 
-	reservation.Status = model.StatusCompleted
 	return reservation, nil
 }
 
-func (s *ReservationService) calculatePrice(equipment *model.Equipment, startDate, endDate time.Time) float64 {
-	duration := endDate.Sub(startDate)
-	days := int(math.Ceil(duration.Hours() / 24))
+// approve moves a pending reservation to approved. When the listing needs
+// prepayment the authorized payment is captured first, so a reservation is
+// never approved without money behind it. A capture whose approval then
+// loses to a concurrent rejection or cancellation is refunded.
+func (s *ReservationService) approve(ctx context.Context, reservation *model.Reservation, equipment *model.Equipment, actorID *uuid.UUID) error {
+	var captured *model.Payment
+	if equipment.RequirePrepayment {
+		payment, err := s.paymentService.Capture(ctx, reservation)
+		if err != nil {
+			return err
+		}
+		captured = payment
+		reservation.Payment = payment
+	}
+
+	if err := s.transition(ctx, reservation, model.StatusApproved, actorID, ""); err != nil {
+		if captured != nil {
+			return errors.Join(err, s.refundLostCapture(ctx, reservation.ID))
+		}
+		return err
+	}
 
-	if equipment.PricePerWeek != nil && days >= 7 {
-		weeks := days / 7
-		remainingDays := days % 7
-		weekPrice := float64(weeks) * *equipment.PricePerWeek
+	s.createNotification(ctx, reservation.RenterID, model.NotificationReservationApproved,
+		map[string]interface{}{"equipment": equipment.Name, "start_date": reservation.StartDate, "automatic": false},
+		&reservation.ID, "reservation")
+
+	if reservation.Payment != nil {
+		s.createNotification(ctx, equipment.OwnerID, model.NotificationPaymentReceived,
+			map[string]interface{}{"equipment": equipment.Name, "amount": reservation.TotalPrice},
+			&reservation.ID, "reservation")
+	}
+
+	return s.holdDeposit(ctx, reservation, equipment)
+}
+
+// refundLostCapture refunds a capture taken for an approval that did not
+// happen. When a concurrent approval won instead, the capture pays for it
+// and is kept.
+func (s *ReservationService) refundLostCapture(ctx context.Context, id uuid.UUID) error {
+	current, err := s.reservationRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
 
-		if remainingDays > 0 && equipment.PricePerDay != nil {
-			return weekPrice + float64(remainingDays)**equipment.PricePerDay
+	if !endedUnapproved(current.Status) {
+		return nil
+	}
+
+	_, err = s.paymentService.Refund(ctx, current, current.TotalPrice)
+	return err
+}
+
+// endedUnapproved reports whether a reservation left pending without being
+// approved, so nothing it paid for will take place.
+func endedUnapproved(status model.ReservationStatus) bool {
+	return status == model.StatusRejected || status == model.StatusCancelled || status == model.StatusExpired
+}
+
+// transition moves a reservation along its lifecycle and records the change
+// in its history. actorID is nil for changes made by the system. Callers
+// set any fields that change together with the status beforehand, and pass
+// the jobs that must follow it, which are enqueued atomically with it.
+func (s *ReservationService) transition(ctx context.Context, reservation *model.Reservation, to model.ReservationStatus, actorID *uuid.UUID, reason string, jobs ...*model.Job) error {
+	if err := checkTransition(reservation, to); err != nil {
+		return err
+	}
+
+	event := newStatusEvent(reservation, to, actorID, reason)
+	if err := s.reservationRepo.Transition(ctx, reservation, event, jobs...); err != nil {
+		return transitionError(err)
+	}
+
+	reservation.Status = to
+	return nil
+}
+
+func newStatusEvent(reservation *model.Reservation, to model.ReservationStatus, actorID *uuid.UUID, reason string) *model.ReservationEvent {
+	return &model.ReservationEvent{
+		ReservationID: reservation.ID,
+		FromStatus:    reservation.Status,
+		ToStatus:      to,
+		ActorID:       actorID,
+		Reason:        reason,
+	}
+}
+
+func transitionError(err error) error {
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return fmt.Errorf("%w: status changed concurrently", ErrInvalidTransition)
+	}
+	return err
+}
+
+func checkTransition(reservation *model.Reservation, to model.ReservationStatus) error {
+	if !reservation.Status.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, reservation.Status, to)
+	}
+	return nil
+}
+
+// settleCancellation works out the refund for a cancellation. Pending
+// reservations are refunded in full. Approved ones follow the policy agreed
+// at booking when the renter cancels; when the owner cancels, the renter is
+// refunded in full and the owner forgoes the rental.
+func settleCancellation(reservation *model.Reservation, userID uuid.UUID, now time.Time) *model.Cancellation {
+	total := reservation.TotalPrice
+	cancellation := &model.Cancellation{
+		CancelledBy:   model.PartyRenter,
+		RefundPercent: 100,
+		CancelledAt:   now,
+	}
+	if userID == reservation.Equipment.OwnerID {
+		cancellation.CancelledBy = model.PartyOwner
+	}
+
+	if reservation.Status == model.StatusApproved && cancellation.CancelledBy == model.PartyRenter {
+		policy := model.DefaultCancellationPolicy
+		if reservation.CancellationPolicy != nil {
+			policy = *reservation.CancellationPolicy
+		}
+		cancellation.RefundPercent = policy.RefundPercent(reservation.StartDate.Sub(now))
+	}
+	cancellation.RefundAmount = total.Percent(float64(cancellation.RefundPercent))
+
+	switch {
+	case reservation.Status == model.StatusApproved && cancellation.CancelledBy == model.PartyOwner:
+		cancellation.ChargedTo = model.PartyOwner
+		cancellation.ChargedAmount = total
+	default:
+		cancellation.ChargedAmount = money.New(total.Amount-cancellation.RefundAmount.Amount, total.Currency)
+		if !cancellation.ChargedAmount.IsZero() {
+			cancellation.ChargedTo = model.PartyRenter
 		}
-		return weekPrice
 	}
 
-	if equipment.PricePerDay != nil {
-		return float64(days) * *equipment.PricePerDay
+	return cancellation
+}
+
+func (s *ReservationService) calculatePrice(ctx context.Context, equipment *model.Equipment, startDate, endDate time.Time, quantity int) (*pricing.Breakdown, error) {
+	rules, err := s.equipmentRepo.ListPricingRules(ctx, equipment.ID, true)
+	if err != nil {
+		return nil, err
 	}
 
-	if equipment.PricePerHour != nil {
-		hours := int(math.Ceil(duration.Hours()))
-		return float64(hours) * *equipment.PricePerHour
+	pricingRules := make([]pricing.Rule, len(rules))
+	for i := range rules {
+		pricingRules[i] = rules[i].ToRule()
+	}
+
+	loc, err := time.LoadLocation(equipment.TimeZone)
+	if err != nil {
+		loc = time.UTC
 	}
 
-	return 0
+	rates := pricing.Rates{
+		Hour: equipment.PricePerHour,
+		Day:  equipment.PricePerDay,
+		Week: equipment.PricePerWeek,
+	}
+
+	return s.pricingEngine.Calculate(rates, pricingRules, pricing.Period{
+		Start:    startDate,
+		End:      endDate,
+		Quantity: quantity,
+		Location: loc,
+	})
 }
 
-func (s *ReservationService) createNotification(ctx context.Context, userID uuid.UUID, notifType model.NotificationType, title, message string, refID *uuid.UUID, refType string) {
+// allocate checks that quantity units are free for the period and, for
+// serialized equipment, picks the units to assign. It backs quotes only;
+// the repository allocates again under a lock when a reservation is created.
+func (s *ReservationService) allocate(ctx context.Context, equipment *model.Equipment, startDate, endDate time.Time, quantity int) ([]uuid.UUID, error) {
+	available, err := s.equipmentRepo.CheckAvailability(ctx, equipment.ID, startDate, endDate, quantity)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, ErrEquipmentUnavailable
+	}
+
+	if equipment.InventoryMode != model.InventorySerialized {
+		return nil, nil
+	}
+
+	unitIDs, err := s.equipmentRepo.FindFreeUnits(ctx, equipment.ID, startDate, endDate, quantity)
+	if err != nil {
+		return nil, err
+	}
+	if len(unitIDs) < quantity {
+		return nil, ErrEquipmentUnavailable
+	}
+
+	return unitIDs, nil
+}
+
+func (s *ReservationService) verifyQuote(req *model.CreateReservationRequest) (*pricing.Breakdown, error) {
+	var quoted quotedPrice
+	if err := s.quoteSigner.Verify(req.QuoteToken, &quoted); err != nil {
+		if errors.Is(err, signer.ErrExpiredToken) {
+			return nil, ErrQuoteExpired
+		}
+		return nil, ErrInvalidQuote
+	}
+
+	if quoted.EquipmentID != req.EquipmentID ||
+		!quoted.StartDate.Equal(req.StartDate) ||
+		!quoted.EndDate.Equal(req.EndDate) ||
+		quoted.Quantity != req.Quantity ||
+		quoted.Price == nil {
+		return nil, ErrInvalidQuote
+	}
+
+	return quoted.Price, nil
+}
+
+// maxRentalDays caps how long a single reservation may run.
+const maxRentalDays = 365
+
+func validateBooking(startDate, endDate time.Time, quantity *int) error {
+	v := validator.New()
+
+	if startDate.IsZero() {
+		v.AddError("start_date", "is required")
+	}
+	if endDate.IsZero() {
+		v.AddError("end_date", "is required")
+	}
+	if !startDate.IsZero() && !endDate.IsZero() {
+		if !endDate.After(startDate) {
+			v.AddError("end_date", "must be after start_date")
+		}
+		if startDate.Before(time.Now().Truncate(24 * time.Hour)) {
+			v.AddError("start_date", "must be in the future")
+		}
+		checkRentalLength(v, startDate, endDate)
+	}
+	if *quantity == 0 {
+		*quantity = 1
+	}
+	if *quantity < 0 {
+		v.AddError("quantity", "must be at least 1")
+	}
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+
+	return nil
+}
+
+func checkRentalLength(v *validator.Validator, startDate, endDate time.Time) {
+	if endDate.Sub(startDate) > maxRentalDays*24*time.Hour {
+		v.AddError("end_date", fmt.Sprintf("must be at most %d days after start_date", maxRentalDays))
+	}
+}
+
+// createNotification queues a notification, so a failure to store it is
+// retried instead of failing the operation that caused it. Its copy is
+// rendered from data with the type's template in the user's locale.
+func (s *ReservationService) createNotification(ctx context.Context, userID uuid.UUID, notifType model.NotificationType, data map[string]interface{}, refID *uuid.UUID, refType string) {
 	notification := &model.Notification{
+		ID:            uuid.New(),
 		UserID:        userID,
 		Type:          notifType,
-		Title:         title,
-		Message:       message,
+		Data:          data,
 		ReferenceID:   refID,
 		ReferenceType: refType,
+		CreatedAt:     time.Now(),
+	}
+	if err := s.jobQueue.Enqueue(ctx, JobCreateNotification, notification); err != nil {
+		logger.Error("failed to queue notification", logger.WithFields(map[string]interface{}{
+			"user_id": userID.String(),
+			"type":    string(notifType),
+			"error":   err.Error(),
+		}))
 	}
-	s.notificationRepo.Create(ctx, notification)
+}
+
+// withRecipient copies data for a notification sent to several people whose
+// copy depends on whether they are the renter or the owner.
+func withRecipient(data map[string]interface{}, recipient string) map[string]interface{} {
+	copied := maps.Clone(data)
+	copied["recipient"] = recipient
+	return copied
 }
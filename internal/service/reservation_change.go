@@ -0,0 +1,467 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/pricing"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+var (
+	ErrChangeNotFound     = errors.New("change request not found")
+	ErrChangePending      = errors.New("reservation already has a pending change request")
+	ErrChangeNotPending   = errors.New("change request is no longer pending")
+	ErrCannotChange       = errors.New("reservation dates can no longer be changed")
+	ErrChangeNeedsPayment = errors.New("price increase needs a payment method to be charged to")
+)
+
+// RequestChange proposes new dates for a reservation. The new dates must be
+// free apart from the reservation itself and are priced with the listing's
+// current rates and rules. A price increase on a prepaid reservation is
+// authorized on req.PaymentMethod now and captured when the change is
+// approved. Listings with auto-approve apply the change straight away;
+// otherwise it waits for the owner.
+func (s *ReservationService) RequestChange(ctx context.Context, id uuid.UUID, renterID uuid.UUID, req *model.CreateReservationChangeRequest) (*model.ReservationChange, error) {
+	reservation, err := s.reservationRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+
+	if reservation.RenterID != renterID {
+		return nil, ErrNotAuthorized
+	}
+
+	if !changeable(reservation) {
+		return nil, ErrCannotChange
+	}
+
+	if err := validateChange(reservation, req); err != nil {
+		return nil, err
+	}
+
+	equipment, err := s.equipmentRepo.GetByID(ctx, reservation.EquipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkChangeAvailability(ctx, equipment, reservation, req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+
+	price, err := s.calculatePrice(ctx, equipment, req.StartDate, req.EndDate, reservation.Quantity)
+	if err != nil {
+		if errors.Is(err, pricing.ErrNoRates) {
+			return nil, ErrEquipmentUnavailable
+		}
+		return nil, err
+	}
+
+	change := &model.ReservationChange{
+		ReservationID: id,
+		RequestedBy:   renterID,
+		Status:        model.ChangePending,
+		FromStartDate: reservation.StartDate,
+		FromEndDate:   reservation.EndDate,
+		StartDate:     req.StartDate,
+		EndDate:       req.EndDate,
+		Price:         price,
+		PriceDelta:    priceDelta(reservation, price),
+	}
+
+	hold, err := s.authorizeChange(ctx, reservation, change, req.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reservationRepo.CreateChange(ctx, change); err != nil {
+		if errors.Is(err, repository.ErrChangePending) {
+			err = ErrChangePending
+		}
+		if hold != nil {
+			err = errors.Join(err, s.paymentService.Void(ctx, hold))
+		}
+		return nil, err
+	}
+
+	if equipment.AutoApprove {
+		if err := s.applyChange(ctx, reservation, change, nil); err != nil {
+			return nil, err
+		}
+		return change, nil
+	}
+
+	s.createNotification(ctx, equipment.OwnerID, model.NotificationChangeRequested,
+		map[string]interface{}{
+			"equipment":  equipment.Name,
+			"renter":     reservation.Renter.Name,
+			"start_date": change.StartDate,
+			"end_date":   change.EndDate,
+		},
+		&id, "reservation")
+
+	return change, nil
+}
+
+func (s *ReservationService) ListChanges(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]model.ReservationChange, error) {
+	if _, err := s.getParticipantReservation(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.reservationRepo.ListChanges(ctx, id)
+}
+
+// ApproveChange applies a pending change after checking once more that the
+// new dates are still free.
+func (s *ReservationService) ApproveChange(ctx context.Context, id, changeID uuid.UUID, ownerID uuid.UUID) (*model.ReservationChange, error) {
+	reservation, change, err := s.getPendingChange(ctx, id, changeID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !changeable(reservation) {
+		return nil, ErrCannotChange
+	}
+
+	equipment, err := s.equipmentRepo.GetByID(ctx, reservation.EquipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkChangeAvailability(ctx, equipment, reservation, change.StartDate, change.EndDate); err != nil {
+		return nil, err
+	}
+
+	if err := s.applyChange(ctx, reservation, change, &ownerID); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (s *ReservationService) RejectChange(ctx context.Context, id, changeID uuid.UUID, ownerID uuid.UUID, reason string) (*model.ReservationChange, error) {
+	reservation, change, err := s.getPendingChange(ctx, id, changeID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	change.Reason = reason
+	change.DecidedBy = &ownerID
+	if err := s.reservationRepo.RejectChange(ctx, change); err != nil {
+		if errors.Is(err, repository.ErrChangeConflict) {
+			return nil, ErrChangeNotPending
+		}
+		return nil, err
+	}
+
+	if err := s.releaseChangeHold(ctx, reservation, change); err != nil {
+		return nil, err
+	}
+
+	s.createNotification(ctx, reservation.RenterID, model.NotificationChangeRejected,
+		map[string]interface{}{"equipment": reservation.Equipment.Name},
+		&id, "reservation")
+
+	return change, nil
+}
+
+// applyChange moves the reservation to the change's dates and settles the
+// price difference. A charge on a captured payment is captured from the
+// change's hold first and refunded if the change cannot be saved; on a
+// reservation still holding its payment, the change's hold is captured with
+// the rest on approval. An overdue reservation extended past now is no
+// longer late, so its accrued late fee is dropped. actorID is nil when the
+// change is approved automatically.
+func (s *ReservationService) applyChange(ctx context.Context, reservation *model.Reservation, change *model.ReservationChange, actorID *uuid.UUID) error {
+	settlement, hold, err := s.changeSettlement(ctx, reservation, change)
+	if err != nil {
+		return err
+	}
+
+	charge := settlement.Charge
+	if charge.Amount > 0 && (hold == nil || hold.Status != model.PaymentAuthorized || hold.Amount.Amount < charge.Amount) {
+		return ErrChangeNeedsPayment
+	}
+
+	captured := false
+	if charge.Amount > 0 && !settlement.Held {
+		if err := s.paymentService.CaptureCharge(ctx, reservation, hold, charge); err != nil {
+			return err
+		}
+		captured = true
+	}
+
+	resetLateReturn(reservation, change.EndDate, time.Now())
+
+	change.DecidedBy = actorID
+	event := newStatusEvent(reservation, reservation.Status, actorID, fmt.Sprintf("dates changed from %s - %s to %s - %s",
+		change.FromStartDate.Format(time.RFC3339), change.FromEndDate.Format(time.RFC3339),
+		change.StartDate.Format(time.RFC3339), change.EndDate.Format(time.RFC3339)))
+
+	if err := s.reservationRepo.ApplyChange(ctx, reservation, change, event); err != nil {
+		if errors.Is(err, repository.ErrChangeConflict) {
+			err = ErrChangeNotPending
+		}
+		if errors.Is(err, repository.ErrNoAvailability) {
+			err = ErrEquipmentUnavailable
+		}
+		if captured {
+			_, refundErr := s.paymentService.refund(ctx, reservation, hold, charge)
+			err = errors.Join(err, refundErr)
+		}
+		return err
+	}
+
+	reservation.StartDate = change.StartDate
+	reservation.EndDate = change.EndDate
+	reservation.TotalPrice = change.Price.Total
+	reservation.PriceBreakdown = change.Price
+
+	if charge.IsZero() && hold != nil {
+		if err := s.paymentService.Void(ctx, hold); err != nil {
+			return err
+		}
+	}
+
+	if settlement.Refund.Amount > 0 {
+		if reservation.Payment, err = s.paymentService.Refund(ctx, reservation, settlement.Refund); err != nil {
+			return err
+		}
+	} else if captured {
+		reservation.Payment = hold
+	}
+
+	data := map[string]interface{}{
+		"equipment":  reservation.Equipment.Name,
+		"start_date": change.StartDate,
+		"end_date":   change.EndDate,
+	}
+	switch {
+	case change.PriceDelta.Amount > 0:
+		data["amount_due"] = change.PriceDelta
+	case change.PriceDelta.Amount < 0:
+		data["amount_saved"] = money.New(-change.PriceDelta.Amount, change.PriceDelta.Currency)
+	}
+	s.createNotification(ctx, reservation.RenterID, model.NotificationChangeApproved,
+		data,
+		&reservation.ID, "reservation")
+
+	return nil
+}
+
+func (s *ReservationService) getPendingChange(ctx context.Context, id, changeID uuid.UUID, ownerID uuid.UUID) (*model.Reservation, *model.ReservationChange, error) {
+	reservation, err := s.reservationRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			return nil, nil, ErrReservationNotFound
+		}
+		return nil, nil, err
+	}
+
+	if reservation.Equipment.OwnerID != ownerID {
+		return nil, nil, ErrNotAuthorized
+	}
+
+	change, err := s.reservationRepo.GetChange(ctx, id, changeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrChangeNotFound) {
+			return nil, nil, ErrChangeNotFound
+		}
+		return nil, nil, err
+	}
+
+	if change.Status != model.ChangePending {
+		return nil, nil, ErrChangeNotPending
+	}
+
+	return reservation, change, nil
+}
+
+// checkChangeAvailability checks the new dates against every booking but
+// the reservation's own. Serialized reservations keep their units, so those
+// units must be free.
+func (s *ReservationService) checkChangeAvailability(ctx context.Context, equipment *model.Equipment, reservation *model.Reservation, startDate, endDate time.Time) error {
+	available, err := s.equipmentRepo.CheckAvailabilityExcluding(ctx, equipment.ID, reservation.ID, startDate, endDate, reservation.Quantity)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return ErrEquipmentUnavailable
+	}
+
+	if len(reservation.UnitIDs) > 0 {
+		booked, err := s.equipmentRepo.UnitsBooked(ctx, reservation.UnitIDs, reservation.ID, startDate, endDate)
+		if err != nil {
+			return err
+		}
+		if booked {
+			return ErrEquipmentUnavailable
+		}
+	}
+
+	return nil
+}
+
+// priceDelta is what the renter owes, or gets back when negative, for
+// moving the reservation to a new price.
+func priceDelta(reservation *model.Reservation, price *pricing.Breakdown) money.Money {
+	return money.New(price.Total.Amount-reservation.TotalPrice.Amount, price.Currency)
+}
+
+// resetLateReturn drops a provisional late fee when the new end date is
+// still ahead, since the reservation is no longer overdue. A final fee stays.
+func resetLateReturn(reservation *model.Reservation, endDate, now time.Time) {
+	if reservation.LateReturn != nil && !reservation.LateReturn.Final && endDate.After(now) {
+		reservation.LateReturn = nil
+	}
+}
+
+// changeSettlement is how a change is settled against the reservation's
+// rental payments.
+type changeSettlement struct {
+	// Refund is returned from what the renter has paid.
+	Refund money.Money
+	// Charge is owed on top of what the renter has paid or authorized.
+	Charge money.Money
+	// Held is set while the reservation's payment is only authorized, so a
+	// charge stays a hold and is captured with it on approval.
+	Held bool
+}
+
+// authorizeChange places a hold on paymentMethod for what a change adds to
+// a prepaid reservation's price, and returns it, or nil when nothing is
+// owed.
+func (s *ReservationService) authorizeChange(ctx context.Context, reservation *model.Reservation, change *model.ReservationChange, paymentMethod string) (*model.Payment, error) {
+	settlement, _, err := s.changeSettlement(ctx, reservation, change)
+	if err != nil || settlement.Charge.IsZero() {
+		return nil, err
+	}
+
+	if paymentMethod == "" {
+		return nil, ErrChangeNeedsPayment
+	}
+
+	hold, err := s.paymentService.AuthorizeCharge(ctx, reservation, settlement.Charge, paymentMethod)
+	if err != nil {
+		return nil, err
+	}
+	change.PaymentID = &hold.ID
+	return hold, nil
+}
+
+// changeSettlement settles a change against the reservation's rental
+// payments other than the change's own hold, which it returns alongside.
+func (s *ReservationService) changeSettlement(ctx context.Context, reservation *model.Reservation, change *model.ReservationChange) (changeSettlement, *model.Payment, error) {
+	payments, err := s.paymentService.Rental(ctx, reservation.ID)
+	if err != nil {
+		return changeSettlement{}, nil, err
+	}
+
+	var hold *model.Payment
+	others := payments[:0:0]
+	for _, p := range payments {
+		if change.PaymentID != nil && p.ID == *change.PaymentID {
+			hold = p
+			continue
+		}
+		others = append(others, p)
+	}
+
+	return settleChange(others, change), hold, nil
+}
+
+// releaseChangeHold voids the hold placed for a change that will not be
+// applied.
+func (s *ReservationService) releaseChangeHold(ctx context.Context, reservation *model.Reservation, change *model.ReservationChange) error {
+	_, hold, err := s.changeSettlement(ctx, reservation, change)
+	if err != nil || hold == nil {
+		return err
+	}
+	return s.paymentService.Void(ctx, hold)
+}
+
+// settleChange works out what a change refunds or charges. Without an
+// active payment there is nothing to settle. Holds are captured at the
+// reservation's total later, so only a total beyond them is charged, as
+// another hold. A captured payment is refunded a lower price and charged
+// a higher one.
+func settleChange(payments []*model.Payment, change *model.ReservationChange) changeSettlement {
+	currency := change.PriceDelta.Currency
+	settlement := changeSettlement{
+		Refund: money.New(0, currency),
+		Charge: money.New(0, currency),
+	}
+
+	held, paid := int64(0), false
+	for _, p := range payments {
+		switch {
+		case p.Status == model.PaymentAuthorized:
+			held += p.Amount.Amount
+		case active(p):
+			paid = true
+		}
+	}
+
+	switch {
+	case held > 0:
+		settlement.Held = true
+		if due := change.Price.Total.Amount - held; due > 0 {
+			settlement.Charge.Amount = due
+		}
+	case paid && change.PriceDelta.Amount > 0:
+		settlement.Charge.Amount = change.PriceDelta.Amount
+	case paid:
+		settlement.Refund.Amount = -change.PriceDelta.Amount
+	}
+
+	return settlement
+}
+
+func changeable(reservation *model.Reservation) bool {
+	switch reservation.Status {
+	case model.StatusPending, model.StatusApproved, model.StatusActive:
+		return reservation.ReturnedAt == nil
+	}
+	return false
+}
+
+// validateChange checks the proposed dates. Once the equipment has been
+// picked up only the end date can move.
+func validateChange(reservation *model.Reservation, req *model.CreateReservationChangeRequest) error {
+	if reservation.Status != model.StatusActive {
+		quantity := reservation.Quantity
+		if err := validateBooking(req.StartDate, req.EndDate, &quantity); err != nil {
+			return err
+		}
+	}
+
+	v := validator.New()
+
+	if reservation.Status == model.StatusActive {
+		if !req.StartDate.Equal(reservation.StartDate) {
+			v.AddError("start_date", "cannot change after pickup")
+		}
+		if !req.EndDate.After(time.Now()) {
+			v.AddError("end_date", "must be in the future")
+		}
+		checkRentalLength(v, reservation.StartDate, req.EndDate)
+	}
+	if req.StartDate.Equal(reservation.StartDate) && req.EndDate.Equal(reservation.EndDate) {
+		v.AddError("end_date", "must differ from the current dates")
+	}
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+
+	return nil
+}
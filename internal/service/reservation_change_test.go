@@ -0,0 +1,120 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/pricing"
+)
+
+func TestPriceDelta(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		total   string
+		want    string
+	}{
+		{"extension", "100.00", "140.50", "40.50"},
+		{"shorter", "100.00", "60.00", "-40.00"},
+		{"same price", "100.00", "100.00", "0.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reservation := &model.Reservation{TotalPrice: money.MustParse(tt.current, "EUR")}
+			price := &pricing.Breakdown{Currency: "EUR", Total: money.MustParse(tt.total, "EUR")}
+
+			if got, want := priceDelta(reservation, price), money.MustParse(tt.want, "EUR"); got != want {
+				t.Errorf("expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestSettleChange(t *testing.T) {
+	eur := func(amount string) money.Money { return money.MustParse(amount, "EUR") }
+	change := func(total, delta string) *model.ReservationChange {
+		return &model.ReservationChange{
+			Price:      &pricing.Breakdown{Currency: "EUR", Total: eur(total)},
+			PriceDelta: eur(delta),
+		}
+	}
+
+	hold := func(amount string) *model.Payment {
+		return &model.Payment{Status: model.PaymentAuthorized, Amount: eur(amount)}
+	}
+	captured := func(amount string) *model.Payment {
+		return &model.Payment{Status: model.PaymentCaptured, Amount: eur(amount), Captured: eur(amount)}
+	}
+
+	tests := []struct {
+		name     string
+		payments []*model.Payment
+		change   *model.ReservationChange
+		refund   string
+		charge   string
+		held     bool
+	}{
+		{"no payment", nil, change("150.00", "50.00"), "0.00", "0.00", false},
+		{"failed payment", []*model.Payment{{Status: model.PaymentFailed, Amount: eur("100.00")}}, change("150.00", "50.00"), "0.00", "0.00", false},
+		{"refunded payment", []*model.Payment{{Status: model.PaymentRefunded, Amount: eur("100.00")}}, change("150.00", "50.00"), "0.00", "0.00", false},
+		{"hold covers lower price", []*model.Payment{hold("100.00")}, change("80.00", "-20.00"), "0.00", "0.00", true},
+		{"hold covers price back up to it", []*model.Payment{hold("100.00")}, change("100.00", "20.00"), "0.00", "0.00", true},
+		{"hold too small", []*model.Payment{hold("100.00")}, change("120.00", "20.00"), "0.00", "20.00", true},
+		{"holds from an earlier change", []*model.Payment{hold("100.00"), hold("20.00")}, change("130.00", "10.00"), "0.00", "10.00", true},
+		{"captured, lower price", []*model.Payment{captured("100.00")}, change("70.00", "-30.00"), "30.00", "0.00", false},
+		{"captured, same price", []*model.Payment{captured("100.00")}, change("100.00", "0.00"), "0.00", "0.00", false},
+		{"captured, higher price", []*model.Payment{captured("100.00")}, change("110.00", "10.00"), "0.00", "10.00", false},
+		{"captured twice, higher price", []*model.Payment{captured("100.00"), captured("10.00")}, change("125.00", "15.00"), "0.00", "15.00", false},
+		{"partially refunded, lower price", []*model.Payment{{Status: model.PaymentPartiallyRefunded, Amount: eur("100.00"), Captured: eur("100.00"), Refunded: eur("10.00")}}, change("80.00", "-10.00"), "10.00", "0.00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settlement := settleChange(tt.payments, tt.change)
+			if want := eur(tt.refund); settlement.Refund != want {
+				t.Errorf("expected refund %s, got %s", want, settlement.Refund)
+			}
+			if want := eur(tt.charge); settlement.Charge != want {
+				t.Errorf("expected charge %s, got %s", want, settlement.Charge)
+			}
+			if settlement.Held != tt.held {
+				t.Errorf("expected held %v, got %v", tt.held, settlement.Held)
+			}
+		})
+	}
+}
+
+func TestResetLateReturn(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		late    *model.LateReturn
+		endDate time.Time
+		cleared bool
+	}{
+		{"not late", nil, now.Add(48 * time.Hour), false},
+		{"extended past now", &model.LateReturn{Fee: money.MustParse("25.00", "EUR")}, now.Add(24 * time.Hour), true},
+		{"still overdue", &model.LateReturn{Fee: money.MustParse("25.00", "EUR")}, now.Add(-time.Hour), false},
+		{"ends now", &model.LateReturn{Fee: money.MustParse("25.00", "EUR")}, now, false},
+		{"final fee kept", &model.LateReturn{Fee: money.MustParse("25.00", "EUR"), Final: true}, now.Add(24 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reservation := &model.Reservation{LateReturn: tt.late}
+
+			resetLateReturn(reservation, tt.endDate, now)
+
+			if cleared := tt.late != nil && reservation.LateReturn == nil; cleared != tt.cleared {
+				t.Errorf("expected cleared %v, got %v", tt.cleared, cleared)
+			}
+			if tt.late != nil && !tt.cleared && reservation.LateReturn != tt.late {
+				t.Error("expected the late return to be kept")
+			}
+		})
+	}
+}
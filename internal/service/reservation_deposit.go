@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+var (
+	ErrNoDeposit      = errors.New("reservation has no deposit")
+	ErrDepositNotHeld = errors.New("deposit is not held")
+)
+
+func (s *ReservationService) CaptureDeposit(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, req *model.CaptureDepositRequest) (*model.Reservation, error) {
+	reservation, err := s.getHeldDeposit(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	deposit := reservation.Deposit
+
+	amount, err := validateCapture(deposit, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.paymentService.CaptureDeposit(ctx, reservation, amount); err != nil {
+		return nil, err
+	}
+
+	deposit.Capture(amount, req.Reason, time.Now())
+	if err := s.reservationRepo.UpdateDeposit(ctx, id, deposit); err != nil {
+		return nil, err
+	}
+
+	released, _ := deposit.Amount.Sub(amount)
+	data := map[string]interface{}{
+		"equipment": reservation.Equipment.Name,
+		"amount":    amount,
+		"reason":    req.Reason,
+	}
+	if !released.IsZero() {
+		data["released"] = released
+	}
+	s.createNotification(ctx, reservation.RenterID, model.NotificationDepositCaptured,
+		data, &id, "reservation")
+
+	return reservation, nil
+}
+
+// validateCapture checks the amount an owner keeps of a held deposit: more
+// than nothing and no more than the deposit.
+func validateCapture(deposit *model.Deposit, req *model.CaptureDepositRequest) (money.Money, error) {
+	v := validator.New()
+	v.Required("reason", req.Reason)
+	v.MaxLength("reason", req.Reason, 500)
+	amount := parsePrice(v, "amount", req.Amount, deposit.Amount.Currency)
+	if req.Amount == nil {
+		v.AddError("amount", "is required")
+	} else if amount != nil {
+		if amount.IsZero() {
+			v.AddError("amount", "must be greater than zero")
+		} else if amount.Amount > deposit.Amount.Amount {
+			v.AddError("amount", "must not exceed the deposit of "+deposit.Amount.Format())
+		}
+	}
+	if v.Errors().HasErrors() {
+		return money.Money{}, v.Errors()
+	}
+
+	return *amount, nil
+}
+
+func (s *ReservationService) ReleaseDeposit(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) (*model.Reservation, error) {
+	reservation, err := s.getHeldDeposit(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.releaseDeposit(ctx, reservation); err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (s *ReservationService) getHeldDeposit(ctx context.Context, id uuid.UUID, ownerID uuid.UUID) (*model.Reservation, error) {
+	reservation, err := s.reservationRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+
+	if reservation.Equipment.OwnerID != ownerID {
+		return nil, ErrNotAuthorized
+	}
+
+	if reservation.Deposit == nil {
+		return nil, ErrNoDeposit
+	}
+
+	if reservation.Deposit.Status != model.DepositHeld {
+		return nil, ErrDepositNotHeld
+	}
+
+	return reservation, nil
+}
+
+// newDeposit returns the deposit owed for quantity units of equipment, or
+// nil when the listing does not ask for one.
+func newDeposit(equipment *model.Equipment, quantity int) *model.Deposit {
+	if equipment.DepositAmount == nil || equipment.DepositAmount.IsZero() {
+		return nil
+	}
+
+	amount := equipment.DepositAmount.Multiply(int64(quantity))
+	return &model.Deposit{
+		Status:   model.DepositRequired,
+		Amount:   amount,
+		Captured: money.New(0, amount.Currency),
+	}
+}
+
+// holdDeposit marks an approved reservation's deposit held and tells the
+// renter. On prepaid listings that waits for the provider to hold it, so
+// the deposit stays required without an authorized deposit payment; on
+// other listings the deposit is only recorded.
+func (s *ReservationService) holdDeposit(ctx context.Context, reservation *model.Reservation, equipment *model.Equipment) error {
+	deposit := reservation.Deposit
+	if deposit == nil || deposit.Status != model.DepositRequired {
+		return nil
+	}
+
+	if equipment.RequirePrepayment {
+		held, err := s.paymentService.DepositHeld(ctx, reservation.ID)
+		if err != nil || !held {
+			return err
+		}
+	}
+
+	deposit.Status = model.DepositHeld
+	if err := s.reservationRepo.UpdateDeposit(ctx, reservation.ID, deposit); err != nil {
+		deposit.Status = model.DepositRequired
+		return err
+	}
+
+	s.createNotification(ctx, reservation.RenterID, model.NotificationDepositHeld,
+		map[string]interface{}{"equipment": equipment.Name, "amount": deposit.Amount},
+		&reservation.ID, "reservation")
+	return nil
+}
+
+// releaseDeposit returns an unsettled deposit in full, on rejection,
+// cancellation, expiry or completion. The renter is only notified when
+// money was actually held.
+func (s *ReservationService) releaseDeposit(ctx context.Context, reservation *model.Reservation) error {
+	deposit := reservation.Deposit
+	if deposit == nil || deposit.Settled() {
+		return nil
+	}
+
+	if _, err := s.paymentService.ReleaseDeposit(ctx, reservation); err != nil {
+		return err
+	}
+
+	wasHeld := deposit.Release(time.Now())
+	if err := s.reservationRepo.UpdateDeposit(ctx, reservation.ID, deposit); err != nil {
+		return err
+	}
+
+	if wasHeld {
+		s.createNotification(ctx, reservation.RenterID, model.NotificationDepositReleased,
+			map[string]interface{}{"equipment": reservation.Equipment.Name, "amount": deposit.Amount},
+			&reservation.ID, "reservation")
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+)
+
+func TestNewDeposit(t *testing.T) {
+	perUnit := money.MustParse("150.00", "EUR")
+	zero := money.New(0, "EUR")
+
+	tests := []struct {
+		name     string
+		amount   *money.Money
+		quantity int
+		want     string
+	}{
+		{"no deposit", nil, 1, ""},
+		{"zero deposit", &zero, 2, ""},
+		{"one unit", &perUnit, 1, "150.00"},
+		{"per unit booked", &perUnit, 3, "450.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeposit(&model.Equipment{DepositAmount: tt.amount}, tt.quantity)
+
+			if tt.want == "" {
+				if d != nil {
+					t.Fatalf("expected no deposit, got %+v", d)
+				}
+				return
+			}
+			if d == nil {
+				t.Fatal("expected a deposit")
+			}
+			if d.Status != model.DepositRequired {
+				t.Errorf("expected status required, got %s", d.Status)
+			}
+			if want := money.MustParse(tt.want, "EUR"); d.Amount != want {
+				t.Errorf("expected %s, got %s", want, d.Amount)
+			}
+			if !d.Captured.IsZero() || d.Captured.Currency != "EUR" {
+				t.Errorf("expected nothing captured in EUR, got %s", d.Captured)
+			}
+		})
+	}
+}
+
+func TestValidateCapture(t *testing.T) {
+	amount := func(s string) *json.Number {
+		n := json.Number(s)
+		return &n
+	}
+
+	tests := []struct {
+		name    string
+		req     model.CaptureDepositRequest
+		want    string
+		invalid bool
+	}{
+		{"part of the deposit", model.CaptureDepositRequest{Amount: amount("49.99"), Reason: "cracked screen"}, "49.99", false},
+		{"the whole deposit", model.CaptureDepositRequest{Amount: amount("200"), Reason: "not returned"}, "200.00", false},
+		{"more than the deposit", model.CaptureDepositRequest{Amount: amount("200.01"), Reason: "cracked screen"}, "", true},
+		{"zero", model.CaptureDepositRequest{Amount: amount("0"), Reason: "cracked screen"}, "", true},
+		{"negative", model.CaptureDepositRequest{Amount: amount("-5"), Reason: "cracked screen"}, "", true},
+		{"too precise", model.CaptureDepositRequest{Amount: amount("10.005"), Reason: "cracked screen"}, "", true},
+		{"missing amount", model.CaptureDepositRequest{Reason: "cracked screen"}, "", true},
+		{"missing reason", model.CaptureDepositRequest{Amount: amount("10")}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deposit := &model.Deposit{
+				Status:   model.DepositHeld,
+				Amount:   money.MustParse("200.00", "EUR"),
+				Captured: money.New(0, "EUR"),
+			}
+
+			got, err := validateCapture(deposit, &tt.req)
+
+			if tt.invalid {
+				var validationErrors validator.ValidationErrors
+				if !errors.As(err, &validationErrors) {
+					t.Fatalf("expected validation errors, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := money.MustParse(tt.want, "EUR"); got != want {
+				t.Errorf("expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestApplyPriceUpdate_Deposit(t *testing.T) {
+	deposit := money.MustParse("150.00", "EUR")
+	amount := func(s string) *json.Number {
+		n := json.Number(s)
+		return &n
+	}
+
+	tests := []struct {
+		name     string
+		req      model.UpdateEquipmentRequest
+		want     string
+		currency string
+		invalid  bool
+	}{
+		{"unchanged", model.UpdateEquipmentRequest{}, "150.00", "EUR", false},
+		{"new amount", model.UpdateEquipmentRequest{DepositAmount: amount("80.50")}, "80.50", "EUR", false},
+		{"currency change with the amount", model.UpdateEquipmentRequest{Currency: "USD", DepositAmount: amount("160.00")}, "160.00", "USD", false},
+		{"currency change without the amount", model.UpdateEquipmentRequest{Currency: "USD"}, "", "", true},
+		{"same currency sent again", model.UpdateEquipmentRequest{Currency: "EUR"}, "150.00", "EUR", false},
+		{"negative", model.UpdateEquipmentRequest{DepositAmount: amount("-1")}, "", "", true},
+		{"too precise", model.UpdateEquipmentRequest{DepositAmount: amount("10.005")}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equipment := &model.Equipment{Currency: "EUR", DepositAmount: &deposit}
+
+			err := applyPriceUpdate(equipment, &tt.req)
+
+			if tt.invalid {
+				var validationErrors validator.ValidationErrors
+				if !errors.As(err, &validationErrors) {
+					t.Fatalf("expected validation errors, got %v", err)
+				}
+				if equipment.DepositAmount != &deposit {
+					t.Error("expected the deposit to be left as it was")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := money.MustParse(tt.want, tt.currency); equipment.DepositAmount == nil || *equipment.DepositAmount != want {
+				t.Errorf("expected deposit %s, got %v", want, equipment.DepositAmount)
+			}
+		})
+	}
+}
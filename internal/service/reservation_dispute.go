@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+// maxDisputeText bounds the reason and resolution kept in the history.
+const maxDisputeText = 1000
+
+// Dispute holds a returned reservation open while the renter and owner
+// disagree about the return, for example over damage. Either of them may
+// open it. A disputed reservation is not completed automatically and its
+// deposit stays held until the dispute is resolved.
+func (s *ReservationService) Dispute(ctx context.Context, id uuid.UUID, userID uuid.UUID, req *model.DisputeReservationRequest) (*model.Reservation, error) {
+	if err := validateDisputeText("reason", req.Reason); err != nil {
+		return nil, err
+	}
+
+	reservation, err := s.reservationRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+
+	if reservation.RenterID != userID && reservation.Equipment.OwnerID != userID {
+		return nil, ErrNotAuthorized
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if err := s.transition(ctx, reservation, model.StatusDisputed, &userID, reason); err != nil {
+		return nil, err
+	}
+
+	notifyUserID := reservation.Equipment.OwnerID
+	if userID == reservation.Equipment.OwnerID {
+		notifyUserID = reservation.RenterID
+	}
+
+	s.createNotification(ctx, notifyUserID, model.NotificationReservationDisputed,
+		map[string]interface{}{"equipment": reservation.Equipment.Name, "reason": reason},
+		&id, "reservation")
+
+	return reservation, nil
+}
+
+// ResolveDispute completes a disputed reservation. The owner or an admin
+// resolves it, after capturing whatever part of the deposit the dispute
+// settled on; the rest of the deposit is released in the background.
+func (s *ReservationService) ResolveDispute(ctx context.Context, id uuid.UUID, userID uuid.UUID, admin bool, req *model.ResolveDisputeRequest) (*model.Reservation, error) {
+	if err := validateDisputeText("resolution", req.Resolution); err != nil {
+		return nil, err
+	}
+
+	reservation, err := s.reservationRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+
+	if reservation.Equipment.OwnerID != userID && !admin {
+		return nil, ErrNotAuthorized
+	}
+
+	if reservation.Status != model.StatusDisputed {
+		return nil, fmt.Errorf("%w: %s is not disputed", ErrInvalidTransition, reservation.Status)
+	}
+
+	settle, err := s.settlement(ctx, reservation, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.transition(ctx, reservation, model.StatusCompleted, &userID, strings.TrimSpace(req.Resolution), settle); err != nil {
+		return nil, err
+	}
+
+	for _, recipient := range []uuid.UUID{reservation.RenterID, reservation.Equipment.OwnerID} {
+		if recipient == userID {
+			continue
+		}
+		s.createNotification(ctx, recipient, model.NotificationReservationCompleted,
+			map[string]interface{}{"equipment": reservation.Equipment.Name},
+			&id, "reservation")
+	}
+
+	return reservation, nil
+}
+
+func validateDisputeText(field, text string) error {
+	v := validator.New()
+	v.Required(field, text)
+	if len(text) > maxDisputeText {
+		v.AddError(field, fmt.Sprintf("must be at most %d characters", maxDisputeText))
+	}
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+)
+
+func TestValidateDisputeText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		valid bool
+	}{
+		{"reason given", "Lens returned scratched", true},
+		{"empty", "", false},
+		{"blank", "   ", false},
+		{"at the limit", strings.Repeat("a", maxDisputeText), true},
+		{"too long", strings.Repeat("a", maxDisputeText+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDisputeText("reason", tt.text)
+			if tt.valid {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var validationErrors validator.ValidationErrors
+			if !errors.As(err, &validationErrors) || validationErrors[0].Field != "reason" {
+				t.Errorf("expected a reason validation error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+const (
+	maxHandoverPhotos  = 10
+	maxChecklistItems  = 50
+	maxSignatureLength = 256 << 10
+)
+
+// Pickup records the equipment leaving the owner and moves the reservation
+// to active.
+func (s *ReservationService) Pickup(ctx context.Context, id uuid.UUID, userID uuid.UUID, req *model.HandoverRequest, photos []Upload) (*model.HandoverReport, error) {
+	return s.handover(ctx, id, userID, model.HandoverPickup, req, photos)
+}
+
+// Return records the equipment coming back and moves the reservation to
+// returned.
+func (s *ReservationService) Return(ctx context.Context, id uuid.UUID, userID uuid.UUID, req *model.HandoverRequest, photos []Upload) (*model.HandoverReport, error) {
+	return s.handover(ctx, id, userID, model.HandoverReturn, req, photos)
+}
+
+func (s *ReservationService) ListHandovers(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]model.HandoverReport, error) {
+	if _, err := s.getParticipantReservation(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.reservationRepo.ListHandovers(ctx, id)
+}
+
+func (s *ReservationService) handover(ctx context.Context, id uuid.UUID, userID uuid.UUID, kind model.HandoverKind, req *model.HandoverRequest, photos []Upload) (*model.HandoverReport, error) {
+	if err := validateHandover(req, len(photos)); err != nil {
+		return nil, err
+	}
+
+	reservation, err := s.getParticipantReservation(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := model.StatusActive
+	if kind == model.HandoverReturn {
+		to = model.StatusReturned
+	}
+	if err := checkTransition(reservation, to); err != nil {
+		return nil, err
+	}
+
+	if kind == model.HandoverReturn && req.MeterReading != nil {
+		if err := s.checkMeterReading(ctx, id, req); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	report := &model.HandoverReport{
+		ReservationID:   id,
+		Kind:            kind,
+		RecordedBy:      userID,
+		RecordedAt:      now,
+		MeterType:       req.MeterType,
+		MeterReading:    req.MeterReading,
+		FuelLevel:       req.FuelLevel,
+		Checklist:       req.Checklist,
+		Notes:           req.Notes,
+		Photos:          []string{},
+		RenterSignature: *req.RenterSignature,
+		OwnerSignature:  *req.OwnerSignature,
+	}
+	if report.Checklist == nil {
+		report.Checklist = []model.ChecklistItem{}
+	}
+	report.RenterSignature.SignedAt = now
+	report.OwnerSignature.SignedAt = now
+
+	var saved []string
+	removeSaved := func() {
+		for _, path := range saved {
+			os.Remove(path)
+		}
+	}
+	for _, photo := range photos {
+		url, path, err := saveUpload(s.uploadPath, photo.Content, photo.Filename)
+		if err != nil {
+			removeSaved()
+			return nil, err
+		}
+		saved = append(saved, path)
+		report.Photos = append(report.Photos, url)
+	}
+
+	if kind == model.HandoverPickup {
+		reservation.PickedUpAt = &now
+	} else {
+		reservation.ReturnedAt = &now
+		if now.After(reservation.EndDate) {
+			if err := s.accrueLateFee(ctx, reservation, now); err != nil {
+				removeSaved()
+				return nil, err
+			}
+			reservation.LateReturn.Final = true
+		}
+	}
+
+	event := newStatusEvent(reservation, to, &userID, "")
+	if err := s.reservationRepo.RecordHandover(ctx, reservation, event, report); err != nil {
+		removeSaved()
+		return nil, transitionError(err)
+	}
+	reservation.Status = to
+
+	notifyUserID := reservation.Equipment.OwnerID
+	if userID == reservation.Equipment.OwnerID {
+		notifyUserID = reservation.RenterID
+	}
+	if kind == model.HandoverPickup {
+		s.createNotification(ctx, notifyUserID, model.NotificationEquipmentPickedUp,
+			map[string]interface{}{"equipment": reservation.Equipment.Name},
+			&id, "reservation")
+	} else {
+		data := map[string]interface{}{"equipment": reservation.Equipment.Name}
+		if reservation.LateReturn != nil {
+			data["late_fee"] = reservation.LateReturn.Fee
+		}
+		s.createNotification(ctx, notifyUserID, model.NotificationEquipmentReturned,
+			data,
+			&id, "reservation")
+	}
+
+	return report, nil
+}
+
+// checkMeterReading rejects a return reading below the one taken at pickup.
+func (s *ReservationService) checkMeterReading(ctx context.Context, id uuid.UUID, req *model.HandoverRequest) error {
+	reports, err := s.reservationRepo.ListHandovers(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		if report.Kind == model.HandoverPickup && report.MeterType == req.MeterType &&
+			report.MeterReading != nil && *req.MeterReading < *report.MeterReading {
+			v := validator.New()
+			v.AddError("meter_reading", "must not be below the pickup reading of "+strconv.FormatFloat(*report.MeterReading, 'f', -1, 64))
+			return v.Errors()
+		}
+	}
+	return nil
+}
+
+func (s *ReservationService) getParticipantReservation(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*model.Reservation, error) {
+	reservation, err := s.reservationRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+
+	if reservation.RenterID != userID && reservation.Equipment.OwnerID != userID {
+		return nil, ErrNotAuthorized
+	}
+
+	return reservation, nil
+}
+
+func validateHandover(req *model.HandoverRequest, photos int) error {
+	v := validator.New()
+
+	if req.MeterType != "" && !req.MeterType.Valid() {
+		v.AddError("meter_type", "must be hours or odometer")
+	}
+	if req.MeterReading != nil {
+		if req.MeterType == "" {
+			v.AddError("meter_type", "is required with meter_reading")
+		}
+		if *req.MeterReading < 0 {
+			v.AddError("meter_reading", "must not be negative")
+		}
+	}
+	if req.FuelLevel != nil && (*req.FuelLevel < 0 || *req.FuelLevel > 100) {
+		v.AddError("fuel_level", "must be between 0 and 100")
+	}
+
+	if len(req.Checklist) > maxChecklistItems {
+		v.AddError("checklist", "must have at most "+strconv.Itoa(maxChecklistItems)+" items")
+	}
+	for _, item := range req.Checklist {
+		if strings.TrimSpace(item.Item) == "" {
+			v.AddError("checklist", "items must have a name")
+			break
+		}
+	}
+	v.MaxLength("notes", req.Notes, 2000)
+
+	if photos > maxHandoverPhotos {
+		v.AddError("photos", "must have at most "+strconv.Itoa(maxHandoverPhotos)+" files")
+	}
+
+	validateSignature(v, "renter_signature", req.RenterSignature)
+	validateSignature(v, "owner_signature", req.OwnerSignature)
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+	return nil
+}
+
+func validateSignature(v *validator.Validator, field string, signature *model.Signature) {
+	if signature == nil {
+		v.AddError(field, "is required")
+		return
+	}
+	v.Required(field+".name", signature.Name)
+	if !strings.HasPrefix(signature.Image, "data:image/") {
+		v.AddError(field+".image", "must be an image data URL")
+	} else if len(signature.Image) > maxSignatureLength {
+		v.AddError(field+".image", "is too large")
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/pricing"
+)
+
+// collisionWarningWindow is how far ahead of the next booking its renter is
+// warned that the equipment is still out.
+const collisionWarningWindow = 24 * time.Hour
+
+// ProcessLateReturns accrues late fees on reservations past their end date
+// with no return recorded, and warns the people affected. It returns the
+// number of overdue reservations processed; one failing does not stop the
+// others.
+func (s *ReservationService) ProcessLateReturns(ctx context.Context, now time.Time) (int, error) {
+	ids, err := s.reservationRepo.ListOverdue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.forEach(ctx, ids, func(reservation *model.Reservation) (bool, error) {
+		return true, s.processLateReturn(ctx, reservation, now)
+	})
+}
+
+func (s *ReservationService) processLateReturn(ctx context.Context, reservation *model.Reservation, now time.Time) error {
+	id := reservation.ID
+	equipment, err := s.equipmentRepo.GetByID(ctx, reservation.EquipmentID)
+	if err != nil {
+		return err
+	}
+
+	firstDetected := reservation.LateReturn == nil
+	if err := s.accrueLateFee(ctx, reservation, now); err != nil {
+		return err
+	}
+	late := reservation.LateReturn
+
+	upcoming, err := s.reservationRepo.ListUpcoming(ctx, equipment.ID, reservation.ID, reservation.EndDate, now.Add(collisionWarningWindow))
+	if err != nil {
+		return err
+	}
+
+	var collisions []*model.Reservation
+	for _, next := range upcoming {
+		if slices.Contains(late.WarnedReservationIDs, next.ID) {
+			continue
+		}
+		collides, err := s.collides(ctx, equipment, reservation, next)
+		if err != nil {
+			return err
+		}
+		if collides {
+			collisions = append(collisions, next)
+			late.WarnedReservationIDs = append(late.WarnedReservationIDs, next.ID)
+		}
+	}
+
+	// Saved before notifying so a failed save does not repeat the
+	// notifications on the next run.
+	if err := s.reservationRepo.UpdateLateReturn(ctx, id, late); err != nil {
+		return err
+	}
+
+	if firstDetected {
+		data := map[string]interface{}{
+			"equipment": reservation.Equipment.Name,
+			"renter":    reservation.Renter.Name,
+			"due_at":    reservation.EndDate,
+		}
+		s.createNotification(ctx, reservation.RenterID, model.NotificationReturnOverdue,
+			withRecipient(data, "renter"),
+			&id, "reservation")
+		s.createNotification(ctx, equipment.OwnerID, model.NotificationReturnOverdue,
+			withRecipient(data, "owner"),
+			&id, "reservation")
+	}
+
+	for _, next := range collisions {
+		data := map[string]interface{}{"equipment": equipment.Name, "start_date": next.StartDate}
+		s.createNotification(ctx, equipment.OwnerID, model.NotificationReturnConflict,
+			withRecipient(data, "owner"),
+			&next.ID, "reservation")
+		s.createNotification(ctx, next.RenterID, model.NotificationReturnConflict,
+			withRecipient(data, "renter"),
+			&next.ID, "reservation")
+	}
+
+	return nil
+}
+
+// accrueLateFee sets the late fee owed on reservation for the time it has
+// been kept past its end date, up to until.
+func (s *ReservationService) accrueLateFee(ctx context.Context, reservation *model.Reservation, until time.Time) error {
+	rules, err := s.equipmentRepo.ListPricingRules(ctx, reservation.EquipmentID, true)
+	if err != nil {
+		return err
+	}
+
+	pricingRules := make([]pricing.Rule, len(rules))
+	for i := range rules {
+		pricingRules[i] = rules[i].ToRule()
+	}
+
+	period := pricing.Period{
+		Start:    reservation.EndDate,
+		End:      until,
+		Quantity: reservation.Quantity,
+	}
+	fee, err := pricing.LateFee(pricing.Rates{
+		Hour: reservation.Equipment.PricePerHour,
+		Day:  reservation.Equipment.PricePerDay,
+		Week: reservation.Equipment.PricePerWeek,
+	}, pricingRules, period)
+	if errors.Is(err, pricing.ErrNoRates) {
+		fee = money.New(0, reservation.TotalPrice.Currency)
+	} else if err != nil {
+		return err
+	}
+
+	if reservation.LateReturn == nil {
+		reservation.LateReturn = &model.LateReturn{DetectedAt: until}
+	}
+	reservation.LateReturn.OverdueHours = period.Hours()
+	reservation.LateReturn.Fee = fee
+	reservation.LateReturn.AccruedAt = until
+
+	return nil
+}
+
+// collides reports whether next cannot go ahead while reservation's
+// equipment is still out: for serialized equipment when they share a unit,
+// otherwise when there is not enough stock left for both.
+func (s *ReservationService) collides(ctx context.Context, equipment *model.Equipment, reservation, next *model.Reservation) (bool, error) {
+	if equipment.InventoryMode == model.InventorySerialized {
+		for _, unitID := range next.UnitIDs {
+			if slices.Contains(reservation.UnitIDs, unitID) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	reserved, err := s.equipmentRepo.ReservedQuantities(ctx, equipment.ID, next.StartDate, next.EndDate)
+	if err != nil {
+		return false, err
+	}
+
+	return model.PeakQuantity(reserved, next.StartDate, next.EndDate)+reservation.Quantity > equipment.Quantity, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+// Pay authorizes the renter's payment for a pending reservation on a
+// listing that requires prepayment. Auto-approve listings are approved as
+// soon as the authorization succeeds.
+func (s *ReservationService) Pay(ctx context.Context, id uuid.UUID, renterID uuid.UUID, req *model.PayReservationRequest) (*model.Reservation, error) {
+	v := validator.New()
+	v.Required("payment_method", req.PaymentMethod)
+	if v.Errors().HasErrors() {
+		return nil, v.Errors()
+	}
+
+	reservation, err := s.reservationRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+
+	if err := checkPayable(reservation, renterID); err != nil {
+		return nil, err
+	}
+
+	if err := s.pay(ctx, reservation, reservation.Equipment, req.PaymentMethod); err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// checkPayable reports why the renter cannot pay for a reservation now:
+// only its own renter pays, only listings requiring prepayment take it, and
+// only before the owner decides.
+func checkPayable(reservation *model.Reservation, renterID uuid.UUID) error {
+	if reservation.RenterID != renterID {
+		return ErrNotAuthorized
+	}
+
+	if !reservation.Equipment.RequirePrepayment {
+		return ErrPaymentNotRequired
+	}
+
+	if reservation.Status != model.StatusPending {
+		return ErrReservationNotPending
+	}
+
+	return nil
+}
+
+func (s *ReservationService) ListPayments(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*model.ReservationPayments, error) {
+	reservation, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.paymentService.List(ctx, reservation.ID)
+}
+
+func (s *ReservationService) pay(ctx context.Context, reservation *model.Reservation, equipment *model.Equipment, paymentMethod string) error {
+	payment, err := s.paymentService.Authorize(ctx, reservation, paymentMethod)
+	reservation.Payment = payment
+	if err != nil {
+		return err
+	}
+
+	// The deposit is held on the same payment method. When that fails the
+	// rental hold is voided too, so the renter retries both together.
+	if _, err := s.paymentService.AuthorizeDeposit(ctx, reservation, paymentMethod); err != nil {
+		payment, voidErr := s.paymentService.Refund(ctx, reservation, reservation.TotalPrice)
+		if voidErr != nil {
+			return errors.Join(err, voidErr)
+		}
+		reservation.Payment = payment
+		return err
+	}
+
+	if equipment.AutoApprove {
+		return s.approve(ctx, reservation, equipment, nil)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+)
+
+func TestCheckPayable(t *testing.T) {
+	renterID := uuid.New()
+
+	tests := []struct {
+		name       string
+		renter     uuid.UUID
+		prepayment bool
+		status     model.ReservationStatus
+		wantErr    error
+	}{
+		{"pending prepaid reservation", renterID, true, model.StatusPending, nil},
+		{"someone else", uuid.New(), true, model.StatusPending, ErrNotAuthorized},
+		{"listing without prepayment", renterID, false, model.StatusPending, ErrPaymentNotRequired},
+		{"already approved", renterID, true, model.StatusApproved, ErrReservationNotPending},
+		{"cancelled", renterID, true, model.StatusCancelled, ErrReservationNotPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reservation := &model.Reservation{
+				RenterID:  renterID,
+				Status:    tt.status,
+				Equipment: &model.Equipment{RequirePrepayment: tt.prepayment},
+			}
+
+			if err := checkPayable(reservation, tt.renter); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
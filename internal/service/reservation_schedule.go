@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+// ExpireStalePending expires pending reservations the owner never decided
+// on, either because their start date has passed or because they have
+// waited longer than maxAge. Deposits and authorized payments are released
+// as on rejection. It returns the number of reservations expired.
+func (s *ReservationService) ExpireStalePending(ctx context.Context, now time.Time, maxAge time.Duration) (int, error) {
+	ids, err := s.reservationRepo.ListStalePending(ctx, now, now.Add(-maxAge))
+	if err != nil {
+		return 0, err
+	}
+
+	return s.forEach(ctx, ids, func(reservation *model.Reservation) (bool, error) {
+		settle, err := s.settlement(ctx, reservation, &reservation.TotalPrice)
+		if err != nil {
+			return false, err
+		}
+
+		if err := s.transition(ctx, reservation, model.StatusExpired, nil, "not approved in time", settle); err != nil {
+			return false, err
+		}
+
+		data := map[string]interface{}{"equipment": reservation.Equipment.Name}
+		s.createNotification(ctx, reservation.RenterID, model.NotificationReservationExpired,
+			withRecipient(data, "renter"),
+			&reservation.ID, "reservation")
+		s.createNotification(ctx, reservation.Equipment.OwnerID, model.NotificationReservationExpired,
+			withRecipient(data, "owner"),
+			&reservation.ID, "reservation")
+		return true, nil
+	})
+}
+
+// SendReminders reminds renters of pickups and returns due within lead.
+// Each reminder is sent once. It returns the number of reminders sent.
+func (s *ReservationService) SendReminders(ctx context.Context, now time.Time, lead time.Duration) (int, error) {
+	sent := 0
+	var errs []error
+	for _, kind := range []repository.ReminderKind{repository.ReminderPickup, repository.ReminderReturn} {
+		ids, err := s.reservationRepo.ListDueReminders(ctx, kind, now, now.Add(lead))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		n, err := s.forEach(ctx, ids, func(reservation *model.Reservation) (bool, error) {
+			marked, err := s.reservationRepo.MarkReminded(ctx, reservation.ID, kind, now)
+			if err != nil || !marked {
+				return false, err
+			}
+
+			data := map[string]interface{}{
+				"equipment": reservation.Equipment.Name,
+				"kind":      "pickup",
+				"due_at":    reservation.StartDate,
+			}
+			if kind == repository.ReminderReturn {
+				data["kind"] = "return"
+				data["due_at"] = reservation.EndDate
+			}
+			s.createNotification(ctx, reservation.RenterID, model.NotificationReservationReminder,
+				data,
+				&reservation.ID, "reservation")
+			return true, nil
+		})
+		sent += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// AutoComplete completes reservations returned more than after ago without
+// a dispute, releasing any deposit the owner has not captured. It returns
+// the number of reservations completed.
+func (s *ReservationService) AutoComplete(ctx context.Context, now time.Time, after time.Duration) (int, error) {
+	ids, err := s.reservationRepo.ListReturnedBefore(ctx, now.Add(-after))
+	if err != nil {
+		return 0, err
+	}
+
+	return s.forEach(ctx, ids, func(reservation *model.Reservation) (bool, error) {
+		settle, err := s.settlement(ctx, reservation, nil)
+		if err != nil {
+			return false, err
+		}
+
+		if err := s.transition(ctx, reservation, model.StatusCompleted, nil, "completed automatically after return", settle); err != nil {
+			return false, err
+		}
+
+		s.createNotification(ctx, reservation.RenterID, model.NotificationReservationCompleted,
+			map[string]interface{}{"equipment": reservation.Equipment.Name},
+			&reservation.ID, "reservation")
+		return true, nil
+	})
+}
+
+// forEach loads each reservation and applies fn, carrying on past
+// failures. It returns how many calls reported doing something. A
+// reservation that changed status since it was listed is skipped.
+func (s *ReservationService) forEach(ctx context.Context, ids []uuid.UUID, fn func(*model.Reservation) (bool, error)) (int, error) {
+	done := 0
+	var errs []error
+	for _, id := range ids {
+		reservation, err := s.reservationRepo.GetByID(ctx, id)
+		if err == nil {
+			var ok bool
+			ok, err = fn(reservation)
+			if ok {
+				done++
+			}
+		}
+		if errors.Is(err, repository.ErrReservationNotFound) || errors.Is(err, ErrInvalidTransition) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reservation %s: %w", id, err))
+		}
+	}
+
+	return done, errors.Join(errs...)
+}
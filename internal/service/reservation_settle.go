@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
+	"github.com/abneribeiro/goapi/internal/queue"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+// JobSettleReservation returns what an ended reservation still holds of the
+// renter's money: it releases the deposit and refunds the rental payment.
+const JobSettleReservation = "reservation.settle"
+
+// settleAttempts keeps a settlement retrying for about two days at the
+// longest backoff before it is dead-lettered for an admin to retry.
+const settleAttempts = 50
+
+type settleReservationPayload struct {
+	ReservationID uuid.UUID `json:"reservation_id"`
+	// Refunded is the total the rental payments have refunded once
+	// settled. Nil leaves them alone.
+	Refunded *money.Money `json:"refunded,omitempty"`
+}
+
+// RegisterJobs sets the queue handlers for reservation jobs.
+func (s *ReservationService) RegisterJobs(q *queue.Queue) {
+	queue.Handle(q, JobSettleReservation, s.settle)
+}
+
+// settlement builds the job that settles a reservation, to be saved with
+// the transition that ends it. refund is how much more of the rental
+// payments to return, or nil for none.
+func (s *ReservationService) settlement(ctx context.Context, reservation *model.Reservation, refund *money.Money) (*model.Job, error) {
+	payload := settleReservationPayload{ReservationID: reservation.ID}
+	if refund != nil {
+		refunded, err := s.paymentService.RefundTarget(ctx, reservation.ID, *refund)
+		if err != nil {
+			return nil, err
+		}
+		payload.Refunded = &refunded
+	}
+
+	return s.jobQueue.NewJob(JobSettleReservation, payload, queue.MaxAttempts(settleAttempts))
+}
+
+// settle runs a settlement job. Both steps can be repeated safely, so a
+// job that failed half way is simply run again.
+func (s *ReservationService) settle(ctx context.Context, payload settleReservationPayload) error {
+	reservation, err := s.reservationRepo.GetByID(ctx, payload.ReservationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			return queue.Permanent(err)
+		}
+		return err
+	}
+
+	if err := s.releaseDeposit(ctx, reservation); err != nil {
+		return err
+	}
+
+	if payload.Refunded != nil {
+		if _, err := s.paymentService.RefundTo(ctx, reservation, *payload.Refunded); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
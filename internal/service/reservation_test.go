@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+)
+
+func TestValidateBooking(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		field string
+	}{
+		{"one day", start, start.Add(24 * time.Hour), ""},
+		{"longest rental", start, start.Add(maxRentalDays * 24 * time.Hour), ""},
+		{"too long", start, start.Add((maxRentalDays*24 + 1) * time.Hour), "end_date"},
+		{"end before start", start, start.Add(-time.Hour), "end_date"},
+		{"in the past", start.AddDate(0, 0, -7), start.AddDate(0, 0, -6), "start_date"},
+		{"missing end", start, time.Time{}, "end_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quantity := 0
+			err := validateBooking(tt.start, tt.end, &quantity)
+
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if quantity != 1 {
+					t.Errorf("expected quantity to default to 1, got %d", quantity)
+				}
+				return
+			}
+
+			var validationErrors validator.ValidationErrors
+			if !errors.As(err, &validationErrors) || validationErrors[0].Field != tt.field {
+				t.Errorf("expected a %s validation error, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestEndedUnapproved(t *testing.T) {
+	tests := []struct {
+		status model.ReservationStatus
+		want   bool
+	}{
+		{model.StatusRejected, true},
+		{model.StatusCancelled, true},
+		{model.StatusExpired, true},
+		{model.StatusPending, false},
+		{model.StatusApproved, false},
+		{model.StatusActive, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := endedUnapproved(tt.status); got != tt.want {
+				t.Errorf("endedUnapproved(%s) = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
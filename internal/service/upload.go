@@ -0,0 +1,39 @@
+package service
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// Upload is a file received with a request.
+type Upload struct {
+	Filename string
+	Content  io.Reader
+}
+
+// saveUpload stores an uploaded file under dir with a random name, keeping
+// the original extension. It returns the public URL and the path on disk.
+func saveUpload(dir string, file io.Reader, filename string) (string, string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+
+	newFilename := uuid.New().String() + filepath.Ext(filename)
+	filePath := filepath.Join(dir, newFilename)
+
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return "", "", err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, file); err != nil {
+		os.Remove(filePath)
+		return "", "", err
+	}
+
+	return "/uploads/" + newFilename, filePath, nil
+}
@@ -6,7 +6,9 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/abneribeiro/goapi/internal/i18n"
 	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
 	"github.com/abneribeiro/goapi/internal/repository"
 )
 
@@ -48,6 +50,13 @@ func (s *UserService) Update(ctx context.Context, id uuid.UUID, req *model.Updat
 	if req.Phone != "" {
 		user.Phone = req.Phone
 	}
+	if req.Locale != "" {
+		v := validator.New()
+		if v.InList("locale", req.Locale, i18n.Locales()); v.Errors().HasErrors() {
+			return nil, v.Errors()
+		}
+		user.Locale = req.Locale
+	}
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, err
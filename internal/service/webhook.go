@@ -0,0 +1,466 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/abneribeiro/goapi/internal/config"
+	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/outbox"
+	"github.com/abneribeiro/goapi/internal/pkg/egress"
+	"github.com/abneribeiro/goapi/internal/pkg/pagination"
+	"github.com/abneribeiro/goapi/internal/pkg/signer"
+	"github.com/abneribeiro/goapi/internal/pkg/validator"
+	"github.com/abneribeiro/goapi/internal/queue"
+	"github.com/abneribeiro/goapi/internal/repository"
+)
+
+var ErrWebhookNotFound = errors.New("webhook subscription not found")
+
+// JobDeliverWebhook sends one logged delivery to its subscription.
+const JobDeliverWebhook = "webhook.deliver"
+
+// maxResponseBody is how much of a successful receiver's response is kept
+// in the delivery log. Failed responses are not kept, so a URL cannot be
+// used to read whatever answers at it.
+const maxResponseBody = 2048
+
+type deliverWebhookPayload struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+type WebhookService struct {
+	webhookRepo   *repository.WebhookRepository
+	equipmentRepo *repository.EquipmentRepository
+	jobQueue      *queue.Queue
+	client        *http.Client
+	cfg           config.WebhookConfig
+}
+
+func NewWebhookService(
+	webhookRepo *repository.WebhookRepository,
+	equipmentRepo *repository.EquipmentRepository,
+	jobQueue *queue.Queue,
+	cfg config.WebhookConfig,
+) *WebhookService {
+	return &WebhookService{
+		webhookRepo:   webhookRepo,
+		equipmentRepo: equipmentRepo,
+		jobQueue:      jobQueue,
+		client:        egress.NewClient(cfg.Timeout),
+		cfg:           cfg,
+	}
+}
+
+// Subscribe fans domain events from the outbox bus out to subscriptions.
+func (s *WebhookService) Subscribe(bus *outbox.Bus) {
+	bus.Subscribe(s.fanOut)
+}
+
+// RegisterJobs sets the queue handlers for webhook jobs.
+func (s *WebhookService) RegisterJobs(q *queue.Queue) {
+	queue.Handle(q, JobDeliverWebhook, func(ctx context.Context, payload deliverWebhookPayload) error {
+		return s.deliver(ctx, payload.DeliveryID)
+	})
+}
+
+func (s *WebhookService) Create(ctx context.Context, userID uuid.UUID, req *model.CreateWebhookRequest) (*model.WebhookSubscription, error) {
+	sub := &model.WebhookSubscription{
+		UserID:      userID,
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+		Active:      true,
+	}
+
+	if err := validateWebhook(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	sub.Secret = "whsec_" + hex.EncodeToString(secret)
+
+	if err := s.webhookRepo.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+func (s *WebhookService) List(ctx context.Context, userID uuid.UUID) ([]*model.WebhookSubscription, error) {
+	subs, err := s.webhookRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		sub.Secret = ""
+	}
+	return subs, nil
+}
+
+func (s *WebhookService) Get(ctx context.Context, id, userID uuid.UUID) (*model.WebhookSubscription, error) {
+	sub, err := s.getOwned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+func (s *WebhookService) Update(ctx context.Context, id, userID uuid.UUID, req *model.UpdateWebhookRequest) (*model.WebhookSubscription, error) {
+	sub, err := s.getOwned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		sub.URL = *req.URL
+	}
+	if req.Description != nil {
+		sub.Description = *req.Description
+	}
+	if req.Events != nil {
+		sub.Events = req.Events
+	}
+	if req.Active != nil {
+		if *req.Active && !sub.Active {
+			sub.ConsecutiveFailures = 0
+			sub.DisabledAt = nil
+			sub.DisabledReason = ""
+		}
+		sub.Active = *req.Active
+	}
+
+	if err := validateWebhook(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	if err := s.webhookRepo.Update(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	sub.Secret = ""
+	return sub, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	if _, err := s.getOwned(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.webhookRepo.Delete(ctx, id)
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, id, userID uuid.UUID, pag pagination.Params) ([]*model.WebhookDelivery, int64, error) {
+	if _, err := s.getOwned(ctx, id, userID); err != nil {
+		return nil, 0, err
+	}
+	return s.webhookRepo.ListDeliveries(ctx, id, pag)
+}
+
+// SendTest sends a webhook.test event to the subscription straight away and
+// returns the logged delivery. It is not retried and does not count towards
+// disabling the subscription.
+func (s *WebhookService) SendTest(ctx context.Context, id, userID uuid.UUID) (*model.WebhookDelivery, error) {
+	sub, err := s.getOwned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(map[string]string{"message": "This is a test event"})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.logDelivery(ctx, sub, &model.DomainEvent{
+		ID:            uuid.New(),
+		Type:          model.EventWebhookTest,
+		AggregateType: "webhook",
+		AggregateID:   sub.ID,
+		Data:          data,
+		OccurredAt:    time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attempt(ctx, sub, delivery); err != nil {
+		delivery.Status = model.DeliveryFailed
+		if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+			return nil, err
+		}
+	}
+
+	return delivery, nil
+}
+
+// fanOut logs a delivery for each subscription that wants the event and
+// queues it. An event relayed again finds its deliveries already logged
+// and only queues the ones still pending.
+func (s *WebhookService) fanOut(ctx context.Context, event *model.DomainEvent) error {
+	userIDs, err := s.involvedUsers(ctx, event)
+	if err != nil || len(userIDs) == 0 {
+		return err
+	}
+
+	subs, err := s.webhookRepo.ListActiveForUsers(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		if !sub.Wants(event.Type) {
+			continue
+		}
+
+		delivery, err := s.logDelivery(ctx, sub, event)
+		if err != nil {
+			return err
+		}
+		if delivery.Status != model.DeliveryPending {
+			continue
+		}
+
+		err = s.jobQueue.Enqueue(ctx, JobDeliverWebhook, deliverWebhookPayload{DeliveryID: delivery.ID},
+			queue.UniqueKey(JobDeliverWebhook+":"+delivery.ID.String()),
+			queue.MaxAttempts(s.cfg.MaxAttempts))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// involvedUsers returns who may see an event: the renter and the equipment
+// owner of a reservation, or the owner of a listing.
+func (s *WebhookService) involvedUsers(ctx context.Context, event *model.DomainEvent) ([]uuid.UUID, error) {
+	switch event.AggregateType {
+	case "reservation":
+		var data model.ReservationEventData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, err
+		}
+
+		equipment, err := s.equipmentRepo.GetByID(ctx, data.EquipmentID)
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return []uuid.UUID{data.RenterID}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []uuid.UUID{data.RenterID, equipment.OwnerID}, nil
+
+	case "equipment":
+		var data struct {
+			OwnerID uuid.UUID `json:"owner_id"`
+		}
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, err
+		}
+		return []uuid.UUID{data.OwnerID}, nil
+	}
+
+	return nil, nil
+}
+
+func (s *WebhookService) logDelivery(ctx context.Context, sub *model.WebhookSubscription, event *model.DomainEvent) (*model.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &model.WebhookDelivery{
+		SubscriptionID: sub.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+	}
+	if err := s.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// deliver makes one attempt at a queued delivery. It returns an error only
+// when the delivery should be retried; the queue's backoff spaces the
+// retries out. Failures count against the subscription, which is disabled
+// and its owner notified after WebhookConfig.DisableAfter in a row.
+func (s *WebhookService) deliver(ctx context.Context, deliveryID uuid.UUID) error {
+	delivery, err := s.webhookRepo.GetDelivery(ctx, deliveryID)
+	if errors.Is(err, repository.ErrDeliveryNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delivery.Status != model.DeliveryPending {
+		return nil
+	}
+
+	sub, err := s.webhookRepo.GetByID(ctx, delivery.SubscriptionID)
+	if errors.Is(err, repository.ErrWebhookNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !sub.Active {
+		delivery.Status = model.DeliveryFailed
+		delivery.Error = "subscription is disabled"
+		return s.webhookRepo.UpdateDelivery(ctx, delivery)
+	}
+
+	deliveryErr := s.attempt(ctx, sub, delivery)
+	if deliveryErr == nil {
+		return s.webhookRepo.RecordSuccess(ctx, sub.ID)
+	}
+
+	reason := fmt.Sprintf("disabled after %d failed deliveries in a row", s.cfg.DisableAfter)
+	disabled, err := s.webhookRepo.RecordFailure(ctx, sub.ID, s.cfg.DisableAfter, reason)
+	if err != nil {
+		return err
+	}
+
+	if disabled {
+		notification := &model.Notification{
+			ID:            uuid.New(),
+			UserID:        sub.UserID,
+			Type:          model.NotificationWebhookDisabled,
+			Data:          map[string]interface{}{"url": sub.URL, "failures": s.cfg.DisableAfter},
+			ReferenceID:   &sub.ID,
+			ReferenceType: "webhook",
+			CreatedAt:     time.Now(),
+		}
+		if err := s.jobQueue.Enqueue(ctx, JobCreateNotification, notification); err != nil {
+			return err
+		}
+	}
+
+	if disabled || delivery.Attempts >= s.cfg.MaxAttempts {
+		delivery.Status = model.DeliveryFailed
+		return s.webhookRepo.UpdateDelivery(ctx, delivery)
+	}
+
+	return deliveryErr
+}
+
+// attempt POSTs the delivery's payload, signed with the subscription's
+// secret, and logs the outcome on the delivery. It returns why the attempt
+// failed, if it did.
+func (s *WebhookService) attempt(ctx context.Context, sub *model.WebhookSubscription, delivery *model.WebhookDelivery) error {
+	now := time.Now()
+
+	delivery.ResponseStatus = nil
+	delivery.ResponseBody = ""
+
+	deliveryErr := func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "goapi-webhooks/1.0")
+		req.Header.Set("X-Webhook-ID", delivery.ID.String())
+		req.Header.Set("X-Webhook-Event", string(delivery.EventType))
+		req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set("X-Webhook-Signature", signer.WebhookSignature(sub.Secret, now, delivery.Payload))
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		delivery.ResponseStatus = &resp.StatusCode
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+		}
+
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		delivery.ResponseBody = string(body)
+		return nil
+	}()
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.DurationMs = time.Since(now).Milliseconds()
+	delivery.Error = ""
+	if deliveryErr != nil {
+		delivery.Error = deliveryErr.Error()
+	} else {
+		delivery.Status = model.DeliverySucceeded
+		delivery.DeliveredAt = &now
+	}
+
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return errors.Join(deliveryErr, err)
+	}
+
+	return deliveryErr
+}
+
+func (s *WebhookService) getOwned(ctx context.Context, id, userID uuid.UUID) (*model.WebhookSubscription, error) {
+	sub, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	// Another user's subscription is reported as missing rather than
+	// forbidden, so IDs cannot be probed.
+	if sub.UserID != userID {
+		return nil, ErrWebhookNotFound
+	}
+
+	return sub, nil
+}
+
+func validateWebhook(ctx context.Context, sub *model.WebhookSubscription) error {
+	v := validator.New()
+
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.AddError("url", "must be an absolute http or https URL")
+	} else if err := egress.CheckHost(ctx, u.Hostname()); errors.Is(err, egress.ErrBlockedAddress) {
+		v.AddError("url", "must not point to a loopback, private or link-local address")
+	} else if err != nil {
+		v.AddError("url", "host could not be resolved")
+	}
+	v.MaxLength("description", sub.Description, 255)
+
+	if len(sub.Events) == 0 {
+		v.AddError("events", "at least one event type is required")
+	}
+	known := model.DomainEventTypes()
+	for _, e := range sub.Events {
+		if e != model.WebhookAllEvents && !slices.Contains(known, e) {
+			v.AddError("events", "unknown event type "+string(e))
+		}
+	}
+
+	if v.Errors().HasErrors() {
+		return v.Errors()
+	}
+
+	return nil
+}
@@ -11,11 +11,15 @@ import (
 	"github.com/abneribeiro/goapi/internal/config"
 	"github.com/abneribeiro/goapi/internal/database"
 	"github.com/abneribeiro/goapi/internal/model"
+	"github.com/abneribeiro/goapi/internal/pkg/money"
 	"github.com/abneribeiro/goapi/internal/repository"
 )
 
 func main() {
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
 
 	db, err := database.NewPostgresConnection(&cfg.Database)
 	if err != nil {
@@ -30,6 +34,7 @@ func main() {
 	ctx := context.Background()
 
 	userRepo := repository.NewUserRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
 	equipmentRepo := repository.NewEquipmentRepository(db)
 
 	password, _ := bcrypt.GenerateFromPassword([]byte("Password123"), bcrypt.DefaultCost)
@@ -73,13 +78,42 @@ func main() {
 		fmt.Printf("Created user: %s (ID: %s)\n", user.Email, user.ID)
 	}
 
-	priceHour := 15.0
-	priceDay := 50.0
-	priceWeek := 200.0
-	priceDay2 := 75.0
-	priceWeek2 := 350.0
-	priceDay3 := 100.0
-	priceDay4 := 30.0
+	categoriesData := []struct {
+		Name string
+		Slug string
+		Icon string
+	}{
+		{"Photography", "photography", "camera"},
+		{"Drones", "drones", "drone"},
+		{"Lighting", "lighting", "lightbulb"},
+		{"Computers", "computers", "laptop"},
+	}
+
+	categories := make(map[string]*model.Category)
+
+	fmt.Println("\nCreating/fetching categories...")
+	for _, data := range categoriesData {
+		if existing, err := categoryRepo.GetBySlug(ctx, data.Slug); err == nil {
+			categories[data.Name] = existing
+			continue
+		}
+
+		category := &model.Category{Name: data.Name, Slug: data.Slug, Icon: data.Icon}
+		if err := categoryRepo.Create(ctx, category); err != nil {
+			log.Fatalf("failed to create category %s: %v", data.Name, err)
+		}
+		categories[data.Name] = category
+		fmt.Printf("Created category: %s (ID: %s)\n", category.Name, category.ID)
+	}
+
+	priceHour := money.MustParse("15.00", money.DefaultCurrency)
+	priceDay := money.MustParse("50.00", money.DefaultCurrency)
+	priceWeek := money.MustParse("200.00", money.DefaultCurrency)
+	priceDay2 := money.MustParse("75.00", money.DefaultCurrency)
+	priceWeek2 := money.MustParse("350.00", money.DefaultCurrency)
+	priceDay3 := money.MustParse("100.00", money.DefaultCurrency)
+	priceDay4 := money.MustParse("30.00", money.DefaultCurrency)
+	droneDeposit := money.MustParse("500.00", money.DefaultCurrency)
 
 	equipmentList := []*model.Equipment{
 		{
@@ -94,14 +128,16 @@ func main() {
 			AutoApprove:  false,
 		},
 		{
-			OwnerID:      users[0].ID,
-			Name:         "DJI Mavic 3 Pro Drone",
-			Description:  "Professional drone with 4/3 CMOS sensor. Perfect for aerial photography and videography.",
-			Category:     "Drones",
-			PricePerDay:  &priceDay2,
-			PricePerWeek: &priceWeek2,
-			Location:     "New York, NY",
-			AutoApprove:  true,
+			OwnerID:            users[0].ID,
+			Name:               "DJI Mavic 3 Pro Drone",
+			Description:        "Professional drone with 4/3 CMOS sensor. Perfect for aerial photography and videography.",
+			Category:           "Drones",
+			PricePerDay:        &priceDay2,
+			PricePerWeek:       &priceWeek2,
+			DepositAmount:      &droneDeposit,
+			Location:           "New York, NY",
+			AutoApprove:        true,
+			CancellationPolicy: model.NewCancellationPolicy(model.CancellationModerate, nil),
 		},
 		{
 			OwnerID:      users[2].ID,
@@ -121,6 +157,7 @@ func main() {
 			PricePerDay:  &priceDay4,
 			Location:     "Los Angeles, CA",
 			AutoApprove:  true,
+			Quantity:     3,
 		},
 		{
 			OwnerID:      users[0].ID,
@@ -135,6 +172,7 @@ func main() {
 
 	fmt.Println("\nCreating equipment...")
 	for _, equipment := range equipmentList {
+		equipment.CategoryID = &categories[equipment.Category].ID
 		if err := equipmentRepo.Create(ctx, equipment); err != nil {
 			fmt.Printf("Warning: Could not create equipment %s: %v\n", equipment.Name, err)
 		} else {
@@ -151,7 +189,7 @@ func main() {
 			StartDate:   time.Now().AddDate(0, 0, 7),
 			EndDate:     time.Now().AddDate(0, 0, 10),
 			Status:      model.StatusApproved,
-			TotalPrice:  150.0,
+			TotalPrice:  money.MustParse("150.00", money.DefaultCurrency),
 		},
 		{
 			EquipmentID: equipmentList[1].ID,
@@ -159,7 +197,7 @@ func main() {
 			StartDate:   time.Now().AddDate(0, 0, 14),
 			EndDate:     time.Now().AddDate(0, 0, 16),
 			Status:      model.StatusPending,
-			TotalPrice:  150.0,
+			TotalPrice:  money.MustParse("150.00", money.DefaultCurrency),
 		},
 	}
 